@@ -63,9 +63,10 @@ func (coe *CollectionObjectExecutor) Execute(
 	request.evalQuerySelectionFields(coe.Request.Query.Fields)
 
 	options := &common.ListStorageObjectsOptions{
-		Prefix:     request.ObjectNamePredicate.GetPrefix(),
-		Include:    request.Include,
-		NumThreads: coe.Concurrency,
+		Prefix:         request.ObjectNamePredicate.GetPrefix(),
+		Include:        request.Include,
+		NumThreads:     coe.Concurrency,
+		OngoingRestore: request.OngoingRestore,
 	}
 
 	if err := request.EvalArguments(coe.Arguments); err != nil {
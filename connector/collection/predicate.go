@@ -17,6 +17,7 @@ type PredicateEvaluator struct {
 	IsValid           bool
 	Include           common.StorageObjectIncludeOptions
 	IncludeObjectLock bool
+	OngoingRestore    *bool
 
 	variables           map[string]any
 	BucketPredicate     StringFilterPredicate
@@ -299,6 +300,12 @@ func (pe *PredicateEvaluator) evalExpressionBinaryComparisonOperator(
 			}
 
 			return ok, nil
+		case StorageObjectColumnOngoingRestore:
+			if forBucket {
+				return false, errors.New("unsupported predicate on column " + column.Name)
+			}
+
+			return pe.evalPredicateOngoingRestore(expr)
 		default:
 			return false, errors.New("unsupported predicate on column " + column.Name)
 		}
@@ -344,6 +351,36 @@ func (pe *PredicateEvaluator) evalPredicateClientID(
 	}
 }
 
+func (pe *PredicateEvaluator) evalPredicateOngoingRestore(
+	expr *schema.ExpressionBinaryComparisonOperator,
+) (bool, error) {
+	switch expr.Operator {
+	case OperatorEqual:
+		value, err := getComparisonValueBoolean(expr.Value, pe.variables)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", StorageObjectColumnOngoingRestore, err)
+		}
+
+		if value == nil {
+			return true, nil
+		}
+
+		if pe.OngoingRestore != nil {
+			return *pe.OngoingRestore == *value, nil
+		}
+
+		pe.OngoingRestore = value
+
+		return true, nil
+	default:
+		return false, fmt.Errorf(
+			"unsupported operator `%s` for %s",
+			expr.Operator,
+			StorageObjectColumnOngoingRestore,
+		)
+	}
+}
+
 func (pe *PredicateEvaluator) evalStringFilter(
 	predicate *StringFilterPredicate,
 	expr *schema.ExpressionBinaryComparisonOperator,
@@ -1,18 +1,22 @@
 package collection
 
 import (
+	"slices"
+
 	"github.com/hasura/ndc-sdk-go/v2/schema"
 	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"github.com/hasura/ndc-storage/connector/storage/common"
 )
 
 const (
-	CollectionStorageObjects    = "storage_objects"
-	CollectionStorageBuckets    = "storage_buckets"
-	StorageObjectName           = "StorageObject"
-	StorageBucketName           = "StorageBucket"
-	StorageObjectColumnClientID = "client_id"
-	StorageObjectColumnBucket   = "bucket"
-	StorageObjectColumnName     = "name"
+	CollectionStorageObjects          = "storage_objects"
+	CollectionStorageBuckets          = "storage_buckets"
+	StorageObjectName                 = "StorageObject"
+	StorageBucketName                 = "StorageBucket"
+	StorageObjectColumnClientID       = "client_id"
+	StorageObjectColumnBucket         = "bucket"
+	StorageObjectColumnName           = "name"
+	StorageObjectColumnOngoingRestore = "ongoing_restore"
 )
 
 const (
@@ -28,6 +32,7 @@ const (
 	ScalarStorageClientID = "StorageClientID"
 	ScalarBucketName      = "StorageBucketName"
 	ScalarStringFilter    = "StorageStringFilter"
+	ScalarBooleanFilter   = "StorageBooleanFilter"
 )
 
 const (
@@ -37,8 +42,89 @@ const (
 	ArgumentEndpoint        = "endpoint"
 	ArgumentAccessKeyID     = "access_key_id"
 	ArgumentSecretAccessKey = "secret_access_key"
+	ArgumentSessionToken    = "session_token"
+	ArgumentServiceAccount  = "service_account_json"
+	ArgumentAccessToken     = "access_token"
+	ArgumentSASToken        = "sas_token"
+	ArgumentAccountURL      = "account_url"
 )
 
+var dynamicCredentialArgumentInfos = map[string]schema.ArgumentInfo{
+	ArgumentClientType: {
+		Description: utils.ToPtr("The cloud storage provider type"),
+		Type:        schema.NewNullableType(schema.NewNamedType("StorageProviderType")).Encode(),
+	},
+	ArgumentEndpoint: {
+		Description: utils.ToPtr("Endpoint of the cloud storage service"),
+		Type:        schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+	},
+	ArgumentAccessKeyID: {
+		Description: utils.ToPtr("Access key ID or Account name credential"),
+		Type:        schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+	},
+	ArgumentSecretAccessKey: {
+		Description: utils.ToPtr("Secret Access key ID or Account key credential"),
+		Type:        schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+	},
+	ArgumentSessionToken: {
+		Description: utils.ToPtr("Session token of temporary S3 credentials"),
+		Type:        schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+	},
+	ArgumentServiceAccount: {
+		Description: utils.ToPtr("Service account JSON credentials of Google Cloud Storage"),
+		Type:        schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+	},
+	ArgumentAccessToken: {
+		Description: utils.ToPtr("OAuth2 access token of Google Cloud Storage"),
+		Type:        schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+	},
+	ArgumentSASToken: {
+		Description: utils.ToPtr("Shared access signature token of Azure Blob Storage"),
+		Type:        schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+	},
+	ArgumentAccountURL: {
+		Description: utils.ToPtr("Service URL of the Azure Blob Storage account"),
+		Type:        schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+	},
+}
+
+var commonDynamicCredentialArguments = []string{
+	ArgumentClientType,
+	ArgumentEndpoint,
+	ArgumentAccessKeyID,
+	ArgumentSecretAccessKey,
+}
+
+var providerDynamicCredentialArguments = map[common.StorageProviderType][]string{
+	common.StorageProviderTypeS3:     {ArgumentSessionToken},
+	common.StorageProviderTypeGcs:    {ArgumentServiceAccount, ArgumentAccessToken},
+	common.StorageProviderTypeAzblob: {ArgumentSASToken, ArgumentAccountURL},
+}
+
+// GetDynamicCredentialArguments returns names of dynamic credential arguments of the storage providers.
+// Arguments of all providers are returned if the provider list is empty.
+func GetDynamicCredentialArguments(providers []common.StorageProviderType) []string {
+	if len(providers) == 0 {
+		providers = []common.StorageProviderType{
+			common.StorageProviderTypeS3,
+			common.StorageProviderTypeGcs,
+			common.StorageProviderTypeAzblob,
+		}
+	}
+
+	results := slices.Clone(commonDynamicCredentialArguments)
+
+	for _, provider := range providers {
+		for _, name := range providerDynamicCredentialArguments[provider] {
+			if !slices.Contains(results, name) {
+				results = append(results, name)
+			}
+		}
+	}
+
+	return results
+}
+
 var checksumColumnNames = []string{
 	"checksum_crc32",
 	"checksum_crc32c",
@@ -54,7 +140,13 @@ type StringComparisonOperator struct {
 }
 
 // GetConnectorSchema returns connector schema for object collections.
-func GetConnectorSchema(clientIDs []string, dynamicCredentials bool) *schema.SchemaResponse {
+// The dynamic credential arguments are added if the list of providers is not nil.
+// An empty list enables arguments of all providers.
+func GetConnectorSchema(
+	clientIDs []string,
+	dynamicCredentialProviders []common.StorageProviderType,
+) *schema.SchemaResponse {
+	dynamicCredentials := dynamicCredentialProviders != nil
 	storageObjectArguments := buildDynamicCredentialArguments(schema.CollectionInfoArguments{
 		argumentAfter: {
 			Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
@@ -62,7 +154,7 @@ func GetConnectorSchema(clientIDs []string, dynamicCredentials bool) *schema.Sch
 		argumentRecursive: {
 			Type: schema.NewNullableType(schema.NewNamedType("Boolean")).Encode(),
 		},
-	}, dynamicCredentials)
+	}, dynamicCredentialProviders)
 
 	if dynamicCredentials {
 		storageObjectArguments[StorageObjectColumnBucket] = schema.ArgumentInfo{
@@ -87,7 +179,7 @@ func GetConnectorSchema(clientIDs []string, dynamicCredentials bool) *schema.Sch
 					argumentAfter: {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
-				}, dynamicCredentials),
+				}, dynamicCredentialProviders),
 				UniquenessConstraints: schema.CollectionInfoUniquenessConstraints{},
 			},
 		},
@@ -113,10 +205,20 @@ func GetConnectorSchema(clientIDs []string, dynamicCredentials bool) *schema.Sch
 					StorageObjectColumnName: schema.ObjectField{
 						Type: schema.NewNamedType(ScalarStringFilter).Encode(),
 					},
+					StorageObjectColumnOngoingRestore: schema.ObjectField{
+						Type: schema.NewNamedType(ScalarBooleanFilter).Encode(),
+					},
 				},
 			},
 		},
 		ScalarTypes: schema.SchemaResponseScalarTypes{
+			ScalarBooleanFilter: schema.ScalarType{
+				AggregateFunctions: schema.ScalarTypeAggregateFunctions{},
+				ComparisonOperators: map[string]schema.ComparisonOperatorDefinition{
+					OperatorEqual: schema.NewComparisonOperatorEqual().Encode(),
+				},
+				Representation: schema.NewTypeRepresentationBoolean().Encode(),
+			},
 			ScalarBucketName: schema.ScalarType{
 				AggregateFunctions: schema.ScalarTypeAggregateFunctions{},
 				ComparisonOperators: map[string]schema.ComparisonOperatorDefinition{
@@ -150,30 +252,16 @@ func GetConnectorSchema(clientIDs []string, dynamicCredentials bool) *schema.Sch
 
 func buildDynamicCredentialArguments(
 	arguments map[string]schema.ArgumentInfo,
-	dynamicCredentials bool,
+	providers []common.StorageProviderType,
 ) map[string]schema.ArgumentInfo {
-	if !dynamicCredentials {
+	if providers == nil {
 		return arguments
 	}
 
-	results := map[string]schema.ArgumentInfo{
-		ArgumentClientType: {
-			Description: utils.ToPtr("The cloud storage provider type"),
-			Type: schema.NewNullableType(schema.NewNamedType("StorageProviderType")).
-				Encode(),
-		},
-		ArgumentEndpoint: {
-			Description: utils.ToPtr("Endpoint of the cloud storage service"),
-			Type:        schema.NewNullableType(schema.NewNamedType("String")).Encode(),
-		},
-		ArgumentAccessKeyID: {
-			Description: utils.ToPtr("Access key ID or Account name credential"),
-			Type:        schema.NewNullableType(schema.NewNamedType("String")).Encode(),
-		},
-		ArgumentSecretAccessKey: {
-			Description: utils.ToPtr("Secret Access key ID or Account key credential"),
-			Type:        schema.NewNullableType(schema.NewNamedType("String")).Encode(),
-		},
+	results := map[string]schema.ArgumentInfo{}
+
+	for _, name := range GetDynamicCredentialArguments(providers) {
+		results[name] = dynamicCredentialArgumentInfos[name]
 	}
 
 	for key, arg := range arguments {
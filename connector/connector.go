@@ -9,6 +9,8 @@ import (
 	"os"
 	"path/filepath"
 	"slices"
+	"sync"
+	"sync/atomic"
 
 	"github.com/hasura/ndc-sdk-go/v2/connector"
 	"github.com/hasura/ndc-sdk-go/v2/schema"
@@ -17,15 +19,24 @@ import (
 	"github.com/hasura/ndc-storage/connector/functions"
 	"github.com/hasura/ndc-storage/connector/storage"
 	"github.com/hasura/ndc-storage/connector/types"
+	"go.opentelemetry.io/otel/metric"
 	"go.yaml.in/yaml/v4"
 )
 
 // Connector implements the SDK interface of NDC specification.
+// The configuration, schema and capabilities are swapped atomically when the configuration file is reloaded.
 type Connector struct {
-	capabilities *schema.RawCapabilitiesResponse
-	rawSchema    *schema.RawSchemaResponse
-	config       *types.Configuration
+	capabilities atomic.Pointer[schema.RawCapabilitiesResponse]
+	rawSchema    atomic.Pointer[schema.RawSchemaResponse]
+	config       atomic.Pointer[types.Configuration]
 	apiHandler   functions.DataConnectorHandler
+
+	configurationDir  string
+	configHash        string
+	reloadLock        sync.Mutex
+	stopConfigWatcher context.CancelFunc
+	// storage managers that are replaced by reloads and are shutting down.
+	retiredManagers sync.WaitGroup
 }
 
 // ParseConfiguration validates the configuration files provided by the user, returning a validated 'Configuration',
@@ -34,22 +45,46 @@ func (c *Connector) ParseConfiguration(
 	ctx context.Context,
 	configurationDir string,
 ) (*types.Configuration, error) {
+	config, configHash, err := readConfiguration(configurationDir)
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities, err := buildCapabilities(config)
+	if err != nil {
+		return nil, err
+	}
+
+	c.configurationDir = configurationDir
+	c.configHash = configHash
+	c.config.Store(config)
+	c.capabilities.Store(capabilities)
+	c.apiHandler = functions.DataConnectorHandler{}
+
+	return config, nil
+}
+
+// readConfiguration reads and decodes the configuration file in the directory.
+// The hash of the file content is returned to detect changes.
+func readConfiguration(configurationDir string) (*types.Configuration, string, error) {
 	configBytes, err := os.ReadFile(filepath.Join(configurationDir, types.ConfigurationFileName))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read configuration: %w", err)
+		return nil, "", fmt.Errorf("failed to read configuration: %w", err)
 	}
 
 	var config types.Configuration
 	if err := yaml.Unmarshal(configBytes, &config); err != nil {
-		return nil, fmt.Errorf("failed to decode configuration: %w", err)
+		return nil, "", fmt.Errorf("failed to decode configuration: %w", err)
 	}
 
 	if !config.Generator.DynamicCredentials && len(config.Clients) == 0 {
-		return nil, errors.New("failed to initialize storage clients: config is empty")
+		return nil, "", errors.New("failed to initialize storage clients: config is empty")
 	}
 
-	c.config = &config
+	return &config, hashConfiguration(configBytes), nil
+}
 
+func buildCapabilities(config *types.Configuration) (*schema.RawCapabilitiesResponse, error) {
 	connectorCapabilities := schema.CapabilitiesResponse{
 		Version: schema.NDCVersion,
 		Capabilities: schema.Capabilities{
@@ -60,15 +95,16 @@ func (c *Connector) ParseConfiguration(
 		},
 	}
 
+	if config.Transaction.Enabled {
+		connectorCapabilities.Capabilities.Mutation.Transactional = &schema.LeafCapability{}
+	}
+
 	rawCapabilities, err := json.Marshal(connectorCapabilities)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode capabilities: %w", err)
 	}
 
-	c.capabilities = schema.NewRawCapabilitiesResponseUnsafe(rawCapabilities)
-	c.apiHandler = functions.DataConnectorHandler{}
-
-	return &config, nil
+	return schema.NewRawCapabilitiesResponseUnsafe(rawCapabilities), nil
 }
 
 // TryInitState initializes the connector's in-memory state.
@@ -84,37 +120,82 @@ func (c *Connector) TryInitState(
 	metrics *connector.TelemetryState,
 ) (*types.State, error) {
 	logger := connector.GetLogger(ctx)
+	config := c.getConfig()
+
+	manager, err := newStorageManager(ctx, config, metrics, logger)
+	if err != nil {
+		return nil, err
+	}
 
-	manager, err := storage.NewManager(ctx, configuration.Clients, configuration.Runtime, logger)
+	rawSchema, err := buildSchema(config, manager)
 	if err != nil {
 		return nil, err
 	}
 
+	c.rawSchema.Store(rawSchema)
+
+	state := types.NewState(metrics, manager, config.Concurrency)
+	c.watchConfiguration(state, logger)
+
+	return state, nil
+}
+
+func newStorageManager(
+	ctx context.Context,
+	config *types.Configuration,
+	telemetry *connector.TelemetryState,
+	logger *slog.Logger,
+) (*storage.Manager, error) {
+	var meter metric.Meter
+
+	if telemetry != nil {
+		meter = telemetry.Meter
+	}
+
+	manager, err := storage.NewManager(
+		ctx,
+		config.Clients,
+		config.Runtime,
+		config.Policy,
+		config.Audit,
+		meter,
+		logger,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Transaction.Enabled {
+		manager.SetTransactionSnapshotPrefix(config.Transaction.SnapshotPrefix)
+	}
+
+	return manager, nil
+}
+
+// buildSchema generates the connector schema from the configuration and client IDs of the storage manager.
+func buildSchema(
+	config *types.Configuration,
+	manager *storage.Manager,
+) (*schema.RawSchemaResponse, error) {
 	connectorSchema, errs := utils.MergeSchemas(
 		GetConnectorSchema(),
 		collection.GetConnectorSchema(
 			manager.GetClientIDs(),
-			c.config.Generator.DynamicCredentials,
+			config.Generator.GetDynamicCredentialProviders(),
 		),
 	)
 	for _, err := range errs {
 		slog.Debug(err.Error())
 	}
 
-	c.evalSchema(connectorSchema)
+	evalSchema(config, connectorSchema)
 
 	schemaBytes, err := json.Marshal(connectorSchema)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode schema: %w", err)
 	}
 
-	c.rawSchema = schema.NewRawSchemaResponseUnsafe(schemaBytes)
-
-	return &types.State{
-		Storage:        manager,
-		TelemetryState: metrics,
-		Concurrency:    c.config.Concurrency,
-	}, nil
+	return schema.NewRawSchemaResponseUnsafe(schemaBytes), nil
 }
 
 // HealthCheck checks the health of the connector.
@@ -128,14 +209,14 @@ func (c *Connector) HealthCheck(
 	configuration *types.Configuration,
 	state *types.State,
 ) error {
-	return nil
+	return state.Storage().HealthCheck(ctx)
 }
 
 // GetCapabilities get the connector's capabilities.
 func (c *Connector) GetCapabilities(
 	configuration *types.Configuration,
 ) schema.CapabilitiesResponseMarshaler {
-	return c.capabilities
+	return c.capabilities.Load()
 }
 
 // GetSchema gets the connector's schema.
@@ -144,10 +225,15 @@ func (c *Connector) GetSchema(
 	configuration *types.Configuration,
 	_ *types.State,
 ) (schema.SchemaResponseMarshaler, error) {
-	return c.rawSchema, nil
+	return c.rawSchema.Load(), nil
+}
+
+// getConfig returns the current configuration.
+func (c *Connector) getConfig() *types.Configuration {
+	return c.config.Load()
 }
 
-func (c *Connector) evalSchema(connectorSchema *schema.SchemaResponse) {
+func evalSchema(config *types.Configuration, connectorSchema *schema.SchemaResponse) {
 	// override field types of the StorageObject object
 	objectClientID := connectorSchema.ObjectTypes[collection.StorageObjectName].Fields[collection.StorageObjectColumnClientID]
 	objectClientID.Type = schema.NewNamedType(collection.ScalarStorageClientID).Encode()
@@ -170,15 +256,10 @@ func (c *Connector) evalSchema(connectorSchema *schema.SchemaResponse) {
 	bucketNameField.Type = schema.NewNamedType(collection.ScalarStringFilter).Encode()
 	connectorSchema.ObjectTypes[collection.StorageBucketName].Fields[collection.StorageObjectColumnName] = bucketNameField
 
-	dynamicCredentialArguments := []string{
-		collection.ArgumentClientType,
-		collection.ArgumentEndpoint,
-		collection.ArgumentAccessKeyID,
-		collection.ArgumentSecretAccessKey,
-	}
+	dynamicCredentialArguments := getExcludedCredentialArguments(config)
 
 	for i, f := range connectorSchema.Functions {
-		if c.config.Generator.PromptQLCompatible &&
+		if config.Generator.PromptQLCompatible &&
 			!slices.Contains(
 				[]string{"storageBucketConnections", "storageObjectConnections"},
 				f.Name,
@@ -187,14 +268,12 @@ func (c *Connector) evalSchema(connectorSchema *schema.SchemaResponse) {
 			delete(f.Arguments, "where")
 		}
 
-		if !c.config.Generator.DynamicCredentials {
-			// delete dynamic credential arguments
-			for _, key := range dynamicCredentialArguments {
-				delete(f.Arguments, key)
-			}
+		// delete disabled dynamic credential arguments
+		for _, key := range dynamicCredentialArguments {
+			delete(f.Arguments, key)
 		}
 
-		if len(c.config.Clients) == 0 {
+		if len(config.Clients) == 0 {
 			delete(f.Arguments, collection.StorageObjectColumnClientID)
 		}
 
@@ -204,21 +283,21 @@ func (c *Connector) evalSchema(connectorSchema *schema.SchemaResponse) {
 	procedures := []schema.ProcedureInfo{}
 
 	for _, f := range connectorSchema.Procedures {
-		if !c.config.Generator.DynamicCredentials {
-			// delete dynamic credential arguments
-			for _, key := range dynamicCredentialArguments {
-				delete(f.Arguments, key)
-			}
-		} else if len(c.config.Clients) == 0 && slices.Contains([]string{"composeStorageObject", "copyStorageObject"}, f.Name) {
+		// delete disabled dynamic credential arguments
+		for _, key := range dynamicCredentialArguments {
+			delete(f.Arguments, key)
+		}
+
+		if config.Generator.DynamicCredentials && len(config.Clients) == 0 && slices.Contains([]string{"composeStorageObject", "copyStorageObject"}, f.Name) {
 			continue
 		}
 
-		if c.config.Generator.PromptQLCompatible {
+		if config.Generator.PromptQLCompatible {
 			// remove boolean expression arguments in commands
 			delete(f.Arguments, "where")
 		}
 
-		if len(c.config.Clients) == 0 {
+		if len(config.Clients) == 0 {
 			delete(f.Arguments, collection.StorageObjectColumnClientID)
 		}
 
@@ -227,14 +306,18 @@ func (c *Connector) evalSchema(connectorSchema *schema.SchemaResponse) {
 
 	connectorSchema.Procedures = procedures
 
-	if c.config.Generator.PromptQLCompatible {
+	if sessionArgument := getSessionArgument(config); sessionArgument != "" {
+		evalSessionArgument(connectorSchema, sessionArgument)
+	}
+
+	if config.Generator.PromptQLCompatible {
 		// PromptQL doesn't support bytes representation.
 		bytesScalar := schema.NewScalarType()
 		bytesScalar.Representation = schema.NewTypeRepresentationString().Encode()
 		connectorSchema.ScalarTypes["Bytes"] = *bytesScalar
 	}
 
-	if !c.config.Generator.DynamicCredentials {
+	if len(dynamicCredentialArguments) > 0 {
 		for name, object := range connectorSchema.ObjectTypes {
 			for _, key := range dynamicCredentialArguments {
 				delete(object.Fields, key)
@@ -245,7 +328,127 @@ func (c *Connector) evalSchema(connectorSchema *schema.SchemaResponse) {
 	}
 }
 
+// getExcludedCredentialArguments returns dynamic credential arguments which are removed from the schema.
+// All arguments are removed if dynamic credentials are disabled.
+// Otherwise, only arguments of storage providers that aren't enabled are removed.
+func getExcludedCredentialArguments(config *types.Configuration) []string {
+	allArguments := collection.GetDynamicCredentialArguments(nil)
+
+	providers := config.Generator.GetDynamicCredentialProviders()
+	if providers == nil {
+		return allArguments
+	}
+
+	enabledArguments := collection.GetDynamicCredentialArguments(providers)
+
+	return slices.DeleteFunc(allArguments, func(name string) bool {
+		return slices.Contains(enabledArguments, name)
+	})
+}
+
+// getSessionArgument returns the name of the session argument of the current configuration.
+func (c *Connector) getSessionArgument() string {
+	return getSessionArgument(c.getConfig())
+}
+
+// getSessionArgument returns the name of the session argument if access policies or audit logs are enabled.
+// The result is empty if the session argument isn't used.
+func getSessionArgument(config *types.Configuration) string {
+	policyEnabled := config.Policy != nil && config.Policy.Enabled
+	if !policyEnabled && !config.Audit.IsEnabled() {
+		return ""
+	}
+
+	if config.Policy != nil {
+		return config.Policy.GetSessionArgument()
+	}
+
+	return storage.DefaultPolicySessionArgument
+}
+
+// evalSessionArgument adds the session argument to all commands and collections
+// so the engine can forward session variables or headers for access policies and audit logs.
+func evalSessionArgument(
+	connectorSchema *schema.SchemaResponse,
+	argumentName string,
+) {
+	sessionArgument := schema.ArgumentInfo{
+		Description: utils.ToPtr(
+			"Session variables or forwarded headers that are evaluated by access policies or recorded in audit logs",
+		),
+		Type: schema.NewNullableType(schema.NewNamedType("JSON")).Encode(),
+	}
+
+	for i, f := range connectorSchema.Functions {
+		f.Arguments[argumentName] = sessionArgument
+		connectorSchema.Functions[i] = f
+	}
+
+	for i, p := range connectorSchema.Procedures {
+		p.Arguments[argumentName] = sessionArgument
+		connectorSchema.Procedures[i] = p
+	}
+
+	for i, col := range connectorSchema.Collections {
+		col.Arguments[argumentName] = sessionArgument
+		connectorSchema.Collections[i] = col
+	}
+}
+
+// withPolicySession decodes session variables from the session argument into the context
+// if access policies or audit logs are enabled.
+// Session variables don't authorize requests if the session secret isn't configured.
+func (c *Connector) withPolicySession(
+	ctx context.Context,
+	rawArgs map[string]any,
+) (context.Context, error) {
+	sessionArgument := c.getSessionArgument()
+	if sessionArgument == "" {
+		return ctx, nil
+	}
+
+	session, err := storage.ParseSession(rawArgs[sessionArgument])
+	if err != nil {
+		return nil, schema.UnprocessableContentError("failed to decode session variables", map[string]any{
+			"cause": err.Error(),
+		})
+	}
+
+	policy := c.getConfig().Policy
+	// without the session secret, session variables are only recorded as the unverified identity of audit events.
+	if !policy.HasSessionSecret() {
+		delete(session, storage.PolicySessionSecretKey)
+
+		return storage.ContextWithUnverifiedSession(ctx, session), nil
+	}
+
+	// the session argument is trusted only if the engine sends the shared secret.
+	session, err = policy.VerifySession(session)
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.ContextWithSession(ctx, session), nil
+}
+
 // Close handles the graceful shutdown that cleans up the connector's state.
+// In-flight uploads are drained before storage clients are closed.
 func (c *Connector) Close(state *types.State) error {
-	return nil
+	if c.stopConfigWatcher != nil {
+		c.stopConfigWatcher()
+	}
+
+	// wait until the in-progress reload, if any, finishes.
+	c.reloadLock.Lock()
+	defer c.reloadLock.Unlock()
+
+	var err error
+
+	if state != nil && state.Storage() != nil {
+		err = state.Storage().Close(context.Background())
+	}
+
+	c.retiredManagers.Wait()
+
+	return err
 }
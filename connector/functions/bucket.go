@@ -17,7 +17,7 @@ func ProcedureCreateStorageBucket(
 	state *types.State,
 	args *common.MakeStorageBucketArguments,
 ) (SuccessResponse, error) {
-	err := state.Storage.MakeBucket(ctx, args.ClientID, &args.MakeStorageBucketOptions)
+	err := state.Storage().MakeBucket(ctx, args.ClientID, &args.MakeStorageBucketOptions)
 	if err != nil {
 		return SuccessResponse{}, err
 	}
@@ -68,7 +68,7 @@ func FunctionStorageBucketConnections(
 
 	bucketArguments := request.GetBucketArguments()
 
-	buckets, err := state.Storage.ListBuckets(
+	buckets, err := state.Storage().ListBuckets(
 		ctx,
 		bucketArguments.StorageClientCredentialArguments,
 		&common.ListStorageBucketsOptions{
@@ -82,7 +82,7 @@ func FunctionStorageBucketConnections(
 				Encryption: request.Include.Encryption,
 				ObjectLock: request.IncludeObjectLock,
 			},
-			NumThreads: state.Concurrency.Query,
+			NumThreads: state.Concurrency().Query,
 		},
 		predicate,
 	)
@@ -132,7 +132,7 @@ func FunctionStorageBucket(
 		return nil, err
 	}
 
-	return state.Storage.GetBucket(ctx, args.ToStorageBucketArguments(), common.BucketOptions{
+	return state.Storage().GetBucket(ctx, args.ToStorageBucketArguments(), common.BucketOptions{
 		Include: common.BucketIncludeOptions{
 			Tags:       request.Include.Tags,
 			Versioning: request.Include.Versions,
@@ -140,7 +140,7 @@ func FunctionStorageBucket(
 			Encryption: request.Include.Encryption,
 			ObjectLock: request.IncludeObjectLock,
 		},
-		NumThreads: state.Concurrency.Query,
+		NumThreads: state.Concurrency().Query,
 	})
 }
 
@@ -167,7 +167,7 @@ func FunctionStorageBucketExists(
 		return ExistsResponse{}, nil
 	}
 
-	exists, err := state.Storage.BucketExists(ctx, args.ToStorageBucketArguments())
+	exists, err := state.Storage().BucketExists(ctx, args.ToStorageBucketArguments())
 	if err != nil {
 		return ExistsResponse{}, err
 	}
@@ -198,7 +198,7 @@ func ProcedureRemoveStorageBucket(
 		return SuccessResponse{}, errors.New("permission denied")
 	}
 
-	if err := state.Storage.RemoveBucket(ctx, args.ToStorageBucketArguments()); err != nil {
+	if err := state.Storage().RemoveBucket(ctx, args.ToStorageBucketArguments()); err != nil {
 		return SuccessResponse{}, err
 	}
 
@@ -228,7 +228,7 @@ func ProcedureUpdateStorageBucket(
 		return SuccessResponse{}, errors.New("permission denied")
 	}
 
-	if err := state.Storage.UpdateBucket(ctx, args); err != nil {
+	if err := state.Storage().UpdateBucket(ctx, args); err != nil {
 		return SuccessResponse{}, err
 	}
 
@@ -12,6 +12,7 @@ import (
 	"github.com/hasura/ndc-storage/connector/storage/common"
 	"github.com/hasura/ndc-storage/connector/storage/common/encoding"
 	"github.com/hasura/ndc-storage/connector/types"
+	"golang.org/x/sync/errgroup"
 )
 
 // FunctionStorageObjectConnections lists objects in a bucket using the relay style.
@@ -37,7 +38,7 @@ func FunctionStorageObjectConnections(
 		predicate = nil
 	}
 
-	objects, err := state.Storage.ListObjects(ctx, request.GetBucketArguments(), options, predicate)
+	objects, err := state.Storage().ListObjects(ctx, request.GetBucketArguments(), options, predicate)
 	if err != nil {
 		return StorageConnection[common.StorageObject]{}, err
 	}
@@ -80,7 +81,7 @@ func FunctionStorageDeletedObjects(
 		predicate = nil
 	}
 
-	objects, err := state.Storage.ListDeletedObjects(
+	objects, err := state.Storage().ListDeletedObjects(
 		ctx,
 		request.GetBucketArguments(),
 		options,
@@ -123,7 +124,7 @@ func FunctionStorageObject(
 	opts := args.GetStorageObjectOptions
 	opts.Include = request.Include
 
-	return state.Storage.StatObject(
+	return state.Storage().StatObject(
 		ctx,
 		request.GetBucketArguments(),
 		request.ObjectNamePredicate.GetPrefix(),
@@ -299,7 +300,7 @@ func downloadStorageObject(
 		return nil, nil, nil
 	}
 
-	return state.Storage.GetObject(
+	return state.Storage().GetObject(
 		ctx,
 		request.GetBucketArguments(),
 		request.ObjectNamePredicate.GetPrefix(),
@@ -333,7 +334,7 @@ func FunctionStoragePresignedDownloadUrl(
 		return nil, nil
 	}
 
-	return state.Storage.PresignedGetObject(
+	return state.Storage().PresignedGetObject(
 		ctx,
 		request.GetBucketArguments(),
 		request.ObjectNamePredicate.GetPrefix(),
@@ -367,7 +368,7 @@ func FunctionStoragePresignedUploadUrl(
 		return nil, nil
 	}
 
-	return state.Storage.PresignedPutObject(
+	return state.Storage().PresignedPutObject(
 		ctx,
 		request.GetBucketArguments(),
 		request.ObjectNamePredicate.GetPrefix(),
@@ -381,7 +382,7 @@ func FunctionStorageIncompleteUploads(
 	state *types.State,
 	args *common.ListIncompleteUploadsArguments,
 ) ([]common.StorageObjectMultipartInfo, error) {
-	return state.Storage.ListIncompleteUploads(
+	return state.Storage().ListIncompleteUploads(
 		ctx,
 		args.StorageBucketArguments,
 		args.ListIncompleteUploadsOptions,
@@ -421,7 +422,7 @@ func uploadStorageObject(
 		return common.StorageUploadInfo{}, schema.ForbiddenError("permission denied", nil)
 	}
 
-	result, err := state.Storage.PutObject(
+	result, err := state.Storage().PutObject(
 		ctx,
 		request.GetBucketArguments(),
 		request.ObjectNamePredicate.GetPrefix(),
@@ -450,35 +451,99 @@ func ProcedureUploadStorageObjectFromURL(
 	state *types.State,
 	args *common.UploadStorageObjectFromURLArguments,
 ) (common.StorageUploadInfo, error) {
-	request, err := collection.EvalObjectPredicate(
+	result, err := uploadStorageObjectFromURL(
+		ctx,
+		state,
 		args.StorageBucketArguments,
+		args.Name,
+		args.Where,
+		&args.HTTPRequestOptions,
+		&args.Options,
+	)
+	if err != nil {
+		return common.StorageUploadInfo{}, err
+	}
+
+	return *result, nil
+}
+
+// ProcedureUploadStorageObjectsFromURLs uploads many objects from remote files that are downloaded from HTTP URLs.
+// Items are uploaded concurrently. A failed item doesn't fail the whole batch, the error is returned in the item result instead.
+func ProcedureUploadStorageObjectsFromURLs(
+	ctx context.Context,
+	state *types.State,
+	args *common.UploadStorageObjectsFromURLsArguments,
+) ([]common.UploadStorageObjectFromURLResult, error) {
+	results := make([]common.UploadStorageObjectFromURLResult, len(args.Items))
+
+	eg := errgroup.Group{}
+	eg.SetLimit(state.Concurrency().GetUpload())
+
+	for i, item := range args.Items {
+		eg.Go(func() error {
+			results[i] = common.UploadStorageObjectFromURLResult{
+				Name: item.Name,
+				URL:  item.URL,
+			}
+
+			object, err := uploadStorageObjectFromURL(
+				ctx,
+				state,
+				args.StorageBucketArguments,
+				item.Name,
+				args.Where,
+				&item.HTTPRequestOptions,
+				&item.Options,
+			)
+			if err != nil {
+				errMsg := err.Error()
+				results[i].Error = &errMsg
+			} else {
+				results[i].Object = object
+			}
+
+			return nil
+		})
+	}
+
+	_ = eg.Wait()
+
+	return results, nil
+}
+
+func uploadStorageObjectFromURL(
+	ctx context.Context,
+	state *types.State,
+	bucketArguments common.StorageBucketArguments,
+	name string,
+	where schema.Expression,
+	httpRequest *common.HTTPRequestOptions,
+	opts *common.PutStorageObjectOptions,
+) (*common.StorageUploadInfo, error) {
+	request, err := collection.EvalObjectPredicate(
+		bucketArguments,
 		&collection.StringComparisonOperator{
-			Value:    args.Name,
+			Value:    name,
 			Operator: collection.OperatorEqual,
 		},
-		args.Where,
+		where,
 		types.QueryVariablesFromContext(ctx),
 	)
 	if err != nil {
-		return common.StorageUploadInfo{}, err
+		return nil, err
 	}
 
 	if !request.IsValid {
-		return common.StorageUploadInfo{}, schema.ForbiddenError("permission denied", nil)
+		return nil, schema.ForbiddenError("permission denied", nil)
 	}
 
-	result, err := state.Storage.UploadObjectFromURL(
+	return state.Storage().UploadObjectFromURL(
 		ctx,
 		request.GetBucketArguments(),
 		request.ObjectNamePredicate.GetPrefix(),
-		&args.HTTPRequestOptions,
-		&args.Options,
+		httpRequest,
+		opts,
 	)
-	if err != nil {
-		return common.StorageUploadInfo{}, err
-	}
-
-	return *result, nil
 }
 
 // ProcedureCopyStorageObject creates or replaces an object through server-side copying of an existing object.
@@ -489,7 +554,7 @@ func ProcedureCopyStorageObject(
 	state *types.State,
 	args *common.CopyStorageObjectArguments,
 ) (common.StorageUploadInfo, error) {
-	result, err := state.Storage.CopyObject(ctx, args)
+	result, err := state.Storage().CopyObject(ctx, args)
 	if err != nil {
 		return common.StorageUploadInfo{}, err
 	}
@@ -503,7 +568,7 @@ func ProcedureComposeStorageObject(
 	state *types.State,
 	args *common.ComposeStorageObjectArguments,
 ) (common.StorageUploadInfo, error) {
-	result, err := state.Storage.ComposeObject(ctx, args)
+	result, err := state.Storage().ComposeObject(ctx, args)
 	if err != nil {
 		return common.StorageUploadInfo{}, err
 	}
@@ -534,7 +599,7 @@ func ProcedureUpdateStorageObject(
 		return SuccessResponse{}, errPermissionDenied
 	}
 
-	if err := state.Storage.UpdateObject(ctx, request.GetBucketArguments(), request.ObjectNamePredicate.GetPrefix(), args.UpdateStorageObjectOptions); err != nil {
+	if err := state.Storage().UpdateObject(ctx, request.GetBucketArguments(), request.ObjectNamePredicate.GetPrefix(), args.UpdateStorageObjectOptions); err != nil {
 		return SuccessResponse{}, err
 	}
 
@@ -564,7 +629,7 @@ func ProcedureRemoveStorageObject(
 		return SuccessResponse{}, errPermissionDenied
 	}
 
-	if err := state.Storage.RemoveObject(ctx, request.GetBucketArguments(), request.ObjectNamePredicate.GetPrefix(), args.RemoveStorageObjectOptions); err != nil {
+	if err := state.Storage().RemoveObject(ctx, request.GetBucketArguments(), request.ObjectNamePredicate.GetPrefix(), args.RemoveStorageObjectOptions); err != nil {
 		return SuccessResponse{}, err
 	}
 
@@ -597,7 +662,7 @@ func ProcedureRemoveStorageObjects(
 		predicate = nil
 	}
 
-	return state.Storage.RemoveObjects(
+	return state.Storage().RemoveObjects(
 		ctx,
 		request.GetBucketArguments(),
 		&common.RemoveStorageObjectsOptions{
@@ -614,7 +679,7 @@ func ProcedureRemoveIncompleteStorageUpload(
 	state *types.State,
 	args *common.RemoveIncompleteUploadArguments,
 ) (SuccessResponse, error) {
-	err := state.Storage.RemoveIncompleteUpload(ctx, args)
+	err := state.Storage().RemoveIncompleteUpload(ctx, args)
 	if err != nil {
 		return SuccessResponse{}, err
 	}
@@ -645,7 +710,50 @@ func ProcedureRestoreStorageObject(
 		return SuccessResponse{}, errPermissionDenied
 	}
 
-	if err := state.Storage.RestoreObject(ctx, request.GetBucketArguments(), request.ObjectNamePredicate.GetPrefix()); err != nil {
+	if err := state.Storage().RestoreObject(ctx, request.GetBucketArguments(), request.ObjectNamePredicate.GetPrefix()); err != nil {
+		return SuccessResponse{}, err
+	}
+
+	return NewSuccessResponse(), nil
+}
+
+// ProcedureRestoreArchivedStorageObject restores an object from the archive storage tier, e.g. S3 Glacier or Azure Archive.
+func ProcedureRestoreArchivedStorageObject(
+	ctx context.Context,
+	state *types.State,
+	args *common.RestoreArchivedStorageObjectArguments,
+) (SuccessResponse, error) {
+	request, err := collection.EvalObjectPredicate(
+		args.StorageBucketArguments,
+		&collection.StringComparisonOperator{
+			Value:    args.Name,
+			Operator: collection.OperatorEqual,
+		},
+		args.Where,
+		types.QueryVariablesFromContext(ctx),
+	)
+	if err != nil {
+		return SuccessResponse{}, err
+	}
+
+	if !request.IsValid {
+		return SuccessResponse{}, errPermissionDenied
+	}
+
+	if err := state.Storage().RestoreArchivedObject(ctx, request.GetBucketArguments(), request.ObjectNamePredicate.GetPrefix(), args.RestoreArchivedStorageObjectOptions); err != nil {
+		return SuccessResponse{}, err
+	}
+
+	return NewSuccessResponse(), nil
+}
+
+// ProcedurePurgeStorageObjectCache removes cached contents and metadata of objects from caches of storage clients.
+func ProcedurePurgeStorageObjectCache(
+	ctx context.Context,
+	state *types.State,
+	args *common.PurgeStorageObjectCacheArguments,
+) (SuccessResponse, error) {
+	if err := state.Storage().PurgeCache(ctx, args.ClientID, args.Bucket, args.Prefix); err != nil {
 		return SuccessResponse{}, err
 	}
 
@@ -686,10 +794,11 @@ func evalStorageObjectsArguments(
 	}
 
 	options := &common.ListStorageObjectsOptions{
-		Prefix:     request.ObjectNamePredicate.GetPrefix(),
-		Recursive:  args.Recursive,
-		Include:    request.Include,
-		NumThreads: state.Concurrency.Query,
+		Prefix:         request.ObjectNamePredicate.GetPrefix(),
+		Recursive:      args.Recursive,
+		Include:        request.Include,
+		NumThreads:     state.Concurrency().Query,
+		OngoingRestore: request.OngoingRestore,
 	}
 
 	if args.First != nil {
@@ -669,6 +669,37 @@ func (dch DataConnectorHandler) Mutation(ctx context.Context, state *types.State
 		}
 		return schema.NewProcedureResult(result).Encode(), nil
 
+	case "purge_storage_object_cache":
+
+		selection, err := operation.Fields.AsObject()
+		if err != nil {
+			return nil, schema.UnprocessableContentError("the selection field type must be object", map[string]any{
+				"cause": err.Error(),
+			})
+		}
+		var args common.PurgeStorageObjectCacheArguments
+		if err := json.Unmarshal(operation.Arguments, &args); err != nil {
+			return nil, schema.UnprocessableContentError("failed to decode arguments", map[string]any{
+				"cause": err.Error(),
+			})
+		}
+		span.AddEvent("execute_procedure")
+		rawResult, err := ProcedurePurgeStorageObjectCache(ctx, state, &args)
+
+		if err != nil {
+			return nil, err
+		}
+
+		connector_addSpanEvent(span, logger, "evaluate_response_selection", map[string]any{
+			"raw_result": rawResult,
+		})
+		result, err := utils.EvalNestedColumnObject(selection, rawResult)
+
+		if err != nil {
+			return nil, err
+		}
+		return schema.NewProcedureResult(result).Encode(), nil
+
 	case "remove_incomplete_storage_upload":
 
 		selection, err := operation.Fields.AsObject()
@@ -793,6 +824,37 @@ func (dch DataConnectorHandler) Mutation(ctx context.Context, state *types.State
 		}
 		return schema.NewProcedureResult(result).Encode(), nil
 
+	case "restore_archived_storage_object":
+
+		selection, err := operation.Fields.AsObject()
+		if err != nil {
+			return nil, schema.UnprocessableContentError("the selection field type must be object", map[string]any{
+				"cause": err.Error(),
+			})
+		}
+		var args common.RestoreArchivedStorageObjectArguments
+		if err := json.Unmarshal(operation.Arguments, &args); err != nil {
+			return nil, schema.UnprocessableContentError("failed to decode arguments", map[string]any{
+				"cause": err.Error(),
+			})
+		}
+		span.AddEvent("execute_procedure")
+		rawResult, err := ProcedureRestoreArchivedStorageObject(ctx, state, &args)
+
+		if err != nil {
+			return nil, err
+		}
+
+		connector_addSpanEvent(span, logger, "evaluate_response_selection", map[string]any{
+			"raw_result": rawResult,
+		})
+		result, err := utils.EvalNestedColumnObject(selection, rawResult)
+
+		if err != nil {
+			return nil, err
+		}
+		return schema.NewProcedureResult(result).Encode(), nil
+
 	case "restore_storage_object":
 
 		selection, err := operation.Fields.AsObject()
@@ -979,12 +1041,43 @@ func (dch DataConnectorHandler) Mutation(ctx context.Context, state *types.State
 		}
 		return schema.NewProcedureResult(result).Encode(), nil
 
+	case "upload_storage_objects_from_urls":
+
+		selection, err := operation.Fields.AsArray()
+		if err != nil {
+			return nil, schema.UnprocessableContentError("the selection field type must be array", map[string]any{
+				"cause": err.Error(),
+			})
+		}
+		var args common.UploadStorageObjectsFromURLsArguments
+		if err := json.Unmarshal(operation.Arguments, &args); err != nil {
+			return nil, schema.UnprocessableContentError("failed to decode arguments", map[string]any{
+				"cause": err.Error(),
+			})
+		}
+		span.AddEvent("execute_procedure")
+		rawResult, err := ProcedureUploadStorageObjectsFromURLs(ctx, state, &args)
+
+		if err != nil {
+			return nil, err
+		}
+
+		connector_addSpanEvent(span, logger, "evaluate_response_selection", map[string]any{
+			"raw_result": rawResult,
+		})
+		result, err := utils.EvalNestedColumnArrayIntoSlice(selection, rawResult)
+
+		if err != nil {
+			return nil, err
+		}
+		return schema.NewProcedureResult(result).Encode(), nil
+
 	default:
 		return nil, utils.ErrHandlerNotfound
 	}
 }
 
-var enumValues_ProcedureName = []string{"compose_storage_object", "copy_storage_object", "create_storage_bucket", "remove_incomplete_storage_upload", "remove_storage_bucket", "remove_storage_object", "remove_storage_objects", "restore_storage_object", "update_storage_bucket", "update_storage_object", "upload_storage_object_as_base64", "upload_storage_object_as_text", "upload_storage_object_from_url"}
+var enumValues_ProcedureName = []string{"compose_storage_object", "copy_storage_object", "create_storage_bucket", "purge_storage_object_cache", "remove_incomplete_storage_upload", "remove_storage_bucket", "remove_storage_object", "remove_storage_objects", "restore_archived_storage_object", "restore_storage_object", "update_storage_bucket", "update_storage_object", "upload_storage_object_as_base64", "upload_storage_object_as_text", "upload_storage_object_from_url", "upload_storage_objects_from_urls"}
 
 func connector_addSpanEvent(span trace.Span, logger *slog.Logger, name string, data map[string]any, options ...trace.EventOption) {
 	logger.Debug(name, slog.Any("data", data))
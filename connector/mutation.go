@@ -2,15 +2,32 @@ package connector
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"slices"
 
+	"github.com/hasura/ndc-sdk-go/v2/connector"
 	"github.com/hasura/ndc-sdk-go/v2/schema"
+	"github.com/hasura/ndc-storage/connector/storage"
 	"github.com/hasura/ndc-storage/connector/types"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 )
 
+// procedures whose changes can be reverted in transactional mutations.
+var transactionalProcedures = []string{
+	"compose_storage_object",
+	"copy_storage_object",
+	"remove_storage_object",
+	"update_storage_object",
+	"upload_storage_object_as_base64",
+	"upload_storage_object_as_text",
+	"upload_storage_object_from_url",
+}
+
 // MutationExplain explains a mutation by creating an execution plan.
 func (c *Connector) MutationExplain(
 	ctx context.Context,
@@ -28,7 +45,16 @@ func (c *Connector) Mutation(
 	state *types.State,
 	request *schema.MutationRequest,
 ) (*schema.MutationResponse, error) {
-	concurrencyLimit := c.config.Concurrency.Mutation
+	// clients that are evicted or reloaded during the request are closed after the request finishes.
+	ctx, release := storage.ContextWithClientLeases(ctx)
+	defer release()
+
+	config := c.getConfig()
+	if config.Transaction.Enabled && len(request.Operations) > 1 {
+		return c.execMutationTransaction(ctx, state, request)
+	}
+
+	concurrencyLimit := config.Concurrency.Mutation
 	if len(request.Operations) <= 1 || concurrencyLimit <= 1 {
 		return c.execMutationSync(ctx, state, request)
 	}
@@ -57,6 +83,69 @@ func (c *Connector) execMutationSync(
 	}, nil
 }
 
+// execMutationTransaction executes operations sequentially in a transaction.
+// If an operation fails, changes of previous operations are reverted in the reverse order.
+func (c *Connector) execMutationTransaction(
+	ctx context.Context,
+	state *types.State,
+	request *schema.MutationRequest,
+) (*schema.MutationResponse, error) {
+	for _, operation := range request.Operations {
+		if !slices.Contains(transactionalProcedures, operation.Name) {
+			return nil, schema.UnprocessableContentError(
+				fmt.Sprintf("procedure %s doesn't support transactional mutations", operation.Name),
+				nil,
+			)
+		}
+	}
+
+	ctx, span := state.Tracer.Start(ctx, "Execute Transaction")
+	defer span.End()
+
+	tx := storage.NewTransaction(c.getConfig().Transaction.SnapshotPrefix)
+	span.SetAttributes(attribute.String("storage.transaction_id", tx.ID()))
+
+	response, err := c.execMutationSync(storage.ContextWithTransaction(ctx, tx), state, request)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to execute the transaction")
+		span.RecordError(err)
+
+		// the request context may be canceled. Rollback in a detached context.
+		if rbErr := tx.Rollback(context.WithoutCancel(ctx)); rbErr != nil {
+			span.AddEvent("rollback_error", trace.WithAttributes(
+				attribute.String("error", rbErr.Error()),
+			))
+			connector.GetLogger(ctx).Error(
+				"failed to rollback the transaction",
+				slog.String("transaction_id", tx.ID()),
+				slog.String("snapshot_prefix", tx.SnapshotPrefix()),
+				slog.String("error", rbErr.Error()),
+			)
+
+			return nil, schema.InternalServerError(err.Error(), map[string]any{
+				"transaction_id":  tx.ID(),
+				"snapshot_prefix": tx.SnapshotPrefix(),
+				"rollback_error":  rbErr.Error(),
+			})
+		}
+
+		return nil, err
+	}
+
+	if err := tx.Commit(context.WithoutCancel(ctx)); err != nil {
+		span.AddEvent("commit_cleanup_error", trace.WithAttributes(
+			attribute.String("error", err.Error()),
+		))
+		connector.GetLogger(ctx).Warn(
+			"failed to clean up transaction snapshots",
+			slog.String("transaction_id", tx.ID()),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	return response, nil
+}
+
 func (c *Connector) execMutationAsync(
 	ctx context.Context,
 	state *types.State,
@@ -64,7 +153,7 @@ func (c *Connector) execMutationAsync(
 ) (*schema.MutationResponse, error) {
 	operationResults := make([]schema.MutationOperationResults, len(request.Operations))
 	eg, ctx := errgroup.WithContext(ctx)
-	eg.SetLimit(c.config.Concurrency.Mutation)
+	eg.SetLimit(c.getConfig().Concurrency.Mutation)
 
 	for i, operation := range request.Operations {
 		func(index int, op schema.MutationOperation) {
@@ -107,6 +196,14 @@ func (c *Connector) execMutation(
 
 	switch operation.Type {
 	case schema.MutationOperationProcedure:
+		ctx, err := c.withPolicySessionFromRawArguments(ctx, operation.Arguments)
+		if err != nil {
+			span.SetStatus(codes.Error, "failed to decode session variables")
+			span.RecordError(err)
+
+			return nil, err
+		}
+
 		result, err := c.execProcedure(ctx, state, &operation)
 		if err != nil {
 			span.SetStatus(codes.Error, fmt.Sprintf("failed to execute procedure %d", index))
@@ -124,6 +221,27 @@ func (c *Connector) execMutation(
 	}
 }
 
+func (c *Connector) withPolicySessionFromRawArguments(
+	ctx context.Context,
+	rawArguments json.RawMessage,
+) (context.Context, error) {
+	if c.getSessionArgument() == "" {
+		return ctx, nil
+	}
+
+	var rawArgs map[string]any
+
+	if len(rawArguments) > 0 {
+		if err := json.Unmarshal(rawArguments, &rawArgs); err != nil {
+			return nil, schema.UnprocessableContentError("failed to decode arguments", map[string]any{
+				"cause": err.Error(),
+			})
+		}
+	}
+
+	return c.withPolicySession(ctx, rawArgs)
+}
+
 func (c *Connector) execProcedure(
 	ctx context.Context,
 	state *types.State,
@@ -7,6 +7,7 @@ import (
 	"github.com/hasura/ndc-sdk-go/v2/schema"
 	"github.com/hasura/ndc-sdk-go/v2/utils"
 	"github.com/hasura/ndc-storage/connector/collection"
+	"github.com/hasura/ndc-storage/connector/storage"
 	"github.com/hasura/ndc-storage/connector/types"
 	"go.opentelemetry.io/otel/codes"
 	"golang.org/x/sync/errgroup"
@@ -29,12 +30,16 @@ func (c *Connector) Query(
 	state *types.State,
 	request *schema.QueryRequest,
 ) (schema.QueryResponse, error) {
+	// clients that are evicted or reloaded during the request are closed after the request finishes.
+	ctx, release := storage.ContextWithClientLeases(ctx)
+	defer release()
+
 	requestVars := request.Variables
 	if len(requestVars) == 0 {
 		requestVars = []schema.QueryRequestVariablesElem{make(schema.QueryRequestVariablesElem)}
 	}
 
-	concurrencyLimit := c.config.Concurrency.Query
+	concurrencyLimit := c.getConfig().Concurrency.Query
 	if concurrencyLimit <= 1 || len(request.Variables) <= 1 {
 		return c.execQuerySync(ctx, state, request, requestVars)
 	}
@@ -70,7 +75,7 @@ func (c *Connector) execQueryAsync(
 ) (schema.QueryResponse, error) {
 	rowSets := make([]schema.RowSet, len(requestVars))
 	eg, ctx := errgroup.WithContext(ctx)
-	eg.SetLimit(c.config.Concurrency.Query)
+	eg.SetLimit(c.getConfig().Concurrency.Query)
 
 	for i, requestVar := range requestVars {
 		func(index int, vars schema.QueryRequestVariablesElem) {
@@ -118,25 +123,33 @@ func (c *Connector) execQuery(
 		)
 	}
 
+	ctx, err = c.withPolicySession(ctx, rawArgs)
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to decode session variables")
+		span.RecordError(err)
+
+		return nil, err
+	}
+
 	var result *schema.RowSet
 
 	switch request.Collection {
 	case collection.CollectionStorageBuckets:
 		executor := collection.CollectionBucketExecutor{
-			Storage:     state.Storage,
+			Storage:     state.Storage(),
 			Request:     request,
 			Arguments:   rawArgs,
 			Variables:   variables,
-			Concurrency: c.config.Concurrency.Query,
+			Concurrency: c.getConfig().Concurrency.Query,
 		}
 		result, err = executor.Execute(ctx)
 	case collection.CollectionStorageObjects:
 		executor := collection.CollectionObjectExecutor{
-			Storage:     state.Storage,
+			Storage:     state.Storage(),
 			Request:     request,
 			Arguments:   rawArgs,
 			Variables:   variables,
-			Concurrency: c.config.Concurrency.Query,
+			Concurrency: c.getConfig().Concurrency.Query,
 		}
 		result, err = executor.Execute(ctx)
 	default:
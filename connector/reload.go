@@ -0,0 +1,140 @@
+package connector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hasura/ndc-storage/connector/types"
+)
+
+// watchConfiguration reloads the configuration file periodically or when the SIGHUP signal is received.
+func (c *Connector) watchConfiguration(state *types.State, logger *slog.Logger) {
+	reloadSettings := c.getConfig().Reload
+	if !reloadSettings.Enabled || c.configurationDir == "" {
+		return
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	c.stopConfigWatcher = stop
+
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(signalChan)
+
+		var tickerChan <-chan time.Time
+
+		if interval := reloadSettings.GetInterval(); interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			tickerChan = ticker.C
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signalChan:
+				logger.Info("received SIGHUP, reloading the configuration")
+				c.reloadConfiguration(ctx, state, logger, true)
+			case <-tickerChan:
+				c.reloadConfiguration(ctx, state, logger, false)
+			}
+		}
+	}()
+}
+
+// reloadConfiguration reads the configuration file and swaps the configuration, schema and storage manager atomically.
+// In-flight requests keep using the old storage manager. The current configuration is kept if the new one is invalid.
+func (c *Connector) reloadConfiguration(
+	ctx context.Context,
+	state *types.State,
+	logger *slog.Logger,
+	force bool,
+) {
+	c.reloadLock.Lock()
+	defer c.reloadLock.Unlock()
+
+	if err := c.applyConfiguration(ctx, state, logger, force); err != nil {
+		logger.Error(
+			"failed to reload the configuration. Keep using the current configuration",
+			"error", err,
+		)
+	}
+}
+
+func (c *Connector) applyConfiguration(
+	ctx context.Context,
+	state *types.State,
+	logger *slog.Logger,
+	force bool,
+) error {
+	config, configHash, err := readConfiguration(c.configurationDir)
+	if err != nil {
+		return err
+	}
+
+	if !force && configHash == c.configHash {
+		return nil
+	}
+
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	capabilities, err := buildCapabilities(config)
+	if err != nil {
+		return err
+	}
+
+	manager, err := newStorageManager(ctx, config, state.TelemetryState, logger)
+	if err != nil {
+		return err
+	}
+
+	rawSchema, err := buildSchema(config, manager)
+	if err != nil {
+		_ = manager.Close(ctx)
+
+		return err
+	}
+
+	c.configHash = configHash
+	c.config.Store(config)
+	c.capabilities.Store(capabilities)
+	c.rawSchema.Store(rawSchema)
+
+	oldManager := state.Reload(manager, config.Concurrency)
+
+	logger.Info("reloaded the configuration", "client_ids", manager.GetClientIDs())
+
+	if oldManager != nil {
+		// the previous manager drains its in-flight uploads in the background.
+		c.retiredManagers.Add(1)
+
+		go func() {
+			defer c.retiredManagers.Done()
+
+			if err := oldManager.Close(context.WithoutCancel(ctx)); err != nil {
+				logger.Warn("failed to close the previous storage manager", "error", err)
+			}
+		}()
+	}
+
+	return nil
+}
+
+func hashConfiguration(content []byte) string {
+	hash := sha256.Sum256(content)
+
+	return hex.EncodeToString(hash[:])
+}
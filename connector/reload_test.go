@@ -0,0 +1,105 @@
+package connector
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hasura/ndc-storage/connector/types"
+	"gotest.tools/v3/assert"
+)
+
+func writeReloadTestConfig(t *testing.T, configDir string, content string) {
+	t.Helper()
+
+	assert.NilError(t, os.WriteFile(filepath.Join(configDir, types.ConfigurationFileName), []byte(content), 0o644))
+}
+
+func TestReloadConfiguration(t *testing.T) {
+	configDir := t.TempDir()
+	dataDir := t.TempDir()
+
+	writeReloadTestConfig(t, configDir, `
+clients:
+  - id: fs
+    type: fs
+    defaultDirectory:
+      value: `+dataDir+`
+runtime:
+  maxDownloadSizeMBs: 10
+reload:
+  enabled: true
+  interval: 0
+`)
+
+	c := &Connector{}
+	config, err := c.ParseConfiguration(context.TODO(), configDir)
+	assert.NilError(t, err)
+
+	state, err := c.TryInitState(context.TODO(), config, nil)
+	assert.NilError(t, err)
+
+	defer func() {
+		assert.NilError(t, c.Close(state))
+	}()
+
+	assert.DeepEqual(t, state.Storage().GetClientIDs(), []string{"fs"})
+
+	oldManager := state.Storage()
+	oldSchema := c.rawSchema.Load()
+
+	// unchanged configuration files are skipped.
+	assert.NilError(t, c.applyConfiguration(context.TODO(), state, slog.Default(), false))
+	assert.Equal(t, state.Storage(), oldManager)
+	assert.Equal(t, c.rawSchema.Load(), oldSchema)
+
+	writeReloadTestConfig(t, configDir, `
+clients:
+  - id: fs
+    type: fs
+    defaultDirectory:
+      value: `+dataDir+`
+  - id: fs2
+    type: fs
+    defaultDirectory:
+      value: `+dataDir+`
+runtime:
+  maxDownloadSizeMBs: 10
+concurrency:
+  query: 2
+  mutation: 3
+reload:
+  enabled: true
+`)
+
+	assert.NilError(t, c.applyConfiguration(context.TODO(), state, slog.Default(), false))
+	assert.Assert(t, state.Storage() != oldManager)
+	assert.Assert(t, c.rawSchema.Load() != oldSchema)
+	assert.DeepEqual(t, state.Storage().GetClientIDs(), []string{"fs", "fs2"})
+	assert.DeepEqual(t, state.Concurrency(), types.ConcurrencySettings{Query: 2, Mutation: 3})
+	assert.Equal(t, len(c.getConfig().Clients), 2)
+
+	currentManager := state.Storage()
+	currentSchema := c.rawSchema.Load()
+
+	writeReloadTestConfig(t, configDir, `
+clients:
+  - id: fs
+    type: fs
+    defaultDirectory:
+      value: `+dataDir+`
+runtime:
+  maxDownloadSizeMBs: 0
+`)
+
+	assert.ErrorContains(
+		t,
+		c.applyConfiguration(context.TODO(), state, slog.Default(), false),
+		"maxDownloadSizeMBs must be larger than 0",
+	)
+	assert.Equal(t, state.Storage(), currentManager)
+	assert.Equal(t, c.rawSchema.Load(), currentSchema)
+	assert.Equal(t, len(c.getConfig().Clients), 2)
+}
@@ -146,6 +146,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"client_id": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("StorageClientID")).Encode(),
 					},
@@ -158,9 +164,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"name": schema.ObjectField{
 						Type: schema.NewNamedType("String").Encode(),
 					},
+					"sas_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"where": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewPredicateType("StorageBucketFilter")).Encode(),
 					},
@@ -172,6 +187,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -196,9 +217,21 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"request_params": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewArrayType(schema.NewNamedType("StorageKeyValue"))).Encode(),
 					},
+					"sas_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"server_side_encryption": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("ServerSideEncryption")).Encode(),
+					},
+					"service_account_json": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"version_id": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -219,16 +252,33 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"request_params": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewArrayType(schema.NewNamedType("StorageKeyValue"))).Encode(),
 					},
+					"server_side_encryption": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("ServerSideEncryption")).Encode(),
+					},
 					"version_id": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
 				},
 			},
+			"HTTPExpectedChecksum": schema.ObjectType{
+				Description: toPtr("represents the expected checksum of the downloaded content."),
+				Fields: schema.ObjectTypeFields{
+					"algorithm": schema.ObjectField{
+						Type: schema.NewNamedType("HTTPChecksumAlgorithm").Encode(),
+					},
+					"value": schema.ObjectField{
+						Type: schema.NewNamedType("String").Encode(),
+					},
+				},
+			},
 			"HTTPRequestOptions": schema.ObjectType{
 				Fields: schema.ObjectTypeFields{
 					"body_text": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"expected_checksum": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("HTTPExpectedChecksum")).Encode(),
+					},
 					"headers": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewArrayType(schema.NewNamedType("StorageKeyValue"))).Encode(),
 					},
@@ -254,6 +304,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"after": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -278,9 +334,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"recursive": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("Boolean")).Encode(),
 					},
+					"sas_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"where": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewPredicateType("StorageObjectFilter")).Encode(),
 					},
@@ -481,6 +546,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -499,9 +570,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"options": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("PutStorageObjectOptions")).Encode(),
 					},
+					"sas_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"where": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewPredicateType("StorageObjectFilter")).Encode(),
 					},
@@ -543,6 +623,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"expires": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("TimestampTZ")).Encode(),
 					},
+					"if_match": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"if_none_match": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"legal_hold": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("Boolean")).Encode(),
 					},
@@ -561,6 +647,9 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"send_content_md5": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("Boolean")).Encode(),
 					},
+					"server_side_encryption": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("ServerSideEncryption")).Encode(),
+					},
 					"storage_class": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -617,6 +706,20 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					},
 				},
 			},
+			"ServerSideEncryption": schema.ObjectType{
+				Description: toPtr("represents the server-side encryption options of an object."),
+				Fields: schema.ObjectTypeFields{
+					"key": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"key_id": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"method": schema.ObjectField{
+						Type: schema.NewNamedType("ServerSideEncryptionMethod").Encode(),
+					},
+				},
+			},
 			"ServerSideEncryptionConfiguration": schema.ObjectType{
 				Description: toPtr("is the default encryption configuration structure."),
 				Fields: schema.ObjectTypeFields{
@@ -736,6 +839,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -748,9 +857,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"endpoint": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"sas_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 				},
 			},
 			"StorageBucketVersioningConfiguration": schema.ObjectType{
@@ -775,6 +893,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"client_id": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("StorageClientID")).Encode(),
 					},
@@ -784,9 +908,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"endpoint": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"sas_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 				},
 			},
 			"StorageConnectionEdge_StorageBucket": schema.ObjectType{
@@ -837,6 +970,9 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"bucket": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"encryption": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("ServerSideEncryption")).Encode(),
+					},
 					"legal_hold": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("Boolean")).Encode(),
 					},
@@ -866,6 +1002,9 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"bucket": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"encryption": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("ServerSideEncryption")).Encode(),
+					},
 					"end": schema.ObjectField{
 						Type: schema.NewNullableType(schema.NewNamedType("Int64")).Encode(),
 					},
@@ -1355,6 +1494,49 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					},
 				},
 			},
+			"UploadStorageObjectFromURLItem": schema.ObjectType{
+				Description: toPtr("represents an item of the batch upload from URLs."),
+				Fields: schema.ObjectTypeFields{
+					"body_text": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"expected_checksum": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("HTTPExpectedChecksum")).Encode(),
+					},
+					"headers": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewArrayType(schema.NewNamedType("StorageKeyValue"))).Encode(),
+					},
+					"method": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("DownloadHTTPMethod")).Encode(),
+					},
+					"name": schema.ObjectField{
+						Type: schema.NewNamedType("String").Encode(),
+					},
+					"options": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("PutStorageObjectOptions")).Encode(),
+					},
+					"url": schema.ObjectField{
+						Type: schema.NewNamedType("String").Encode(),
+					},
+				},
+			},
+			"UploadStorageObjectFromURLResult": schema.ObjectType{
+				Description: toPtr("represents the upload result of an item in the batch upload from URLs."),
+				Fields: schema.ObjectTypeFields{
+					"error": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"name": schema.ObjectField{
+						Type: schema.NewNamedType("String").Encode(),
+					},
+					"object": schema.ObjectField{
+						Type: schema.NewNullableType(schema.NewNamedType("StorageUploadInfo")).Encode(),
+					},
+					"url": schema.ObjectField{
+						Type: schema.NewNamedType("String").Encode(),
+					},
+				},
+			},
 		},
 		Functions: []schema.FunctionInfo{
 			{
@@ -1365,6 +1547,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1389,9 +1577,21 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"request_params": {
 						Type: schema.NewNullableType(schema.NewArrayType(schema.NewNamedType("StorageKeyValue"))).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"server_side_encryption": {
+						Type: schema.NewNullableType(schema.NewNamedType("ServerSideEncryption")).Encode(),
+					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"version_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1408,6 +1608,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1435,9 +1641,21 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"request_params": {
 						Type: schema.NewNullableType(schema.NewArrayType(schema.NewNamedType("StorageKeyValue"))).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"server_side_encryption": {
+						Type: schema.NewNullableType(schema.NewNamedType("ServerSideEncryption")).Encode(),
+					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"version_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1454,6 +1672,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1478,9 +1702,21 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"request_params": {
 						Type: schema.NewNullableType(schema.NewArrayType(schema.NewNamedType("StorageKeyValue"))).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"server_side_encryption": {
+						Type: schema.NewNullableType(schema.NewNamedType("ServerSideEncryption")).Encode(),
+					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"version_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1497,6 +1733,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1521,9 +1763,21 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"request_params": {
 						Type: schema.NewNullableType(schema.NewArrayType(schema.NewNamedType("StorageKeyValue"))).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"server_side_encryption": {
+						Type: schema.NewNullableType(schema.NewNamedType("ServerSideEncryption")).Encode(),
+					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"version_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1540,6 +1794,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"client_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("StorageClientID")).Encode(),
 					},
@@ -1552,9 +1812,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"name": {
 						Type: schema.NewNamedType("String").Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"where": {
 						Type: schema.NewNullableType(schema.NewPredicateType("StorageBucketFilter")).Encode(),
 					},
@@ -1568,6 +1837,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"after": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1586,9 +1861,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"prefix": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"where": {
 						Type: schema.NewNullableType(schema.NewPredicateType("StorageBucketFilter")).Encode(),
 					},
@@ -1602,6 +1886,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"client_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("StorageClientID")).Encode(),
 					},
@@ -1614,9 +1904,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"name": {
 						Type: schema.NewNamedType("String").Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"where": {
 						Type: schema.NewNullableType(schema.NewPredicateType("StorageBucketFilter")).Encode(),
 					},
@@ -1630,6 +1929,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"after": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1654,9 +1959,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"recursive": {
 						Type: schema.NewNullableType(schema.NewNamedType("Boolean")).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"where": {
 						Type: schema.NewNullableType(schema.NewPredicateType("StorageObjectFilter")).Encode(),
 					},
@@ -1670,6 +1984,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1685,9 +2005,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"prefix": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 				},
 			},
 			{
@@ -1698,6 +2027,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1722,9 +2057,21 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"request_params": {
 						Type: schema.NewNullableType(schema.NewArrayType(schema.NewNamedType("StorageKeyValue"))).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"server_side_encryption": {
+						Type: schema.NewNullableType(schema.NewNamedType("ServerSideEncryption")).Encode(),
+					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"version_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1741,6 +2088,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"after": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1765,9 +2118,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"recursive": {
 						Type: schema.NewNullableType(schema.NewNamedType("Boolean")).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"where": {
 						Type: schema.NewNullableType(schema.NewPredicateType("StorageObjectFilter")).Encode(),
 					},
@@ -1781,6 +2143,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1802,9 +2170,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"request_params": {
 						Type: schema.NewNullableType(schema.NewArrayType(schema.NewNamedType("StorageKeyValue"))).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"where": {
 						Type: schema.NewNullableType(schema.NewPredicateType("StorageObjectFilter")).Encode(),
 					},
@@ -1818,6 +2195,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1836,9 +2219,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"name": {
 						Type: schema.NewNamedType("String").Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"where": {
 						Type: schema.NewNullableType(schema.NewPredicateType("StorageObjectFilter")).Encode(),
 					},
@@ -1886,6 +2278,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"client_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("StorageClientID")).Encode(),
 					},
@@ -1904,14 +2302,39 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"region": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"tags": {
 						Type: schema.NewNullableType(schema.NewArrayType(schema.NewNamedType("StorageKeyValue"))).Encode(),
 					},
 				},
 			},
+			{
+				Name:        "purge_storage_object_cache",
+				Description: toPtr("removes cached contents and metadata of objects from caches of storage clients."),
+				ResultType:  schema.NewNamedType("SuccessResponse").Encode(),
+				Arguments: map[string]schema.ArgumentInfo{
+					"bucket": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"client_id": {
+						Type: schema.NewNullableType(schema.NewNamedType("StorageClientID")).Encode(),
+					},
+					"prefix": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+				},
+			},
 			{
 				Name:        "remove_incomplete_storage_upload",
 				Description: toPtr("removes a partially uploaded object."),
@@ -1920,6 +2343,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1935,9 +2364,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"name": {
 						Type: schema.NewNamedType("String").Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 				},
 			},
 			{
@@ -1948,6 +2386,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"client_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("StorageClientID")).Encode(),
 					},
@@ -1960,9 +2404,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"name": {
 						Type: schema.NewNamedType("String").Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"where": {
 						Type: schema.NewNullableType(schema.NewPredicateType("StorageBucketFilter")).Encode(),
 					},
@@ -1976,6 +2429,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -1997,9 +2456,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"name": {
 						Type: schema.NewNamedType("String").Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"soft_delete": {
 						Type: schema.NewNullableType(schema.NewNamedType("Boolean")).Encode(),
 					},
@@ -2019,6 +2487,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"after": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -2046,9 +2520,79 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"recursive": {
 						Type: schema.NewNullableType(schema.NewNamedType("Boolean")).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"secret_access_key": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"where": {
+						Type: schema.NewNullableType(schema.NewPredicateType("StorageObjectFilter")).Encode(),
+					},
+				},
+			},
+			{
+				Name:        "restore_archived_storage_object",
+				Description: toPtr("restores an object from the archive storage tier, e.g. S3 Glacier or Azure Archive."),
+				ResultType:  schema.NewNamedType("SuccessResponse").Encode(),
+				Arguments: map[string]schema.ArgumentInfo{
+					"access_key_id": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"bucket": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"client_id": {
+						Type: schema.NewNullableType(schema.NewNamedType("StorageClientID")).Encode(),
+					},
+					"client_type": {
+						Type: schema.NewNullableType(schema.NewNamedType("StorageProviderType")).Encode(),
+					},
+					"days": {
+						Type: schema.NewNullableType(schema.NewNamedType("Int32")).Encode(),
+					},
+					"endpoint": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"name": {
+						Type: schema.NewNamedType("String").Encode(),
+					},
+					"priority": {
+						Type: schema.NewNullableType(schema.NewNamedType("StorageRehydratePriority")).Encode(),
+					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"storage_class": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"tier": {
+						Type: schema.NewNullableType(schema.NewNamedType("StorageRestoreTier")).Encode(),
+					},
+					"version_id": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"where": {
 						Type: schema.NewNullableType(schema.NewPredicateType("StorageObjectFilter")).Encode(),
 					},
@@ -2062,6 +2606,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -2077,9 +2627,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"name": {
 						Type: schema.NewNamedType("String").Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"where": {
 						Type: schema.NewNullableType(schema.NewPredicateType("StorageObjectFilter")).Encode(),
 					},
@@ -2093,6 +2652,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"client_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("StorageClientID")).Encode(),
 					},
@@ -2114,9 +2679,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"object_lock": {
 						Type: schema.NewNullableType(schema.NewNamedType("SetStorageObjectLockConfig")).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"tags": {
 						Type: schema.NewNullableType(schema.NewArrayType(schema.NewNamedType("StorageKeyValue"))).Encode(),
 					},
@@ -2136,6 +2710,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -2148,6 +2728,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"endpoint": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"if_match": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"if_none_match": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"legal_hold": {
 						Type: schema.NewNullableType(schema.NewNamedType("Boolean")).Encode(),
 					},
@@ -2160,9 +2746,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"retention": {
 						Type: schema.NewNullableType(schema.NewNamedType("SetStorageObjectRetentionOptions")).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"tags": {
 						Type: schema.NewNullableType(schema.NewArrayType(schema.NewNamedType("StorageKeyValue"))).Encode(),
 					},
@@ -2182,6 +2777,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -2203,9 +2804,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"options": {
 						Type: schema.NewNullableType(schema.NewNamedType("PutStorageObjectOptions")).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"where": {
 						Type: schema.NewNullableType(schema.NewPredicateType("StorageObjectFilter")).Encode(),
 					},
@@ -2219,6 +2829,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"bucket": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -2240,9 +2856,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"options": {
 						Type: schema.NewNullableType(schema.NewNamedType("PutStorageObjectOptions")).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"where": {
 						Type: schema.NewNullableType(schema.NewPredicateType("StorageObjectFilter")).Encode(),
 					},
@@ -2256,6 +2881,12 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"access_key_id": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"body_text": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
@@ -2271,6 +2902,9 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"endpoint": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"expected_checksum": {
+						Type: schema.NewNullableType(schema.NewNamedType("HTTPExpectedChecksum")).Encode(),
+					},
 					"headers": {
 						Type: schema.NewNullableType(schema.NewArrayType(schema.NewNamedType("StorageKeyValue"))).Encode(),
 					},
@@ -2283,9 +2917,18 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					"options": {
 						Type: schema.NewNullableType(schema.NewNamedType("PutStorageObjectOptions")).Encode(),
 					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"secret_access_key": {
 						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
 					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
 					"url": {
 						Type: schema.NewNamedType("String").Encode(),
 					},
@@ -2294,6 +2937,52 @@ func GetConnectorSchema() *schema.SchemaResponse {
 					},
 				},
 			},
+			{
+				Name:        "upload_storage_objects_from_urls",
+				Description: toPtr("uploads many objects from remote files that are downloaded from HTTP URLs. Items are uploaded concurrently. A failed item doesn't fail the whole batch, the error is returned in the item result instead."),
+				ResultType:  schema.NewArrayType(schema.NewNamedType("UploadStorageObjectFromURLResult")).Encode(),
+				Arguments: map[string]schema.ArgumentInfo{
+					"access_key_id": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"access_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"account_url": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"bucket": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"client_id": {
+						Type: schema.NewNullableType(schema.NewNamedType("StorageClientID")).Encode(),
+					},
+					"client_type": {
+						Type: schema.NewNullableType(schema.NewNamedType("StorageProviderType")).Encode(),
+					},
+					"endpoint": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"items": {
+						Type: schema.NewArrayType(schema.NewNamedType("UploadStorageObjectFromURLItem")).Encode(),
+					},
+					"sas_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"secret_access_key": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"service_account_json": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"session_token": {
+						Type: schema.NewNullableType(schema.NewNamedType("String")).Encode(),
+					},
+					"where": {
+						Type: schema.NewNullableType(schema.NewPredicateType("StorageObjectFilter")).Encode(),
+					},
+				},
+			},
 		},
 		ScalarTypes: schema.SchemaResponseScalarTypes{
 			"Boolean": schema.ScalarType{
@@ -2331,6 +3020,11 @@ func GetConnectorSchema() *schema.SchemaResponse {
 				ComparisonOperators: map[string]schema.ComparisonOperatorDefinition{},
 				Representation:      schema.NewTypeRepresentationEnum([]string{"DEFAULT", "ASYNC_TURBO"}).Encode(),
 			},
+			"HTTPChecksumAlgorithm": schema.ScalarType{
+				AggregateFunctions:  schema.ScalarTypeAggregateFunctions{},
+				ComparisonOperators: map[string]schema.ComparisonOperatorDefinition{},
+				Representation:      schema.NewTypeRepresentationEnum([]string{"MD5", "SHA1", "SHA256", "CRC32", "CRC32C"}).Encode(),
+			},
 			"Int32": schema.ScalarType{
 				AggregateFunctions:  schema.ScalarTypeAggregateFunctions{},
 				ComparisonOperators: map[string]schema.ComparisonOperatorDefinition{},
@@ -2346,6 +3040,11 @@ func GetConnectorSchema() *schema.SchemaResponse {
 				ComparisonOperators: map[string]schema.ComparisonOperatorDefinition{},
 				Representation:      schema.NewTypeRepresentationJSON().Encode(),
 			},
+			"ServerSideEncryptionMethod": schema.ScalarType{
+				AggregateFunctions:  schema.ScalarTypeAggregateFunctions{},
+				ComparisonOperators: map[string]schema.ComparisonOperatorDefinition{},
+				Representation:      schema.NewTypeRepresentationEnum([]string{"SSE_C", "KMS", "S3"}).Encode(),
+			},
 			"StorageClientID": schema.ScalarType{
 				AggregateFunctions:  schema.ScalarTypeAggregateFunctions{},
 				ComparisonOperators: map[string]schema.ComparisonOperatorDefinition{},
@@ -2361,6 +3060,16 @@ func GetConnectorSchema() *schema.SchemaResponse {
 				ComparisonOperators: map[string]schema.ComparisonOperatorDefinition{},
 				Representation:      schema.NewTypeRepresentationEnum([]string{"s3", "gcs", "azblob", "fs"}).Encode(),
 			},
+			"StorageRehydratePriority": schema.ScalarType{
+				AggregateFunctions:  schema.ScalarTypeAggregateFunctions{},
+				ComparisonOperators: map[string]schema.ComparisonOperatorDefinition{},
+				Representation:      schema.NewTypeRepresentationEnum([]string{"Standard", "High"}).Encode(),
+			},
+			"StorageRestoreTier": schema.ScalarType{
+				AggregateFunctions:  schema.ScalarTypeAggregateFunctions{},
+				ComparisonOperators: map[string]schema.ComparisonOperatorDefinition{},
+				Representation:      schema.NewTypeRepresentationEnum([]string{"Standard", "Bulk", "Expedited"}).Encode(),
+			},
 			"StorageRetentionMode": schema.ScalarType{
 				AggregateFunctions:  schema.ScalarTypeAggregateFunctions{},
 				ComparisonOperators: map[string]schema.ComparisonOperatorDefinition{},
@@ -0,0 +1,368 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"github.com/invopop/jsonschema"
+)
+
+// AuditOperation represents the type of audited operations.
+type AuditOperation string
+
+const (
+	AuditOperationCreateBucket           AuditOperation = "createBucket"
+	AuditOperationUpdateBucket           AuditOperation = "updateBucket"
+	AuditOperationRemoveBucket           AuditOperation = "removeBucket"
+	AuditOperationUploadObject           AuditOperation = "uploadObject"
+	AuditOperationUploadObjectFromURL    AuditOperation = "uploadObjectFromUrl"
+	AuditOperationCopyObject             AuditOperation = "copyObject"
+	AuditOperationComposeObject          AuditOperation = "composeObject"
+	AuditOperationUpdateObject           AuditOperation = "updateObject"
+	AuditOperationRemoveObject           AuditOperation = "removeObject"
+	AuditOperationRemoveObjects          AuditOperation = "removeObjects"
+	AuditOperationRestoreObject          AuditOperation = "restoreObject"
+	AuditOperationRestoreArchivedObject  AuditOperation = "restoreArchivedObject"
+	AuditOperationRemoveIncompleteUpload AuditOperation = "removeIncompleteUpload"
+	AuditOperationPresignDownload        AuditOperation = "presignDownload"
+	AuditOperationPresignUpload          AuditOperation = "presignUpload"
+)
+
+// AuditOutcome represents the outcome of audited operations.
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeFailure AuditOutcome = "failure"
+)
+
+// AuditSinkType represents the destination type of audit events.
+type AuditSinkType string
+
+const (
+	AuditSinkTypeStdout AuditSinkType = "stdout"
+	AuditSinkTypeFile   AuditSinkType = "file"
+	AuditSinkTypeObject AuditSinkType = "object"
+)
+
+var enumValues_AuditSinkType = []AuditSinkType{
+	AuditSinkTypeStdout, AuditSinkTypeFile, AuditSinkTypeObject,
+}
+
+// ParseAuditSinkType parses the AuditSinkType from string.
+func ParseAuditSinkType(input string) (AuditSinkType, error) {
+	result := AuditSinkType(input)
+	if !slices.Contains(enumValues_AuditSinkType, result) {
+		return "", fmt.Errorf(
+			"invalid AuditSinkType, expected one of %v, got: %s",
+			enumValues_AuditSinkType,
+			input,
+		)
+	}
+
+	return result, nil
+}
+
+// Validate checks if the sink type is valid.
+func (ast AuditSinkType) Validate() error {
+	_, err := ParseAuditSinkType(string(ast))
+
+	return err
+}
+
+// JSONSchema is used to generate a custom jsonschema.
+func (ast AuditSinkType) JSONSchema() *jsonschema.Schema {
+	enumValues := make([]any, len(enumValues_AuditSinkType))
+	for i, item := range enumValues_AuditSinkType {
+		enumValues[i] = string(item)
+	}
+
+	return &jsonschema.Schema{
+		Type: "string",
+		Enum: enumValues,
+	}
+}
+
+var defaultAuditIdentityHeaders = []string{"x-hasura-user-id", "x-hasura-role"}
+
+// AuditSettings represent settings of audit logs of mutations and presigned URL issuance.
+type AuditSettings struct {
+	// Enable audit logs.
+	Enabled bool `json:"enabled"                   jsonschema:"default=false" yaml:"enabled"`
+	// Session variables or forwarded headers that identify the caller. Defaults to x-hasura-user-id and x-hasura-role.
+	IdentityHeaders []string `json:"identityHeaders,omitempty" yaml:"identityHeaders,omitempty"`
+	// Destinations of audit events.
+	Sinks []AuditSinkConfig `json:"sinks"                     yaml:"sinks"`
+}
+
+// IsEnabled checks if audit logs are enabled.
+func (as *AuditSettings) IsEnabled() bool {
+	return as != nil && as.Enabled
+}
+
+// Validate checks if the audit settings are valid.
+func (as AuditSettings) Validate() error {
+	if !as.Enabled {
+		return nil
+	}
+
+	if len(as.Sinks) == 0 {
+		return errors.New("require at least 1 sink")
+	}
+
+	for i, sink := range as.Sinks {
+		if err := sink.Validate(); err != nil {
+			return fmt.Errorf("invalid audit sink at %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// AuditSinkConfig represents the configuration of a destination of audit events.
+type AuditSinkConfig struct {
+	// Type of the sink.
+	Type AuditSinkType `json:"type"                    yaml:"type"`
+	// Path of the audit log file. Required for the file sink.
+	Path string `json:"path,omitempty"          yaml:"path,omitempty"`
+	// Maximum size in MB of the audit log file before it is rotated.
+	MaxSizeMBs int `json:"maxSizeMBs,omitempty"    jsonschema:"min=1,default=100"           yaml:"maxSizeMBs,omitempty"`
+	// Maximum number of rotated files to retain.
+	MaxBackups int `json:"maxBackups,omitempty"    jsonschema:"min=1,default=5"             yaml:"maxBackups,omitempty"`
+	// ID of the storage client that stores audit objects. Use the first client if empty.
+	ClientID string `json:"clientId,omitempty"      yaml:"clientId,omitempty"`
+	// Bucket that stores audit objects. Required for the object sink.
+	Bucket string `json:"bucket,omitempty"        yaml:"bucket,omitempty"`
+	// Key prefix of audit objects.
+	Prefix string `json:"prefix,omitempty"        jsonschema:"default=.ndc-storage/audit/" yaml:"prefix,omitempty"`
+	// Interval in seconds to flush buffered events to a new audit object.
+	FlushInterval int `json:"flushInterval,omitempty" jsonschema:"min=1,default=60"            yaml:"flushInterval,omitempty"`
+	// Maximum number of buffered events. Events are flushed early if the buffer is full.
+	BufferSize int `json:"bufferSize,omitempty"    jsonschema:"min=1,default=1000"          yaml:"bufferSize,omitempty"`
+}
+
+// Validate checks if the sink configuration is valid.
+func (asc AuditSinkConfig) Validate() error {
+	if err := asc.Type.Validate(); err != nil {
+		return err
+	}
+
+	switch asc.Type {
+	case AuditSinkTypeFile:
+		if asc.Path == "" {
+			return errors.New("path is required for the file sink")
+		}
+	case AuditSinkTypeObject:
+		if asc.Bucket == "" {
+			return errors.New("bucket is required for the object sink")
+		}
+	default:
+	}
+
+	return nil
+}
+
+// AuditEvent represents a structured audit record of an operation.
+type AuditEvent struct {
+	Time      time.Time         `json:"time"`
+	Operation AuditOperation    `json:"operation"`
+	ClientID  string            `json:"clientId,omitempty"`
+	Bucket    string            `json:"bucket,omitempty"`
+	Object    string            `json:"object,omitempty"`
+	VersionID string            `json:"versionId,omitempty"`
+	Size      *int64            `json:"size,omitempty"`
+	ExpiredAt *time.Time        `json:"expiredAt,omitempty"`
+	Identity  map[string]string `json:"identity,omitempty"`
+	// Identity from session variables that aren't verified because the session secret isn't configured.
+	// Clients may forge these values if the session argument isn't set by the engine.
+	UnverifiedIdentity map[string]string `json:"unverifiedIdentity,omitempty"`
+	Outcome            AuditOutcome      `json:"outcome"`
+	Error              string            `json:"error,omitempty"`
+	DurationMs         int64             `json:"durationMs"`
+}
+
+// auditSink is the destination of audit events.
+type auditSink interface {
+	Write(event *AuditEvent) error
+	Close(ctx context.Context) error
+}
+
+// Auditor emits audit events of operations to sinks. A nil auditor means audit logs are disabled.
+type Auditor struct {
+	identityHeaders []string
+	sinks           []auditSink
+	logger          *slog.Logger
+}
+
+func newAuditor(m *Manager, settings *AuditSettings) (*Auditor, error) {
+	if !settings.IsEnabled() {
+		return nil, nil
+	}
+
+	auditor := &Auditor{
+		identityHeaders: settings.IdentityHeaders,
+		logger:          m.logger,
+	}
+
+	if len(auditor.identityHeaders) == 0 {
+		auditor.identityHeaders = defaultAuditIdentityHeaders
+	}
+
+	for i, config := range settings.Sinks {
+		var sink auditSink
+
+		var err error
+
+		switch config.Type {
+		case AuditSinkTypeStdout:
+			sink = newAuditStdoutSink()
+		case AuditSinkTypeFile:
+			sink, err = newAuditFileSink(config)
+		case AuditSinkTypeObject:
+			sink, err = newAuditObjectSink(m, config)
+		default:
+			err = config.Type.Validate()
+		}
+
+		if err != nil {
+			_ = auditor.Close(context.Background())
+
+			return nil, fmt.Errorf("failed to initialize the audit sink at %d: %w", i, err)
+		}
+
+		auditor.sinks = append(auditor.sinks, sink)
+	}
+
+	return auditor, nil
+}
+
+// Close flushes buffered events and closes sinks.
+func (a *Auditor) Close(ctx context.Context) error {
+	if a == nil {
+		return nil
+	}
+
+	var errs []error
+
+	for _, sink := range a.sinks {
+		if err := sink.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// begin starts recording an operation. The record is nil if audit logs are disabled.
+func (a *Auditor) begin(
+	ctx context.Context,
+	op AuditOperation,
+	bucketName, objectName string,
+) *auditRecord {
+	if a == nil {
+		return nil
+	}
+
+	return &auditRecord{
+		auditor: a,
+		event: AuditEvent{
+			Time:               time.Now().UTC(),
+			Operation:          op,
+			Bucket:             bucketName,
+			Object:             objectName,
+			Identity:           a.evalIdentity(SessionFromContext(ctx)),
+			UnverifiedIdentity: a.evalIdentity(unverifiedSessionFromContext(ctx)),
+		},
+	}
+}
+
+// evalIdentity picks identity headers from session variables.
+func (a *Auditor) evalIdentity(session Session) map[string]string {
+	identity := map[string]string{}
+
+	for _, key := range a.identityHeaders {
+		if value := session.Get(key); value != "" {
+			identity[key] = value
+		}
+	}
+
+	return identity
+}
+
+// auditRecord collects attributes of an operation until it finishes.
+type auditRecord struct {
+	auditor *Auditor
+	event   AuditEvent
+}
+
+// setTarget sets the resolved client and bucket of the operation.
+func (ar *auditRecord) setTarget(client *Client, bucketName string) {
+	if ar == nil {
+		return
+	}
+
+	ar.event.ClientID = string(client.id)
+	ar.event.Bucket = bucketName
+}
+
+// setUploadInfo sets the version and size of the uploaded object.
+func (ar *auditRecord) setUploadInfo(info *common.StorageUploadInfo) {
+	if ar == nil || info == nil {
+		return
+	}
+
+	if info.VersionID != nil {
+		ar.event.VersionID = *info.VersionID
+	}
+
+	ar.event.Size = info.Size
+}
+
+// setVersionID sets the object version of the operation.
+func (ar *auditRecord) setVersionID(versionID string) {
+	if ar == nil {
+		return
+	}
+
+	ar.event.VersionID = versionID
+}
+
+// setExpiredAt sets the expiry time of the presigned URL.
+func (ar *auditRecord) setExpiredAt(expiredAt time.Time) {
+	if ar == nil {
+		return
+	}
+
+	ar.event.ExpiredAt = &expiredAt
+}
+
+// end finishes the record and writes the event to sinks.
+// Failures of sinks are logged and don't fail the operation.
+func (ar *auditRecord) end(err error) {
+	if ar == nil {
+		return
+	}
+
+	ar.event.DurationMs = time.Since(ar.event.Time).Milliseconds()
+	ar.event.Outcome = AuditOutcomeSuccess
+
+	if err != nil {
+		ar.event.Outcome = AuditOutcomeFailure
+		ar.event.Error = err.Error()
+	}
+
+	for _, sink := range ar.auditor.sinks {
+		if err := sink.Write(&ar.event); err != nil {
+			ar.auditor.logger.Error(
+				"failed to write the audit event",
+				slog.String("operation", string(ar.event.Operation)),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hasura/ndc-storage/connector/storage/common"
+)
+
+const (
+	defaultAuditFileMaxSizeMBs     = 100
+	defaultAuditFileMaxBackups     = 5
+	defaultAuditObjectPrefix       = ".ndc-storage/audit/"
+	defaultAuditObjectFlushSeconds = 60
+	defaultAuditObjectBufferSize   = 1000
+)
+
+// auditStdoutSink writes audit events to the standard output in JSON lines.
+type auditStdoutSink struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+func newAuditStdoutSink() *auditStdoutSink {
+	return &auditStdoutSink{
+		writer: os.Stdout,
+	}
+}
+
+// Write writes the event to the standard output.
+func (ass *auditStdoutSink) Write(event *AuditEvent) error {
+	line, err := encodeAuditEvent(event)
+	if err != nil {
+		return err
+	}
+
+	ass.mu.Lock()
+	defer ass.mu.Unlock()
+
+	_, err = ass.writer.Write(line)
+
+	return err
+}
+
+// Close does nothing because the standard output is owned by the process.
+func (ass *auditStdoutSink) Close(_ context.Context) error {
+	return nil
+}
+
+// auditFileSink appends audit events to a local file in JSON lines.
+// The file is rotated when it exceeds the maximum size. Rotated files are suffixed with numbers, e.g. audit.log.1.
+type auditFileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func newAuditFileSink(config AuditSinkConfig) (*auditFileSink, error) {
+	maxSizeMBs := config.MaxSizeMBs
+	if maxSizeMBs <= 0 {
+		maxSizeMBs = defaultAuditFileMaxSizeMBs
+	}
+
+	maxBackups := config.MaxBackups
+	if maxBackups <= 0 {
+		maxBackups = defaultAuditFileMaxBackups
+	}
+
+	sink := &auditFileSink{
+		path:       config.Path,
+		maxSize:    int64(maxSizeMBs) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+
+	if err := os.MkdirAll(filepath.Dir(config.Path), 0o750); err != nil {
+		return nil, err
+	}
+
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+
+	return sink, nil
+}
+
+func (afs *auditFileSink) open() error {
+	file, err := os.OpenFile(afs.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open the audit log file: %w", err)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+
+		return err
+	}
+
+	afs.file = file
+	afs.size = stat.Size()
+
+	return nil
+}
+
+// Write appends the event to the file.
+func (afs *auditFileSink) Write(event *AuditEvent) error {
+	line, err := encodeAuditEvent(event)
+	if err != nil {
+		return err
+	}
+
+	afs.mu.Lock()
+	defer afs.mu.Unlock()
+
+	if afs.file == nil {
+		return errors.New("the audit log file is closed")
+	}
+
+	if afs.size > 0 && afs.size+int64(len(line)) > afs.maxSize {
+		if err := afs.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := afs.file.Write(line)
+	afs.size += int64(n)
+
+	return err
+}
+
+// rotate renames the current file to a backup and opens a new file.
+// The oldest backups are removed if the number of backups exceeds the limit.
+func (afs *auditFileSink) rotate() error {
+	if err := afs.file.Close(); err != nil {
+		return err
+	}
+
+	afs.file = nil
+
+	_ = os.Remove(fmt.Sprintf("%s.%d", afs.path, afs.maxBackups))
+
+	for i := afs.maxBackups - 1; i > 0; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", afs.path, i), fmt.Sprintf("%s.%d", afs.path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := os.Rename(afs.path, afs.path+".1"); err != nil {
+		return err
+	}
+
+	return afs.open()
+}
+
+// Close closes the file.
+func (afs *auditFileSink) Close(_ context.Context) error {
+	afs.mu.Lock()
+	defer afs.mu.Unlock()
+
+	if afs.file == nil {
+		return nil
+	}
+
+	err := afs.file.Close()
+	afs.file = nil
+
+	return err
+}
+
+// auditObjectSink buffers audit events and flushes them to new objects in a designated bucket.
+// Objects are never overwritten, so the bucket is an append-only log.
+// It's recommended to protect the bucket with object lock or retention policies.
+type auditObjectSink struct {
+	manager    *Manager
+	clientID   *common.StorageClientID
+	bucket     string
+	prefix     string
+	bufferSize int
+
+	mu       sync.Mutex
+	buffer   bytes.Buffer
+	count    int
+	sequence int
+
+	flushChan chan struct{}
+	stop      context.CancelFunc
+	done      chan struct{}
+}
+
+func newAuditObjectSink(m *Manager, config AuditSinkConfig) (*auditObjectSink, error) {
+	var clientID *common.StorageClientID
+
+	if config.ClientID != "" {
+		clientID = (*common.StorageClientID)(&config.ClientID)
+	}
+
+	if _, ok := m.GetClient(clientID); !ok {
+		return nil, fmt.Errorf("storage client %s of the object sink doesn't exist", config.ClientID)
+	}
+
+	prefix := config.Prefix
+	if prefix == "" {
+		prefix = defaultAuditObjectPrefix
+	}
+
+	flushInterval := config.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultAuditObjectFlushSeconds
+	}
+
+	bufferSize := config.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultAuditObjectBufferSize
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	sink := &auditObjectSink{
+		manager:    m,
+		clientID:   clientID,
+		bucket:     config.Bucket,
+		prefix:     prefix,
+		bufferSize: bufferSize,
+		flushChan:  make(chan struct{}, 1),
+		stop:       stop,
+		done:       make(chan struct{}),
+	}
+
+	go sink.run(ctx, time.Duration(flushInterval)*time.Second)
+
+	return sink, nil
+}
+
+func (aos *auditObjectSink) run(ctx context.Context, interval time.Duration) {
+	defer close(aos.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			aos.flush(ctx)
+		case <-aos.flushChan:
+			aos.flush(ctx)
+		}
+	}
+}
+
+// Write buffers the event. Events are flushed in the background when the buffer is full.
+func (aos *auditObjectSink) Write(event *AuditEvent) error {
+	line, err := encodeAuditEvent(event)
+	if err != nil {
+		return err
+	}
+
+	aos.mu.Lock()
+	defer aos.mu.Unlock()
+
+	aos.buffer.Write(line)
+	aos.count++
+
+	if aos.count >= aos.bufferSize {
+		select {
+		case aos.flushChan <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// flush writes buffered events to a new object.
+func (aos *auditObjectSink) flush(ctx context.Context) {
+	aos.mu.Lock()
+
+	if aos.count == 0 {
+		aos.mu.Unlock()
+
+		return
+	}
+
+	data := bytes.Clone(aos.buffer.Bytes())
+	count := aos.count
+	aos.sequence++
+	sequence := aos.sequence
+
+	aos.buffer.Reset()
+	aos.count = 0
+	aos.mu.Unlock()
+
+	if err := aos.put(ctx, data, sequence); err != nil {
+		aos.manager.logger.Error(
+			"failed to write audit events to the storage object",
+			"bucket", aos.bucket,
+			"count", count,
+			"error", err,
+		)
+	}
+}
+
+func (aos *auditObjectSink) put(ctx context.Context, data []byte, sequence int) error {
+	ctx, release := ContextWithClientLeases(ctx)
+	defer release()
+
+	// use the latest client in case its credentials were rotated.
+	// The client is held so that a concurrent rotation doesn't close it while the events are written.
+	var client *Client
+
+	for {
+		var ok bool
+
+		client, ok = aos.manager.GetClient(aos.clientID)
+		if !ok {
+			return errors.New("storage client of the object sink doesn't exist")
+		}
+
+		if clientLeasesFromContext(ctx).add(client) {
+			break
+		}
+	}
+
+	now := time.Now().UTC()
+	objectName := path.Join(
+		aos.prefix,
+		now.Format("2006/01/02"),
+		fmt.Sprintf("%s-%s-%06d.jsonl", now.Format("150405.000000000"), newTransactionID(), sequence),
+	)
+
+	_, err := client.PutObject(ctx, aos.bucket, objectName, &common.PutStorageObjectOptions{
+		ContentType: "application/x-ndjson",
+	}, bytes.NewReader(data), int64(len(data)))
+
+	return err
+}
+
+// Close stops the background worker and flushes remaining events.
+func (aos *auditObjectSink) Close(ctx context.Context) error {
+	aos.stop()
+	<-aos.done
+
+	aos.flush(ctx)
+
+	return nil
+}
+
+func encodeAuditEvent(event *AuditEvent) ([]byte, error) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the audit event: %w", err)
+	}
+
+	return append(line, '\n'), nil
+}
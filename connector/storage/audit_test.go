@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"gotest.tools/v3/assert"
+)
+
+func readAuditEvents(t *testing.T, data []byte) []AuditEvent {
+	t.Helper()
+
+	var results []AuditEvent
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var event AuditEvent
+		assert.NilError(t, json.Unmarshal(scanner.Bytes(), &event))
+
+		results = append(results, event)
+	}
+
+	return results
+}
+
+func TestAuditEvents(t *testing.T) {
+	dataDir := t.TempDir()
+	auditDir := t.TempDir()
+
+	manager, err := NewManager(context.TODO(), []ClientConfig{
+		{
+			"type":               "fs",
+			"defaultDirectory":   map[string]any{"value": dataDir},
+			"allowedDirectories": []any{auditDir},
+		},
+	}, RuntimeSettings{
+		MaxUploadSizeMBs: 1,
+	}, nil, &AuditSettings{
+		Enabled: true,
+		Sinks: []AuditSinkConfig{
+			{
+				Type:          AuditSinkTypeObject,
+				Bucket:        auditDir,
+				FlushInterval: 3600,
+			},
+		},
+	}, nil, slog.Default())
+	assert.NilError(t, err)
+
+	defer manager.stopSecretWatcher()
+
+	stdout := &bytes.Buffer{}
+	manager.audit.sinks = append(manager.audit.sinks, &auditStdoutSink{writer: stdout})
+
+	ctx := ContextWithSession(context.TODO(), Session{
+		"x-hasura-user-id": "user-1",
+		"x-hasura-role":    "user",
+		"authorization":    "Bearer secret",
+	})
+
+	_, err = manager.PutObject(
+		ctx,
+		common.StorageBucketArguments{},
+		"a.txt",
+		&common.PutStorageObjectOptions{},
+		[]byte("hello"),
+	)
+	assert.NilError(t, err)
+
+	// session variables that can't be verified are recorded separately.
+	unverifiedCtx := ContextWithUnverifiedSession(context.TODO(), Session{
+		"x-hasura-user-id": "user-2",
+	})
+
+	err = manager.RemoveObject(
+		unverifiedCtx,
+		common.StorageBucketArguments{},
+		"a.txt",
+		common.RemoveStorageObjectOptions{},
+	)
+	assert.NilError(t, err)
+
+	_, err = manager.PutObject(
+		ctx,
+		common.StorageBucketArguments{},
+		"large.txt",
+		&common.PutStorageObjectOptions{},
+		make([]byte, 2*1024*1024),
+	)
+	assert.ErrorContains(t, err, "is not allowed to be upload directly")
+
+	events := readAuditEvents(t, stdout.Bytes())
+	assert.Equal(t, len(events), 3)
+
+	assert.Equal(t, events[0].Operation, AuditOperationUploadObject)
+	assert.Equal(t, events[0].ClientID, "0")
+	assert.Equal(t, events[0].Bucket, dataDir)
+	assert.Equal(t, events[0].Object, "a.txt")
+	assert.Equal(t, events[0].Outcome, AuditOutcomeSuccess)
+	assert.DeepEqual(t, events[0].Identity, map[string]string{
+		"x-hasura-user-id": "user-1",
+		"x-hasura-role":    "user",
+	})
+
+	assert.Equal(t, events[1].Operation, AuditOperationRemoveObject)
+	assert.Equal(t, events[1].Outcome, AuditOutcomeSuccess)
+	assert.Assert(t, events[1].Identity == nil)
+	assert.DeepEqual(t, events[1].UnverifiedIdentity, map[string]string{
+		"x-hasura-user-id": "user-2",
+	})
+
+	assert.Equal(t, events[2].Operation, AuditOperationUploadObject)
+	assert.Equal(t, events[2].Outcome, AuditOutcomeFailure)
+	assert.Assert(t, events[2].Error != "")
+
+	// flush buffered events to the audit bucket.
+	assert.NilError(t, manager.audit.Close(context.TODO()))
+
+	var auditObjects []string
+
+	assert.NilError(t, filepath.WalkDir(auditDir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			auditObjects = append(auditObjects, path)
+		}
+
+		return err
+	}))
+	assert.Equal(t, len(auditObjects), 1)
+	assert.Assert(t, strings.Contains(auditObjects[0], filepath.Join(".ndc-storage", "audit")))
+
+	content, err := os.ReadFile(auditObjects[0])
+	assert.NilError(t, err)
+	assert.DeepEqual(t, readAuditEvents(t, content), events)
+}
+
+// mockPartialRemoveClient fails to remove some objects.
+type mockPartialRemoveClient struct {
+	common.StorageClient
+}
+
+func (m *mockPartialRemoveClient) RemoveObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.RemoveStorageObjectsOptions,
+	predicate func(string) bool,
+) []common.RemoveStorageObjectError {
+	return []common.RemoveStorageObjectError{{ObjectName: "a.txt", Error: "access denied"}}
+}
+
+func TestAuditRemoveObjectsPartialFailure(t *testing.T) {
+	manager, err := NewManager(context.TODO(), []ClientConfig{
+		{
+			"type":             "fs",
+			"defaultDirectory": map[string]any{"value": t.TempDir()},
+		},
+	}, RuntimeSettings{}, nil, &AuditSettings{
+		Enabled: true,
+		Sinks:   []AuditSinkConfig{{Type: AuditSinkTypeStdout}},
+	}, nil, slog.Default())
+	assert.NilError(t, err)
+
+	defer manager.stopSecretWatcher()
+
+	stdout := &bytes.Buffer{}
+	manager.audit.sinks = []auditSink{&auditStdoutSink{writer: stdout}}
+
+	clients := manager.getClients()
+	clients[0].StorageClient = &mockPartialRemoveClient{StorageClient: clients[0].StorageClient}
+
+	errs, err := manager.RemoveObjects(
+		context.TODO(),
+		common.StorageBucketArguments{},
+		&common.RemoveStorageObjectsOptions{},
+		nil,
+	)
+	assert.NilError(t, err)
+	assert.Equal(t, len(errs), 1)
+
+	events := readAuditEvents(t, stdout.Bytes())
+	assert.Equal(t, len(events), 1)
+	assert.Equal(t, events[0].Operation, AuditOperationRemoveObjects)
+	assert.Equal(t, events[0].Outcome, AuditOutcomeFailure)
+	assert.Equal(t, events[0].Error, "failed to remove 1 objects")
+}
+
+func TestAuditFileSinkRotation(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "audit", "audit.log")
+
+	sink, err := newAuditFileSink(AuditSinkConfig{
+		Type:       AuditSinkTypeFile,
+		Path:       logPath,
+		MaxBackups: 2,
+	})
+	assert.NilError(t, err)
+
+	// rotate the file after every event.
+	sink.maxSize = 1
+
+	for _, op := range []AuditOperation{
+		AuditOperationCreateBucket,
+		AuditOperationUploadObject,
+		AuditOperationRemoveObject,
+		AuditOperationRemoveBucket,
+	} {
+		assert.NilError(t, sink.Write(&AuditEvent{Operation: op, Outcome: AuditOutcomeSuccess}))
+	}
+
+	assert.NilError(t, sink.Close(context.TODO()))
+
+	for path, expected := range map[string]AuditOperation{
+		logPath:        AuditOperationRemoveBucket,
+		logPath + ".1": AuditOperationRemoveObject,
+		logPath + ".2": AuditOperationUploadObject,
+	} {
+		content, err := os.ReadFile(path)
+		assert.NilError(t, err)
+
+		events := readAuditEvents(t, content)
+		assert.Equal(t, len(events), 1)
+		assert.Equal(t, events[0].Operation, expected)
+	}
+
+	_, err = os.Stat(logPath + ".3")
+	assert.Assert(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestAuditSettingsValidate(t *testing.T) {
+	assert.NilError(t, AuditSettings{}.Validate())
+	assert.ErrorContains(t, AuditSettings{Enabled: true}.Validate(), "require at least 1 sink")
+	assert.ErrorContains(t, AuditSettings{
+		Enabled: true,
+		Sinks:   []AuditSinkConfig{{Type: AuditSinkTypeFile}},
+	}.Validate(), "path is required")
+	assert.ErrorContains(t, AuditSettings{
+		Enabled: true,
+		Sinks:   []AuditSinkConfig{{Type: AuditSinkTypeObject}},
+	}.Validate(), "bucket is required")
+	assert.ErrorContains(t, AuditSettings{
+		Enabled: true,
+		Sinks:   []AuditSinkConfig{{Type: "unknown"}},
+	}.Validate(), "invalid AuditSinkType")
+}
@@ -3,6 +3,7 @@ package azblob
 import (
 	"context"
 	"log/slog"
+	"net/http"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/hasura/ndc-sdk-go/v2/utils"
@@ -13,23 +14,40 @@ import (
 
 // Client represents a Minio client wrapper.
 type Client struct {
-	client  *azblob.Client
-	isDebug bool
+	client    *azblob.Client
+	transport http.RoundTripper
+	isDebug   bool
+	// Presigned URLs can't be signed if the sas authentication is used.
+	useSASToken bool
+	// Sign presigned URLs with user delegation keys if the Microsoft Entra authentication is used.
+	useUserDelegation bool
 }
 
 var _ common.StorageClient = &Client{}
 
 // New creates a new Minio client.
 func New(ctx context.Context, cfg *ClientConfig, logger *slog.Logger) (*Client, error) {
-	client, err := cfg.toAzureBlobClient(logger)
+	client, transport, err := cfg.toAzureBlobClient(logger)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Client{
-		client:  client,
-		isDebug: utils.IsDebug(logger),
-	}, nil
+	result := &Client{
+		client:            client,
+		transport:         transport,
+		isDebug:           utils.IsDebug(logger),
+		useSASToken:       cfg.Authentication.Type == AuthTypeSAS,
+		useUserDelegation: cfg.Authentication.Type == AuthTypeEntra,
+	}
+
+	return result, nil
+}
+
+// Close closes idle connections of the HTTP transport.
+func (c *Client) Close() error {
+	common.CloseIdleConnections(c.transport)
+
+	return nil
 }
 
 func (c *Client) startOtelSpan(
@@ -45,10 +45,12 @@ func (cc ClientConfig) JSONSchema() *jsonschema.Schema {
 	return result
 }
 
-func (cc ClientConfig) toAzureBlobClient(logger *slog.Logger) (*azblob.Client, error) {
+func (cc ClientConfig) toAzureBlobClient(
+	logger *slog.Logger,
+) (*azblob.Client, http.RoundTripper, error) {
 	endpointURL, port, useSSL, err := cc.ValidateEndpoint()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	maxRetries := 0
@@ -56,6 +58,11 @@ func (cc ClientConfig) toAzureBlobClient(logger *slog.Logger) (*azblob.Client, e
 		maxRetries = *cc.MaxRetries
 	}
 
+	if cc.Retry != nil {
+		// a negative value disables retries. They are handled by the connector's retry policy.
+		maxRetries = -1
+	}
+
 	isDebug := utils.IsDebug(logger)
 
 	transport, err := common.NewTransport(cc.HTTP, exhttp.TelemetryConfig{
@@ -63,7 +70,7 @@ func (cc ClientConfig) toAzureBlobClient(logger *slog.Logger) (*azblob.Client, e
 		Port:   port,
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	opts := &azblob.ClientOptions{
@@ -86,7 +93,12 @@ func (cc ClientConfig) toAzureBlobClient(logger *slog.Logger) (*azblob.Client, e
 		endpoint = endpointURL.String()
 	}
 
-	return cc.Authentication.toAzureBlobClient(endpoint, opts)
+	client, err := cc.Authentication.toAzureBlobClient(endpoint, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return client, transport, nil
 }
 
 // OtherConfig holds MinIO-specific configurations.
@@ -105,6 +117,7 @@ const (
 	AuthTypeSharedKey        AuthType = "sharedKey"
 	AuthTypeEntra            AuthType = "entra"
 	AuthTypeConnectionString AuthType = "connectionString"
+	AuthTypeSAS              AuthType = "sas"
 )
 
 var enumValues_AuthType = []AuthType{
@@ -112,6 +125,7 @@ var enumValues_AuthType = []AuthType{
 	AuthTypeSharedKey,
 	AuthTypeEntra,
 	AuthTypeConnectionString,
+	AuthTypeSAS,
 }
 
 // ParseAuthType parses the AuthType from string.
@@ -176,6 +190,10 @@ type AuthCredentials struct {
 	AdditionallyAllowedTenants []string `json:"additionallyAllowedTenants,omitempty" mapstructure:"additionallyAllowedTenants" yaml:"additionallyAllowedTenants,omitempty"`
 	// Audience to use when requesting tokens for Azure Active Directory authentication.
 	Audience *utils.EnvString `json:"audience,omitempty"                   mapstructure:"audience"                   yaml:"audience,omitempty"`
+	// The shared access signature (SAS) token.
+	SASToken *utils.EnvString `json:"sasToken,omitempty"                   mapstructure:"sasToken"                   yaml:"sasToken,omitempty"`
+	// The service URL of the storage account. Defaults to the endpoint of the client.
+	AccountURL *utils.EnvString `json:"accountUrl,omitempty"                 mapstructure:"accountUrl"                 yaml:"accountUrl,omitempty"`
 }
 
 // JSONSchema is used to generate a custom jsonschema.
@@ -213,6 +231,21 @@ func (ac AuthCredentials) JSONSchema() *jsonschema.Schema {
 		Ref:         envStringRefName,
 	})
 
+	sasProps := jsonschema.NewProperties()
+	sasProps.Set("type", &jsonschema.Schema{
+		Type:        "string",
+		Description: "Authorize with a shared access signature (SAS) token",
+		Enum:        []any{AuthTypeSAS},
+	})
+	sasProps.Set("sasToken", &jsonschema.Schema{
+		Description: "The shared access signature (SAS) token",
+		Ref:         envStringRefName,
+	})
+	sasProps.Set("accountUrl", &jsonschema.Schema{
+		Description: "The service URL of the storage account. Defaults to the endpoint of the client",
+		Ref:         envStringRefName,
+	})
+
 	entraProps := jsonschema.NewProperties()
 	entraProps.Set("type", &jsonschema.Schema{
 		Type: "string",
@@ -295,6 +328,11 @@ func (ac AuthCredentials) JSONSchema() *jsonschema.Schema {
 				Properties: anonymousProps,
 				Required:   []string{"type"},
 			},
+			{
+				Type:       "object",
+				Properties: sasProps,
+				Required:   []string{"type", "sasToken"},
+			},
 			{
 				Type:       "object",
 				Properties: entraProps,
@@ -320,7 +358,7 @@ func (ac AuthCredentials) toAzureBlobClient(
 
 	serviceURL := endpoint
 	if accountName != "" && endpoint == "" {
-		serviceURL = fmt.Sprintf("https://%s.blob.core.windows.net/", accountKey)
+		serviceURL = fmt.Sprintf("https://%s.blob.core.windows.net/", accountName)
 	}
 
 	switch ac.Type {
@@ -330,6 +368,8 @@ func (ac AuthCredentials) toAzureBlobClient(
 		}
 
 		return azblob.NewClientWithNoCredential(serviceURL, options)
+	case AuthTypeSAS:
+		return ac.toSASClient(serviceURL, options)
 	case AuthTypeSharedKey:
 		if accountName == "" {
 			return nil, errRequireAccountName
@@ -16,11 +16,13 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/lease"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
 	"github.com/hasura/ndc-sdk-go/v2/schema"
 	"github.com/hasura/ndc-storage/connector/storage/common"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ListObjects list objects in a bucket.
@@ -55,7 +57,7 @@ func (c *Client) listFlatObjects(
 	}
 
 	maxResults := int32(opts.MaxResults)
-	if opts.MaxResults > 0 && predicate == nil {
+	if opts.MaxResults > 0 && predicate == nil && opts.OngoingRestore == nil {
 		options.MaxResults = &maxResults
 	}
 
@@ -80,7 +82,8 @@ L:
 		}
 
 		for i, item := range resp.Segment.BlobItems {
-			if item.Name == nil || (predicate != nil && !predicate(*item.Name)) {
+			if item.Name == nil || (predicate != nil && !predicate(*item.Name)) ||
+				!matchOngoingRestore(opts, item) {
 				continue
 			}
 
@@ -125,7 +128,7 @@ func (c *Client) listHierarchyObjects(
 
 	maxResults := opts.MaxResults
 
-	if opts.MaxResults > 0 && predicate == nil {
+	if opts.MaxResults > 0 && predicate == nil && opts.OngoingRestore == nil {
 		mr := int32(opts.MaxResults)
 		options.MaxResults = &mr
 
@@ -158,7 +161,7 @@ L:
 
 		for i, item := range resp.Segment.BlobPrefixes {
 			// azure does not returns results after the marker. We should ignore the start result.
-			if item.Name == nil || (opts.StartAfter != "" && strings.TrimRight(*item.Name, "/") == strings.TrimRight(opts.StartAfter, "/")) || (predicate != nil && !predicate(*item.Name)) {
+			if item.Name == nil || (opts.OngoingRestore != nil && *opts.OngoingRestore) || (opts.StartAfter != "" && strings.TrimRight(*item.Name, "/") == strings.TrimRight(opts.StartAfter, "/")) || (predicate != nil && !predicate(*item.Name)) {
 				continue
 			}
 
@@ -179,7 +182,8 @@ L:
 		}
 
 		for i, item := range resp.Segment.BlobItems {
-			if item.Name == nil || (predicate != nil && !predicate(*item.Name)) {
+			if item.Name == nil || (predicate != nil && !predicate(*item.Name)) ||
+				!matchOngoingRestore(opts, item) {
 				continue
 			}
 
@@ -371,12 +375,22 @@ func (c *Client) GetObject(
 
 	span.SetAttributes(attribute.String("storage.key", objectName))
 
-	result, err := c.client.DownloadStream(
-		ctx,
-		bucketName,
-		objectName,
-		&blob.DownloadStreamOptions{},
-	)
+	cpkInfo, cpkScopeInfo, err := evalCPKOptions(opts.ServerSideEncryption)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &blob.DownloadStreamOptions{
+		CPKInfo:      cpkInfo,
+		CPKScopeInfo: cpkScopeInfo,
+	}
+
+	if opts.IfNoneMatch != "" {
+		span.SetAttributes(attribute.String("storage.options.if_none_match", opts.IfNoneMatch))
+		options.AccessConditions = newAccessConditions("", opts.IfNoneMatch)
+	}
+
+	result, err := c.client.DownloadStream(ctx, bucketName, objectName, options)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
@@ -405,12 +419,28 @@ func (c *Client) PutObject(
 		attribute.Int64("http.response.body.size", objectSize),
 	)
 
+	cpkInfo, cpkScopeInfo, err := evalCPKOptions(opts.ServerSideEncryption)
+	if err != nil {
+		return nil, err
+	}
+
 	uploadOptions := &azblob.UploadStreamOptions{
-		HTTPHeaders: &blob.HTTPHeaders{},
-		Tags:        common.KeyValuesToStringMap(opts.Tags),
-		Metadata:    map[string]*string{},
-		Concurrency: int(opts.NumThreads),
-		BlockSize:   int64(opts.PartSize),
+		HTTPHeaders:  &blob.HTTPHeaders{},
+		Tags:         common.KeyValuesToStringMap(opts.Tags),
+		Metadata:     map[string]*string{},
+		Concurrency:  int(opts.NumThreads),
+		BlockSize:    int64(opts.PartSize),
+		CPKInfo:      cpkInfo,
+		CPKScopeInfo: cpkScopeInfo,
+	}
+
+	if opts.HasPrecondition() {
+		span.SetAttributes(
+			attribute.String("storage.options.if_match", opts.IfMatch),
+			attribute.String("storage.options.if_none_match", opts.IfNoneMatch),
+		)
+
+		uploadOptions.AccessConditions = newAccessConditions(opts.IfMatch, opts.IfNoneMatch)
 	}
 
 	if opts.StorageClass != "" {
@@ -448,8 +478,6 @@ func (c *Client) PutObject(
 	if opts.SendContentMd5 {
 		var hash []byte
 
-		var err error
-
 		reader, hash, err = common.CalculateContentMd5(reader)
 		if err != nil {
 			span.SetStatus(codes.Error, "failed to calculate content md5")
@@ -512,13 +540,49 @@ func (c *Client) CopyObject(
 		attribute.String("storage.copy_source", src.Name),
 	)
 
-	srcURL := c.client.ServiceClient().NewContainerClient(src.Bucket).NewBlobClient(src.Name).URL()
+	srcCPKInfo, _, err := evalCPKOptions(src.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	destCPKInfo, destCPKScopeInfo, err := evalCPKOptions(dest.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	if srcCPKInfo != nil || destCPKInfo != nil {
+		return c.copyObjectWithCustomerKey(
+			ctx,
+			span,
+			dest,
+			src,
+			srcCPKInfo,
+			destCPKInfo,
+			destCPKScopeInfo,
+		)
+	}
+
+	srcClient := c.client.ServiceClient().NewContainerClient(src.Bucket).NewBlobClient(src.Name)
+
+	if src.VersionID != "" {
+		span.SetAttributes(attribute.String("storage.copy_source_version", src.VersionID))
+
+		versionClient, err := srcClient.WithVersionID(src.VersionID)
+		if err != nil {
+			return nil, schema.UnprocessableContentError(err.Error(), nil)
+		}
+
+		srcClient = versionClient
+	}
+
+	srcURL := srcClient.URL()
 	blobClient := c.client.ServiceClient().NewContainerClient(dest.Bucket).NewBlobClient(dest.Name)
 
 	options := &blob.CopyFromURLOptions{
-		BlobTags:  common.KeyValuesToStringMap(dest.Tags),
-		Metadata:  make(map[string]*string),
-		LegalHold: dest.LegalHold,
+		BlobTags:     common.KeyValuesToStringMap(dest.Tags),
+		Metadata:     make(map[string]*string),
+		LegalHold:    dest.LegalHold,
+		CPKScopeInfo: destCPKScopeInfo,
 	}
 
 	for _, item := range dest.Metadata {
@@ -566,6 +630,95 @@ func (c *Client) CopyObject(
 	return result, nil
 }
 
+// copyObjectWithCustomerKey copies the object by streaming the data through the connector.
+// Azure Blob Storage doesn't support customer-provided keys in server-side copy operations,
+// so the source is decrypted when downloading and the destination is encrypted when uploading.
+func (c *Client) copyObjectWithCustomerKey(
+	ctx context.Context,
+	span trace.Span,
+	dest common.StorageCopyDestOptions,
+	src common.StorageCopySrcOptions,
+	srcCPKInfo, destCPKInfo *blob.CPKInfo,
+	destCPKScopeInfo *blob.CPKScopeInfo,
+) (*common.StorageUploadInfo, error) {
+	srcClient := c.client.ServiceClient().NewContainerClient(src.Bucket).NewBlobClient(src.Name)
+
+	if src.VersionID != "" {
+		versionClient, err := srcClient.WithVersionID(src.VersionID)
+		if err != nil {
+			return nil, schema.UnprocessableContentError(err.Error(), nil)
+		}
+
+		srcClient = versionClient
+	}
+
+	downloadOptions := &blob.DownloadStreamOptions{
+		CPKInfo: srcCPKInfo,
+	}
+
+	if src.MatchRange {
+		downloadOptions.Range = blob.HTTPRange{
+			Offset: src.Start,
+			Count:  src.End - src.Start + 1,
+		}
+	}
+
+	source, err := srcClient.DownloadStream(ctx, downloadOptions)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+
+		return nil, serializeErrorResponse(err)
+	}
+
+	defer source.Body.Close()
+
+	uploadOptions := &azblob.UploadStreamOptions{
+		HTTPHeaders: &blob.HTTPHeaders{
+			BlobCacheControl:       source.CacheControl,
+			BlobContentDisposition: source.ContentDisposition,
+			BlobContentEncoding:    source.ContentEncoding,
+			BlobContentLanguage:    source.ContentLanguage,
+			BlobContentType:        source.ContentType,
+		},
+		Tags:         common.KeyValuesToStringMap(dest.Tags),
+		Metadata:     source.Metadata,
+		CPKInfo:      destCPKInfo,
+		CPKScopeInfo: destCPKScopeInfo,
+	}
+
+	if dest.Metadata != nil {
+		uploadOptions.Metadata = make(map[string]*string)
+
+		for _, item := range dest.Metadata {
+			uploadOptions.Metadata[item.Key] = &item.Value
+		}
+	}
+
+	resp, err := c.client.UploadStream(ctx, dest.Bucket, dest.Name, source.Body, uploadOptions)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+
+		return nil, serializeErrorResponse(err)
+	}
+
+	result := serializeUploadObjectInfo(resp)
+	result.Bucket = dest.Bucket
+	result.Name = dest.Name
+
+	if dest.LegalHold != nil {
+		err := c.SetObjectLegalHold(ctx, dest.Bucket, dest.Name, "", *dest.LegalHold)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	common.SetUploadInfoAttributes(span, &result)
+
+	return &result, nil
+}
+
 // ComposeObject creates an object by concatenating a list of source objects using server-side copying.
 func (c *Client) ComposeObject(
 	ctx context.Context,
@@ -738,6 +891,22 @@ func (c *Client) UpdateObject(
 		span.SetAttributes(attribute.String("storage.options.version", opts.VersionID))
 	}
 
+	var leaseID *string
+
+	if opts.HasPrecondition() {
+		id, release, err := c.acquireConditionalLease(ctx, bucketName, objectName, opts)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+
+			return err
+		}
+
+		defer release()
+
+		leaseID = id
+	}
+
 	if opts.LegalHold != nil {
 		err := c.SetObjectLegalHold(ctx, bucketName, objectName, opts.VersionID, *opts.LegalHold)
 		if err != nil {
@@ -746,12 +915,13 @@ func (c *Client) UpdateObject(
 	}
 
 	if opts.Tags != nil {
-		err := c.SetObjectTags(
+		err := c.setObjectTags(
 			ctx,
 			bucketName,
 			objectName,
 			opts.VersionID,
 			common.KeyValuesToStringMap(*opts.Tags),
+			leaseID,
 		)
 		if err != nil {
 			return err
@@ -768,6 +938,58 @@ func (c *Client) UpdateObject(
 	return nil
 }
 
+// conditionalLeaseDuration is the lease duration in seconds of conditional updates.
+// The lease expires if it isn't released, e.g. when the connector crashes.
+const conditionalLeaseDuration = 60
+
+// acquireConditionalLease validates conditional options of the update and returns the lease ID
+// and the release function. Legal hold, tags and immutability policy APIs don't accept ETag conditions,
+// so a lease of the blob is acquired with the conditions to prevent the blob from being replaced
+// until the update is done. Versions are immutable, so conditions of a version are validated against its properties.
+func (c *Client) acquireConditionalLease(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.UpdateStorageObjectOptions,
+) (*string, func(), error) {
+	blobClient := c.client.ServiceClient().NewContainerClient(bucketName).NewBlobClient(objectName)
+	conditions := newAccessConditions(opts.IfMatch, opts.IfNoneMatch)
+
+	if opts.VersionID != "" {
+		versionClient, err := blobClient.WithVersionID(opts.VersionID)
+		if err != nil {
+			return nil, nil, serializeErrorResponse(err)
+		}
+
+		_, err = versionClient.GetProperties(ctx, &blob.GetPropertiesOptions{
+			AccessConditions: conditions,
+		})
+		if err != nil {
+			return nil, nil, serializeErrorResponse(err)
+		}
+
+		return nil, func() {}, nil
+	}
+
+	leaseClient, err := lease.NewBlobClient(blobClient, nil)
+	if err != nil {
+		return nil, nil, serializeErrorResponse(err)
+	}
+
+	_, err = leaseClient.AcquireLease(ctx, conditionalLeaseDuration, &lease.BlobAcquireOptions{
+		ModifiedAccessConditions: conditions.ModifiedAccessConditions,
+	})
+	if err != nil {
+		return nil, nil, serializeErrorResponse(err)
+	}
+
+	release := func() {
+		// the lease expires anyway if it can't be released.
+		_, _ = leaseClient.ReleaseLease(context.WithoutCancel(ctx), nil)
+	}
+
+	return leaseClient.LeaseID(), release, nil
+}
+
 // RestoreObject restores an object with some specified options.
 func (c *Client) RestoreObject(ctx context.Context, bucketName string, objectName string) error {
 	ctx, span := c.startOtelSpan(ctx, "RestoreObject", bucketName)
@@ -788,6 +1010,59 @@ func (c *Client) RestoreObject(ctx context.Context, bucketName string, objectNam
 	return nil
 }
 
+// RestoreArchivedObject rehydrates a blob from the archive tier to an online tier.
+func (c *Client) RestoreArchivedObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.RestoreArchivedStorageObjectOptions,
+) error {
+	ctx, span := c.startOtelSpan(ctx, "RestoreArchivedObject", bucketName)
+	defer span.End()
+
+	span.SetAttributes(attribute.String("storage.key", objectName))
+
+	accessTier := blob.AccessTierHot
+	if opts.StorageClass != nil && *opts.StorageClass != "" {
+		accessTier = blob.AccessTier(*opts.StorageClass)
+	}
+
+	span.SetAttributes(attribute.String("storage.options.storage_class", string(accessTier)))
+
+	blobClient := c.client.ServiceClient().NewContainerClient(bucketName).NewBlobClient(objectName)
+	if opts.VersionID != "" {
+		span.SetAttributes(attribute.String("storage.options.version", opts.VersionID))
+
+		versionClient, err := blobClient.WithVersionID(opts.VersionID)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+
+			return schema.UnprocessableContentError(err.Error(), nil)
+		}
+
+		blobClient = versionClient
+	}
+
+	options := &blob.SetTierOptions{}
+
+	if opts.Priority != nil {
+		span.SetAttributes(attribute.String("storage.options.priority", string(*opts.Priority)))
+		priority := blob.RehydratePriority(*opts.Priority)
+		options.RehydratePriority = &priority
+	}
+
+	_, err := blobClient.SetTier(ctx, accessTier, options)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+
+		return serializeErrorResponse(err)
+	}
+
+	return nil
+}
+
 // SetObjectRetention applies object retention lock onto an object.
 func (c *Client) SetObjectRetention(
 	ctx context.Context,
@@ -877,6 +1152,17 @@ func (c *Client) SetObjectTags(
 	bucketName string,
 	objectName, versionID string,
 	tags map[string]string,
+) error {
+	return c.setObjectTags(ctx, bucketName, objectName, versionID, tags, nil)
+}
+
+// setObjectTags sets tags of the object. The lease ID is required if the blob has an active lease.
+func (c *Client) setObjectTags(
+	ctx context.Context,
+	bucketName string,
+	objectName, versionID string,
+	tags map[string]string,
+	leaseID *string,
 ) error {
 	ctx, span := c.startOtelSpan(ctx, "SetObjectTags", bucketName)
 	defer span.End()
@@ -890,6 +1176,12 @@ func (c *Client) SetObjectTags(
 		opts.VersionID = &versionID
 	}
 
+	if leaseID != nil {
+		opts.AccessConditions = &blob.AccessConditions{
+			LeaseAccessConditions: &blob.LeaseAccessConditions{LeaseID: leaseID},
+		}
+	}
+
 	client := c.client.ServiceClient().NewContainerClient(bucketName).NewBlobClient(objectName)
 
 	_, err := client.SetTags(ctx, tags, opts)
@@ -944,18 +1236,41 @@ func (c *Client) presignedObject(
 	expiry time.Duration,
 	permissions sas.BlobPermissions,
 ) (string, error) {
-	_, span := c.startOtelSpan(ctx, method+"PresignedObject", bucketName)
+	ctx, span := c.startOtelSpan(ctx, method+"PresignedObject", bucketName)
 	defer span.End()
 
 	span.SetAttributes(attribute.String("storage.key", objectName))
 	span.SetAttributes(attribute.String("storage.expiry", expiry.String()))
 
-	expiredAt := time.Now().Add(expiry)
+	// the SAS token of the client can't be reused because it would ignore the expiry and grant all permissions of the token.
+	if c.useSASToken {
+		span.SetStatus(codes.Error, errPresignWithSASToken.Error())
+
+		return "", errPresignWithSASToken
+	}
 
-	result, err := c.client.ServiceClient().
+	expiredAt := time.Now().Add(expiry)
+	blobClient := c.client.ServiceClient().
 		NewContainerClient(bucketName).
-		NewBlobClient(objectName).
-		GetSASURL(permissions, expiredAt, nil)
+		NewBlobClient(objectName)
+
+	var result string
+
+	var err error
+
+	if c.useUserDelegation {
+		result, err = c.presignWithUserDelegation(
+			ctx,
+			bucketName,
+			objectName,
+			blobClient,
+			permissions,
+			expiredAt,
+		)
+	} else {
+		result, err = blobClient.GetSASURL(permissions, expiredAt, nil)
+	}
+
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
@@ -0,0 +1,151 @@
+package azblob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/service"
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+)
+
+var (
+	errRequireSASToken   = errors.New("sasToken is required")
+	errRequireAccountURL = errors.New("accountUrl or endpoint is required")
+	errInvalidSASToken   = errors.New("sasToken: the signature (sig) parameter is required")
+	errSASTokenExpired   = errors.New("the SAS token of the client is expired")
+
+	errPresignWithSASToken = schema.NotSupportedError(
+		"presigned URLs can't be signed with the SAS token of the client. "+
+			"Use the sharedKey, connectionString or entra authentication instead",
+		nil,
+	)
+)
+
+// sasExpiryPolicy fails requests early if the SAS token is expired,
+// instead of sending requests that are rejected with ambiguous authentication errors.
+type sasExpiryPolicy struct {
+	expiry time.Time
+}
+
+// Do implements the policy.Policy interface.
+func (sep sasExpiryPolicy) Do(req *policy.Request) (*http.Response, error) {
+	if time.Now().After(sep.expiry) {
+		return nil, fmt.Errorf("%w at %s", errSASTokenExpired, sep.expiry.Format(time.RFC3339))
+	}
+
+	return req.Next()
+}
+
+// parseSASToken parses and validates query parameters of the SAS token.
+func (ac AuthCredentials) parseSASToken() (string, *sas.QueryParameters, error) {
+	if ac.SASToken == nil {
+		return "", nil, errRequireSASToken
+	}
+
+	rawToken, err := ac.SASToken.GetOrDefault("")
+	if err != nil {
+		return "", nil, fmt.Errorf("sasToken: %w", err)
+	}
+
+	rawToken = strings.TrimPrefix(strings.TrimSpace(rawToken), "?")
+	if rawToken == "" {
+		return "", nil, errRequireSASToken
+	}
+
+	values, err := url.ParseQuery(rawToken)
+	if err != nil {
+		return "", nil, fmt.Errorf("sasToken: %w", err)
+	}
+
+	if values.Get("sig") == "" {
+		return "", nil, errInvalidSASToken
+	}
+
+	params := sas.NewQueryParameters(values, false)
+
+	return rawToken, &params, nil
+}
+
+func (ac AuthCredentials) toSASClient(
+	serviceURL string,
+	options *azblob.ClientOptions,
+) (*azblob.Client, error) {
+	rawToken, params, err := ac.parseSASToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if ac.AccountURL != nil {
+		accountURL, err := ac.AccountURL.GetOrDefault("")
+		if err != nil {
+			return nil, fmt.Errorf("accountUrl: %w", err)
+		}
+
+		if accountURL != "" {
+			serviceURL = accountURL
+		}
+	}
+
+	if serviceURL == "" {
+		return nil, errRequireAccountURL
+	}
+
+	sasURL, err := url.Parse(serviceURL)
+	if err != nil || sasURL.Scheme == "" || sasURL.Host == "" {
+		return nil, fmt.Errorf("accountUrl: invalid service URL %s", serviceURL)
+	}
+
+	sasURL.RawQuery = rawToken
+
+	if expiry := params.ExpiryTime(); !expiry.IsZero() {
+		options.PerCallPolicies = append(options.PerCallPolicies, sasExpiryPolicy{
+			expiry: expiry,
+		})
+	}
+
+	return azblob.NewClientWithNoCredential(sasURL.String(), options)
+}
+
+// presignWithUserDelegation signs the presigned URL with a user delegation key of the Microsoft Entra identity.
+func (c *Client) presignWithUserDelegation(
+	ctx context.Context,
+	bucketName, objectName string,
+	blobClient *blob.Client,
+	permissions sas.BlobPermissions,
+	expiredAt time.Time,
+) (string, error) {
+	startTime := time.Now().UTC().Add(-time.Minute)
+	expiredAt = expiredAt.UTC()
+
+	credential, err := c.client.ServiceClient().
+		GetUserDelegationCredential(ctx, service.KeyInfo{
+			Start:  to.Ptr(startTime.Format(sas.TimeFormat)),
+			Expiry: to.Ptr(expiredAt.Format(sas.TimeFormat)),
+		}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	params, err := sas.BlobSignatureValues{
+		StartTime:     startTime,
+		ExpiryTime:    expiredAt,
+		Permissions:   permissions.String(),
+		ContainerName: bucketName,
+		BlobName:      objectName,
+	}.SignWithUserDelegation(credential)
+	if err != nil {
+		return "", err
+	}
+
+	return blobClient.URL() + "?" + params.Encode(), nil
+}
@@ -0,0 +1,66 @@
+package azblob
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"gotest.tools/v3/assert"
+)
+
+func newSASTestClient(t *testing.T, sasToken string) *Client {
+	t.Helper()
+
+	client, err := New(context.TODO(), &ClientConfig{
+		OtherConfig: OtherConfig{
+			Authentication: AuthCredentials{
+				Type:       AuthTypeSAS,
+				SASToken:   utils.ToPtr(utils.NewEnvStringValue(sasToken)),
+				AccountURL: utils.ToPtr(utils.NewEnvStringValue("http://127.0.0.1:10000/account")),
+			},
+		},
+	}, slog.Default())
+	assert.NilError(t, err)
+
+	return client
+}
+
+func TestSASAuthentication(t *testing.T) {
+	expiry := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	client := newSASTestClient(t, "?sv=2022-11-02&sp=rl&se="+expiry+"&sig=c2lnbmF0dXJl")
+
+	// the SAS token of the client isn't leaked in presigned URLs.
+	_, err := client.PresignedGetObject(
+		context.TODO(),
+		"bucket",
+		"a/b.txt",
+		common.PresignedGetStorageObjectOptions{},
+	)
+	assert.ErrorIs(t, err, errPresignWithSASToken)
+
+	_, err = client.PresignedPutObject(context.TODO(), "bucket", "a/b.txt", time.Hour)
+	assert.ErrorIs(t, err, errPresignWithSASToken)
+
+	_, err = AuthCredentials{
+		Type:     AuthTypeSAS,
+		SASToken: utils.ToPtr(utils.NewEnvStringValue("sv=2022-11-02&sp=rl")),
+	}.toSASClient("http://127.0.0.1:10000/account", nil)
+	assert.ErrorIs(t, err, errInvalidSASToken)
+
+	_, err = AuthCredentials{
+		Type:     AuthTypeSAS,
+		SASToken: utils.ToPtr(utils.NewEnvStringValue("sv=2022-11-02&sig=abc")),
+	}.toSASClient("", nil)
+	assert.ErrorIs(t, err, errRequireAccountURL)
+}
+
+func TestSASTokenExpired(t *testing.T) {
+	expiry := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	client := newSASTestClient(t, "sv=2022-11-02&sp=rwl&se="+expiry+"&sig=c2lnbmF0dXJl")
+
+	_, err := client.StatObject(context.TODO(), "bucket", "a.txt", common.GetStorageObjectOptions{})
+	assert.ErrorContains(t, err, "the SAS token of the client is expired")
+}
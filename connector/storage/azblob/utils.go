@@ -1,6 +1,7 @@
 package azblob
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
 	"errors"
 	"slices"
@@ -9,6 +10,8 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
 	"github.com/hasura/ndc-sdk-go/v2/connector"
 	"github.com/hasura/ndc-sdk-go/v2/schema"
@@ -119,6 +122,13 @@ func serializeObjectInfo(item *container.BlobItem) common.StorageObject {
 	object.AccessTierChangeTime = item.Properties.AccessTierChangeTime
 	object.AccessTierInferred = item.Properties.AccessTierInferred
 	object.ArchiveStatus = (*string)(item.Properties.ArchiveStatus)
+
+	if isRehydratePending(item.Properties.ArchiveStatus) {
+		object.Restore = &common.StorageRestoreInfo{
+			OngoingRestore: true,
+		}
+	}
+
 	object.BlobSequenceNumber = item.Properties.BlobSequenceNumber
 	object.BlobType = (*string)(item.Properties.BlobType)
 
@@ -189,16 +199,37 @@ func serializeAzureErrorResponse(respErr *azcore.ResponseError) *schema.Connecto
 		details["code"] = respErr.ErrorCode
 	}
 
-	if respErr.StatusCode >= 500 {
-		return schema.NewConnectorError(respErr.StatusCode, respErr.Error(), details)
-	}
+	return common.NewStorageError(evalAzureErrorCode(respErr), respErr.Error(), details)
+}
 
-	return schema.UnprocessableContentError(respErr.Error(), details)
+// evalAzureErrorCode maps the error code of the Azure response to the stable code of storage errors.
+func evalAzureErrorCode(respErr *azcore.ResponseError) common.StorageErrorCode {
+	switch bloberror.Code(respErr.ErrorCode) {
+	// uploads with the If-None-Match: * condition fail with BlobAlreadyExists
+	// while other providers return 412.
+	case bloberror.ConditionNotMet, bloberror.BlobAlreadyExists:
+		return common.ErrorCodePreconditionFailed
+	case bloberror.ContainerAlreadyExists:
+		return common.ErrorCodeAlreadyExists
+	case bloberror.ServerBusy:
+		return common.ErrorCodeThrottled
+	default:
+		return common.EvalStorageErrorCode(respErr.StatusCode)
+	}
 }
 
 func serializeErrorResponse(err error) *schema.ConnectorError {
+	var connectorErr *schema.ConnectorError
+	if errors.As(err, &connectorErr) {
+		return connectorErr
+	}
+
 	var respErr *azcore.ResponseError
 	if !errors.As(err, &respErr) {
+		if common.IsTransientError(err) {
+			return common.NewTransientError(err)
+		}
+
 		return schema.UnprocessableContentError(err.Error(), nil)
 	}
 
@@ -220,3 +251,86 @@ func makeListBlobsInclude(opts common.StorageObjectIncludeOptions) container.Lis
 		UncommittedBlobs:    false,
 	}
 }
+
+func newAccessConditions(ifMatch, ifNoneMatch string) *blob.AccessConditions {
+	conditions := &blob.ModifiedAccessConditions{}
+
+	if ifMatch != "" {
+		conditions.IfMatch = toAzureETag(ifMatch)
+	}
+
+	if ifNoneMatch != "" {
+		conditions.IfNoneMatch = toAzureETag(ifNoneMatch)
+	}
+
+	return &blob.AccessConditions{
+		ModifiedAccessConditions: conditions,
+	}
+}
+
+func toAzureETag(value string) *azcore.ETag {
+	etag := azcore.ETagAny
+
+	if value != common.ETagAny {
+		etag = azcore.ETag(strconv.Quote(common.NormalizeETag(value)))
+	}
+
+	return &etag
+}
+
+// isRehydratePending checks if the blob is being rehydrated from the archive tier.
+func isRehydratePending(status *blob.ArchiveStatus) bool {
+	return status != nil && strings.HasPrefix(string(*status), "rehydrate-pending")
+}
+
+// matchOngoingRestore checks if the blob item matches the ongoing restore filter.
+func matchOngoingRestore(opts *common.ListStorageObjectsOptions, item *container.BlobItem) bool {
+	if opts.OngoingRestore == nil {
+		return true
+	}
+
+	return isRehydratePending(item.Properties.ArchiveStatus) == *opts.OngoingRestore
+}
+
+// evalCPKOptions converts server-side encryption options to customer-provided key options.
+// The KMS method uses the key ID as the name of the encryption scope.
+// Blobs are encrypted by Microsoft-managed keys by default.
+func evalCPKOptions(
+	sse *common.ServerSideEncryption,
+) (*blob.CPKInfo, *blob.CPKScopeInfo, error) {
+	if sse == nil {
+		return nil, nil, nil
+	}
+
+	switch sse.Method {
+	case common.ServerSideEncryptionMethodSseC:
+		key, err := sse.CustomerKey()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		keyHash := sha256.Sum256(key)
+
+		return &blob.CPKInfo{
+			EncryptionAlgorithm: utils.ToPtr(blob.EncryptionAlgorithmTypeAES256),
+			EncryptionKey:       utils.ToPtr(base64.StdEncoding.EncodeToString(key)),
+			EncryptionKeySHA256: utils.ToPtr(base64.StdEncoding.EncodeToString(keyHash[:])),
+		}, nil, nil
+	case common.ServerSideEncryptionMethodKms:
+		scope, err := sse.GetKMSKeyID()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return nil, &blob.CPKScopeInfo{
+			EncryptionScope: &scope,
+		}, nil
+	case common.ServerSideEncryptionMethodS3:
+		return nil, nil, nil
+	default:
+		return nil, nil, schema.UnprocessableContentError(
+			"unsupported server-side encryption method: "+string(sse.Method),
+			nil,
+		)
+	}
+}
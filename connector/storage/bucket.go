@@ -11,7 +11,10 @@ func (m *Manager) MakeBucket(
 	ctx context.Context,
 	clientID *common.StorageClientID,
 	args *common.MakeStorageBucketOptions,
-) error {
+) (err error) {
+	record := m.audit.begin(ctx, AuditOperationCreateBucket, args.Name, "")
+	defer func() { record.end(err) }()
+
 	client, bucketName, err := m.GetClientAndBucket(ctx, common.StorageBucketArguments{
 		StorageClientCredentialArguments: common.StorageClientCredentialArguments{
 			ClientID: clientID,
@@ -22,22 +25,42 @@ func (m *Manager) MakeBucket(
 		return err
 	}
 
+	record.setTarget(client, bucketName)
+
+	err = m.authorizeBucket(ctx, PolicyOperationWrite, client, bucketName)
+	if err != nil {
+		return err
+	}
+
 	args.Name = bucketName
 
 	return client.MakeBucket(ctx, args)
 }
 
 // UpdateBucket updates configurations for the bucket.
-func (m *Manager) UpdateBucket(ctx context.Context, args *common.UpdateBucketArguments) error {
+func (m *Manager) UpdateBucket(
+	ctx context.Context,
+	args *common.UpdateBucketArguments,
+) (err error) {
 	if args.IsEmpty() {
 		return nil
 	}
 
+	record := m.audit.begin(ctx, AuditOperationUpdateBucket, args.Name, "")
+	defer func() { record.end(err) }()
+
 	client, bucketName, err := m.GetClientAndBucket(ctx, *args.ToStorageBucketArguments())
 	if err != nil {
 		return err
 	}
 
+	record.setTarget(client, bucketName)
+
+	err = m.authorizeBucket(ctx, PolicyOperationWrite, client, bucketName)
+	if err != nil {
+		return err
+	}
+
 	return client.UpdateBucket(ctx, bucketName, args.UpdateStorageBucketOptions)
 }
 
@@ -59,7 +82,11 @@ func (m *Manager) ListBuckets(
 		}, nil
 	}
 
-	results, err := client.ListBuckets(ctx, options, predicate)
+	results, err := client.ListBuckets(
+		ctx,
+		options,
+		m.policy.FilterBuckets(ctx, string(client.id), predicate),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -82,6 +109,11 @@ func (m *Manager) GetBucket(
 		return nil, err
 	}
 
+	err = m.authorizeBucket(ctx, PolicyOperationRead, client, bucketName)
+	if err != nil {
+		return nil, err
+	}
+
 	result, err := client.GetBucket(ctx, bucketName, options)
 	if err != nil {
 		return nil, err
@@ -106,15 +138,33 @@ func (m *Manager) BucketExists(
 		return false, err
 	}
 
+	err = m.authorizeBucket(ctx, PolicyOperationRead, client, bucketName)
+	if err != nil {
+		return false, err
+	}
+
 	return client.BucketExists(ctx, bucketName)
 }
 
 // RemoveBucket removes a bucket, bucket should be empty to be successfully removed.
-func (m *Manager) RemoveBucket(ctx context.Context, args *common.StorageBucketArguments) error {
+func (m *Manager) RemoveBucket(
+	ctx context.Context,
+	args *common.StorageBucketArguments,
+) (err error) {
+	record := m.audit.begin(ctx, AuditOperationRemoveBucket, args.Bucket, "")
+	defer func() { record.end(err) }()
+
 	client, bucketName, err := m.GetClientAndBucket(ctx, *args)
 	if err != nil {
 		return err
 	}
 
+	record.setTarget(client, bucketName)
+
+	err = m.authorizeBucket(ctx, PolicyOperationDelete, client, bucketName)
+	if err != nil {
+		return err
+	}
+
 	return client.RemoveBucket(ctx, bucketName)
 }
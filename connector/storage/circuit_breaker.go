@@ -0,0 +1,605 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// circuitState represents the state of a circuit breaker.
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitHalfOpen
+	circuitOpen
+)
+
+// String returns the name of the circuit state.
+func (cs circuitState) String() string {
+	switch cs {
+	case circuitHalfOpen:
+		return "half-open"
+	case circuitOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker stops sending requests to an unavailable storage provider.
+// The circuit opens after consecutive provider failures and rejects requests until the open duration elapses.
+// Then a few probe requests are allowed in the half-open state. The circuit closes if all of them succeed,
+// or opens again if any fails.
+type circuitBreaker struct {
+	clientID         common.StorageClientID
+	failureThreshold int
+	openDuration     time.Duration
+	halfOpenRequests int
+	logger           *slog.Logger
+	now              func() time.Time
+
+	mu        sync.Mutex
+	state     circuitState
+	failures  int
+	openedAt  time.Time
+	probes    int
+	successes int
+	// generation is increased on every state change so results of requests
+	// that started in a previous state are ignored.
+	generation uint64
+}
+
+// newCircuitBreaker creates a circuit breaker from the settings. The result is nil if the setting is empty.
+func newCircuitBreaker(
+	clientID common.StorageClientID,
+	settings *common.CircuitBreakerSettings,
+	logger *slog.Logger,
+) *circuitBreaker {
+	if settings == nil {
+		return nil
+	}
+
+	return &circuitBreaker{
+		clientID:         clientID,
+		failureThreshold: settings.GetFailureThreshold(),
+		openDuration:     settings.GetOpenDuration(),
+		halfOpenRequests: settings.GetHalfOpenRequests(),
+		logger:           logger,
+		now:              time.Now,
+	}
+}
+
+// State returns the current state of the circuit.
+func (cb *circuitBreaker) State() circuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == circuitOpen && cb.now().Sub(cb.openedAt) >= cb.openDuration {
+		return circuitHalfOpen
+	}
+
+	return cb.state
+}
+
+// allow checks if the request can be sent to the provider.
+// The done function must be called with the result of the request.
+func (cb *circuitBreaker) allow() (func(ctx context.Context, err error), error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == circuitOpen {
+		if cb.now().Sub(cb.openedAt) < cb.openDuration {
+			return nil, cb.unavailableError()
+		}
+
+		cb.setState(circuitHalfOpen)
+	}
+
+	if cb.state == circuitHalfOpen {
+		if cb.probes >= cb.halfOpenRequests {
+			return nil, cb.unavailableError()
+		}
+
+		cb.probes++
+	}
+
+	generation := cb.generation
+
+	var once sync.Once
+
+	return func(ctx context.Context, err error) {
+		once.Do(func() {
+			cb.record(generation, isProviderFailure(ctx, err))
+		})
+	}, nil
+}
+
+func (cb *circuitBreaker) record(generation uint64, failed bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if generation != cb.generation {
+		return
+	}
+
+	switch cb.state {
+	case circuitClosed:
+		if !failed {
+			cb.failures = 0
+
+			return
+		}
+
+		cb.failures++
+		if cb.failures >= cb.failureThreshold {
+			cb.setState(circuitOpen)
+		}
+	case circuitHalfOpen:
+		if failed {
+			cb.setState(circuitOpen)
+
+			return
+		}
+
+		cb.successes++
+		if cb.successes >= cb.halfOpenRequests {
+			cb.setState(circuitClosed)
+		}
+	case circuitOpen:
+	}
+}
+
+// setState moves the circuit to the state and resets counters. The lock must be held.
+func (cb *circuitBreaker) setState(state circuitState) {
+	previous := cb.state
+
+	cb.state = state
+	cb.generation++
+	cb.failures = 0
+	cb.probes = 0
+	cb.successes = 0
+
+	if state == circuitOpen {
+		cb.openedAt = cb.now()
+	}
+
+	if cb.logger == nil || previous == state {
+		return
+	}
+
+	if state == circuitOpen {
+		cb.logger.Warn(
+			"the circuit of the storage client is open",
+			slog.String("client_id", string(cb.clientID)),
+			slog.String("previous_state", previous.String()),
+			slog.Duration("open_duration", cb.openDuration),
+		)
+	} else {
+		cb.logger.Info(
+			"the circuit of the storage client is "+state.String(),
+			slog.String("client_id", string(cb.clientID)),
+			slog.String("previous_state", previous.String()),
+		)
+	}
+}
+
+// unavailableError returns the error of requests that are rejected by the open circuit.
+func (cb *circuitBreaker) unavailableError() *schema.ConnectorError {
+	return schema.NewConnectorError(
+		http.StatusServiceUnavailable,
+		"the storage client is unavailable. Please retry later",
+		map[string]any{
+			"client_id":     cb.clientID,
+			"circuit_state": circuitOpen.String(),
+		},
+	)
+}
+
+// isProviderFailure checks if the error means that the storage provider is unavailable,
+// such as server errors, network errors and expired requests. Client errors and canceled requests aren't failures.
+func isProviderFailure(ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return errors.Is(ctxErr, context.DeadlineExceeded)
+	}
+
+	// throttled requests are retryable, but the provider is still available.
+	if code, ok := common.GetStorageErrorCode(err); ok {
+		return code == common.ErrorCodeProviderUnavailable
+	}
+
+	var connectorError *schema.ConnectorError
+	if !errors.As(err, &connectorError) {
+		return common.IsTransientError(err)
+	}
+
+	if retryable, ok := connectorError.Details[common.RetryableErrorDetail].(bool); ok && retryable {
+		return true
+	}
+
+	statusCode, ok := connectorError.Details["statusCode"].(int)
+
+	return ok && statusCode >= http.StatusInternalServerError
+}
+
+// guard wraps the storage client with the circuit breaker. The client is returned as is if the breaker is nil.
+func (cb *circuitBreaker) guard(client common.StorageClient) common.StorageClient {
+	if cb == nil {
+		return client
+	}
+
+	return &circuitBreakerClient{
+		StorageClient: client,
+		breaker:       cb,
+	}
+}
+
+// circuitBreakerClient rejects requests to the inner storage client while the circuit is open.
+// Presigned URLs are generated locally so they aren't guarded.
+type circuitBreakerClient struct {
+	common.StorageClient
+
+	breaker *circuitBreaker
+}
+
+var _ common.StorageClient = (*circuitBreakerClient)(nil)
+
+// Close closes the inner client if it holds resources.
+func (cbc *circuitBreakerClient) Close() error {
+	return closeStorageClient(cbc.StorageClient)
+}
+
+// MakeBucket creates a new bucket.
+func (cbc *circuitBreakerClient) MakeBucket(
+	ctx context.Context,
+	options *common.MakeStorageBucketOptions,
+) error {
+	return guardOperation(ctx, cbc.breaker, func() error {
+		return cbc.StorageClient.MakeBucket(ctx, options)
+	})
+}
+
+// ListBuckets lists all buckets.
+func (cbc *circuitBreakerClient) ListBuckets(
+	ctx context.Context,
+	options *common.ListStorageBucketsOptions,
+	predicate func(string) bool,
+) (*common.StorageBucketListResults, error) {
+	return guardResult(ctx, cbc.breaker, func() (*common.StorageBucketListResults, error) {
+		return cbc.StorageClient.ListBuckets(ctx, options, predicate)
+	})
+}
+
+// GetBucket gets a bucket by name.
+func (cbc *circuitBreakerClient) GetBucket(
+	ctx context.Context,
+	name string,
+	options common.BucketOptions,
+) (*common.StorageBucket, error) {
+	return guardResult(ctx, cbc.breaker, func() (*common.StorageBucket, error) {
+		return cbc.StorageClient.GetBucket(ctx, name, options)
+	})
+}
+
+// BucketExists checks if a bucket exists.
+func (cbc *circuitBreakerClient) BucketExists(ctx context.Context, bucketName string) (bool, error) {
+	return guardResult(ctx, cbc.breaker, func() (bool, error) {
+		return cbc.StorageClient.BucketExists(ctx, bucketName)
+	})
+}
+
+// RemoveBucket removes a bucket, bucket should be empty to be successfully removed.
+func (cbc *circuitBreakerClient) RemoveBucket(ctx context.Context, bucketName string) error {
+	return guardOperation(ctx, cbc.breaker, func() error {
+		return cbc.StorageClient.RemoveBucket(ctx, bucketName)
+	})
+}
+
+// UpdateBucket updates configurations for the bucket.
+func (cbc *circuitBreakerClient) UpdateBucket(
+	ctx context.Context,
+	bucketName string,
+	opts common.UpdateStorageBucketOptions,
+) error {
+	return guardOperation(ctx, cbc.breaker, func() error {
+		return cbc.StorageClient.UpdateBucket(ctx, bucketName, opts)
+	})
+}
+
+// ListObjects lists objects in a bucket.
+func (cbc *circuitBreakerClient) ListObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.ListStorageObjectsOptions,
+	predicate func(string) bool,
+) (*common.StorageObjectListResults, error) {
+	return guardResult(ctx, cbc.breaker, func() (*common.StorageObjectListResults, error) {
+		return cbc.StorageClient.ListObjects(ctx, bucketName, opts, predicate)
+	})
+}
+
+// ListIncompleteUploads lists partially uploaded objects in a bucket.
+func (cbc *circuitBreakerClient) ListIncompleteUploads(
+	ctx context.Context,
+	bucketName string,
+	args common.ListIncompleteUploadsOptions,
+) ([]common.StorageObjectMultipartInfo, error) {
+	return guardResult(ctx, cbc.breaker, func() ([]common.StorageObjectMultipartInfo, error) {
+		return cbc.StorageClient.ListIncompleteUploads(ctx, bucketName, args)
+	})
+}
+
+// ListDeletedObjects lists deleted objects in a bucket.
+func (cbc *circuitBreakerClient) ListDeletedObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.ListStorageObjectsOptions,
+	predicate func(string) bool,
+) (*common.StorageObjectListResults, error) {
+	return guardResult(ctx, cbc.breaker, func() (*common.StorageObjectListResults, error) {
+		return cbc.StorageClient.ListDeletedObjects(ctx, bucketName, opts, predicate)
+	})
+}
+
+// GetObject returns a stream of the object data. Only opening the stream is guarded.
+func (cbc *circuitBreakerClient) GetObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (io.ReadCloser, error) {
+	return guardResult(ctx, cbc.breaker, func() (io.ReadCloser, error) {
+		return cbc.StorageClient.GetObject(ctx, bucketName, objectName, opts)
+	})
+}
+
+// PutObject uploads an object.
+func (cbc *circuitBreakerClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts *common.PutStorageObjectOptions,
+	reader io.Reader,
+	objectSize int64,
+) (*common.StorageUploadInfo, error) {
+	return guardResult(ctx, cbc.breaker, func() (*common.StorageUploadInfo, error) {
+		return cbc.StorageClient.PutObject(ctx, bucketName, objectName, opts, reader, objectSize)
+	})
+}
+
+// CopyObject creates or replaces an object through server-side copying of an existing object.
+func (cbc *circuitBreakerClient) CopyObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	src common.StorageCopySrcOptions,
+) (*common.StorageUploadInfo, error) {
+	return guardResult(ctx, cbc.breaker, func() (*common.StorageUploadInfo, error) {
+		return cbc.StorageClient.CopyObject(ctx, dest, src)
+	})
+}
+
+// ComposeObject creates an object by concatenating a list of source objects using server-side copying.
+func (cbc *circuitBreakerClient) ComposeObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	sources []common.StorageCopySrcOptions,
+) (*common.StorageUploadInfo, error) {
+	return guardResult(ctx, cbc.breaker, func() (*common.StorageUploadInfo, error) {
+		return cbc.StorageClient.ComposeObject(ctx, dest, sources)
+	})
+}
+
+// StatObject fetches metadata of an object.
+func (cbc *circuitBreakerClient) StatObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (*common.StorageObject, error) {
+	return guardResult(ctx, cbc.breaker, func() (*common.StorageObject, error) {
+		return cbc.StorageClient.StatObject(ctx, bucketName, objectName, opts)
+	})
+}
+
+// RemoveObject removes an object with some specified options.
+func (cbc *circuitBreakerClient) RemoveObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.RemoveStorageObjectOptions,
+) error {
+	return guardOperation(ctx, cbc.breaker, func() error {
+		return cbc.StorageClient.RemoveObject(ctx, bucketName, objectName, opts)
+	})
+}
+
+// RemoveObjects removes a list of objects. Errors of single objects don't count as provider failures.
+func (cbc *circuitBreakerClient) RemoveObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.RemoveStorageObjectsOptions,
+	predicate func(string) bool,
+) []common.RemoveStorageObjectError {
+	done, err := cbc.breaker.allow()
+	if err != nil {
+		return []common.RemoveStorageObjectError{
+			{Error: err.Error()},
+		}
+	}
+
+	defer done(ctx, nil)
+
+	return cbc.StorageClient.RemoveObjects(ctx, bucketName, opts, predicate)
+}
+
+// UpdateObject updates object configurations.
+func (cbc *circuitBreakerClient) UpdateObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.UpdateStorageObjectOptions,
+) error {
+	return guardOperation(ctx, cbc.breaker, func() error {
+		return cbc.StorageClient.UpdateObject(ctx, bucketName, objectName, opts)
+	})
+}
+
+// RestoreObject restores a soft-deleted object.
+func (cbc *circuitBreakerClient) RestoreObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+) error {
+	return guardOperation(ctx, cbc.breaker, func() error {
+		return cbc.StorageClient.RestoreObject(ctx, bucketName, objectName)
+	})
+}
+
+// RestoreArchivedObject restores an object from the archive storage tier.
+func (cbc *circuitBreakerClient) RestoreArchivedObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.RestoreArchivedStorageObjectOptions,
+) error {
+	return guardOperation(ctx, cbc.breaker, func() error {
+		return cbc.StorageClient.RestoreArchivedObject(ctx, bucketName, objectName, opts)
+	})
+}
+
+// RemoveIncompleteUpload removes a partially uploaded object.
+func (cbc *circuitBreakerClient) RemoveIncompleteUpload(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+) error {
+	return guardOperation(ctx, cbc.breaker, func() error {
+		return cbc.StorageClient.RemoveIncompleteUpload(ctx, bucketName, objectName)
+	})
+}
+
+// guardResult runs the operation if the circuit allows it and records the result.
+func guardResult[T any](ctx context.Context, cb *circuitBreaker, fn func() (T, error)) (T, error) {
+	done, err := cb.allow()
+	if err != nil {
+		var empty T
+
+		return empty, err
+	}
+
+	result, err := fn()
+	done(ctx, err)
+
+	return result, err
+}
+
+// guardOperation runs the operation without result if the circuit allows it and records the error.
+func guardOperation(ctx context.Context, cb *circuitBreaker, fn func() error) error {
+	_, err := guardResult(ctx, cb, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+
+	return err
+}
+
+// CircuitStates returns states of circuit breakers of configured clients.
+// Clients without the circuit breaker are omitted.
+func (m *Manager) CircuitStates() map[string]string {
+	results := map[string]string{}
+
+	for _, client := range m.getClients() {
+		if client.breaker != nil {
+			results[string(client.id)] = client.breaker.State().String()
+		}
+	}
+
+	return results
+}
+
+// HealthCheck returns an error only if circuits of all configured clients are open,
+// so that the connector isn't restarted or removed from the load balancer while other clients still work.
+// Clients whose circuits are open are logged.
+func (m *Manager) HealthCheck(ctx context.Context) error {
+	clients := m.getClients()
+	states := m.CircuitStates()
+
+	var unavailable []string
+
+	for clientID, state := range states {
+		if state == circuitOpen.String() {
+			unavailable = append(unavailable, clientID)
+		}
+	}
+
+	if len(unavailable) == 0 {
+		return nil
+	}
+
+	if len(unavailable) < len(clients) {
+		m.logger.WarnContext(
+			ctx,
+			"some storage clients are unavailable",
+			"unavailable_clients", unavailable,
+			"circuit_states", states,
+		)
+
+		return nil
+	}
+
+	return schema.NewConnectorError(
+		http.StatusServiceUnavailable,
+		"all storage clients are unavailable",
+		map[string]any{
+			"unavailable_clients": unavailable,
+			"circuit_states":      states,
+		},
+	)
+}
+
+// registerCircuitMetrics observes states of circuit breakers. The state is 0 if the circuit is closed,
+// 1 if half-open and 2 if open.
+func (m *Manager) registerCircuitMetrics() {
+	stateGauge, err := m.meter.Int64ObservableGauge(
+		"storage.client.circuit_state",
+		metric.WithDescription(
+			"The state of the circuit breaker of storage clients. 0 is closed, 1 is half-open and 2 is open",
+		),
+	)
+	if err != nil {
+		return
+	}
+
+	// the callback is unregistered when the manager is closed so closed managers aren't observed after reloads.
+	m.circuitMetrics, _ = m.meter.RegisterCallback(
+		func(_ context.Context, observer metric.Observer) error {
+			for _, client := range m.getClients() {
+				if client.breaker == nil {
+					continue
+				}
+
+				observer.ObserveInt64(
+					stateGauge,
+					int64(client.breaker.State()),
+					metric.WithAttributes(
+						attribute.String("client_id", string(client.id)),
+						attribute.String("provider", string(client.provider)),
+					),
+				)
+			}
+
+			return nil
+		},
+		stateGauge,
+	)
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"gotest.tools/v3/assert"
+)
+
+func assertCircuitOpen(t *testing.T, err error) {
+	t.Helper()
+
+	var connectorError *schema.ConnectorError
+	assert.Assert(t, errors.As(err, &connectorError))
+	assert.Equal(t, connectorError.StatusCode(), http.StatusServiceUnavailable)
+	assert.Equal(t, connectorError.Details["circuit_state"], "open")
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker("test", &common.CircuitBreakerSettings{
+		FailureThreshold: 2,
+		OpenDuration:     10,
+		HalfOpenRequests: 1,
+	}, nil)
+	breaker.now = func() time.Time { return now }
+
+	unavailable := schema.NewConnectorError(http.StatusBadGateway, "bad gateway", map[string]any{
+		"statusCode": http.StatusBadGateway,
+	})
+	inner := &mockFlakyClient{failures: 3, err: unavailable}
+	client := breaker.guard(inner)
+
+	statObject := func() error {
+		_, err := client.StatObject(context.TODO(), "bucket", "a.txt", common.GetStorageObjectOptions{})
+
+		return err
+	}
+
+	assert.ErrorIs(t, statObject(), unavailable)
+	assert.Equal(t, breaker.State(), circuitClosed)
+	assert.ErrorIs(t, statObject(), unavailable)
+	assert.Equal(t, breaker.State(), circuitOpen)
+
+	// requests fail fast without calling the provider.
+	assertCircuitOpen(t, statObject())
+	assert.Equal(t, inner.calls, 2)
+
+	// the failed probe opens the circuit again.
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, breaker.State(), circuitHalfOpen)
+	assert.ErrorIs(t, statObject(), unavailable)
+	assert.Equal(t, breaker.State(), circuitOpen)
+	assertCircuitOpen(t, statObject())
+
+	now = now.Add(10 * time.Second)
+	assert.NilError(t, statObject())
+	assert.Equal(t, breaker.State(), circuitClosed)
+	assert.Equal(t, inner.calls, 4)
+}
+
+func TestCircuitBreakerHalfOpenProbes(t *testing.T) {
+	now := time.Now()
+	breaker := newCircuitBreaker("test", &common.CircuitBreakerSettings{
+		FailureThreshold: 1,
+		HalfOpenRequests: 1,
+	}, nil)
+	breaker.now = func() time.Time { return now }
+
+	done, err := breaker.allow()
+	assert.NilError(t, err)
+	done(context.TODO(), common.NewTransientError(errors.New("connection reset")))
+	assert.Equal(t, breaker.State(), circuitOpen)
+
+	now = now.Add(time.Minute)
+
+	// only one probe is allowed at a time.
+	probeDone, err := breaker.allow()
+	assert.NilError(t, err)
+
+	_, err = breaker.allow()
+	assertCircuitOpen(t, err)
+
+	probeDone(context.TODO(), nil)
+	assert.Equal(t, breaker.State(), circuitClosed)
+}
+
+func TestIsProviderFailure(t *testing.T) {
+	canceledCtx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	expiredCtx, cancelExpired := context.WithDeadline(context.TODO(), time.Now())
+	defer cancelExpired()
+
+	notFound := schema.UnprocessableContentError("not found", map[string]any{
+		"statusCode": http.StatusNotFound,
+	})
+	internalError := schema.NewConnectorError(http.StatusInternalServerError, "internal error", map[string]any{
+		"statusCode": http.StatusInternalServerError,
+	})
+
+	assert.Assert(t, !isProviderFailure(context.TODO(), nil))
+	assert.Assert(t, !isProviderFailure(context.TODO(), notFound))
+	assert.Assert(t, !isProviderFailure(canceledCtx, internalError))
+	assert.Assert(t, isProviderFailure(expiredCtx, notFound))
+	assert.Assert(t, isProviderFailure(context.TODO(), internalError))
+	assert.Assert(t, isProviderFailure(context.TODO(), common.NewTransientError(errors.New("connection refused"))))
+	assert.Assert(t, !isProviderFailure(context.TODO(), errors.New("invalid argument")))
+
+	throttled := common.NewStorageError(common.ErrorCodeThrottled, "slow down", map[string]any{
+		"statusCode": http.StatusServiceUnavailable,
+	})
+	unavailable := common.NewStorageError(common.ErrorCodeProviderUnavailable, "unavailable", nil)
+
+	assert.Assert(t, !isProviderFailure(context.TODO(), throttled))
+	assert.Assert(t, isProviderFailure(context.TODO(), unavailable))
+}
+
+func TestHealthCheck(t *testing.T) {
+	settings := &common.CircuitBreakerSettings{FailureThreshold: 1, OpenDuration: 60}
+	clients := []Client{
+		{id: "a", breaker: newCircuitBreaker("a", settings, nil)},
+		{id: "b", breaker: newCircuitBreaker("b", settings, nil)},
+	}
+	manager := &Manager{logger: slog.Default()}
+	manager.clients.Store(&clients)
+
+	openCircuit := func(breaker *circuitBreaker) {
+		breaker.state = circuitOpen
+		breaker.openedAt = time.Now()
+	}
+
+	assert.NilError(t, manager.HealthCheck(context.TODO()))
+
+	// the connector is healthy while any client is available.
+	openCircuit(clients[0].breaker)
+	assert.NilError(t, manager.HealthCheck(context.TODO()))
+
+	openCircuit(clients[1].breaker)
+
+	var connectorError *schema.ConnectorError
+	assert.Assert(t, errors.As(manager.HealthCheck(context.TODO()), &connectorError))
+	assert.Equal(t, connectorError.StatusCode(), http.StatusServiceUnavailable)
+	assert.DeepEqual(t, connectorError.Details["circuit_states"], map[string]string{"a": "open", "b": "open"})
+}
@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+const (
+	defaultClientCacheMaxSize = 100
+	defaultClientCacheTTL     = 600
+)
+
+// ClientCacheSettings hold settings of the cache of dynamic-credential clients.
+type ClientCacheSettings struct {
+	// Maximum number of cached clients. Set 0 to disable the cache.
+	MaxSize int `json:"maxSize" jsonschema:"min=0,default=100" yaml:"maxSize"`
+	// Time in seconds that an unused client is kept in the cache.
+	TTL int `json:"ttl"     jsonschema:"min=1,default=600" yaml:"ttl"`
+}
+
+var defaultClientCacheSettings = ClientCacheSettings{
+	MaxSize: defaultClientCacheMaxSize,
+	TTL:     defaultClientCacheTTL,
+}
+
+type clientCacheEntry struct {
+	key       string
+	client    *Client
+	expiresAt time.Time
+}
+
+// clientCache is an LRU cache of clients that are created from dynamic credentials.
+// Reusing clients keeps HTTP connections alive so requests don't pay the cost of TLS handshakes.
+type clientCache struct {
+	maxSize int
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+
+	requestCounter  metric.Int64Counter
+	evictionCounter metric.Int64Counter
+	registration    metric.Registration
+}
+
+func newClientCache(settings *ClientCacheSettings, meter metric.Meter) *clientCache {
+	if settings == nil {
+		settings = &defaultClientCacheSettings
+	}
+
+	ttl := settings.TTL
+	if ttl <= 0 {
+		ttl = defaultClientCacheTTL
+	}
+
+	requestCounter, err := meter.Int64Counter(
+		"storage.client_cache.requests",
+		metric.WithDescription("The number of lookups in the cache of dynamic-credential clients"),
+	)
+	if err != nil {
+		requestCounter = noop.Int64Counter{}
+	}
+
+	evictionCounter, err := meter.Int64Counter(
+		"storage.client_cache.evictions",
+		metric.WithDescription("The number of clients that are evicted from the cache"),
+	)
+	if err != nil {
+		evictionCounter = noop.Int64Counter{}
+	}
+
+	cc := &clientCache{
+		maxSize:         settings.MaxSize,
+		ttl:             time.Duration(ttl) * time.Second,
+		entries:         make(map[string]*list.Element),
+		order:           list.New(),
+		requestCounter:  requestCounter,
+		evictionCounter: evictionCounter,
+	}
+
+	sizeGauge, err := meter.Int64ObservableGauge(
+		"storage.client_cache.size",
+		metric.WithDescription("The number of cached dynamic-credential clients"),
+	)
+	if err == nil {
+		// the callback is unregistered when the cache is closed so closed caches aren't observed after reloads.
+		cc.registration, _ = meter.RegisterCallback(
+			func(_ context.Context, observer metric.Observer) error {
+				observer.ObserveInt64(sizeGauge, int64(cc.Len()))
+
+				return nil
+			},
+			sizeGauge,
+		)
+	}
+
+	return cc
+}
+
+// Close evicts all cached clients and unregisters the metric callback.
+func (cc *clientCache) Close() {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	for cc.order.Len() > 0 {
+		elem := cc.order.Back()
+		entry, _ := cc.order.Remove(elem).(*clientCacheEntry)
+		delete(cc.entries, entry.key)
+		entry.client.retire()
+	}
+
+	if cc.registration != nil {
+		_ = cc.registration.Unregister()
+		cc.registration = nil
+	}
+}
+
+// Get returns the cached client of the key, or creates and caches a new one.
+func (cc *clientCache) Get(
+	ctx context.Context,
+	key string,
+	create func() (*Client, error),
+) (*Client, error) {
+	if cc.maxSize <= 0 {
+		client, err := create()
+		if err != nil {
+			return nil, err
+		}
+
+		// uncached clients are closed after the request releases them.
+		if leases := clientLeasesFromContext(ctx); leases != nil && leases.add(client) {
+			client.retire()
+		}
+
+		return client, nil
+	}
+
+	if client := cc.get(ctx, key); client != nil {
+		return client, nil
+	}
+
+	client, err := create()
+	if err != nil {
+		return nil, err
+	}
+
+	return cc.put(ctx, key, client), nil
+}
+
+// Len returns the number of cached clients.
+func (cc *clientCache) Len() int {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	return cc.order.Len()
+}
+
+func (cc *clientCache) get(ctx context.Context, key string) *Client {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	now := time.Now()
+	cc.evictExpired(ctx, now)
+
+	elem, ok := cc.entries[key]
+	if !ok {
+		cc.recordRequest(ctx, "miss")
+
+		return nil
+	}
+
+	entry, _ := elem.Value.(*clientCacheEntry)
+	entry.expiresAt = now.Add(cc.ttl)
+	cc.order.MoveToFront(elem)
+	cc.recordRequest(ctx, "hit")
+
+	return entry.client
+}
+
+func (cc *clientCache) put(ctx context.Context, key string, client *Client) *Client {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if elem, ok := cc.entries[key]; ok {
+		// another request created the client concurrently. Keep the cached one.
+		client.retire()
+
+		entry, _ := elem.Value.(*clientCacheEntry)
+		cc.order.MoveToFront(elem)
+
+		return entry.client
+	}
+
+	cc.entries[key] = cc.order.PushFront(&clientCacheEntry{
+		key:       key,
+		client:    client,
+		expiresAt: time.Now().Add(cc.ttl),
+	})
+
+	for cc.order.Len() > cc.maxSize {
+		cc.evict(ctx, cc.order.Back(), "capacity")
+	}
+
+	return client
+}
+
+func (cc *clientCache) evictExpired(ctx context.Context, now time.Time) {
+	for elem := cc.order.Back(); elem != nil; {
+		entry, _ := elem.Value.(*clientCacheEntry)
+		if entry.expiresAt.After(now) {
+			return
+		}
+
+		prev := elem.Prev()
+		cc.evict(ctx, elem, "expired")
+		elem = prev
+	}
+}
+
+func (cc *clientCache) evict(ctx context.Context, elem *list.Element, reason string) {
+	entry, _ := cc.order.Remove(elem).(*clientCacheEntry)
+	delete(cc.entries, entry.key)
+
+	// the client is closed after in-flight requests release it.
+	entry.client.retire()
+	cc.evictionCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", reason)))
+}
+
+func (cc *clientCache) recordRequest(ctx context.Context, result string) {
+	cc.requestCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("result", result)))
+}
+
+func closeClient(client *Client) {
+	_ = closeStorageClient(client.StorageClient)
+	client.contentCache.Close()
+}
+
+// closeStorageClient closes the storage client if it holds resources such as HTTP connections.
+func closeStorageClient(client common.StorageClient) error {
+	if closer, ok := client.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// newClientCacheKey hashes the client type, endpoint and credentials so that secrets aren't kept as map keys.
+func newClientCacheKey(values ...string) string {
+	hash := sha256.New()
+
+	for _, value := range values {
+		hash.Write([]byte(value))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"go.opentelemetry.io/otel/metric/noop"
+	"gotest.tools/v3/assert"
+)
+
+type mockClosableClient struct {
+	common.StorageClient
+
+	closed bool
+}
+
+func (m *mockClosableClient) Close() error {
+	m.closed = true
+
+	return nil
+}
+
+func TestClientCache(t *testing.T) {
+	cache := newClientCache(&ClientCacheSettings{MaxSize: 2, TTL: 60}, noop.NewMeterProvider().Meter("connector/storage"))
+	created := map[string]*mockClosableClient{}
+
+	getClient := func(key string) *Client {
+		client, err := cache.Get(context.TODO(), key, func() (*Client, error) {
+			inner := &mockClosableClient{}
+			created[key] = inner
+
+			return &Client{StorageClient: inner}, nil
+		})
+		assert.NilError(t, err)
+
+		return client
+	}
+
+	first := getClient("a")
+	assert.Equal(t, getClient("a"), first)
+
+	getClient("b")
+	// a is the most recently used client so b is evicted.
+	getClient("a")
+	getClient("c")
+
+	assert.Equal(t, cache.Len(), 2)
+	assert.Assert(t, created["b"].closed)
+	assert.Assert(t, !created["a"].closed)
+	assert.Equal(t, getClient("a"), first)
+
+	// expired clients are evicted.
+	for _, elem := range cache.entries {
+		entry, _ := elem.Value.(*clientCacheEntry)
+		entry.expiresAt = time.Now().Add(-time.Second)
+	}
+
+	assert.Assert(t, getClient("a") != first)
+	assert.Assert(t, created["c"].closed)
+	assert.Equal(t, cache.Len(), 1)
+
+	assert.Assert(t, newClientCacheKey("s3", "", "a", "b") != newClientCacheKey("s3", "", "ab", ""))
+}
+
+func TestClientCacheDisabled(t *testing.T) {
+	cache := newClientCache(&ClientCacheSettings{MaxSize: 0}, noop.NewMeterProvider().Meter("connector/storage"))
+	create := func() (*Client, error) {
+		return &Client{StorageClient: &mockClosableClient{}}, nil
+	}
+
+	first, err := cache.Get(context.TODO(), "a", create)
+	assert.NilError(t, err)
+
+	second, err := cache.Get(context.TODO(), "a", create)
+	assert.NilError(t, err)
+	assert.Assert(t, first != second)
+	assert.Equal(t, cache.Len(), 0)
+}
+
+func TestClientCacheLease(t *testing.T) {
+	cache := newClientCache(&ClientCacheSettings{MaxSize: 1, TTL: 60}, noop.NewMeterProvider().Meter("connector/storage"))
+	created := map[string]*mockClosableClient{}
+
+	create := func(key string) func() (*Client, error) {
+		return func() (*Client, error) {
+			inner := &mockClosableClient{}
+			created[key] = inner
+			client := &Client{StorageClient: inner}
+			client.lease = newClientLease(client)
+
+			return client, nil
+		}
+	}
+
+	ctx, release := ContextWithClientLeases(context.TODO())
+
+	client, err := cache.Get(ctx, "a", create("a"))
+	assert.NilError(t, err)
+	assert.Assert(t, clientLeasesFromContext(ctx).add(client))
+
+	// the evicted client is closed after the request releases it.
+	_, err = cache.Get(context.TODO(), "b", create("b"))
+	assert.NilError(t, err)
+	assert.Equal(t, cache.Len(), 1)
+	assert.Assert(t, !created["a"].closed)
+
+	release()
+	assert.Assert(t, created["a"].closed)
+	assert.Assert(t, !clientLeasesFromContext(ctx).add(client))
+
+	// uncached clients are closed after the request releases them.
+	cache = newClientCache(&ClientCacheSettings{MaxSize: 0}, noop.NewMeterProvider().Meter("connector/storage"))
+	ctx, release = ContextWithClientLeases(context.TODO())
+
+	_, err = cache.Get(ctx, "c", create("c"))
+	assert.NilError(t, err)
+	assert.Assert(t, !created["c"].closed)
+
+	release()
+	assert.Assert(t, created["c"].closed)
+}
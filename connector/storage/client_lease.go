@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"sync"
+)
+
+// clientLease counts requests that use a client, so that a client which is evicted from the cache
+// or replaced by a reload isn't closed until the last request releases it.
+type clientLease struct {
+	client *Client
+
+	mu      sync.Mutex
+	refs    int
+	retired bool
+	closed  bool
+}
+
+func newClientLease(client *Client) *clientLease {
+	return &clientLease{client: client}
+}
+
+// acquire holds the client. Returns false if the client is already closed.
+func (cl *clientLease) acquire() bool {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if cl.closed {
+		return false
+	}
+
+	cl.refs++
+
+	return true
+}
+
+// release releases the client and closes it if it's retired and no request holds it.
+func (cl *clientLease) release() {
+	cl.mu.Lock()
+	cl.refs--
+	shouldClose := cl.retired && cl.refs <= 0 && !cl.closed
+	cl.closed = cl.closed || shouldClose
+	cl.mu.Unlock()
+
+	if shouldClose {
+		closeClient(cl.client)
+	}
+}
+
+// retire closes the client once no request holds it.
+func (cl *clientLease) retire() {
+	cl.mu.Lock()
+	cl.retired = true
+	shouldClose := cl.refs <= 0 && !cl.closed
+	cl.closed = cl.closed || shouldClose
+	cl.mu.Unlock()
+
+	if shouldClose {
+		closeClient(cl.client)
+	}
+}
+
+// retire closes the client after all requests release it.
+// The client is closed immediately if it isn't reference-counted.
+func (c *Client) retire() {
+	if c.lease == nil {
+		closeClient(c)
+
+		return
+	}
+
+	c.lease.retire()
+}
+
+type clientLeasesContextKey struct{}
+
+// clientLeases hold clients that are used by a request.
+type clientLeases struct {
+	mu     sync.Mutex
+	leases []*clientLease
+}
+
+// ContextWithClientLeases returns a new context that holds storage clients which are used by the request.
+// Clients that are evicted or replaced during the request are closed after the returned function is called.
+func ContextWithClientLeases(ctx context.Context) (context.Context, func()) {
+	leases := &clientLeases{}
+
+	return context.WithValue(ctx, clientLeasesContextKey{}, leases), leases.release
+}
+
+// clientLeasesFromContext gets the client leases of the request if exist.
+func clientLeasesFromContext(ctx context.Context) *clientLeases {
+	leases, ok := ctx.Value(clientLeasesContextKey{}).(*clientLeases)
+	if !ok {
+		return nil
+	}
+
+	return leases
+}
+
+// add holds the client until the request finishes. Returns false if the client is already closed.
+// Clients aren't held if the request doesn't track leases.
+func (cls *clientLeases) add(client *Client) bool {
+	if cls == nil || client.lease == nil {
+		return true
+	}
+
+	if !client.lease.acquire() {
+		return false
+	}
+
+	cls.mu.Lock()
+	cls.leases = append(cls.leases, client.lease)
+	cls.mu.Unlock()
+
+	return true
+}
+
+func (cls *clientLeases) release() {
+	cls.mu.Lock()
+	leases := cls.leases
+	cls.leases = nil
+	cls.mu.Unlock()
+
+	for _, lease := range leases {
+		lease.release()
+	}
+}
@@ -58,12 +58,24 @@ type StorageClientCredentialArguments struct {
 	Endpoint        string               `json:"endpoint,omitempty"`
 	AccessKeyID     string               `json:"access_key_id,omitempty"`
 	SecretAccessKey string               `json:"secret_access_key,omitempty"`
+	// The session token of temporary S3 credentials that are issued by STS.
+	SessionToken string `json:"session_token,omitempty"`
+	// The service account JSON credentials of Google Cloud Storage.
+	ServiceAccountJSON string `json:"service_account_json,omitempty"`
+	// The OAuth2 access token of Google Cloud Storage.
+	AccessToken string `json:"access_token,omitempty"`
+	// The shared access signature token of Azure Blob Storage.
+	SASToken string `json:"sas_token,omitempty"`
+	// The service URL of the Azure Blob Storage account.
+	AccountURL string `json:"account_url,omitempty"`
 }
 
 // IsEmpty checks if all properties are empty.
 func (ca StorageClientCredentialArguments) IsEmpty() bool {
 	return ca.ClientType == nil || !ca.ClientType.IsValid() ||
-		(ca.AccessKeyID == "" && ca.SecretAccessKey == "" && ca.Endpoint == "")
+		(ca.AccessKeyID == "" && ca.SecretAccessKey == "" && ca.Endpoint == "" &&
+			ca.ServiceAccountJSON == "" && ca.AccessToken == "" &&
+			ca.SASToken == "" && ca.AccountURL == "")
 }
 
 // MakeStorageBucketArguments holds all arguments to tweak bucket creation.
@@ -202,6 +214,8 @@ type ListStorageObjectsOptions struct {
 	// Options to be included for the object information.
 	Include    StorageObjectIncludeOptions
 	NumThreads int
+	// Only list objects whose restore from the archive tier is (or isn't) in progress.
+	OngoingRestore *bool
 }
 
 // GetStorageObjectArguments are used to specify additional headers or options during GET requests.
@@ -217,12 +231,16 @@ type GetStorageObjectArguments struct {
 type GetStorageObjectOptions struct {
 	Headers       []StorageKeyValue `json:"headers,omitempty"`
 	RequestParams []StorageKeyValue `json:"request_params,omitempty"`
-	// ServerSideEncryption *ServerSideEncryptionMethod `json:"serverSideEncryption"`
-	VersionID  *string `json:"version_id"`
-	PartNumber *int    `json:"part_number"`
+	// Server-side encryption options. Required to read objects which were encrypted with customer-provided keys.
+	ServerSideEncryption *ServerSideEncryption `json:"server_side_encryption"`
+	VersionID            *string               `json:"version_id"`
+	PartNumber           *int                  `json:"part_number"`
 	// Options to be included for the object information.
 	Include     StorageObjectIncludeOptions `json:"-"`
 	PreValidate func(*StorageObject) error  `json:"-"`
+	// Only download the object if its ETag doesn't match the value.
+	// The download fails with the precondition failed error if the object isn't modified.
+	IfNoneMatch string `json:"-"`
 }
 
 // StorageCopyDestOptions represents options specified by user for CopyObject/ComposeObject APIs.
@@ -232,9 +250,9 @@ type StorageCopyDestOptions struct {
 	// points to destination object
 	Name string `json:"name"`
 
-	// `Encryption` is the key info for server-side-encryption with customer
-	// provided key. If it is nil, no encryption is performed.
-	// Encryption *ServerSideEncryptionMethod `json:"encryption"`
+	// `Encryption` is the key info for server-side-encryption of the destination object.
+	// If it is nil, the default encryption of the bucket is applied.
+	Encryption *ServerSideEncryption `json:"encryption"`
 
 	// `userMeta` is the user-metadata key-value pairs to be set on the
 	// destination. The keys are automatically prefixed with `x-amz-meta-`
@@ -275,7 +293,8 @@ type StorageCopySrcOptions struct {
 	MatchRange           bool       `json:"match_range,omitempty"`
 	Start                int64      `json:"start,omitempty"`
 	End                  int64      `json:"end,omitempty"`
-	// Encryption           *ServerSideEncryptionMethod `json:"encryption"`
+	// The customer-provided key to decrypt the source object if it was encrypted with the SSE_C method.
+	Encryption *ServerSideEncryption `json:"encryption"`
 }
 
 // RemoveStorageObjectArguments represent arguments specified by user for RemoveObject call.
@@ -311,6 +330,10 @@ type UpdateStorageObjectOptions struct {
 	LegalHold *bool                             `json:"legal_hold"`
 	Metadata  *[]StorageKeyValue                `json:"metadata"`
 	Tags      *[]StorageKeyValue                `json:"tags"`
+	// Only update the object if its ETag matches the value. Use * to require an existing object.
+	IfMatch string `json:"if_match,omitempty"`
+	// Only update the object if its ETag doesn't match the value.
+	IfNoneMatch string `json:"if_none_match,omitempty"`
 }
 
 // IsEmpty checks if all elements in the option object is null.
@@ -322,6 +345,11 @@ func (ubo UpdateStorageObjectOptions) IsEmpty() bool {
 		ubo.Metadata == nil
 }
 
+// HasPrecondition checks if the update has any conditional precondition.
+func (ubo UpdateStorageObjectOptions) HasPrecondition() bool {
+	return ubo.IfMatch != "" || ubo.IfNoneMatch != ""
+}
+
 // SetStorageObjectRetentionOptions represents options specified by user for PutObject call.
 type SetStorageObjectRetentionOptions struct {
 	Mode             *StorageRetentionMode `json:"mode"`
@@ -362,15 +390,16 @@ type PutStorageObjectOptions struct {
 	CacheControl       string                            `json:"cache_control,omitempty"`
 	Expires            *time.Time                        `json:"expires,omitempty"`
 	Retention          *PutStorageObjectRetentionOptions `json:"retention,omitempty"`
-	// ServerSideEncryption    *ServerSideEncryptionMethod `json:"serverSideEncryption,omitempty"`
-	NumThreads              uint   `json:"num_threads,omitempty"`
-	StorageClass            string `json:"storage_class,omitempty"`
-	WebsiteRedirectLocation string `json:"website_redirect_location,omitempty"`
-	PartSize                uint64 `json:"part_size,omitempty"`
-	LegalHold               *bool  `json:"legal_hold"`
-	SendContentMd5          bool   `json:"send_content_md5,omitempty"`
-	DisableContentSha256    bool   `json:"disable_content_sha256,omitempty"`
-	DisableMultipart        bool   `json:"disable_multipart,omitempty"`
+	// Server-side encryption options of the object.
+	ServerSideEncryption    *ServerSideEncryption `json:"server_side_encryption"`
+	NumThreads              uint                  `json:"num_threads,omitempty"`
+	StorageClass            string                `json:"storage_class,omitempty"`
+	WebsiteRedirectLocation string                `json:"website_redirect_location,omitempty"`
+	PartSize                uint64                `json:"part_size,omitempty"`
+	LegalHold               *bool                 `json:"legal_hold"`
+	SendContentMd5          bool                  `json:"send_content_md5,omitempty"`
+	DisableContentSha256    bool                  `json:"disable_content_sha256,omitempty"`
+	DisableMultipart        bool                  `json:"disable_multipart,omitempty"`
 
 	// AutoChecksum is the type of checksum that will be added if no other checksum is added,
 	// like MD5 or SHA256 streaming checksum, and it is feasible for the upload type.
@@ -388,6 +417,19 @@ type PutStorageObjectOptions struct {
 	// fill them serially and upload them in parallel.
 	// This can be used for faster uploads on non-seekable or slow-to-seek input.
 	ConcurrentStreamParts bool `json:"concurrent_stream_parts,omitempty"`
+
+	// Only upload the object if the ETag of the existing object matches the value.
+	// Use * to upload only if the object exists.
+	IfMatch string `json:"if_match,omitempty"`
+
+	// Only upload the object if the ETag of the existing object doesn't match the value.
+	// Use * to upload only if the object doesn't exist (create-only).
+	IfNoneMatch string `json:"if_none_match,omitempty"`
+}
+
+// HasPrecondition checks if the upload has any conditional precondition.
+func (opts PutStorageObjectOptions) HasPrecondition() bool {
+	return opts.IfMatch != "" || opts.IfNoneMatch != ""
 }
 
 // PresignedURLResponse holds the presigned URL and expiry information.
@@ -428,6 +470,39 @@ type RestoreStorageObjectArguments struct {
 	Where schema.Expression `json:"where" ndc:"predicate=StorageObjectFilter"`
 }
 
+// RestoreArchivedStorageObjectArguments represent arguments specified by user for RestoreArchivedObject call.
+type RestoreArchivedStorageObjectArguments struct {
+	StorageBucketArguments
+	RestoreArchivedStorageObjectOptions
+
+	Name  string            `json:"name"`
+	Where schema.Expression `json:"where" ndc:"predicate=StorageObjectFilter"`
+}
+
+// RestoreArchivedStorageObjectOptions represent options to restore an object from the archive tier.
+type RestoreArchivedStorageObjectOptions struct {
+	// The version ID of the archived object.
+	VersionID string `json:"version_id,omitempty"`
+	// Lifetime of the active copy in days. Required by S3.
+	Days *int `json:"days"`
+	// The data access tier to retrieve the archived object. S3 only.
+	Tier *StorageRestoreTier `json:"tier"`
+	// The access tier of the rehydrated blob. Azure only. Default is Hot.
+	StorageClass *string `json:"storage_class"`
+	// The priority of the rehydration. Azure only.
+	Priority *StorageRehydratePriority `json:"priority"`
+}
+
+// PurgeStorageObjectCacheArguments represent arguments specified by user for the PurgeCache call.
+type PurgeStorageObjectCacheArguments struct {
+	// The client ID. Caches of all clients are purged if empty.
+	ClientID *StorageClientID `json:"client_id,omitempty"`
+	// The bucket name. All cached objects of the client are purged if empty. Required if access policies are enabled.
+	Bucket string `json:"bucket,omitempty"`
+	// Only purge cached objects whose names start with the prefix.
+	Prefix string `json:"prefix,omitempty"`
+}
+
 // PutStorageObjectArguments represents input arguments of the PutObject method.
 type PutStorageObjectArguments struct {
 	StorageBucketArguments
@@ -443,6 +518,22 @@ type UploadStorageObjectFromURLArguments struct {
 	HTTPRequestOptions
 }
 
+// UploadStorageObjectsFromURLsArguments represent input arguments of the UploadStorageObjectsFromURLs method.
+type UploadStorageObjectsFromURLsArguments struct {
+	StorageBucketArguments
+
+	Items []UploadStorageObjectFromURLItem `json:"items"`
+	Where schema.Expression                `json:"where" ndc:"predicate=StorageObjectFilter"`
+}
+
+// UploadStorageObjectFromURLItem represents an item of the batch upload from URLs.
+type UploadStorageObjectFromURLItem struct {
+	HTTPRequestOptions
+
+	Name    string                  `json:"name"`
+	Options PutStorageObjectOptions `json:"options,omitempty"`
+}
+
 // DownloadStorageObjectAsCsvArguments are used to specify additional headers or options during GET requests.
 type DownloadStorageObjectAsCsvArguments struct {
 	GetStorageObjectArguments
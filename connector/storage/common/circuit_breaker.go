@@ -0,0 +1,99 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/invopop/jsonschema"
+)
+
+const (
+	defaultCircuitFailureThreshold = 5
+	defaultCircuitOpenDuration     = 30
+	defaultCircuitHalfOpenRequests = 1
+)
+
+// CircuitBreakerSettings hold settings of the circuit breaker of a storage client.
+// The circuit opens after consecutive provider failures so requests fail fast instead of waiting for timeouts.
+type CircuitBreakerSettings struct {
+	// Number of consecutive failures that open the circuit.
+	FailureThreshold int `json:"failureThreshold,omitempty" jsonschema:"min=1,default=5"  mapstructure:"failureThreshold" yaml:"failureThreshold,omitempty"`
+	// Time in seconds that the circuit stays open before probe requests are allowed.
+	OpenDuration int `json:"openDuration,omitempty"     jsonschema:"min=1,default=30" mapstructure:"openDuration"     yaml:"openDuration,omitempty"`
+	// Number of probe requests in the half-open state. The circuit closes when all of them succeed.
+	HalfOpenRequests int `json:"halfOpenRequests,omitempty" jsonschema:"min=1,default=1"  mapstructure:"halfOpenRequests" yaml:"halfOpenRequests,omitempty"`
+}
+
+// Validate checks if the circuit breaker settings are valid.
+func (cbs CircuitBreakerSettings) Validate() error {
+	if cbs.FailureThreshold < 0 {
+		return errors.New("failureThreshold must not be negative")
+	}
+
+	if cbs.OpenDuration < 0 {
+		return errors.New("openDuration must not be negative")
+	}
+
+	if cbs.HalfOpenRequests < 0 {
+		return errors.New("halfOpenRequests must not be negative")
+	}
+
+	return nil
+}
+
+// GetFailureThreshold returns the number of consecutive failures that open the circuit.
+func (cbs CircuitBreakerSettings) GetFailureThreshold() int {
+	if cbs.FailureThreshold <= 0 {
+		return defaultCircuitFailureThreshold
+	}
+
+	return cbs.FailureThreshold
+}
+
+// GetOpenDuration returns the duration that the circuit stays open.
+func (cbs CircuitBreakerSettings) GetOpenDuration() time.Duration {
+	if cbs.OpenDuration <= 0 {
+		return defaultCircuitOpenDuration * time.Second
+	}
+
+	return time.Duration(cbs.OpenDuration) * time.Second
+}
+
+// GetHalfOpenRequests returns the number of probe requests in the half-open state.
+func (cbs CircuitBreakerSettings) GetHalfOpenRequests() int {
+	if cbs.HalfOpenRequests <= 0 {
+		return defaultCircuitHalfOpenRequests
+	}
+
+	return cbs.HalfOpenRequests
+}
+
+// JSONSchema is used to generate a custom jsonschema.
+func (cbs CircuitBreakerSettings) JSONSchema() *jsonschema.Schema {
+	properties := jsonschema.NewProperties()
+	properties.Set("failureThreshold", &jsonschema.Schema{
+		Description: "Number of consecutive failures that open the circuit",
+		Type:        "integer",
+		Minimum:     json.Number("1"),
+		Default:     defaultCircuitFailureThreshold,
+	})
+	properties.Set("openDuration", &jsonschema.Schema{
+		Description: "Time in seconds that the circuit stays open before probe requests are allowed",
+		Type:        "integer",
+		Minimum:     json.Number("1"),
+		Default:     defaultCircuitOpenDuration,
+	})
+	properties.Set("halfOpenRequests", &jsonschema.Schema{
+		Description: "Number of probe requests in the half-open state. The circuit closes when all of them succeed",
+		Type:        "integer",
+		Minimum:     json.Number("1"),
+		Default:     defaultCircuitHalfOpenRequests,
+	})
+
+	return &jsonschema.Schema{
+		Description: "Circuit breaker of the client that fails requests fast when the storage provider is unavailable",
+		Type:        "object",
+		Properties:  properties,
+	}
+}
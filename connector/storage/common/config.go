@@ -29,6 +29,22 @@ type BaseClientConfig struct {
 	// This setting is useful to let the connector know which buckets belong to this client.
 	// The empty value means all buckets are allowed. The storage server will handle the validation.
 	AllowedBuckets []string `json:"allowedBuckets,omitempty"         mapstructure:"allowedBuckets"         yaml:"allowedBuckets,omitempty"`
+	// Client-side envelope encryption settings. Objects are encrypted before uploading and decrypted after downloading.
+	Encryption *ClientEncryptionConfig `json:"encryption,omitempty"             mapstructure:"encryption"             yaml:"encryption,omitempty"`
+	// Customer-provided keys of the server-side encryption. Arguments can reference keys by ID instead of sending raw keys.
+	CustomerKeys []EncryptionKeyConfig `json:"customerKeys,omitempty"           mapstructure:"customerKeys"           yaml:"customerKeys,omitempty"`
+	// Restrictions of uploaded objects. The first policy that matches the bucket applies.
+	UploadPolicies []UploadPolicyConfig `json:"uploadPolicies,omitempty"         mapstructure:"uploadPolicies"         yaml:"uploadPolicies,omitempty"`
+	// Retry policy of idempotent operations. Built-in retries of provider SDKs and the maxRetries setting are ignored if set.
+	Retry *RetryPolicy `json:"retry,omitempty"                  mapstructure:"retry"                  yaml:"retry,omitempty"`
+	// Circuit breaker that fails requests fast when the storage provider is unavailable.
+	CircuitBreaker *CircuitBreakerSettings `json:"circuitBreaker,omitempty"         mapstructure:"circuitBreaker"         yaml:"circuitBreaker,omitempty"`
+	// In-memory cache of object metadata and listings.
+	MetadataCache *MetadataCacheSettings `json:"metadataCache,omitempty"          mapstructure:"metadataCache"          yaml:"metadataCache,omitempty"`
+	// Cache of small downloaded objects.
+	ContentCache *ContentCacheSettings `json:"contentCache,omitempty"           mapstructure:"contentCache"           yaml:"contentCache,omitempty"`
+	// Rate limits and concurrency quotas of the client.
+	Limits *ClientLimitSettings `json:"limits,omitempty"                 mapstructure:"limits"                 yaml:"limits,omitempty"`
 }
 
 // Validate checks if the configuration is valid.
@@ -42,6 +58,56 @@ func (bcc BaseClientConfig) Validate() error {
 		return err
 	}
 
+	if bcc.Encryption != nil {
+		if err := bcc.Encryption.Validate(); err != nil {
+			return fmt.Errorf("encryption: %w", err)
+		}
+	}
+
+	if _, err := LoadCustomerKeys(bcc.CustomerKeys); err != nil {
+		return err
+	}
+
+	for i, policy := range bcc.UploadPolicies {
+		if err := policy.Validate(); err != nil {
+			return fmt.Errorf("uploadPolicies[%d]: %w", i, err)
+		}
+	}
+
+	if bcc.Retry != nil {
+		if err := bcc.Retry.Validate(); err != nil {
+			return fmt.Errorf("retry: %w", err)
+		}
+	}
+
+	if bcc.CircuitBreaker != nil {
+		if err := bcc.CircuitBreaker.Validate(); err != nil {
+			return fmt.Errorf("circuitBreaker: %w", err)
+		}
+	}
+
+	if bcc.MetadataCache != nil {
+		if err := bcc.MetadataCache.Validate(); err != nil {
+			return fmt.Errorf("metadataCache: %w", err)
+		}
+	}
+
+	if bcc.ContentCache != nil {
+		if err := bcc.ContentCache.Validate(); err != nil {
+			return fmt.Errorf("contentCache: %w", err)
+		}
+
+		if bcc.Encryption != nil && bcc.ContentCache.Storage == ContentCacheStorageDisk {
+			return fmt.Errorf("contentCache: %w", ErrContentCacheDiskEncrypted)
+		}
+	}
+
+	if bcc.Limits != nil {
+		if err := bcc.Limits.Validate(); err != nil {
+			return fmt.Errorf("limits: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -120,6 +186,22 @@ func (bcc BaseClientConfig) GetJSONSchema(providerTypes []any) *jsonschema.Schem
 			Type: "string",
 		},
 	})
+	properties.Set("encryption", ClientEncryptionConfig{}.JSONSchema())
+	properties.Set("customerKeys", &jsonschema.Schema{
+		Description: "Customer-provided keys of the server-side encryption. Arguments can reference keys by ID instead of sending raw keys",
+		Type:        "array",
+		Items:       EncryptionKeyConfig{}.JSONSchema(),
+	})
+	properties.Set("uploadPolicies", &jsonschema.Schema{
+		Description: "Restrictions of uploaded objects. The first policy that matches the bucket applies",
+		Type:        "array",
+		Items:       UploadPolicyConfig{}.JSONSchema(),
+	})
+	properties.Set("retry", RetryPolicy{}.JSONSchema())
+	properties.Set("circuitBreaker", CircuitBreakerSettings{}.JSONSchema())
+	properties.Set("metadataCache", MetadataCacheSettings{}.JSONSchema())
+	properties.Set("contentCache", ContentCacheSettings{}.JSONSchema())
+	properties.Set("limits", ClientLimitSettings{}.JSONSchema())
 
 	return &jsonschema.Schema{
 		Type:       "object",
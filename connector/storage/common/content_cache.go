@@ -0,0 +1,147 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/invopop/jsonschema"
+)
+
+const (
+	defaultContentCacheMaxSize       = 64 * 1024 * 1024
+	defaultContentCacheMaxObjectSize = 1024 * 1024
+)
+
+// ContentCacheStorage represents the storage of cached object contents.
+type ContentCacheStorage string
+
+const (
+	ContentCacheStorageMemory ContentCacheStorage = "memory"
+	ContentCacheStorageDisk   ContentCacheStorage = "disk"
+)
+
+var enumValues_ContentCacheStorage = []ContentCacheStorage{
+	ContentCacheStorageMemory,
+	ContentCacheStorageDisk,
+}
+
+// Validate checks if the storage type is valid.
+func (ccs ContentCacheStorage) Validate() error {
+	if ccs != "" && !slices.Contains(enumValues_ContentCacheStorage, ccs) {
+		return fmt.Errorf(
+			"invalid content cache storage, expected one of %v, got: %s",
+			enumValues_ContentCacheStorage,
+			ccs,
+		)
+	}
+
+	return nil
+}
+
+// ErrContentCacheDiskEncrypted is returned if the disk storage of the content cache is used with the client-side encryption,
+// because decrypted contents must not be written to the local disk.
+var ErrContentCacheDiskEncrypted = errors.New("the disk storage isn't allowed with the client-side encryption")
+
+// ContentCacheSettings hold settings of the cache of small downloaded objects of a storage client.
+type ContentCacheSettings struct {
+	// Storage of cached contents. Accept one of memory and disk.
+	Storage ContentCacheStorage `json:"storage,omitempty"       jsonschema:"enum=memory,enum=disk,default=memory" mapstructure:"storage"       yaml:"storage,omitempty"`
+	// The parent directory of cached files of the disk storage. Defaults to the temporary directory of the system.
+	Directory string `json:"directory,omitempty"     mapstructure:"directory"     yaml:"directory,omitempty"`
+	// Maximum total size in bytes of cached contents. The least recently used object is evicted when the cache is full.
+	MaxSize int64 `json:"maxSize,omitempty"       jsonschema:"min=1,default=67108864"               mapstructure:"maxSize"       yaml:"maxSize,omitempty"`
+	// Maximum size in bytes of a cached object. Larger objects are always downloaded from the storage.
+	MaxObjectSize int64 `json:"maxObjectSize,omitempty" jsonschema:"min=1,default=1048576"                mapstructure:"maxObjectSize" yaml:"maxObjectSize,omitempty"`
+	// Time in seconds that a cached object is served without checking whether the object was changed.
+	// If empty, the ETag and the last modified time of the object are validated on every download.
+	TTL int `json:"ttl,omitempty"           jsonschema:"min=0"                                mapstructure:"ttl"           yaml:"ttl,omitempty"`
+}
+
+// Validate checks if the cache settings are valid.
+func (ccs ContentCacheSettings) Validate() error {
+	if err := ccs.Storage.Validate(); err != nil {
+		return err
+	}
+
+	if ccs.MaxSize < 0 {
+		return errors.New("maxSize must not be negative")
+	}
+
+	if ccs.MaxObjectSize < 0 {
+		return errors.New("maxObjectSize must not be negative")
+	}
+
+	if ccs.GetMaxObjectSize() > ccs.GetMaxSize() {
+		return errors.New("maxObjectSize must not be greater than maxSize")
+	}
+
+	if ccs.TTL < 0 {
+		return errors.New("ttl must not be negative")
+	}
+
+	return nil
+}
+
+// GetMaxSize returns the maximum total size in bytes of cached contents.
+func (ccs ContentCacheSettings) GetMaxSize() int64 {
+	if ccs.MaxSize <= 0 {
+		return defaultContentCacheMaxSize
+	}
+
+	return ccs.MaxSize
+}
+
+// GetMaxObjectSize returns the maximum size in bytes of a cached object.
+func (ccs ContentCacheSettings) GetMaxObjectSize() int64 {
+	if ccs.MaxObjectSize <= 0 {
+		return defaultContentCacheMaxObjectSize
+	}
+
+	return ccs.MaxObjectSize
+}
+
+// GetTTL returns the duration that a cached object is served without validation.
+func (ccs ContentCacheSettings) GetTTL() time.Duration {
+	return time.Duration(ccs.TTL) * time.Second
+}
+
+// JSONSchema is used to generate a custom jsonschema.
+func (ccs ContentCacheSettings) JSONSchema() *jsonschema.Schema {
+	properties := jsonschema.NewProperties()
+	properties.Set("storage", &jsonschema.Schema{
+		Description: "Storage of cached contents",
+		Type:        "string",
+		Enum:        []any{ContentCacheStorageMemory, ContentCacheStorageDisk},
+		Default:     ContentCacheStorageMemory,
+	})
+	properties.Set("directory", &jsonschema.Schema{
+		Description: "The parent directory of cached files of the disk storage. Defaults to the temporary directory of the system",
+		Type:        "string",
+	})
+	properties.Set("maxSize", &jsonschema.Schema{
+		Description: "Maximum total size in bytes of cached contents. The least recently used object is evicted when the cache is full",
+		Type:        "integer",
+		Minimum:     json.Number("1"),
+		Default:     defaultContentCacheMaxSize,
+	})
+	properties.Set("maxObjectSize", &jsonschema.Schema{
+		Description: "Maximum size in bytes of a cached object. Larger objects are always downloaded from the storage",
+		Type:        "integer",
+		Minimum:     json.Number("1"),
+		Default:     defaultContentCacheMaxObjectSize,
+	})
+	properties.Set("ttl", &jsonschema.Schema{
+		Description: "Time in seconds that a cached object is served without checking whether the object was changed. If empty, the ETag and the last modified time are validated on every download",
+		Type:        "integer",
+		Minimum:     json.Number("0"),
+	})
+
+	return &jsonschema.Schema{
+		Description: "Cache of small downloaded objects of the client",
+		Type:        "object",
+		Properties:  properties,
+	}
+}
@@ -0,0 +1,472 @@
+package common
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"github.com/invopop/jsonschema"
+)
+
+// EncryptionAlgorithmAES256GCMStream is the algorithm of client-side encrypted objects.
+// Data is split into segments of 64 KiB which are sealed with AES-256-GCM.
+const EncryptionAlgorithmAES256GCMStream = "AES256-GCM-STREAM-64K"
+
+// Metadata keys of client-side encrypted objects. Keys are valid identifiers of all providers.
+const (
+	EncryptionMetadataAlgorithm  = "ndc_encryption_algorithm"
+	EncryptionMetadataKeyID      = "ndc_encryption_key_id"
+	EncryptionMetadataWrappedKey = "ndc_encryption_wrapped_key"
+	// The size of the plaintext. It's stored if the size is known when the object is uploaded.
+	EncryptionMetadataPlaintextSize = "ndc_encryption_plaintext_size"
+)
+
+const (
+	encryptionKeySize     = 32
+	encryptionSegmentSize = 64 * 1024
+	encryptionTagSize     = 16
+)
+
+var errEncryptedSegmentTooMany = errors.New("the object exceeds the maximum number of encrypted segments")
+
+// ClientEncryptionConfig represents settings of client-side envelope encryption.
+// Each object is encrypted with a random data key which is wrapped by a master key of the keyring.
+type ClientEncryptionConfig struct {
+	// Buckets whose objects are encrypted. Encrypt objects of all buckets if empty.
+	Buckets []string `json:"buckets,omitempty" mapstructure:"buckets"     yaml:"buckets,omitempty"`
+	// The ID of the master key that wraps data keys of new objects.
+	ActiveKeyID string `json:"activeKeyId"       mapstructure:"activeKeyId" yaml:"activeKeyId"`
+	// Master keys. Keep retired keys in the keyring to decrypt existing objects.
+	Keys []EncryptionKeyConfig `json:"keys"              mapstructure:"keys"        yaml:"keys"`
+}
+
+// Validate checks if the configuration is valid.
+func (cec ClientEncryptionConfig) Validate() error {
+	_, err := cec.NewKeyring()
+
+	return err
+}
+
+// NewKeyring loads master keys of the keyring.
+func (cec ClientEncryptionConfig) NewKeyring() (*EncryptionKeyring, error) {
+	if cec.ActiveKeyID == "" {
+		return nil, errors.New("activeKeyId is required")
+	}
+
+	keyring := &EncryptionKeyring{
+		activeKeyID: cec.ActiveKeyID,
+		buckets:     cec.Buckets,
+		keys:        make(map[string][]byte),
+	}
+
+	for i, keyConfig := range cec.Keys {
+		if keyConfig.ID == "" {
+			return nil, fmt.Errorf("keys[%d]: id is required", i)
+		}
+
+		if _, ok := keyring.keys[keyConfig.ID]; ok {
+			return nil, fmt.Errorf("keys[%d]: duplicated key id %s", i, keyConfig.ID)
+		}
+
+		key, err := keyConfig.load()
+		if err != nil {
+			return nil, fmt.Errorf("keys[%d]: %w", i, err)
+		}
+
+		keyring.keys[keyConfig.ID] = key
+	}
+
+	if _, ok := keyring.keys[cec.ActiveKeyID]; !ok {
+		return nil, fmt.Errorf("the active key %s doesn't exist in the keyring", cec.ActiveKeyID)
+	}
+
+	return keyring, nil
+}
+
+// JSONSchema is used to generate a custom jsonschema.
+func (cec ClientEncryptionConfig) JSONSchema() *jsonschema.Schema {
+	properties := jsonschema.NewProperties()
+	properties.Set("buckets", &jsonschema.Schema{
+		Description: "Buckets whose objects are encrypted. Encrypt objects of all buckets if empty",
+		Type:        "array",
+		Items: &jsonschema.Schema{
+			Type: "string",
+		},
+	})
+	properties.Set("activeKeyId", &jsonschema.Schema{
+		Description: "The ID of the master key that wraps data keys of new objects",
+		Type:        "string",
+	})
+	properties.Set("keys", &jsonschema.Schema{
+		Description: "Master keys. Keep retired keys in the keyring to decrypt existing objects",
+		Type:        "array",
+		Items:       EncryptionKeyConfig{}.JSONSchema(),
+	})
+
+	return &jsonschema.Schema{
+		Description: "Client-side envelope encryption settings",
+		Type:        "object",
+		Properties:  properties,
+		Required:    []string{"activeKeyId", "keys"},
+	}
+}
+
+// EncryptionKeyConfig represents a 256-bit encryption key.
+type EncryptionKeyConfig struct {
+	// The unique ID of the key.
+	ID string `json:"id"             mapstructure:"id"   yaml:"id"`
+	// The base64-encoded 256-bit key.
+	Key *utils.EnvString `json:"key,omitempty"  mapstructure:"key"  yaml:"key,omitempty"`
+	// Path to a file that contains the base64-encoded 256-bit key.
+	File *string `json:"file,omitempty" mapstructure:"file" yaml:"file,omitempty"`
+}
+
+// JSONSchema is used to generate a custom jsonschema.
+func (ekc EncryptionKeyConfig) JSONSchema() *jsonschema.Schema {
+	keyProperties := jsonschema.NewProperties()
+	keyProperties.Set("id", &jsonschema.Schema{
+		Description: "The unique ID of the key",
+		Type:        "string",
+	})
+	keyProperties.Set("key", &jsonschema.Schema{
+		Description: "The base64-encoded 256-bit key",
+		Ref:         "#/$defs/EnvString",
+	})
+	keyProperties.Set("file", &jsonschema.Schema{
+		Description: "Path to a file that contains the base64-encoded 256-bit key",
+		Type:        "string",
+	})
+
+	return &jsonschema.Schema{
+		Type:       "object",
+		Properties: keyProperties,
+		Required:   []string{"id"},
+		OneOf: []*jsonschema.Schema{
+			{Required: []string{"key"}},
+			{Required: []string{"file"}},
+		},
+	}
+}
+
+func (ekc EncryptionKeyConfig) load() ([]byte, error) {
+	var rawKey string
+
+	switch {
+	case ekc.Key != nil:
+		value, err := ekc.Key.GetOrDefault("")
+		if err != nil {
+			return nil, fmt.Errorf("key: %w", err)
+		}
+
+		rawKey = value
+	case ekc.File != nil && *ekc.File != "":
+		content, err := os.ReadFile(*ekc.File)
+		if err != nil {
+			return nil, fmt.Errorf("file: %w", err)
+		}
+
+		rawKey = string(content)
+	default:
+		return nil, errors.New("require either key or file")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(rawKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the base64 key: %w", err)
+	}
+
+	if len(key) != encryptionKeySize {
+		return nil, fmt.Errorf("the key must be %d bytes, got %d", encryptionKeySize, len(key))
+	}
+
+	return key, nil
+}
+
+// LoadCustomerKeys loads customer-provided keys of the server-side encryption.
+// Returns a map of key IDs to base64-encoded keys.
+func LoadCustomerKeys(configs []EncryptionKeyConfig) (map[string]string, error) {
+	results := make(map[string]string)
+
+	for i, keyConfig := range configs {
+		if keyConfig.ID == "" {
+			return nil, fmt.Errorf("customerKeys[%d]: id is required", i)
+		}
+
+		if _, ok := results[keyConfig.ID]; ok {
+			return nil, fmt.Errorf("customerKeys[%d]: duplicated key id %s", i, keyConfig.ID)
+		}
+
+		key, err := keyConfig.load()
+		if err != nil {
+			return nil, fmt.Errorf("customerKeys[%d]: %w", i, err)
+		}
+
+		results[keyConfig.ID] = base64.StdEncoding.EncodeToString(key)
+	}
+
+	return results, nil
+}
+
+// EncryptionKeyring holds master keys of the client-side encryption.
+type EncryptionKeyring struct {
+	activeKeyID string
+	buckets     []string
+	keys        map[string][]byte
+}
+
+// IsBucketEncrypted checks if objects of the bucket are encrypted.
+func (ek *EncryptionKeyring) IsBucketEncrypted(bucketName string) bool {
+	return len(ek.buckets) == 0 || slices.Contains(ek.buckets, bucketName)
+}
+
+// NewDataKey generates a random data key and wraps it with the active master key.
+// Returns the data key and metadata to be stored with the object.
+func (ek *EncryptionKeyring) NewDataKey() ([]byte, []StorageKeyValue, error) {
+	dataKey := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, err
+	}
+
+	aead, err := newAESGCM(ek.keys[ek.activeKeyID])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+
+	wrappedKey := aead.Seal(nonce, nonce, dataKey, []byte(EncryptionAlgorithmAES256GCMStream))
+
+	return dataKey, []StorageKeyValue{
+		{Key: EncryptionMetadataAlgorithm, Value: EncryptionAlgorithmAES256GCMStream},
+		{Key: EncryptionMetadataKeyID, Value: ek.activeKeyID},
+		{Key: EncryptionMetadataWrappedKey, Value: base64.StdEncoding.EncodeToString(wrappedKey)},
+	}, nil
+}
+
+// UnwrapDataKey decrypts the data key from object metadata.
+// Returns nil if the object isn't encrypted.
+func (ek *EncryptionKeyring) UnwrapDataKey(metadata []StorageKeyValue) ([]byte, error) {
+	algorithm := GetEncryptionMetadata(metadata, EncryptionMetadataAlgorithm)
+	if algorithm == "" {
+		return nil, nil
+	}
+
+	if algorithm != EncryptionAlgorithmAES256GCMStream {
+		return nil, fmt.Errorf("unsupported encryption algorithm: %s", algorithm)
+	}
+
+	keyID := GetEncryptionMetadata(metadata, EncryptionMetadataKeyID)
+
+	masterKey, ok := ek.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("the master key %s doesn't exist in the keyring", keyID)
+	}
+
+	wrappedKey, err := base64.StdEncoding.DecodeString(
+		GetEncryptionMetadata(metadata, EncryptionMetadataWrappedKey),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the wrapped data key: %w", err)
+	}
+
+	aead, err := newAESGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(wrappedKey) < aead.NonceSize() {
+		return nil, errors.New("the wrapped data key is malformed")
+	}
+
+	dataKey, err := aead.Open(
+		nil,
+		wrappedKey[:aead.NonceSize()],
+		wrappedKey[aead.NonceSize():],
+		[]byte(algorithm),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap the data key: %w", err)
+	}
+
+	return dataKey, nil
+}
+
+// GetEncryptionMetadata finds the metadata value by key. Keys are case-insensitive.
+func GetEncryptionMetadata(metadata []StorageKeyValue, key string) string {
+	for _, item := range metadata {
+		if strings.EqualFold(item.Key, key) {
+			return item.Value
+		}
+	}
+
+	return ""
+}
+
+// IsEncryptionMetadata checks if the metadata key is reserved for client-side encryption.
+func IsEncryptionMetadata(key string) bool {
+	return strings.EqualFold(key, EncryptionMetadataAlgorithm) ||
+		strings.EqualFold(key, EncryptionMetadataKeyID) ||
+		strings.EqualFold(key, EncryptionMetadataWrappedKey) ||
+		strings.EqualFold(key, EncryptionMetadataPlaintextSize)
+}
+
+// EncryptedSize calculates the size of the encrypted stream from the plaintext size.
+// Returns -1 if the size is unknown.
+func EncryptedSize(size int64) int64 {
+	if size < 0 {
+		return -1
+	}
+
+	segments := max((size+encryptionSegmentSize-1)/encryptionSegmentSize, 1)
+
+	return size + segments*encryptionTagSize
+}
+
+// DecryptedSize calculates the size of the plaintext from the encrypted stream size.
+func DecryptedSize(size int64) int64 {
+	segments := (size + encryptionSegmentSize + encryptionTagSize - 1) / (encryptionSegmentSize + encryptionTagSize)
+
+	return max(size-segments*encryptionTagSize, 0)
+}
+
+// NewEncryptReader creates a reader that encrypts the plaintext stream with the data key.
+func NewEncryptReader(reader io.Reader, dataKey []byte) (io.Reader, error) {
+	aead, err := newAESGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptionStreamReader{
+		source: bufio.NewReaderSize(reader, encryptionSegmentSize),
+		aead:   aead,
+		input:  make([]byte, encryptionSegmentSize),
+		output: make([]byte, 0, encryptionSegmentSize+encryptionTagSize),
+		seal:   true,
+	}, nil
+}
+
+// NewDecryptReader creates a reader that decrypts the encrypted stream with the data key.
+func NewDecryptReader(reader io.ReadCloser, dataKey []byte) (io.ReadCloser, error) {
+	aead, err := newAESGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptionStreamReader{
+		source: bufio.NewReaderSize(reader, encryptionSegmentSize+encryptionTagSize),
+		closer: reader,
+		aead:   aead,
+		input:  make([]byte, encryptionSegmentSize+encryptionTagSize),
+		output: make([]byte, 0, encryptionSegmentSize),
+	}, nil
+}
+
+// encryptionStreamReader seals or opens a stream segment by segment.
+// The nonce of each segment is derived from the segment counter and a flag of the last segment,
+// so reordered, duplicated or truncated segments are detected. Data keys are unique per object.
+type encryptionStreamReader struct {
+	source  *bufio.Reader
+	closer  io.Closer
+	aead    cipher.AEAD
+	seal    bool
+	counter uint32
+	input   []byte
+	output  []byte
+	pending []byte
+	done    bool
+	err     error
+}
+
+// Read implements the io.Reader interface.
+func (esr *encryptionStreamReader) Read(p []byte) (int, error) {
+	for len(esr.pending) == 0 {
+		if esr.err != nil {
+			return 0, esr.err
+		}
+
+		if esr.done {
+			return 0, io.EOF
+		}
+
+		esr.err = esr.nextSegment()
+	}
+
+	n := copy(p, esr.pending)
+	esr.pending = esr.pending[n:]
+
+	return n, nil
+}
+
+// Close implements the io.Closer interface.
+func (esr *encryptionStreamReader) Close() error {
+	if esr.closer == nil {
+		return nil
+	}
+
+	return esr.closer.Close()
+}
+
+func (esr *encryptionStreamReader) nextSegment() error {
+	n, err := io.ReadFull(esr.source, esr.input)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+
+	last := n < len(esr.input)
+	if !last {
+		if _, err := esr.source.Peek(1); err != nil {
+			if !errors.Is(err, io.EOF) {
+				return err
+			}
+
+			last = true
+		}
+	}
+
+	if esr.counter == ^uint32(0) && !last {
+		return errEncryptedSegmentTooMany
+	}
+
+	nonce := make([]byte, esr.aead.NonceSize())
+	binary.BigEndian.PutUint32(nonce[len(nonce)-5:], esr.counter)
+
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+
+	if esr.seal {
+		esr.pending = esr.aead.Seal(esr.output[:0], nonce, esr.input[:n], nil)
+	} else {
+		esr.pending, err = esr.aead.Open(esr.output[:0], nonce, esr.input[:n], nil)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt the object segment %d: %w", esr.counter, err)
+		}
+	}
+
+	esr.counter++
+	esr.done = last
+
+	return nil
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,102 @@
+package common
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"gotest.tools/v3/assert"
+)
+
+func TestEncryptionStream(t *testing.T) {
+	dataKey := make([]byte, encryptionKeySize)
+	_, err := rand.Read(dataKey)
+	assert.NilError(t, err)
+
+	for _, size := range []int{0, 1, encryptionSegmentSize - 1, encryptionSegmentSize, 3*encryptionSegmentSize + 7} {
+		plaintext := make([]byte, size)
+		_, err := rand.Read(plaintext)
+		assert.NilError(t, err)
+
+		encryptReader, err := NewEncryptReader(bytes.NewReader(plaintext), dataKey)
+		assert.NilError(t, err)
+
+		ciphertext, err := io.ReadAll(encryptReader)
+		assert.NilError(t, err)
+		assert.Equal(t, int64(len(ciphertext)), EncryptedSize(int64(size)))
+		assert.Equal(t, DecryptedSize(int64(len(ciphertext))), int64(size))
+
+		decryptReader, err := NewDecryptReader(io.NopCloser(bytes.NewReader(ciphertext)), dataKey)
+		assert.NilError(t, err)
+
+		result, err := io.ReadAll(decryptReader)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, result, plaintext)
+
+		if size <= encryptionSegmentSize {
+			continue
+		}
+
+		// truncated streams are detected.
+		decryptReader, err = NewDecryptReader(
+			io.NopCloser(bytes.NewReader(ciphertext[:encryptionSegmentSize+encryptionTagSize])),
+			dataKey,
+		)
+		assert.NilError(t, err)
+
+		_, err = io.ReadAll(decryptReader)
+		assert.ErrorContains(t, err, "failed to decrypt")
+	}
+}
+
+func TestEncryptionKeyring(t *testing.T) {
+	newKey := func() *utils.EnvString {
+		key := make([]byte, encryptionKeySize)
+		_, err := rand.Read(key)
+		assert.NilError(t, err)
+
+		return &utils.EnvString{
+			Value: utils.ToPtr(base64.StdEncoding.EncodeToString(key)),
+		}
+	}
+
+	oldKey, newerKey := newKey(), newKey()
+
+	oldKeyring, err := ClientEncryptionConfig{
+		ActiveKeyID: "v1",
+		Keys:        []EncryptionKeyConfig{{ID: "v1", Key: oldKey}},
+	}.NewKeyring()
+	assert.NilError(t, err)
+
+	dataKey, metadata, err := oldKeyring.NewDataKey()
+	assert.NilError(t, err)
+	assert.Equal(t, GetEncryptionMetadata(metadata, EncryptionMetadataKeyID), "v1")
+
+	// rotate the active key. Objects which were encrypted by the old key are still readable.
+	keyring, err := ClientEncryptionConfig{
+		ActiveKeyID: "v2",
+		Keys: []EncryptionKeyConfig{
+			{ID: "v1", Key: oldKey},
+			{ID: "v2", Key: newerKey},
+		},
+	}.NewKeyring()
+	assert.NilError(t, err)
+
+	unwrappedKey, err := keyring.UnwrapDataKey(metadata)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, unwrappedKey, dataKey)
+
+	_, metadata, err = keyring.NewDataKey()
+	assert.NilError(t, err)
+	assert.Equal(t, GetEncryptionMetadata(metadata, EncryptionMetadataKeyID), "v2")
+
+	_, err = oldKeyring.UnwrapDataKey(metadata)
+	assert.ErrorContains(t, err, "doesn't exist in the keyring")
+
+	unwrappedKey, err = keyring.UnwrapDataKey([]StorageKeyValue{{Key: "foo", Value: "bar"}})
+	assert.NilError(t, err)
+	assert.Assert(t, unwrappedKey == nil)
+}
@@ -0,0 +1,164 @@
+package common
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+)
+
+// ETagAny is the wildcard value of conditional headers that matches any existing object.
+const ETagAny = "*"
+
+// ErrorCodeDetail is the detail key of the stable code of storage errors.
+const ErrorCodeDetail = "errorCode"
+
+// StorageErrorCode represents a stable code of storage errors that is consistent across storage providers.
+type StorageErrorCode string
+
+const (
+	// ErrorCodeNotFound means the bucket, object or upload doesn't exist.
+	ErrorCodeNotFound StorageErrorCode = "NotFound"
+	// ErrorCodeAlreadyExists means the bucket or object already exists.
+	ErrorCodeAlreadyExists StorageErrorCode = "AlreadyExists"
+	// ErrorCodePreconditionFailed means a condition of the request, e.g. If-Match, isn't satisfied.
+	ErrorCodePreconditionFailed StorageErrorCode = "PreconditionFailed"
+	// ErrorCodeAccessDenied means the credentials aren't allowed to access the resource.
+	ErrorCodeAccessDenied StorageErrorCode = "AccessDenied"
+	// ErrorCodeThrottled means the request rate exceeds the limit of the storage provider.
+	ErrorCodeThrottled StorageErrorCode = "Throttled"
+	// ErrorCodeInvalidArgument means the request is rejected by the storage provider.
+	ErrorCodeInvalidArgument StorageErrorCode = "InvalidArgument"
+	// ErrorCodeProviderUnavailable means the storage provider fails or can't be reached.
+	ErrorCodeProviderUnavailable StorageErrorCode = "ProviderUnavailable"
+)
+
+// HTTPStatus returns the HTTP status of connector errors with the code.
+func (sec StorageErrorCode) HTTPStatus() int {
+	switch sec {
+	case ErrorCodeNotFound:
+		return http.StatusNotFound
+	case ErrorCodeAlreadyExists:
+		return http.StatusConflict
+	case ErrorCodePreconditionFailed:
+		return http.StatusPreconditionFailed
+	case ErrorCodeAccessDenied:
+		return http.StatusForbidden
+	case ErrorCodeThrottled:
+		return http.StatusTooManyRequests
+	case ErrorCodeProviderUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+// IsRetryable checks if requests that failed with the code may succeed later.
+func (sec StorageErrorCode) IsRetryable() bool {
+	return sec == ErrorCodeThrottled || sec == ErrorCodeProviderUnavailable
+}
+
+// NewStorageError creates a connector error with the stable code.
+// The HTTP status and the retryability of the error are derived from the code.
+func NewStorageError(code StorageErrorCode, message string, details map[string]any) *schema.ConnectorError {
+	if details == nil {
+		details = map[string]any{}
+	}
+
+	details[ErrorCodeDetail] = string(code)
+
+	if code.IsRetryable() {
+		details[RetryableErrorDetail] = true
+	}
+
+	return schema.NewConnectorError(code.HTTPStatus(), message, details)
+}
+
+// GetStorageErrorCode returns the stable code of the storage error if exists.
+func GetStorageErrorCode(err error) (StorageErrorCode, bool) {
+	var connectorError *schema.ConnectorError
+	if !errors.As(err, &connectorError) {
+		return "", false
+	}
+
+	code, ok := connectorError.Details[ErrorCodeDetail].(string)
+
+	return StorageErrorCode(code), ok
+}
+
+// EvalStorageErrorCode classifies the HTTP status of a storage provider response.
+// Providers should map their specific error codes first. Conflicts are invalid arguments
+// unless providers map them to AlreadyExists, because 409 is also returned for other conflicts,
+// such as non-empty buckets or concurrent operations.
+func EvalStorageErrorCode(statusCode int) StorageErrorCode {
+	switch {
+	case statusCode == http.StatusNotFound:
+		return ErrorCodeNotFound
+	case statusCode == http.StatusPreconditionFailed || statusCode == http.StatusNotModified:
+		return ErrorCodePreconditionFailed
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ErrorCodeAccessDenied
+	case statusCode == http.StatusTooManyRequests:
+		return ErrorCodeThrottled
+	case statusCode == http.StatusRequestTimeout || statusCode >= http.StatusInternalServerError:
+		return ErrorCodeProviderUnavailable
+	default:
+		return ErrorCodeInvalidArgument
+	}
+}
+
+// NewPreconditionFailedError creates an error for a conditional request whose precondition isn't satisfied.
+func NewPreconditionFailedError(message string, details map[string]any) *schema.ConnectorError {
+	if message == "" {
+		message = "At least one of the pre-conditions you specified did not hold"
+	}
+
+	return NewStorageError(ErrorCodePreconditionFailed, message, details)
+}
+
+// ValidateETagPrecondition evaluates If-Match and If-None-Match conditions against the current ETag of the object.
+// The current ETag is nil if the object doesn't exist.
+func ValidateETagPrecondition(currentETag *string, ifMatch string, ifNoneMatch string) error {
+	if ifMatch != "" {
+		if currentETag == nil {
+			return NewPreconditionFailedError("the object doesn't exist", map[string]any{
+				"if_match": ifMatch,
+			})
+		}
+
+		if ifMatch != ETagAny && !ETagEqual(*currentETag, ifMatch) {
+			return NewPreconditionFailedError("the ETag of the object doesn't match", map[string]any{
+				"if_match": ifMatch,
+				"etag":     *currentETag,
+			})
+		}
+	}
+
+	if ifNoneMatch != "" && currentETag != nil {
+		if ifNoneMatch == ETagAny {
+			return NewPreconditionFailedError("the object already exists", map[string]any{
+				"if_none_match": ifNoneMatch,
+			})
+		}
+
+		if ETagEqual(*currentETag, ifNoneMatch) {
+			return NewPreconditionFailedError("the ETag of the object matches", map[string]any{
+				"if_none_match": ifNoneMatch,
+				"etag":          *currentETag,
+			})
+		}
+	}
+
+	return nil
+}
+
+// ETagEqual compares two entity tags, ignoring quotes and the weak validator prefix.
+func ETagEqual(a, b string) bool {
+	return NormalizeETag(a) == NormalizeETag(b)
+}
+
+// NormalizeETag trims quotes and the weak validator prefix from the entity tag.
+func NormalizeETag(value string) string {
+	return strings.Trim(strings.TrimPrefix(strings.TrimSpace(value), "W/"), `"`)
+}
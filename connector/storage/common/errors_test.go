@@ -0,0 +1,87 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+	"gotest.tools/v3/assert"
+)
+
+func TestValidateETagPrecondition(t *testing.T) {
+	etag := `"abc"`
+
+	testCases := []struct {
+		Name        string
+		ETag        *string
+		IfMatch     string
+		IfNoneMatch string
+		Failed      bool
+	}{
+		{Name: "no_condition", ETag: &etag},
+		{Name: "if_match", ETag: &etag, IfMatch: "abc"},
+		{Name: "if_match_weak", ETag: &etag, IfMatch: `W/"abc"`},
+		{Name: "if_match_mismatch", ETag: &etag, IfMatch: "def", Failed: true},
+		{Name: "if_match_any", ETag: &etag, IfMatch: "*"},
+		{Name: "if_match_not_found", IfMatch: "*", Failed: true},
+		{Name: "if_none_match_any", ETag: &etag, IfNoneMatch: "*", Failed: true},
+		{Name: "if_none_match_any_not_found", IfNoneMatch: "*"},
+		{Name: "if_none_match", ETag: &etag, IfNoneMatch: "def"},
+		{Name: "if_none_match_matched", ETag: &etag, IfNoneMatch: "abc", Failed: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := ValidateETagPrecondition(tc.ETag, tc.IfMatch, tc.IfNoneMatch)
+			if !tc.Failed {
+				assert.NilError(t, err)
+
+				return
+			}
+
+			var connErr *schema.ConnectorError
+			assert.Assert(t, errors.As(err, &connErr))
+			assert.Equal(t, http.StatusPreconditionFailed, connErr.StatusCode())
+		})
+	}
+}
+
+func TestStorageError(t *testing.T) {
+	testCases := []struct {
+		StatusCode int
+		Code       StorageErrorCode
+		HTTPStatus int
+		Retryable  bool
+	}{
+		{StatusCode: http.StatusNotFound, Code: ErrorCodeNotFound, HTTPStatus: http.StatusNotFound},
+		{StatusCode: http.StatusConflict, Code: ErrorCodeInvalidArgument, HTTPStatus: http.StatusUnprocessableEntity},
+		{StatusCode: http.StatusNotModified, Code: ErrorCodePreconditionFailed, HTTPStatus: http.StatusPreconditionFailed},
+		{StatusCode: http.StatusUnauthorized, Code: ErrorCodeAccessDenied, HTTPStatus: http.StatusForbidden},
+		{StatusCode: http.StatusTooManyRequests, Code: ErrorCodeThrottled, HTTPStatus: http.StatusTooManyRequests, Retryable: true},
+		{StatusCode: http.StatusBadRequest, Code: ErrorCodeInvalidArgument, HTTPStatus: http.StatusUnprocessableEntity},
+		{StatusCode: http.StatusBadGateway, Code: ErrorCodeProviderUnavailable, HTTPStatus: http.StatusServiceUnavailable, Retryable: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.Code), func(t *testing.T) {
+			code := EvalStorageErrorCode(tc.StatusCode)
+			assert.Equal(t, code, tc.Code)
+
+			err := NewStorageError(code, "failed", map[string]any{"statusCode": tc.StatusCode})
+			assert.Equal(t, err.StatusCode(), tc.HTTPStatus)
+			assert.Equal(t, err.Details["statusCode"], tc.StatusCode)
+
+			retryable, _ := err.Details[RetryableErrorDetail].(bool)
+			assert.Equal(t, retryable, tc.Retryable)
+
+			resultCode, ok := GetStorageErrorCode(fmt.Errorf("wrapped: %w", err))
+			assert.Assert(t, ok)
+			assert.Equal(t, resultCode, tc.Code)
+		})
+	}
+
+	_, ok := GetStorageErrorCode(errors.New("unknown"))
+	assert.Assert(t, !ok)
+}
@@ -1,11 +1,25 @@
 package common
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"  //nolint:gosec
+	"crypto/sha1" //nolint:gosec
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
 	"io"
 	"log/slog"
+	"math/rand/v2"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hasura/ndc-http/exhttp"
 	"github.com/hasura/ndc-sdk-go/v2/schema"
@@ -21,11 +35,114 @@ type HTTPRequestOptions struct {
 	Method   *DownloadHTTPMethod `json:"method"`
 	Headers  []StorageKeyValue   `json:"headers,omitempty"`
 	BodyText string              `json:"body_text,omitempty"`
+	// Verify the downloaded content with the expected checksum.
+	// The object isn't uploaded if the checksum doesn't match.
+	ExpectedChecksum *HTTPExpectedChecksum `json:"expected_checksum"`
+}
+
+// HTTPExpectedChecksum represents the expected checksum of the downloaded content.
+type HTTPExpectedChecksum struct {
+	Algorithm HTTPChecksumAlgorithm `json:"algorithm"`
+	// The checksum value in hex or base64 encoding.
+	Value string `json:"value"`
+}
+
+// NewHash creates a hash function of the checksum algorithm.
+func (hec HTTPExpectedChecksum) NewHash() (hash.Hash, error) {
+	switch hec.Algorithm {
+	case HTTPChecksumAlgorithmMd5:
+		return md5.New(), nil //nolint:gosec
+	case HTTPChecksumAlgorithmSha1:
+		return sha1.New(), nil //nolint:gosec
+	case HTTPChecksumAlgorithmSha256:
+		return sha256.New(), nil
+	case HTTPChecksumAlgorithmCrc32:
+		return crc32.NewIEEE(), nil
+	case HTTPChecksumAlgorithmCrc32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli)), nil
+	default:
+		return nil, schema.UnprocessableContentError(
+			"unsupported checksum algorithm: "+string(hec.Algorithm),
+			nil,
+		)
+	}
+}
+
+// Verify compares the checksum sum with the expected value.
+func (hec HTTPExpectedChecksum) Verify(sum []byte) error {
+	expected := strings.TrimSpace(hec.Value)
+
+	if strings.EqualFold(hex.EncodeToString(sum), expected) {
+		return nil
+	}
+
+	if decoded, err := base64.StdEncoding.DecodeString(expected); err == nil && bytes.Equal(decoded, sum) {
+		return nil
+	}
+
+	return schema.UnprocessableContentError(
+		fmt.Sprintf("%s checksum mismatch", hec.Algorithm),
+		map[string]any{
+			"expected": expected,
+			"actual":   hex.EncodeToString(sum),
+		},
+	)
+}
+
+// HTTPChecksumAlgorithm represents a checksum algorithm enum to verify downloaded files.
+// @enum MD5,SHA1,SHA256,CRC32,CRC32C
+type HTTPChecksumAlgorithm string
+
+// HTTPRetrySettings represent the retry policy of HTTP requests to download files.
+type HTTPRetrySettings struct {
+	// Maximum number of retry attempts. Set 0 to disable retries.
+	MaxAttempts int `json:"maxAttempts" jsonschema:"min=0,default=3"     yaml:"maxAttempts"`
+	// Initial delay in milliseconds before retrying. The delay is doubled after every attempt.
+	Delay int `json:"delay"       jsonschema:"min=0,default=1000"  yaml:"delay"`
+	// Maximum delay in milliseconds between retries.
+	MaxDelay int `json:"maxDelay"    jsonschema:"min=0,default=30000" yaml:"maxDelay"`
+}
+
+var defaultHTTPRetrySettings = HTTPRetrySettings{
+	MaxAttempts: 3,
+	Delay:       1000,
+	MaxDelay:    30000,
+}
+
+var retryableHTTPStatuses = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// backoff returns the delay duration of the retry attempt with jitter.
+func (hrs HTTPRetrySettings) backoff(attempt int) time.Duration {
+	delay := time.Duration(hrs.Delay) * time.Millisecond
+	maxDelay := time.Duration(hrs.MaxDelay) * time.Millisecond
+
+	for i := 1; i < attempt && (maxDelay <= 0 || delay < maxDelay); i++ {
+		delay *= 2
+	}
+
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	// add up to 20% jitter to avoid retrying at the same time.
+	return delay + rand.N(delay/5+1) //nolint:gosec
 }
 
 // HTTPClient extends the native http.Client with custom configurations and methods.
 type HTTPClient struct {
 	client *http.Client
+	retry  HTTPRetrySettings
 }
 
 // NewTransport creates a new http transport from config.
@@ -48,12 +165,35 @@ func NewTransport(
 
 	httpTransport.DisableCompression = true
 
-	return exhttp.NewTelemetryTransport(httpTransport, telemetry), nil
+	return &idleClosingTransport{
+		RoundTripper: exhttp.NewTelemetryTransport(httpTransport, telemetry),
+		transport:    httpTransport,
+	}, nil
+}
+
+// idleClosingTransport exposes CloseIdleConnections of the underlying transport through the telemetry wrapper.
+type idleClosingTransport struct {
+	http.RoundTripper
+
+	transport *http.Transport
+}
+
+// CloseIdleConnections closes any connections which were previously connected but are now in an idle state.
+func (t *idleClosingTransport) CloseIdleConnections() {
+	t.transport.CloseIdleConnections()
+}
+
+// CloseIdleConnections closes idle connections of the transport if supported.
+func CloseIdleConnections(transport http.RoundTripper) {
+	if closer, ok := transport.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
 }
 
 // NewHTTPClient creates an HTTP client from an HTTP transport configuration.
 func NewHTTPClient(
 	config *exhttp.HTTPTransportTLSConfig,
+	retry *HTTPRetrySettings,
 	logger *slog.Logger,
 ) (*HTTPClient, error) {
 	transport, err := NewTransport(config, exhttp.TelemetryConfig{
@@ -64,11 +204,23 @@ func NewHTTPClient(
 		return nil, err
 	}
 
-	return &HTTPClient{
+	result := &HTTPClient{
 		client: &http.Client{
 			Transport: transport,
 		},
-	}, nil
+		retry: defaultHTTPRetrySettings,
+	}
+
+	if retry != nil {
+		result.retry = *retry
+	}
+
+	return result, nil
+}
+
+// CloseIdleConnections closes idle connections of the HTTP transport.
+func (hc *HTTPClient) CloseIdleConnections() {
+	hc.client.CloseIdleConnections()
 }
 
 // Request sends a HTTP request to the remote endpoint.
@@ -76,6 +228,49 @@ func (hc HTTPClient) Request(
 	ctx context.Context,
 	options *HTTPRequestOptions,
 ) (*http.Response, error) {
+	req, err := hc.newRequest(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := hc.client.Do(req)
+	if err != nil {
+		return nil, schema.UnprocessableContentError(err.Error(), nil)
+	}
+
+	return resp, nil
+}
+
+// Download sends a HTTP request to download the file from the remote endpoint.
+// GET requests are retried with backoff if failed. Interrupted transfers are resumed from
+// the last received byte with the HTTP Range header if the server supports range requests.
+func (hc *HTTPClient) Download(
+	ctx context.Context,
+	options *HTTPRequestOptions,
+) (*HTTPDownloadReader, error) {
+	reader := &HTTPDownloadReader{
+		ctx:     ctx,
+		client:  hc,
+		options: options,
+	}
+
+	resp, err := reader.request(false)
+	if err != nil {
+		return nil, err
+	}
+
+	reader.Response = resp
+	reader.body = resp.Body
+	reader.validator = resp.Header.Get("ETag")
+
+	if reader.validator == "" || strings.HasPrefix(reader.validator, "W/") {
+		reader.validator = resp.Header.Get("Last-Modified")
+	}
+
+	return reader, nil
+}
+
+func (hc HTTPClient) newRequest(ctx context.Context, options *HTTPRequestOptions) (*http.Request, error) {
 	method := http.MethodGet
 
 	if options.Method != nil && *options.Method != "" {
@@ -106,10 +301,192 @@ func (hc HTTPClient) Request(
 		req.Header.Set(kv.Key, kv.Value)
 	}
 
-	resp, err := hc.client.Do(req)
+	return req, nil
+}
+
+// HTTPDownloadReader reads the response body of the download request
+// and resumes the transfer if the connection is interrupted.
+type HTTPDownloadReader struct {
+	// The response of the first request.
+	Response *http.Response
+
+	ctx          context.Context
+	client       *HTTPClient
+	options      *HTTPRequestOptions
+	body         io.ReadCloser
+	offset       int64
+	failedOffset int64
+	attempts     int
+	validator    string
+}
+
+// Read implements the io.Reader interface.
+func (r *HTTPDownloadReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+
+	if err == nil || errors.Is(err, io.EOF) {
+		return n, err
+	}
+
+	// reset the retry budget if the transfer made progress since the last failure.
+	if r.offset > r.failedOffset {
+		r.attempts = 0
+		r.failedOffset = r.offset
+	}
+
+	if !r.canResume() {
+		return n, err
+	}
+
+	slog.Debug(
+		"download interrupted, resuming: "+err.Error(),
+		slog.String("url", r.options.URL),
+		slog.Int64("offset", r.offset),
+	)
+
+	_ = r.body.Close()
+	r.attempts++
+
+	if waitErr := r.wait(); waitErr != nil {
+		return n, waitErr
+	}
+
+	// the download restarts from the beginning if nothing was read.
+	resp, resumeErr := r.request(r.offset > 0)
+	if resumeErr != nil {
+		return n, fmt.Errorf("%w; failed to resume the download: %w", err, resumeErr)
+	}
+
+	r.body = resp.Body
+
+	return n, nil
+}
+
+// Close closes the response body.
+func (r *HTTPDownloadReader) Close() error {
+	return r.body.Close()
+}
+
+func (r *HTTPDownloadReader) isIdempotent() bool {
+	return r.options.Method == nil || *r.options.Method == "" || *r.options.Method == http.MethodGet
+}
+
+// canResume checks if the interrupted download can be continued. Transfers that made progress are resumed
+// only if the content has a validator, ETag or Last-Modified, so that the If-Range condition detects changes of the source.
+func (r *HTTPDownloadReader) canResume() bool {
+	return r.isIdempotent() && r.attempts < r.client.retry.MaxAttempts &&
+		r.Response.Header.Get("Accept-Ranges") != "none" &&
+		(r.offset == 0 || r.validator != "")
+}
+
+// request sends the download request. Failed requests are retried if the method is idempotent.
+func (r *HTTPDownloadReader) request(resume bool) (*http.Response, error) {
+	for {
+		resp, err := r.doRequest(resume)
+		if err == nil {
+			return resp, nil
+		}
+
+		var retryable *retryableHTTPError
+		if !errors.As(err, &retryable) {
+			return nil, err
+		}
+
+		if !r.isIdempotent() || r.attempts >= r.client.retry.MaxAttempts {
+			return nil, retryable.err
+		}
+
+		r.attempts++
+
+		if err := r.wait(); err != nil {
+			return nil, err
+		}
+
+		resume = r.offset > 0
+	}
+}
+
+func (r *HTTPDownloadReader) doRequest(resume bool) (*http.Response, error) {
+	req, err := r.client.newRequest(r.ctx, r.options)
 	if err != nil {
-		return nil, schema.UnprocessableContentError(err.Error(), nil)
+		return nil, err
 	}
 
-	return resp, nil
+	if resume {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(r.offset, 10)+"-")
+		req.Header.Set("If-Range", r.validator)
+	}
+
+	resp, err := r.client.client.Do(req)
+	if err != nil {
+		if r.ctx.Err() != nil {
+			return nil, schema.UnprocessableContentError(err.Error(), nil)
+		}
+
+		return nil, &retryableHTTPError{err: err}
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && resume:
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), "bytes "+strconv.FormatInt(r.offset, 10)+"-") {
+			_ = resp.Body.Close()
+
+			return nil, schema.UnprocessableContentError(
+				"invalid Content-Range header of the resumed download: "+resp.Header.Get("Content-Range"),
+				nil,
+			)
+		}
+
+		return resp, nil
+	case resume && resp.StatusCode == http.StatusOK:
+		// the full content is returned if the source was changed and the If-Range condition fails,
+		// or if the server ignores the range. The received prefix can't be joined with the new content.
+		_ = resp.Body.Close()
+
+		return nil, schema.UnprocessableContentError(
+			"the source was changed or doesn't support range requests, the download can't be resumed",
+			map[string]any{
+				"url": r.options.URL,
+			},
+		)
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return resp, nil
+	}
+
+	_ = resp.Body.Close()
+
+	err = schema.UnprocessableContentError("failed to download the file: "+resp.Status, map[string]any{
+		"url": r.options.URL,
+	})
+
+	if slices.Contains(retryableHTTPStatuses, resp.StatusCode) {
+		return nil, &retryableHTTPError{err: err}
+	}
+
+	return nil, err
+}
+
+func (r *HTTPDownloadReader) wait() error {
+	timer := time.NewTimer(r.client.retry.backoff(r.attempts))
+	defer timer.Stop()
+
+	select {
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type retryableHTTPError struct {
+	err error
+}
+
+func (e *retryableHTTPError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableHTTPError) Unwrap() error {
+	return e.err
 }
@@ -0,0 +1,179 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestHTTPDownloadResume(t *testing.T) {
+	content := strings.Repeat("hello world ", 1024)
+	half := len(content) / 2
+
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("ETag", `"abc"`)
+		w.Header().Set("Accept-Ranges", "bytes")
+
+		rangeHeader := r.Header.Get("Range")
+		if rangeHeader == "" {
+			// send a half of the content and drop the connection.
+			w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(content[:half]))
+
+			conn, _, err := w.(http.Hijacker).Hijack()
+			assert.NilError(t, err)
+			_ = conn.Close()
+
+			return
+		}
+
+		var start int
+
+		_, err := fmt.Sscanf(rangeHeader, "bytes=%d-", &start)
+		assert.NilError(t, err)
+		assert.Equal(t, r.Header.Get("If-Range"), `"abc"`)
+
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte(content[start:]))
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(nil, &HTTPRetrySettings{MaxAttempts: 2, Delay: 1}, slog.Default())
+	assert.NilError(t, err)
+
+	reader, err := client.Download(context.TODO(), &HTTPRequestOptions{URL: server.URL})
+	assert.NilError(t, err)
+
+	defer reader.Close()
+
+	result, err := io.ReadAll(reader)
+	assert.NilError(t, err)
+	assert.Equal(t, string(result), content)
+	assert.Equal(t, requests.Load(), int32(2))
+}
+
+func TestHTTPDownloadResumeFailure(t *testing.T) {
+	content := strings.Repeat("hello world ", 1024)
+	half := len(content) / 2
+
+	testCases := []struct {
+		Name      string
+		ETag      string
+		Requests  int32
+		ErrorText string
+	}{
+		// the source is changed so the If-Range condition fails and the server returns the new content.
+		{Name: "changed", ETag: `"abc"`, Requests: 2, ErrorText: "can't be resumed"},
+		// the download isn't resumed if changes of the source can't be detected.
+		{Name: "no_validator", Requests: 1, ErrorText: "unexpected EOF"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var requests atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.Header().Set("Accept-Ranges", "bytes")
+
+				if tc.ETag != "" {
+					w.Header().Set("ETag", tc.ETag)
+				}
+
+				if r.Header.Get("Range") != "" {
+					_, _ = w.Write([]byte(strings.ToUpper(content)))
+
+					return
+				}
+
+				w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(content[:half]))
+
+				conn, _, err := w.(http.Hijacker).Hijack()
+				assert.NilError(t, err)
+				_ = conn.Close()
+			}))
+			defer server.Close()
+
+			client, err := NewHTTPClient(nil, &HTTPRetrySettings{MaxAttempts: 2, Delay: 1}, slog.Default())
+			assert.NilError(t, err)
+
+			reader, err := client.Download(context.TODO(), &HTTPRequestOptions{URL: server.URL})
+			assert.NilError(t, err)
+
+			defer reader.Close()
+
+			_, err = io.ReadAll(reader)
+			assert.ErrorContains(t, err, tc.ErrorText)
+			assert.Equal(t, requests.Load(), tc.Requests)
+		})
+	}
+}
+
+func TestHTTPDownloadRetryStatus(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		if r.URL.Path == "/not-found" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	client, err := NewHTTPClient(nil, &HTTPRetrySettings{MaxAttempts: 2, Delay: 1}, slog.Default())
+	assert.NilError(t, err)
+
+	reader, err := client.Download(context.TODO(), &HTTPRequestOptions{URL: server.URL})
+	assert.NilError(t, err)
+
+	result, err := io.ReadAll(reader)
+	assert.NilError(t, err)
+	assert.Equal(t, string(result), "hello")
+	assert.NilError(t, reader.Close())
+
+	_, err = client.Download(context.TODO(), &HTTPRequestOptions{URL: server.URL + "/not-found"})
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestHTTPExpectedChecksum(t *testing.T) {
+	checksum := HTTPExpectedChecksum{
+		Algorithm: HTTPChecksumAlgorithmSha256,
+		Value:     "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+	}
+
+	h, err := checksum.NewHash()
+	assert.NilError(t, err)
+
+	_, _ = h.Write([]byte("hello world"))
+	assert.NilError(t, checksum.Verify(h.Sum(nil)))
+
+	checksum.Value = "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek="
+	assert.NilError(t, checksum.Verify(h.Sum(nil)))
+
+	checksum.Value = "invalid"
+	assert.ErrorContains(t, checksum.Verify(h.Sum(nil)), "checksum mismatch")
+}
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/invopop/jsonschema"
+)
+
+const defaultLimitQueueTimeout = 10
+
+// ClientLimitSettings hold rate limits and concurrency quotas of a storage client.
+// Operations that exceed limits wait in a queue until the queue timeout.
+type ClientLimitSettings struct {
+	// Maximum number of concurrent operations of the client. Unlimited if empty.
+	MaxConcurrency int `json:"maxConcurrency,omitempty"         jsonschema:"min=0"            mapstructure:"maxConcurrency"         yaml:"maxConcurrency,omitempty"`
+	// Maximum number of operations per second. Unlimited if empty.
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty"      jsonschema:"min=0"            mapstructure:"requestsPerSecond"      yaml:"requestsPerSecond,omitempty"`
+	// Maximum number of operations that are allowed at once above the rate. Defaults to the ceiling of requestsPerSecond.
+	Burst int `json:"burst,omitempty"                  jsonschema:"min=0"            mapstructure:"burst"                  yaml:"burst,omitempty"`
+	// Maximum upload bandwidth in bytes per second. Unlimited if empty.
+	UploadBytesPerSecond int64 `json:"uploadBytesPerSecond,omitempty"   jsonschema:"min=0"            mapstructure:"uploadBytesPerSecond"   yaml:"uploadBytesPerSecond,omitempty"`
+	// Maximum download bandwidth in bytes per second. Unlimited if empty.
+	DownloadBytesPerSecond int64 `json:"downloadBytesPerSecond,omitempty" jsonschema:"min=0"            mapstructure:"downloadBytesPerSecond" yaml:"downloadBytesPerSecond,omitempty"`
+	// Maximum time in seconds that an operation waits for a concurrency slot or a rate limit token.
+	// Set 0 to fail immediately when the limit is exceeded.
+	QueueTimeout *int `json:"queueTimeout,omitempty"           jsonschema:"min=0,default=10" mapstructure:"queueTimeout"           yaml:"queueTimeout,omitempty"`
+}
+
+// Validate checks if the limit settings are valid.
+func (cls ClientLimitSettings) Validate() error {
+	if cls.MaxConcurrency < 0 {
+		return errors.New("maxConcurrency must not be negative")
+	}
+
+	if cls.RequestsPerSecond < 0 {
+		return errors.New("requestsPerSecond must not be negative")
+	}
+
+	if cls.Burst < 0 {
+		return errors.New("burst must not be negative")
+	}
+
+	if cls.UploadBytesPerSecond < 0 {
+		return errors.New("uploadBytesPerSecond must not be negative")
+	}
+
+	if cls.DownloadBytesPerSecond < 0 {
+		return errors.New("downloadBytesPerSecond must not be negative")
+	}
+
+	if cls.QueueTimeout != nil && *cls.QueueTimeout < 0 {
+		return errors.New("queueTimeout must not be negative")
+	}
+
+	return nil
+}
+
+// JSONSchema is used to generate a custom jsonschema.
+func (cls ClientLimitSettings) JSONSchema() *jsonschema.Schema {
+	properties := jsonschema.NewProperties()
+	properties.Set("maxConcurrency", &jsonschema.Schema{
+		Description: "Maximum number of concurrent operations of the client. Unlimited if empty",
+		Type:        "integer",
+		Minimum:     json.Number("0"),
+	})
+	properties.Set("requestsPerSecond", &jsonschema.Schema{
+		Description: "Maximum number of operations per second. Unlimited if empty",
+		Type:        "number",
+		Minimum:     json.Number("0"),
+	})
+	properties.Set("burst", &jsonschema.Schema{
+		Description: "Maximum number of operations that are allowed at once above the rate. Defaults to the ceiling of requestsPerSecond",
+		Type:        "integer",
+		Minimum:     json.Number("0"),
+	})
+	properties.Set("uploadBytesPerSecond", &jsonschema.Schema{
+		Description: "Maximum upload bandwidth in bytes per second. Unlimited if empty",
+		Type:        "integer",
+		Minimum:     json.Number("0"),
+	})
+	properties.Set("downloadBytesPerSecond", &jsonschema.Schema{
+		Description: "Maximum download bandwidth in bytes per second. Unlimited if empty",
+		Type:        "integer",
+		Minimum:     json.Number("0"),
+	})
+	properties.Set("queueTimeout", &jsonschema.Schema{
+		Description: "Maximum time in seconds that an operation waits for a concurrency slot or a rate limit token. Set 0 to fail immediately",
+		Type:        "integer",
+		Minimum:     json.Number("0"),
+		Default:     defaultLimitQueueTimeout,
+	})
+
+	return &jsonschema.Schema{
+		Description: "Rate limits and concurrency quotas of the client",
+		Type:        "object",
+		Properties:  properties,
+	}
+}
+
+// GetQueueTimeout returns the maximum duration that an operation waits in the queue.
+func (cls ClientLimitSettings) GetQueueTimeout() time.Duration {
+	if cls.QueueTimeout == nil {
+		return defaultLimitQueueTimeout * time.Second
+	}
+
+	return time.Duration(*cls.QueueTimeout) * time.Second
+}
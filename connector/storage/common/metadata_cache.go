@@ -0,0 +1,76 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/invopop/jsonschema"
+)
+
+const (
+	defaultMetadataCacheTTL        = 60
+	defaultMetadataCacheMaxEntries = 1000
+)
+
+// MetadataCacheSettings hold settings of the in-memory cache of object metadata and listings of a storage client.
+type MetadataCacheSettings struct {
+	// Time in seconds that a cached result is kept.
+	TTL int `json:"ttl,omitempty"        jsonschema:"min=1,default=60"   mapstructure:"ttl"        yaml:"ttl,omitempty"`
+	// Maximum number of cached results. The least recently used result is evicted when the cache is full.
+	MaxEntries int `json:"maxEntries,omitempty" jsonschema:"min=1,default=1000" mapstructure:"maxEntries" yaml:"maxEntries,omitempty"`
+}
+
+// Validate checks if the cache settings are valid.
+func (mcs MetadataCacheSettings) Validate() error {
+	if mcs.TTL < 0 {
+		return errors.New("ttl must not be negative")
+	}
+
+	if mcs.MaxEntries < 0 {
+		return errors.New("maxEntries must not be negative")
+	}
+
+	return nil
+}
+
+// GetTTL returns the duration that a cached result is kept.
+func (mcs MetadataCacheSettings) GetTTL() time.Duration {
+	if mcs.TTL <= 0 {
+		return defaultMetadataCacheTTL * time.Second
+	}
+
+	return time.Duration(mcs.TTL) * time.Second
+}
+
+// GetMaxEntries returns the maximum number of cached results.
+func (mcs MetadataCacheSettings) GetMaxEntries() int {
+	if mcs.MaxEntries <= 0 {
+		return defaultMetadataCacheMaxEntries
+	}
+
+	return mcs.MaxEntries
+}
+
+// JSONSchema is used to generate a custom jsonschema.
+func (mcs MetadataCacheSettings) JSONSchema() *jsonschema.Schema {
+	properties := jsonschema.NewProperties()
+	properties.Set("ttl", &jsonschema.Schema{
+		Description: "Time in seconds that a cached result is kept",
+		Type:        "integer",
+		Minimum:     json.Number("1"),
+		Default:     defaultMetadataCacheTTL,
+	})
+	properties.Set("maxEntries", &jsonschema.Schema{
+		Description: "Maximum number of cached results. The least recently used result is evicted when the cache is full",
+		Type:        "integer",
+		Minimum:     json.Number("1"),
+		Default:     defaultMetadataCacheMaxEntries,
+	})
+
+	return &jsonschema.Schema{
+		Description: "In-memory cache of object metadata and listings of the client",
+		Type:        "object",
+		Properties:  properties,
+	}
+}
@@ -0,0 +1,239 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+	"github.com/invopop/jsonschema"
+)
+
+// RetryableErrorDetail is the detail key of errors that are safe to retry, e.g. transient network errors.
+const RetryableErrorDetail = "retryable"
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 200
+	defaultRetryMaxBackoff     = 10000
+	defaultRetryJitter         = 0.2
+)
+
+// RetryPolicy represents the retry policy of storage operations. Retries are applied by the connector
+// to idempotent operations, so built-in retries of provider SDKs are disabled when the policy is set.
+// Copies and compositions are retried. Writes with ETag preconditions and bucket removals are attempted once.
+type RetryPolicy struct {
+	// Maximum number of attempts, including the first request. Set 1 to disable retries.
+	MaxAttempts int `json:"maxAttempts,omitempty"          jsonschema:"min=1,default=3"         mapstructure:"maxAttempts"          yaml:"maxAttempts,omitempty"`
+	// Initial backoff in milliseconds before retrying. The backoff is doubled after every attempt.
+	InitialBackoff int `json:"initialBackoff,omitempty"       jsonschema:"min=1,default=200"       mapstructure:"initialBackoff"       yaml:"initialBackoff,omitempty"`
+	// Maximum backoff in milliseconds between attempts.
+	MaxBackoff int `json:"maxBackoff,omitempty"           jsonschema:"min=1,default=10000"     mapstructure:"maxBackoff"           yaml:"maxBackoff,omitempty"`
+	// Ratio of the random jitter that is added to the backoff, from 0 to 1.
+	Jitter *float64 `json:"jitter,omitempty"               jsonschema:"min=0,max=1,default=0.2" mapstructure:"jitter"               yaml:"jitter,omitempty"`
+	// HTTP status codes of provider responses that are retried. Defaults to 408, 429, 500, 502, 503 and 504.
+	RetryableStatusCodes []int `json:"retryableStatusCodes,omitempty" mapstructure:"retryableStatusCodes" yaml:"retryableStatusCodes,omitempty"`
+	// Timeout in seconds of each attempt. No timeout if empty.
+	Timeout int `json:"timeout,omitempty"              jsonschema:"min=0"                   mapstructure:"timeout"              yaml:"timeout,omitempty"`
+	// Timeouts in seconds of each attempt by the operation name, e.g. GetObject. Overrides the default timeout.
+	OperationTimeouts map[string]int `json:"operationTimeouts,omitempty"    mapstructure:"operationTimeouts"    yaml:"operationTimeouts,omitempty"`
+}
+
+// Validate checks if the retry policy is valid.
+func (rp RetryPolicy) Validate() error {
+	if rp.MaxAttempts < 0 {
+		return errors.New("maxAttempts must not be negative")
+	}
+
+	if rp.InitialBackoff < 0 || rp.MaxBackoff < 0 {
+		return errors.New("backoff must not be negative")
+	}
+
+	if rp.Jitter != nil && (*rp.Jitter < 0 || *rp.Jitter > 1) {
+		return errors.New("jitter must be in the range of 0 and 1")
+	}
+
+	for _, code := range rp.RetryableStatusCodes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("invalid retryable status code: %d", code)
+		}
+	}
+
+	if rp.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+
+	for operation, timeout := range rp.OperationTimeouts {
+		if timeout < 0 {
+			return fmt.Errorf("timeout of %s must not be negative", operation)
+		}
+	}
+
+	return nil
+}
+
+// GetMaxAttempts returns the maximum number of attempts.
+func (rp RetryPolicy) GetMaxAttempts() int {
+	if rp.MaxAttempts <= 0 {
+		return defaultRetryMaxAttempts
+	}
+
+	return rp.MaxAttempts
+}
+
+// GetTimeout returns the timeout of each attempt of the operation. The result is 0 if there is no timeout.
+func (rp RetryPolicy) GetTimeout(operation string) time.Duration {
+	if timeout, ok := rp.OperationTimeouts[operation]; ok {
+		return time.Duration(timeout) * time.Second
+	}
+
+	return time.Duration(rp.Timeout) * time.Second
+}
+
+// Backoff returns the delay duration before the next attempt with jitter.
+func (rp RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := time.Duration(defaultRetryInitialBackoff) * time.Millisecond
+	if rp.InitialBackoff > 0 {
+		delay = time.Duration(rp.InitialBackoff) * time.Millisecond
+	}
+
+	maxDelay := time.Duration(defaultRetryMaxBackoff) * time.Millisecond
+	if rp.MaxBackoff > 0 {
+		maxDelay = time.Duration(rp.MaxBackoff) * time.Millisecond
+	}
+
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	delay = min(delay, maxDelay)
+
+	jitter := defaultRetryJitter
+	if rp.Jitter != nil {
+		jitter = *rp.Jitter
+	}
+
+	if maxJitter := int64(float64(delay) * jitter); maxJitter > 0 {
+		delay += time.Duration(rand.Int64N(maxJitter + 1)) //nolint:gosec
+	}
+
+	return delay
+}
+
+// IsRetryableError checks if the error of the provider response has a retryable status code,
+// or is a transient network error. The status code of the provider response takes precedence over
+// the retryability of the error so that the retryable status codes setting is respected,
+// unless the provider maps the error to a stable code that the status code alone doesn't imply,
+// e.g. 403 responses of Google Cloud Storage whose reason is rateLimitExceeded are throttled and retried.
+func (rp RetryPolicy) IsRetryableError(err error) bool {
+	var connectorError *schema.ConnectorError
+	if !errors.As(err, &connectorError) {
+		return IsTransientError(err)
+	}
+
+	statusCode, ok := connectorError.Details["statusCode"].(int)
+	if !ok {
+		retryable, _ := connectorError.Details[RetryableErrorDetail].(bool)
+
+		return retryable
+	}
+
+	if code, ok := GetStorageErrorCode(err); ok && code != EvalStorageErrorCode(statusCode) {
+		return code.IsRetryable()
+	}
+
+	codes := rp.RetryableStatusCodes
+	if len(codes) == 0 {
+		codes = retryableHTTPStatuses
+	}
+
+	for _, code := range codes {
+		if code == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// JSONSchema is used to generate a custom jsonschema.
+func (rp RetryPolicy) JSONSchema() *jsonschema.Schema {
+	properties := jsonschema.NewProperties()
+	properties.Set("maxAttempts", &jsonschema.Schema{
+		Description: "Maximum number of attempts, including the first request. Set 1 to disable retries",
+		Type:        "integer",
+		Minimum:     json.Number("1"),
+		Default:     defaultRetryMaxAttempts,
+	})
+	properties.Set("initialBackoff", &jsonschema.Schema{
+		Description: "Initial backoff in milliseconds before retrying. The backoff is doubled after every attempt",
+		Type:        "integer",
+		Minimum:     json.Number("1"),
+		Default:     defaultRetryInitialBackoff,
+	})
+	properties.Set("maxBackoff", &jsonschema.Schema{
+		Description: "Maximum backoff in milliseconds between attempts",
+		Type:        "integer",
+		Minimum:     json.Number("1"),
+		Default:     defaultRetryMaxBackoff,
+	})
+	properties.Set("jitter", &jsonschema.Schema{
+		Description: "Ratio of the random jitter that is added to the backoff, from 0 to 1",
+		Type:        "number",
+		Minimum:     json.Number("0"),
+		Maximum:     json.Number("1"),
+		Default:     defaultRetryJitter,
+	})
+	properties.Set("retryableStatusCodes", &jsonschema.Schema{
+		Description: "HTTP status codes of provider responses that are retried. Defaults to 408, 429, 500, 502, 503 and 504",
+		Type:        "array",
+		Items: &jsonschema.Schema{
+			Type: "integer",
+		},
+	})
+	properties.Set("timeout", &jsonschema.Schema{
+		Description: "Timeout in seconds of each attempt. No timeout if empty",
+		Type:        "integer",
+		Minimum:     json.Number("0"),
+	})
+	properties.Set("operationTimeouts", &jsonschema.Schema{
+		Description: "Timeouts in seconds of each attempt by the operation name, e.g. GetObject. Overrides the default timeout",
+		Type:        "object",
+		AdditionalProperties: &jsonschema.Schema{
+			Type:    "integer",
+			Minimum: json.Number("0"),
+		},
+	})
+
+	return &jsonschema.Schema{
+		Description: "Retry policy of idempotent storage operations. Built-in retries of provider SDKs are disabled if set",
+		Type:        "object",
+		Properties:  properties,
+	}
+}
+
+// IsTransientError checks if the error is a transient network error such as a connection reset or a timeout.
+// Canceled or expired contexts aren't transient errors.
+func IsTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// NewTransientError creates a retryable error of the transient network error.
+func NewTransientError(err error) *schema.ConnectorError {
+	return NewStorageError(ErrorCodeProviderUnavailable, err.Error(), nil)
+}
@@ -2,10 +2,13 @@ package common
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"io"
 	"time"
 
 	"github.com/hasura/ndc-sdk-go/v2/scalar"
+	"github.com/hasura/ndc-sdk-go/v2/schema"
 )
 
 // StorageClient abstracts required methods of the storage client.
@@ -108,6 +111,13 @@ type StorageClient interface { //nolint:interfacebloat
 	) error
 	// RestoreObject restores a soft-deleted object.
 	RestoreObject(ctx context.Context, bucketName string, objectName string) error
+	// RestoreArchivedObject restores an object from the archive storage tier.
+	RestoreArchivedObject(
+		ctx context.Context,
+		bucketName string,
+		objectName string,
+		opts RestoreArchivedStorageObjectOptions,
+	) error
 	// RemoveIncompleteUpload removes a partially uploaded object.
 	RemoveIncompleteUpload(ctx context.Context, bucketName string, objectName string) error
 	// PresignedGetObject generates a presigned URL for HTTP GET operations. Browsers/Mobile clients may point to this URL to directly download objects even if the bucket is private.
@@ -561,14 +571,89 @@ type StorageObjectMultipartInfo struct {
 	UploadID *string `json:"upload_id"`
 }
 
-// EncryptionMethod represents a server-side-encryption method enum.
+// ServerSideEncryptionMethod represents a server-side-encryption method enum.
 // @enum SSE_C,KMS,S3
-// type ServerSideEncryptionMethod string
+type ServerSideEncryptionMethod string
+
+// ServerSideEncryption represents the server-side encryption options of an object.
+type ServerSideEncryption struct {
+	// The server-side encryption method.
+	// SSE_C encrypts the object with a customer-provided key: SSE-C for S3, customer-supplied encryption keys for GCS and CPK for Azure.
+	// KMS encrypts the object with a key of the key management service: SSE-KMS for S3, Cloud KMS keys for GCS and encryption scopes for Azure.
+	// S3 encrypts the object with keys that are managed by the storage provider.
+	Method ServerSideEncryptionMethod `json:"method"`
+	// The base64-encoded 256-bit customer-provided key of the SSE_C method.
+	Key *string `json:"key"`
+	// The ID of a customer-provided key in the client configuration for the SSE_C method,
+	// or the key ID of the key management service for the KMS method.
+	KeyID *string `json:"key_id"`
+}
+
+// MarshalJSON implements json.Marshaler. The customer-provided key is redacted.
+func (sse ServerSideEncryption) MarshalJSON() ([]byte, error) {
+	result := map[string]any{
+		"method": sse.Method,
+		"key_id": sse.KeyID,
+	}
+
+	if sse.Key != nil {
+		result["key"] = "[REDACTED]"
+	}
+
+	return json.Marshal(result)
+}
+
+// CustomerKey decodes the customer-provided key of the SSE_C method.
+func (sse ServerSideEncryption) CustomerKey() ([]byte, error) {
+	if sse.Key == nil || *sse.Key == "" {
+		if sse.KeyID != nil && *sse.KeyID != "" {
+			return nil, schema.UnprocessableContentError(
+				"the customer key doesn't exist in the client configuration: "+*sse.KeyID,
+				nil,
+			)
+		}
+
+		return nil, schema.UnprocessableContentError(
+			"the key or key_id of the SSE_C encryption is required",
+			nil,
+		)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(*sse.Key)
+	if err != nil || len(key) != encryptionKeySize {
+		return nil, schema.UnprocessableContentError(
+			"the SSE_C encryption key must be a base64-encoded 256-bit key",
+			nil,
+		)
+	}
+
+	return key, nil
+}
+
+// GetKMSKeyID returns the key ID of the KMS method.
+func (sse ServerSideEncryption) GetKMSKeyID() (string, error) {
+	if sse.KeyID == nil || *sse.KeyID == "" {
+		return "", schema.UnprocessableContentError(
+			"the key_id of the KMS encryption is required",
+			nil,
+		)
+	}
+
+	return *sse.KeyID, nil
+}
 
 // StorageRetentionMode represents a storage retention mode enum.
 // @enum Locked,Unlocked,Mutable,Delete.
 type StorageRetentionMode string
 
+// StorageRestoreTier represents the data access tier to restore an archived object.
+// @enum Standard,Bulk,Expedited
+type StorageRestoreTier string
+
+// StorageRehydratePriority represents the priority of rehydrating an archived blob.
+// @enum Standard,High
+type StorageRehydratePriority string
+
 // RemoveStorageObjectError the container of Multi Delete S3 API error.
 type RemoveStorageObjectError struct {
 	ObjectName string `json:"object_name"`
@@ -576,6 +661,14 @@ type RemoveStorageObjectError struct {
 	Error      string `json:"error"`
 }
 
+// UploadStorageObjectFromURLResult represents the upload result of an item in the batch upload from URLs.
+type UploadStorageObjectFromURLResult struct {
+	Name   string             `json:"name"`
+	URL    string             `json:"url"`
+	Object *StorageUploadInfo `json:"object"`
+	Error  *string            `json:"error"`
+}
+
 // ChecksumType represents a checksum type enum.
 // @enum SHA256,SHA1,CRC32,CRC32C,CRC64NVME,FullObjectCRC32,FullObjectCRC32C,None.
 type ChecksumType string
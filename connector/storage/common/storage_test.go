@@ -1,11 +1,47 @@
 package common
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
 	"testing"
 
+	"github.com/hasura/ndc-sdk-go/v2/utils"
 	"gotest.tools/v3/assert"
 )
 
 func TestServerSideEncryptionConfiguration(t *testing.T) {
 	assert.Assert(t, ServerSideEncryptionConfiguration{}.IsEmpty())
 }
+
+func TestServerSideEncryption(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(make([]byte, encryptionKeySize))
+	sse := ServerSideEncryption{
+		Method: ServerSideEncryptionMethodSseC,
+		Key:    &key,
+	}
+
+	customerKey, err := sse.CustomerKey()
+	assert.NilError(t, err)
+	assert.Equal(t, len(customerKey), encryptionKeySize)
+
+	// the customer-provided key is never written to logs and traces.
+	bs, err := json.Marshal(sse)
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(bs), key))
+
+	_, err = ServerSideEncryption{
+		Method: ServerSideEncryptionMethodSseC,
+		Key:    utils.ToPtr("invalid"),
+	}.CustomerKey()
+	assert.ErrorContains(t, err, "base64-encoded 256-bit key")
+
+	_, err = ServerSideEncryption{
+		Method: ServerSideEncryptionMethodSseC,
+		KeyID:  utils.ToPtr("foo"),
+	}.CustomerKey()
+	assert.ErrorContains(t, err, "doesn't exist in the client configuration")
+
+	_, err = ServerSideEncryption{Method: ServerSideEncryptionMethodKms}.GetKMSKeyID()
+	assert.ErrorContains(t, err, "key_id of the KMS encryption is required")
+}
@@ -81,6 +81,10 @@ func (j *GetStorageObjectOptions) FromValue(input map[string]any) error {
 	if err != nil {
 		return err
 	}
+	j.ServerSideEncryption, err = utils.DecodeNullableObjectValue[ServerSideEncryption](input, "server_side_encryption")
+	if err != nil {
+		return err
+	}
 	j.VersionID, err = utils.GetNullableString(input, "version_id")
 	if err != nil {
 		return err
@@ -247,6 +251,14 @@ func (j *StorageClientCredentialArguments) FromValue(input map[string]any) error
 	if err != nil {
 		return err
 	}
+	j.AccessToken, err = utils.GetStringDefault(input, "access_token")
+	if err != nil {
+		return err
+	}
+	j.AccountURL, err = utils.GetStringDefault(input, "account_url")
+	if err != nil {
+		return err
+	}
 	j.ClientID, err = utils.DecodeNullableObjectValue[StorageClientID](input, "client_id")
 	if err != nil {
 		return err
@@ -259,10 +271,22 @@ func (j *StorageClientCredentialArguments) FromValue(input map[string]any) error
 	if err != nil {
 		return err
 	}
+	j.SASToken, err = utils.GetStringDefault(input, "sas_token")
+	if err != nil {
+		return err
+	}
 	j.SecretAccessKey, err = utils.GetStringDefault(input, "secret_access_key")
 	if err != nil {
 		return err
 	}
+	j.ServiceAccountJSON, err = utils.GetStringDefault(input, "service_account_json")
+	if err != nil {
+		return err
+	}
+	j.SessionToken, err = utils.GetStringDefault(input, "session_token")
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -375,15 +399,30 @@ func (j GetStorageObjectOptions) ToMap() map[string]any {
 		j_RequestParams[i] = j_RequestParams_v
 	}
 	r["request_params"] = j_RequestParams
+	if j.ServerSideEncryption != nil {
+		r["server_side_encryption"] = (*j.ServerSideEncryption)
+	}
 	r["version_id"] = j.VersionID
 
 	return r
 }
 
+// ToMap encodes the struct to a value map
+func (j HTTPExpectedChecksum) ToMap() map[string]any {
+	r := make(map[string]any)
+	r["algorithm"] = j.Algorithm
+	r["value"] = j.Value
+
+	return r
+}
+
 // ToMap encodes the struct to a value map
 func (j HTTPRequestOptions) ToMap() map[string]any {
 	r := make(map[string]any)
 	r["body_text"] = j.BodyText
+	if j.ExpectedChecksum != nil {
+		r["expected_checksum"] = (*j.ExpectedChecksum)
+	}
 	j_Headers := make([]any, len(j.Headers))
 	for i, j_Headers_v := range j.Headers {
 		j_Headers[i] = j_Headers_v
@@ -612,6 +651,8 @@ func (j PutStorageObjectOptions) ToMap() map[string]any {
 	r["disable_content_sha256"] = j.DisableContentSha256
 	r["disable_multipart"] = j.DisableMultipart
 	r["expires"] = j.Expires
+	r["if_match"] = j.IfMatch
+	r["if_none_match"] = j.IfNoneMatch
 	r["legal_hold"] = j.LegalHold
 	j_Metadata := make([]any, len(j.Metadata))
 	for i, j_Metadata_v := range j.Metadata {
@@ -624,6 +665,9 @@ func (j PutStorageObjectOptions) ToMap() map[string]any {
 		r["retention"] = (*j.Retention)
 	}
 	r["send_content_md5"] = j.SendContentMd5
+	if j.ServerSideEncryption != nil {
+		r["server_side_encryption"] = (*j.ServerSideEncryption)
+	}
 	r["storage_class"] = j.StorageClass
 	j_Tags := make([]any, len(j.Tags))
 	for i, j_Tags_v := range j.Tags {
@@ -666,6 +710,16 @@ func (j RemoveStorageObjectOptions) ToMap() map[string]any {
 	return r
 }
 
+// ToMap encodes the struct to a value map
+func (j ServerSideEncryption) ToMap() map[string]any {
+	r := make(map[string]any)
+	r["key"] = j.Key
+	r["key_id"] = j.KeyID
+	r["method"] = j.Method
+
+	return r
+}
+
 // ToMap encodes the struct to a value map
 func (j ServerSideEncryptionConfiguration) ToMap() map[string]any {
 	r := make(map[string]any)
@@ -777,10 +831,15 @@ func (j StorageBucketVersioningConfiguration) ToMap() map[string]any {
 func (j StorageClientCredentialArguments) ToMap() map[string]any {
 	r := make(map[string]any)
 	r["access_key_id"] = j.AccessKeyID
+	r["access_token"] = j.AccessToken
+	r["account_url"] = j.AccountURL
 	r["client_id"] = j.ClientID
 	r["client_type"] = j.ClientType
 	r["endpoint"] = j.Endpoint
+	r["sas_token"] = j.SASToken
 	r["secret_access_key"] = j.SecretAccessKey
+	r["service_account_json"] = j.ServiceAccountJSON
+	r["session_token"] = j.SessionToken
 
 	return r
 }
@@ -789,6 +848,9 @@ func (j StorageClientCredentialArguments) ToMap() map[string]any {
 func (j StorageCopyDestOptions) ToMap() map[string]any {
 	r := make(map[string]any)
 	r["bucket"] = j.Bucket
+	if j.Encryption != nil {
+		r["encryption"] = (*j.Encryption)
+	}
 	r["legal_hold"] = j.LegalHold
 	j_Metadata := make([]any, len(j.Metadata))
 	for i, j_Metadata_v := range j.Metadata {
@@ -812,6 +874,9 @@ func (j StorageCopyDestOptions) ToMap() map[string]any {
 func (j StorageCopySrcOptions) ToMap() map[string]any {
 	r := make(map[string]any)
 	r["bucket"] = j.Bucket
+	if j.Encryption != nil {
+		r["encryption"] = (*j.Encryption)
+	}
 	r["end"] = j.End
 	r["match_etag"] = j.MatchETag
 	r["match_modified_since"] = j.MatchModifiedSince
@@ -1082,6 +1147,8 @@ func (j UpdateStorageBucketOptions) ToMap() map[string]any {
 // ToMap encodes the struct to a value map
 func (j UpdateStorageObjectOptions) ToMap() map[string]any {
 	r := make(map[string]any)
+	r["if_match"] = j.IfMatch
+	r["if_none_match"] = j.IfNoneMatch
 	r["legal_hold"] = j.LegalHold
 	if j.Metadata != nil {
 		j_Metadata := make([]any, len((*j.Metadata)))
@@ -1105,6 +1172,29 @@ func (j UpdateStorageObjectOptions) ToMap() map[string]any {
 	return r
 }
 
+// ToMap encodes the struct to a value map
+func (j UploadStorageObjectFromURLItem) ToMap() map[string]any {
+	r := make(map[string]any)
+	r = utils.MergeMap(r, j.HTTPRequestOptions.ToMap())
+	r["name"] = j.Name
+	r["options"] = j.Options
+
+	return r
+}
+
+// ToMap encodes the struct to a value map
+func (j UploadStorageObjectFromURLResult) ToMap() map[string]any {
+	r := make(map[string]any)
+	r["error"] = j.Error
+	r["name"] = j.Name
+	if j.Object != nil {
+		r["object"] = (*j.Object)
+	}
+	r["url"] = j.URL
+
+	return r
+}
+
 // ScalarName get the schema name of the scalar
 func (j ChecksumType) ScalarName() string {
 	return "ChecksumType"
@@ -1294,6 +1384,132 @@ func (s *GoogleStorageRPO) FromValue(value any) error {
 	return nil
 }
 
+// ScalarName get the schema name of the scalar
+func (j HTTPChecksumAlgorithm) ScalarName() string {
+	return "HTTPChecksumAlgorithm"
+}
+
+const (
+	HTTPChecksumAlgorithmMd5    HTTPChecksumAlgorithm = "MD5"
+	HTTPChecksumAlgorithmSha1   HTTPChecksumAlgorithm = "SHA1"
+	HTTPChecksumAlgorithmSha256 HTTPChecksumAlgorithm = "SHA256"
+	HTTPChecksumAlgorithmCrc32  HTTPChecksumAlgorithm = "CRC32"
+	HTTPChecksumAlgorithmCrc32C HTTPChecksumAlgorithm = "CRC32C"
+)
+
+var enumValues_HTTPChecksumAlgorithm = []HTTPChecksumAlgorithm{HTTPChecksumAlgorithmMd5, HTTPChecksumAlgorithmSha1, HTTPChecksumAlgorithmSha256, HTTPChecksumAlgorithmCrc32, HTTPChecksumAlgorithmCrc32C}
+
+// ParseHTTPChecksumAlgorithm parses a HTTPChecksumAlgorithm enum from string
+func ParseHTTPChecksumAlgorithm(input string) (HTTPChecksumAlgorithm, error) {
+	result := HTTPChecksumAlgorithm(input)
+	if !slices.Contains(enumValues_HTTPChecksumAlgorithm, result) {
+		return HTTPChecksumAlgorithm(""), errors.New("failed to parse HTTPChecksumAlgorithm, expect one of [MD5, SHA1, SHA256, CRC32, CRC32C]")
+	}
+
+	return result, nil
+}
+
+// IsValid checks if the value is invalid
+func (j HTTPChecksumAlgorithm) IsValid() bool {
+	return slices.Contains(enumValues_HTTPChecksumAlgorithm, j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *HTTPChecksumAlgorithm) UnmarshalJSON(b []byte) error {
+	var rawValue string
+	if err := json.Unmarshal(b, &rawValue); err != nil {
+		return err
+	}
+
+	value, err := ParseHTTPChecksumAlgorithm(rawValue)
+	if err != nil {
+		return err
+	}
+
+	*j = value
+	return nil
+}
+
+// FromValue decodes the scalar from an unknown value
+func (s *HTTPChecksumAlgorithm) FromValue(value any) error {
+	valueStr, err := utils.DecodeNullableString(value)
+	if err != nil {
+		return err
+	}
+	if valueStr == nil {
+		return nil
+	}
+	result, err := ParseHTTPChecksumAlgorithm(*valueStr)
+	if err != nil {
+		return err
+	}
+
+	*s = result
+	return nil
+}
+
+// ScalarName get the schema name of the scalar
+func (j ServerSideEncryptionMethod) ScalarName() string {
+	return "ServerSideEncryptionMethod"
+}
+
+const (
+	ServerSideEncryptionMethodSseC ServerSideEncryptionMethod = "SSE_C"
+	ServerSideEncryptionMethodKms  ServerSideEncryptionMethod = "KMS"
+	ServerSideEncryptionMethodS3   ServerSideEncryptionMethod = "S3"
+)
+
+var enumValues_ServerSideEncryptionMethod = []ServerSideEncryptionMethod{ServerSideEncryptionMethodSseC, ServerSideEncryptionMethodKms, ServerSideEncryptionMethodS3}
+
+// ParseServerSideEncryptionMethod parses a ServerSideEncryptionMethod enum from string
+func ParseServerSideEncryptionMethod(input string) (ServerSideEncryptionMethod, error) {
+	result := ServerSideEncryptionMethod(input)
+	if !slices.Contains(enumValues_ServerSideEncryptionMethod, result) {
+		return ServerSideEncryptionMethod(""), errors.New("failed to parse ServerSideEncryptionMethod, expect one of [SSE_C, KMS, S3]")
+	}
+
+	return result, nil
+}
+
+// IsValid checks if the value is invalid
+func (j ServerSideEncryptionMethod) IsValid() bool {
+	return slices.Contains(enumValues_ServerSideEncryptionMethod, j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *ServerSideEncryptionMethod) UnmarshalJSON(b []byte) error {
+	var rawValue string
+	if err := json.Unmarshal(b, &rawValue); err != nil {
+		return err
+	}
+
+	value, err := ParseServerSideEncryptionMethod(rawValue)
+	if err != nil {
+		return err
+	}
+
+	*j = value
+	return nil
+}
+
+// FromValue decodes the scalar from an unknown value
+func (s *ServerSideEncryptionMethod) FromValue(value any) error {
+	valueStr, err := utils.DecodeNullableString(value)
+	if err != nil {
+		return err
+	}
+	if valueStr == nil {
+		return nil
+	}
+	result, err := ParseServerSideEncryptionMethod(*valueStr)
+	if err != nil {
+		return err
+	}
+
+	*s = result
+	return nil
+}
+
 // ScalarName get the schema name of the scalar
 func (j StorageClientID) ScalarName() string {
 	return "StorageClientID"
@@ -1425,6 +1641,129 @@ func (s *StorageProviderType) FromValue(value any) error {
 	return nil
 }
 
+// ScalarName get the schema name of the scalar
+func (j StorageRehydratePriority) ScalarName() string {
+	return "StorageRehydratePriority"
+}
+
+const (
+	StorageRehydratePriorityStandard StorageRehydratePriority = "Standard"
+	StorageRehydratePriorityHigh     StorageRehydratePriority = "High"
+)
+
+var enumValues_StorageRehydratePriority = []StorageRehydratePriority{StorageRehydratePriorityStandard, StorageRehydratePriorityHigh}
+
+// ParseStorageRehydratePriority parses a StorageRehydratePriority enum from string
+func ParseStorageRehydratePriority(input string) (StorageRehydratePriority, error) {
+	result := StorageRehydratePriority(input)
+	if !slices.Contains(enumValues_StorageRehydratePriority, result) {
+		return StorageRehydratePriority(""), errors.New("failed to parse StorageRehydratePriority, expect one of [Standard, High]")
+	}
+
+	return result, nil
+}
+
+// IsValid checks if the value is invalid
+func (j StorageRehydratePriority) IsValid() bool {
+	return slices.Contains(enumValues_StorageRehydratePriority, j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *StorageRehydratePriority) UnmarshalJSON(b []byte) error {
+	var rawValue string
+	if err := json.Unmarshal(b, &rawValue); err != nil {
+		return err
+	}
+
+	value, err := ParseStorageRehydratePriority(rawValue)
+	if err != nil {
+		return err
+	}
+
+	*j = value
+	return nil
+}
+
+// FromValue decodes the scalar from an unknown value
+func (s *StorageRehydratePriority) FromValue(value any) error {
+	valueStr, err := utils.DecodeNullableString(value)
+	if err != nil {
+		return err
+	}
+	if valueStr == nil {
+		return nil
+	}
+	result, err := ParseStorageRehydratePriority(*valueStr)
+	if err != nil {
+		return err
+	}
+
+	*s = result
+	return nil
+}
+
+// ScalarName get the schema name of the scalar
+func (j StorageRestoreTier) ScalarName() string {
+	return "StorageRestoreTier"
+}
+
+const (
+	StorageRestoreTierStandard  StorageRestoreTier = "Standard"
+	StorageRestoreTierBulk      StorageRestoreTier = "Bulk"
+	StorageRestoreTierExpedited StorageRestoreTier = "Expedited"
+)
+
+var enumValues_StorageRestoreTier = []StorageRestoreTier{StorageRestoreTierStandard, StorageRestoreTierBulk, StorageRestoreTierExpedited}
+
+// ParseStorageRestoreTier parses a StorageRestoreTier enum from string
+func ParseStorageRestoreTier(input string) (StorageRestoreTier, error) {
+	result := StorageRestoreTier(input)
+	if !slices.Contains(enumValues_StorageRestoreTier, result) {
+		return StorageRestoreTier(""), errors.New("failed to parse StorageRestoreTier, expect one of [Standard, Bulk, Expedited]")
+	}
+
+	return result, nil
+}
+
+// IsValid checks if the value is invalid
+func (j StorageRestoreTier) IsValid() bool {
+	return slices.Contains(enumValues_StorageRestoreTier, j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *StorageRestoreTier) UnmarshalJSON(b []byte) error {
+	var rawValue string
+	if err := json.Unmarshal(b, &rawValue); err != nil {
+		return err
+	}
+
+	value, err := ParseStorageRestoreTier(rawValue)
+	if err != nil {
+		return err
+	}
+
+	*j = value
+	return nil
+}
+
+// FromValue decodes the scalar from an unknown value
+func (s *StorageRestoreTier) FromValue(value any) error {
+	valueStr, err := utils.DecodeNullableString(value)
+	if err != nil {
+		return err
+	}
+	if valueStr == nil {
+		return nil
+	}
+	result, err := ParseStorageRestoreTier(*valueStr)
+	if err != nil {
+		return err
+	}
+
+	*s = result
+	return nil
+}
+
 // ScalarName get the schema name of the scalar
 func (j StorageRetentionMode) ScalarName() string {
 	return "StorageRetentionMode"
@@ -0,0 +1,239 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+	"github.com/invopop/jsonschema"
+)
+
+// UploadSniffLength is the number of leading bytes that are used to detect the content type of uploaded objects.
+const UploadSniffLength = 512
+
+// UploadPolicyConfig represents restrictions of objects that are uploaded through the connector.
+type UploadPolicyConfig struct {
+	// Buckets that the policy applies to. Apply to all buckets of the client if empty.
+	Buckets []string `json:"buckets,omitempty"             mapstructure:"buckets"             yaml:"buckets,omitempty"`
+	// Allowed MIME types, e.g. image/png. Wildcard subtypes such as image/* are supported. Allow all types if empty.
+	AllowedContentTypes []string `json:"allowedContentTypes,omitempty" mapstructure:"allowedContentTypes" yaml:"allowedContentTypes,omitempty"`
+	// Denied MIME types. Denied types take precedence over allowed types.
+	DeniedContentTypes []string `json:"deniedContentTypes,omitempty"  mapstructure:"deniedContentTypes"  yaml:"deniedContentTypes,omitempty"`
+	// Maximum size in bytes of uploaded objects.
+	MaxSize *int64 `json:"maxSize,omitempty"             mapstructure:"maxSize"             yaml:"maxSize,omitempty"`
+	// Metadata keys that uploaded objects must have.
+	RequiredMetadata []string `json:"requiredMetadata,omitempty"    mapstructure:"requiredMetadata"    yaml:"requiredMetadata,omitempty"`
+}
+
+// Validate checks if the policy is valid.
+func (upc UploadPolicyConfig) Validate() error {
+	for _, contentType := range slices.Concat(upc.AllowedContentTypes, upc.DeniedContentTypes) {
+		mediaType, subType, ok := strings.Cut(contentType, "/")
+		if !ok || mediaType == "" || subType == "" {
+			return fmt.Errorf("invalid MIME type: %s", contentType)
+		}
+	}
+
+	if upc.MaxSize != nil && *upc.MaxSize <= 0 {
+		return errors.New("maxSize must be larger than 0")
+	}
+
+	return nil
+}
+
+// MatchBucket checks if the policy applies to the bucket.
+func (upc UploadPolicyConfig) MatchBucket(bucketName string) bool {
+	return len(upc.Buckets) == 0 || slices.Contains(upc.Buckets, bucketName)
+}
+
+// ValidateUpload checks metadata, the size and the content type of the uploaded object.
+// The head is leading bytes of the content. A negative size means the size is unknown.
+func (upc UploadPolicyConfig) ValidateUpload(
+	opts *PutStorageObjectOptions,
+	head []byte,
+	size int64,
+) error {
+	if err := upc.ValidateMetadata(opts.Metadata); err != nil {
+		return err
+	}
+
+	if err := upc.ValidateSize(size); err != nil {
+		return err
+	}
+
+	return upc.ValidateContent(head, opts.ContentType)
+}
+
+// ValidateMetadata checks if required metadata keys exist. Keys are case-insensitive.
+func (upc UploadPolicyConfig) ValidateMetadata(metadata []StorageKeyValue) error {
+	for _, key := range upc.RequiredMetadata {
+		if !slices.ContainsFunc(metadata, func(item StorageKeyValue) bool {
+			return strings.EqualFold(item.Key, key) && item.Value != ""
+		}) {
+			return schema.UnprocessableContentError(
+				fmt.Sprintf("the metadata key %s is required by the upload policy", key),
+				nil,
+			)
+		}
+	}
+
+	return nil
+}
+
+// ValidateSize checks if the object size is under the limit. A negative size means the size is unknown.
+func (upc UploadPolicyConfig) ValidateSize(size int64) error {
+	if upc.MaxSize == nil || size <= *upc.MaxSize {
+		return nil
+	}
+
+	return upc.MaxSizeError()
+}
+
+// MaxSizeError returns the error when the object exceeds the maximum size.
+func (upc UploadPolicyConfig) MaxSizeError() error {
+	return schema.UnprocessableContentError(
+		fmt.Sprintf("object size > %d bytes is not allowed by the upload policy", *upc.MaxSize),
+		nil,
+	)
+}
+
+// ValidateContent detects the content type from leading bytes of the object
+// and checks it against allowed and denied types. The declared content type isn't trusted.
+func (upc UploadPolicyConfig) ValidateContent(head []byte, declaredType string) error {
+	if len(upc.AllowedContentTypes) == 0 && len(upc.DeniedContentTypes) == 0 {
+		return nil
+	}
+
+	contentType := DetectContentType(head, declaredType)
+
+	if matchContentTypes(upc.DeniedContentTypes, contentType) ||
+		(len(upc.AllowedContentTypes) > 0 && !matchContentTypes(upc.AllowedContentTypes, contentType)) {
+		return schema.UnprocessableContentError(
+			fmt.Sprintf("content type %s is not allowed by the upload policy", contentType),
+			map[string]any{
+				"content_type":          contentType,
+				"declared_content_type": declaredType,
+			},
+		)
+	}
+
+	return nil
+}
+
+// JSONSchema is used to generate a custom jsonschema.
+func (upc UploadPolicyConfig) JSONSchema() *jsonschema.Schema {
+	stringArray := func(description string) *jsonschema.Schema {
+		return &jsonschema.Schema{
+			Description: description,
+			Type:        "array",
+			Items: &jsonschema.Schema{
+				Type: "string",
+			},
+		}
+	}
+
+	properties := jsonschema.NewProperties()
+	properties.Set("buckets", stringArray(
+		"Buckets that the policy applies to. Apply to all buckets of the client if empty",
+	))
+	properties.Set("allowedContentTypes", stringArray(
+		"Allowed MIME types, e.g. image/png. Wildcard subtypes such as image/* are supported. Allow all types if empty",
+	))
+	properties.Set("deniedContentTypes", stringArray(
+		"Denied MIME types. Denied types take precedence over allowed types",
+	))
+	properties.Set("maxSize", &jsonschema.Schema{
+		Description: "Maximum size in bytes of uploaded objects",
+		Type:        "integer",
+		Minimum:     json.Number("1"),
+	})
+	properties.Set("requiredMetadata", stringArray(
+		"Metadata keys that uploaded objects must have",
+	))
+
+	return &jsonschema.Schema{
+		Description: "Restrictions of objects that are uploaded through the connector",
+		Type:        "object",
+		Properties:  properties,
+	}
+}
+
+// FindUploadPolicy returns the first policy that applies to the bucket.
+func FindUploadPolicy(policies []UploadPolicyConfig, bucketName string) *UploadPolicyConfig {
+	for i, policy := range policies {
+		if policy.MatchBucket(bucketName) {
+			return &policies[i]
+		}
+	}
+
+	return nil
+}
+
+// DetectContentType detects the MIME type of the content from magic bytes.
+// The declared type is used only if it is a more specific type of the same format,
+// e.g. application/json of plain text or a document format of a zip archive.
+// Unknown binary content is detected as application/octet-stream regardless of the declared type.
+func DetectContentType(head []byte, declaredType string) string {
+	if len(head) > UploadSniffLength {
+		head = head[:UploadSniffLength]
+	}
+
+	sniffedType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	declaredMediaType, _, err := mime.ParseMediaType(declaredType)
+
+	if err != nil || declaredMediaType == "" {
+		return sniffedType
+	}
+
+	switch sniffedType {
+	case "text/plain", "text/xml":
+		if isTextContentType(declaredMediaType) {
+			return declaredMediaType
+		}
+	case "application/zip":
+		if strings.HasSuffix(declaredMediaType, "+zip") ||
+			strings.HasPrefix(declaredMediaType, "application/vnd.openxmlformats-officedocument.") ||
+			strings.HasPrefix(declaredMediaType, "application/vnd.oasis.opendocument.") {
+			return declaredMediaType
+		}
+	default:
+	}
+
+	return sniffedType
+}
+
+func isTextContentType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml") ||
+		slices.Contains([]string{
+			"application/json",
+			"application/xml",
+			"application/yaml",
+			"application/x-yaml",
+			"application/x-ndjson",
+			"application/javascript",
+		}, mediaType)
+}
+
+func matchContentTypes(patterns []string, contentType string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+
+		if pattern == contentType || pattern == "*/*" {
+			return true
+		}
+
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok &&
+			strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,91 @@
+package common
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+var (
+	pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	elfHeader = []byte("\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00")
+	zipHeader = []byte("PK\x03\x04\x14\x00\x06\x00")
+)
+
+func TestDetectContentType(t *testing.T) {
+	testCases := []struct {
+		Name         string
+		Head         []byte
+		DeclaredType string
+		Expected     string
+	}{
+		{Name: "png", Head: pngHeader, DeclaredType: "image/png", Expected: "image/png"},
+		{Name: "png_without_declared_type", Head: pngHeader, Expected: "image/png"},
+		{Name: "executable_as_png", Head: elfHeader, DeclaredType: "image/png", Expected: "application/octet-stream"},
+		{Name: "html_as_png", Head: []byte("<html><script>"), DeclaredType: "image/png", Expected: "text/html"},
+		{Name: "json", Head: []byte(`{"a": 1}`), DeclaredType: "application/json; charset=utf-8", Expected: "application/json"},
+		{Name: "csv", Head: []byte("a,b\n1,2\n"), DeclaredType: "text/csv", Expected: "text/csv"},
+		{Name: "text_as_png", Head: []byte("hello world"), DeclaredType: "image/png", Expected: "text/plain"},
+		{Name: "docx", Head: zipHeader, DeclaredType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", Expected: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+		{Name: "zip_as_pdf", Head: zipHeader, DeclaredType: "application/pdf", Expected: "application/zip"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, DetectContentType(tc.Head, tc.DeclaredType), tc.Expected)
+		})
+	}
+}
+
+func TestUploadPolicy(t *testing.T) {
+	maxSize := int64(1024)
+	policy := UploadPolicyConfig{
+		Buckets:             []string{"public"},
+		AllowedContentTypes: []string{"image/*", "application/pdf"},
+		DeniedContentTypes:  []string{"image/svg+xml"},
+		MaxSize:             &maxSize,
+		RequiredMetadata:    []string{"owner"},
+	}
+	assert.NilError(t, policy.Validate())
+
+	assert.Assert(t, FindUploadPolicy([]UploadPolicyConfig{policy}, "private") == nil)
+	assert.Equal(t, FindUploadPolicy([]UploadPolicyConfig{policy, {}}, "public").MaxSize, &maxSize)
+
+	opts := &PutStorageObjectOptions{
+		ContentType: "image/png",
+		Metadata:    []StorageKeyValue{{Key: "Owner", Value: "user-1"}},
+	}
+	assert.NilError(t, policy.ValidateUpload(opts, pngHeader, 100))
+	assert.NilError(t, policy.ValidateUpload(opts, pngHeader, -1))
+
+	assert.ErrorContains(
+		t,
+		policy.ValidateUpload(opts, elfHeader, 100),
+		"content type application/octet-stream is not allowed",
+	)
+	assert.ErrorContains(
+		t,
+		policy.ValidateUpload(&PutStorageObjectOptions{
+			ContentType: "image/svg+xml",
+			Metadata:    opts.Metadata,
+		}, []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), 100),
+		"content type image/svg+xml is not allowed",
+	)
+	assert.ErrorContains(
+		t,
+		policy.ValidateUpload(opts, pngHeader, 2048),
+		"object size > 1024 bytes is not allowed",
+	)
+	assert.ErrorContains(
+		t,
+		policy.ValidateUpload(&PutStorageObjectOptions{ContentType: "image/png"}, pngHeader, 100),
+		"the metadata key owner is required",
+	)
+
+	assert.ErrorContains(t, UploadPolicyConfig{
+		AllowedContentTypes: []string{"image"},
+	}.Validate(), "invalid MIME type")
+	assert.ErrorContains(t, UploadPolicyConfig{
+		MaxSize: new(int64),
+	}.Validate(), "maxSize must be larger than 0")
+}
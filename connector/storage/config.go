@@ -27,9 +27,20 @@ type Client struct {
 	common.StorageClient
 
 	id                     common.StorageClientID
+	provider               common.StorageProviderType
 	defaultBucket          string
 	defaultPresignedExpiry *time.Duration
 	allowedBuckets         []string
+	uploadPolicies         []common.UploadPolicyConfig
+	breaker                *circuitBreaker
+	metadataCache          *metadataCache
+	contentCache           *contentCache
+	lease                  *clientLease
+}
+
+// getUploadPolicy returns the upload policy that applies to the bucket, or nil if uploads aren't restricted.
+func (c *Client) getUploadPolicy(bucketName string) *common.UploadPolicyConfig {
+	return common.FindUploadPolicy(c.uploadPolicies, bucketName)
 }
 
 // ValidateBucket checks if the bucket name is valid, or returns the default bucket if empty.
@@ -58,6 +69,12 @@ type ClientConfig map[string]any
 
 // Validate validates the configuration.
 func (cc ClientConfig) Validate() error {
+	// secret files may be mounted in the runtime environment only, so they aren't read when validating.
+	cc, _, err := cc.resolveSecrets(false)
+	if err != nil {
+		return err
+	}
+
 	storageType, err := cc.getStorageType()
 	if err != nil {
 		return err
@@ -201,10 +218,19 @@ func (cc ClientConfig) JSONSchema() *jsonschema.Schema {
 type RuntimeSettings struct {
 	// Maximum size in MB of the object is allowed to download the content in the GraphQL response
 	// to avoid memory leaks. Pre-signed URLs are recommended for large files.
-	MaxDownloadSizeMBs int64 `json:"maxDownloadSizeMBs" jsonschema:"min=1,default=20" yaml:"maxDownloadSizeMBs"`
+	MaxDownloadSizeMBs int64 `json:"maxDownloadSizeMBs"        jsonschema:"min=1,default=20" yaml:"maxDownloadSizeMBs"`
 	// Maximum size in MB of the object is allowed to upload the content from HTTP URL
 	// to avoid memory leaks. Pre-signed URLs are recommended for large files.
-	MaxUploadSizeMBs int64 `json:"maxUploadSizeMBs"   jsonschema:"min=1,default=20" yaml:"maxUploadSizeMBs"`
+	MaxUploadSizeMBs int64 `json:"maxUploadSizeMBs"          jsonschema:"min=1,default=20" yaml:"maxUploadSizeMBs"`
 	// Configuration for the http client that is used for uploading files from URL.
-	HTTP *exhttp.HTTPTransportTLSConfig `json:"http,omitempty"                                   yaml:"http"`
+	HTTP *exhttp.HTTPTransportTLSConfig `json:"http,omitempty"            yaml:"http"`
+	// Retry policy of HTTP requests to download files from URL.
+	HTTPRetry *common.HTTPRetrySettings `json:"httpRetry,omitempty"       yaml:"httpRetry,omitempty"`
+	// Cache of clients that are created from dynamic credentials.
+	ClientCache *ClientCacheSettings `json:"clientCache,omitempty"     yaml:"clientCache,omitempty"`
+	// Settings of the rotation of secrets that are read from files or environment variables.
+	Secrets *SecretSettings `json:"secrets,omitempty"         yaml:"secrets,omitempty"`
+	// Maximum time in seconds to wait for in-flight uploads when the connector shuts down.
+	// Uploads that don't finish in time are aborted and their incomplete multipart uploads are removed.
+	ShutdownTimeout *int `json:"shutdownTimeout,omitempty" jsonschema:"min=0,default=30" yaml:"shutdownTimeout,omitempty"`
 }
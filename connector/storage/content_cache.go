@@ -0,0 +1,544 @@
+package storage
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+type contentCacheEntry struct {
+	id     uint64
+	key    string
+	bucket string
+	object string
+	stat   common.StorageObject
+	size   int64
+	// content of the memory storage.
+	data []byte
+	// the file path of the disk storage.
+	path        string
+	validatedAt time.Time
+}
+
+// matches checks if the cached content has the same version as the object.
+// The ETag is compared if both exist, otherwise the last modified time and the size.
+func (cce *contentCacheEntry) matches(object *common.StorageObject) bool {
+	if cce.stat.ETag != nil && object.ETag != nil {
+		return *cce.stat.ETag == *object.ETag
+	}
+
+	return !object.LastModified.IsZero() &&
+		object.LastModified.Equal(cce.stat.LastModified) &&
+		object.Size != nil && *object.Size == cce.size
+}
+
+// contentCache is a size-bounded LRU cache of small downloaded objects of a storage client.
+// Contents are kept in memory or in files of a temporary directory that is removed when the cache is closed.
+type contentCache struct {
+	maxSize       int64
+	maxObjectSize int64
+	ttl           time.Duration
+	directory     string
+	now           func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	size    int64
+	lastID  uint64
+	// generation is increased on every invalidation so contents of downloads
+	// that were in flight during the mutation aren't cached.
+	generation uint64
+
+	requestCounter metric.Int64Counter
+	sizeCounter    metric.Int64UpDownCounter
+	attributes     []attribute.KeyValue
+}
+
+// newContentCache creates a content cache from the settings. The result is nil if the setting is empty.
+func newContentCache(
+	clientID common.StorageClientID,
+	settings *common.ContentCacheSettings,
+	meter metric.Meter,
+) (*contentCache, error) {
+	if settings == nil {
+		return nil, nil
+	}
+
+	cc := &contentCache{
+		maxSize:       settings.GetMaxSize(),
+		maxObjectSize: settings.GetMaxObjectSize(),
+		ttl:           settings.GetTTL(),
+		now:           time.Now,
+		entries:       map[string]*list.Element{},
+		order:         list.New(),
+		attributes:    []attribute.KeyValue{attribute.String("client_id", string(clientID))},
+	}
+
+	if settings.Storage == common.ContentCacheStorageDisk {
+		parentDir := settings.Directory
+		if parentDir == "" {
+			parentDir = os.TempDir()
+		}
+
+		if err := os.MkdirAll(parentDir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create the content cache directory: %w", err)
+		}
+
+		// each cache owns a new directory so files of other clients or previous processes aren't served.
+		dir, err := os.MkdirTemp(parentDir, "ndc-storage-content-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the content cache directory: %w", err)
+		}
+
+		cc.directory = dir
+	}
+
+	requestCounter, err := meter.Int64Counter(
+		"storage.content_cache.requests",
+		metric.WithDescription("The number of lookups in the content cache of storage clients"),
+	)
+	if err != nil {
+		requestCounter = noop.Int64Counter{}
+	}
+
+	sizeCounter, err := meter.Int64UpDownCounter(
+		"storage.content_cache.size",
+		metric.WithDescription("The total size of cached contents of storage clients"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		sizeCounter = noop.Int64UpDownCounter{}
+	}
+
+	cc.requestCounter = requestCounter
+	cc.sizeCounter = sizeCounter
+
+	return cc, nil
+}
+
+// Close removes all cached contents.
+func (cc *contentCache) Close() {
+	if cc == nil {
+		return
+	}
+
+	cc.purge("", "")
+
+	if cc.directory != "" {
+		// readers of cached files keep reading after the files are removed.
+		_ = os.RemoveAll(cc.directory)
+	}
+}
+
+// Len returns the number of cached objects.
+func (cc *contentCache) Len() int {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	return cc.order.Len()
+}
+
+// lookup returns a snapshot of the cached entry of the key and the current generation of the cache.
+// The entry is fresh if it was validated within the TTL.
+func (cc *contentCache) lookup(key string) (*contentCacheEntry, uint64, bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	elem, ok := cc.entries[key]
+	if !ok {
+		return nil, cc.generation, false
+	}
+
+	entry, _ := elem.Value.(*contentCacheEntry)
+	snapshot := *entry
+
+	return &snapshot, cc.generation, cc.ttl > 0 && cc.now().Sub(entry.validatedAt) < cc.ttl
+}
+
+// open returns a reader of the cached content and marks the entry as recently used.
+// The entry is removed if the cached file can't be opened.
+func (cc *contentCache) open(
+	ctx context.Context,
+	entry *contentCacheEntry,
+	revalidated bool,
+) (io.ReadCloser, bool) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	elem, ok := cc.entries[entry.key]
+	if !ok {
+		return nil, false
+	}
+
+	current, _ := elem.Value.(*contentCacheEntry)
+	if current.id != entry.id {
+		// the entry was replaced concurrently.
+		return nil, false
+	}
+
+	var reader io.ReadCloser
+
+	if current.path == "" {
+		reader = io.NopCloser(bytes.NewReader(current.data))
+	} else {
+		file, err := os.Open(current.path)
+		if err != nil {
+			cc.remove(ctx, elem)
+
+			return nil, false
+		}
+
+		reader = file
+	}
+
+	if revalidated {
+		current.validatedAt = cc.now()
+	}
+
+	cc.order.MoveToFront(elem)
+
+	return reader, true
+}
+
+// store caches the content of the object if the cache wasn't invalidated since the generation.
+// Objects that are larger than the maximum object size are ignored.
+func (cc *contentCache) store(
+	ctx context.Context,
+	generation uint64,
+	key string,
+	bucket string,
+	object string,
+	stat *common.StorageObject,
+	data []byte,
+) {
+	size := int64(len(data))
+	if size > cc.maxObjectSize {
+		return
+	}
+
+	entry := &contentCacheEntry{
+		key:         key,
+		bucket:      bucket,
+		object:      object,
+		stat:        *stat,
+		size:        size,
+		validatedAt: cc.now(),
+	}
+
+	if cc.directory == "" {
+		entry.data = data
+	} else {
+		path, err := cc.writeFile(key, data)
+		if err != nil {
+			return
+		}
+
+		entry.path = path
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if generation != cc.generation {
+		if entry.path != "" {
+			_ = os.Remove(entry.path)
+		}
+
+		return
+	}
+
+	if elem, ok := cc.entries[key]; ok {
+		cc.remove(ctx, elem)
+	}
+
+	cc.lastID++
+	entry.id = cc.lastID
+	cc.entries[key] = cc.order.PushFront(entry)
+	cc.size += size
+	cc.sizeCounter.Add(ctx, size, metric.WithAttributes(cc.attributes...))
+
+	for cc.size > cc.maxSize {
+		cc.remove(ctx, cc.order.Back())
+	}
+}
+
+// writeFile writes the content to a new file so readers of the previous file aren't affected.
+func (cc *contentCache) writeFile(key string, data []byte) (string, error) {
+	hash := sha256.Sum256([]byte(key))
+
+	file, err := os.CreateTemp(cc.directory, hex.EncodeToString(hash[:])+"-")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(file.Name())
+
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+// invalidateObject removes the cached content of the object.
+func (cc *contentCache) invalidateObject(bucket string, object string) {
+	cc.removeIf(context.Background(), func(entry *contentCacheEntry) bool {
+		return entry.bucket == bucket && entry.object == object
+	})
+}
+
+// invalidateBucket removes all cached contents of the bucket.
+func (cc *contentCache) invalidateBucket(bucket string) {
+	cc.removeIf(context.Background(), func(entry *contentCacheEntry) bool {
+		return entry.bucket == bucket
+	})
+}
+
+// purge removes cached contents of objects in the bucket whose names start with the prefix.
+// All cached contents are removed if the bucket is empty. Returns the number of removed objects.
+func (cc *contentCache) purge(bucket string, prefix string) int {
+	return cc.removeIf(context.Background(), func(entry *contentCacheEntry) bool {
+		return (bucket == "" || entry.bucket == bucket) && strings.HasPrefix(entry.object, prefix)
+	})
+}
+
+func (cc *contentCache) removeIf(ctx context.Context, predicate func(entry *contentCacheEntry) bool) int {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	cc.generation++
+
+	var count int
+
+	for elem := cc.order.Front(); elem != nil; {
+		next := elem.Next()
+
+		entry, _ := elem.Value.(*contentCacheEntry)
+		if predicate(entry) {
+			cc.remove(ctx, elem)
+			count++
+		}
+
+		elem = next
+	}
+
+	return count
+}
+
+// remove deletes the entry and its file. The lock must be held.
+func (cc *contentCache) remove(ctx context.Context, elem *list.Element) {
+	entry, _ := cc.order.Remove(elem).(*contentCacheEntry)
+	delete(cc.entries, entry.key)
+
+	cc.size -= entry.size
+	cc.sizeCounter.Add(ctx, -entry.size, metric.WithAttributes(cc.attributes...))
+
+	if entry.path != "" {
+		_ = os.Remove(entry.path)
+	}
+}
+
+func (cc *contentCache) recordRequest(ctx context.Context, result string) {
+	cc.requestCounter.Add(ctx, 1, metric.WithAttributes(
+		slices.Concat(cc.attributes, []attribute.KeyValue{attribute.String("result", result)})...,
+	))
+}
+
+// PurgeCache removes cached contents and metadata of objects in the bucket whose names start with the prefix.
+// All cached objects of the client are removed if the bucket is empty. Caches of all clients are purged if the client ID is empty.
+// The bucket is required if access policies are enabled, so that sessions can't purge caches of buckets they can't write.
+func (m *Manager) PurgeCache(
+	ctx context.Context,
+	clientID *common.StorageClientID,
+	bucketName string,
+	prefix string,
+) error {
+	if m.closed.Load() {
+		return errManagerClosed
+	}
+
+	if bucketName == "" && m.policy != nil {
+		return schema.UnprocessableContentError("bucket is required to purge caches if access policies are enabled", nil)
+	}
+
+	clients := m.getClients()
+
+	if clientID != nil && *clientID != "" {
+		client, ok := m.GetClient(clientID)
+		if !ok {
+			return schema.UnprocessableContentError("client not found: "+string(*clientID), nil)
+		}
+
+		clients = []Client{*client}
+	}
+
+	for _, client := range clients {
+		if bucketName != "" {
+			if err := m.authorizeBucket(ctx, PolicyOperationWrite, &client, bucketName); err != nil {
+				return err
+			}
+		}
+
+		if client.metadataCache != nil {
+			client.metadataCache.purge(bucketName, prefix)
+		}
+
+		if client.contentCache != nil {
+			client.contentCache.purge(bucketName, prefix)
+		}
+	}
+
+	return nil
+}
+
+// getObjectWithContentCache serves the object from the content cache of the client if the cached content
+// is fresh or isn't modified. Stale contents are revalidated with a conditional download by the ETag,
+// or compared with the current object by the last modified time and size if there is no ETag.
+// Otherwise, small objects are downloaded and cached.
+func (m *Manager) getObjectWithContentCache(
+	ctx context.Context,
+	client *Client,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (*common.StorageObject, io.ReadCloser, error) {
+	cache := client.contentCache
+	key := newContentCacheKey(bucketName, objectName, opts)
+	entry, generation, fresh := cache.lookup(key)
+
+	if fresh {
+		objectStat := entry.stat
+		if err := m.validateDownload(&objectStat, objectName, opts); err != nil {
+			return nil, nil, err
+		}
+
+		if reader, ok := cache.open(ctx, entry, false); ok {
+			cache.recordRequest(ctx, "hit")
+
+			return &objectStat, reader, nil
+		}
+	}
+
+	var content io.ReadCloser
+
+	if entry != nil && entry.stat.ETag != nil && *entry.stat.ETag != "" {
+		conditionalOpts := opts
+		conditionalOpts.IfNoneMatch = *entry.stat.ETag
+
+		var err error
+
+		content, err = client.GetObject(ctx, bucketName, objectName, conditionalOpts)
+		if err != nil {
+			if code, ok := common.GetStorageErrorCode(err); !ok || code != common.ErrorCodePreconditionFailed {
+				return nil, nil, err
+			}
+
+			// the object isn't modified.
+			objectStat := entry.stat
+			if err := m.validateDownload(&objectStat, objectName, opts); err != nil {
+				return nil, nil, err
+			}
+
+			if reader, ok := cache.open(ctx, entry, true); ok {
+				cache.recordRequest(ctx, "hit")
+
+				return &objectStat, reader, nil
+			}
+		}
+	}
+
+	objectStat, err := m.statObject(ctx, client, bucketName, objectName, opts)
+	if err == nil && objectStat != nil {
+		err = m.validateDownload(objectStat, objectName, opts)
+	}
+
+	if err != nil || objectStat == nil {
+		if content != nil {
+			_ = content.Close()
+		}
+
+		return nil, nil, err
+	}
+
+	if content == nil && entry != nil && entry.matches(objectStat) {
+		if reader, ok := cache.open(ctx, entry, true); ok {
+			cache.recordRequest(ctx, "hit")
+
+			return objectStat, reader, nil
+		}
+	}
+
+	cache.recordRequest(ctx, "miss")
+
+	if content == nil {
+		content, err = client.GetObject(ctx, bucketName, objectName, opts)
+	}
+
+	if err != nil || content == nil || *objectStat.Size > cache.maxObjectSize {
+		return objectStat, content, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(content, cache.maxObjectSize+1))
+	if err != nil {
+		_ = content.Close()
+
+		return nil, nil, err
+	}
+
+	if int64(len(data)) > cache.maxObjectSize {
+		// the object was replaced by a larger one after the stat, so the rest is streamed without caching.
+		return objectStat, &prefixedReadCloser{
+			Reader: io.MultiReader(bytes.NewReader(data), content),
+			Closer: content,
+		}, nil
+	}
+
+	_ = content.Close()
+
+	cache.store(ctx, generation, key, bucketName, objectName, objectStat, data)
+
+	return objectStat, io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// prefixedReadCloser reads the buffered prefix before the rest of the stream.
+type prefixedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// isContentCacheable checks if downloads with the options can be cached.
+// Requests with custom headers, server-side encryption keys or part numbers aren't cached.
+func isContentCacheable(opts common.GetStorageObjectOptions) bool {
+	return opts.ServerSideEncryption == nil && opts.PartNumber == nil &&
+		len(opts.Headers) == 0 && len(opts.RequestParams) == 0
+}
+
+func newContentCacheKey(bucketName, objectName string, opts common.GetStorageObjectOptions) string {
+	var versionID string
+
+	if opts.VersionID != nil {
+		versionID = *opts.VersionID
+	}
+
+	return strings.Join([]string{bucketName, objectName, versionID}, "\x00")
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"io"
+
+	"github.com/hasura/ndc-storage/connector/storage/common"
+)
+
+// wrap wraps the storage client to invalidate cached contents when objects are mutated through the client.
+// The client is returned as is if the cache is nil.
+func (cc *contentCache) wrap(client common.StorageClient) common.StorageClient {
+	if cc == nil {
+		return client
+	}
+
+	return &contentCacheClient{
+		StorageClient: client,
+		cache:         cc,
+	}
+}
+
+// contentCacheClient invalidates cached contents of mutated objects and buckets
+// after mutations of the inner storage client, whether they succeed or not.
+type contentCacheClient struct {
+	common.StorageClient
+
+	cache *contentCache
+}
+
+var _ common.StorageClient = (*contentCacheClient)(nil)
+
+// Close closes the inner client if it holds resources.
+func (ccc *contentCacheClient) Close() error {
+	return closeStorageClient(ccc.StorageClient)
+}
+
+// RemoveBucket removes a bucket, bucket should be empty to be successfully removed.
+func (ccc *contentCacheClient) RemoveBucket(ctx context.Context, bucketName string) error {
+	defer ccc.cache.invalidateBucket(bucketName)
+
+	return ccc.StorageClient.RemoveBucket(ctx, bucketName)
+}
+
+// UpdateBucket updates configurations for the bucket.
+func (ccc *contentCacheClient) UpdateBucket(
+	ctx context.Context,
+	bucketName string,
+	opts common.UpdateStorageBucketOptions,
+) error {
+	defer ccc.cache.invalidateBucket(bucketName)
+
+	return ccc.StorageClient.UpdateBucket(ctx, bucketName, opts)
+}
+
+// PutObject uploads an object.
+func (ccc *contentCacheClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts *common.PutStorageObjectOptions,
+	reader io.Reader,
+	objectSize int64,
+) (*common.StorageUploadInfo, error) {
+	defer ccc.cache.invalidateObject(bucketName, objectName)
+
+	return ccc.StorageClient.PutObject(ctx, bucketName, objectName, opts, reader, objectSize)
+}
+
+// CopyObject creates or replaces an object through server-side copying of an existing object.
+func (ccc *contentCacheClient) CopyObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	src common.StorageCopySrcOptions,
+) (*common.StorageUploadInfo, error) {
+	defer ccc.cache.invalidateObject(dest.Bucket, dest.Name)
+
+	return ccc.StorageClient.CopyObject(ctx, dest, src)
+}
+
+// ComposeObject creates an object by concatenating a list of source objects using server-side copying.
+func (ccc *contentCacheClient) ComposeObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	sources []common.StorageCopySrcOptions,
+) (*common.StorageUploadInfo, error) {
+	defer ccc.cache.invalidateObject(dest.Bucket, dest.Name)
+
+	return ccc.StorageClient.ComposeObject(ctx, dest, sources)
+}
+
+// RemoveObject removes an object with some specified options.
+func (ccc *contentCacheClient) RemoveObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.RemoveStorageObjectOptions,
+) error {
+	defer ccc.cache.invalidateObject(bucketName, objectName)
+
+	return ccc.StorageClient.RemoveObject(ctx, bucketName, objectName, opts)
+}
+
+// RemoveObjects removes a list of objects. All cached results of the bucket are invalidated.
+func (ccc *contentCacheClient) RemoveObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.RemoveStorageObjectsOptions,
+	predicate func(string) bool,
+) []common.RemoveStorageObjectError {
+	defer ccc.cache.invalidateBucket(bucketName)
+
+	return ccc.StorageClient.RemoveObjects(ctx, bucketName, opts, predicate)
+}
+
+// UpdateObject updates object configurations.
+func (ccc *contentCacheClient) UpdateObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.UpdateStorageObjectOptions,
+) error {
+	defer ccc.cache.invalidateObject(bucketName, objectName)
+
+	return ccc.StorageClient.UpdateObject(ctx, bucketName, objectName, opts)
+}
+
+// RestoreObject restores a soft-deleted object.
+func (ccc *contentCacheClient) RestoreObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+) error {
+	defer ccc.cache.invalidateObject(bucketName, objectName)
+
+	return ccc.StorageClient.RestoreObject(ctx, bucketName, objectName)
+}
+
+// RestoreArchivedObject restores an object from the archive storage tier.
+func (ccc *contentCacheClient) RestoreArchivedObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.RestoreArchivedStorageObjectOptions,
+) error {
+	defer ccc.cache.invalidateObject(bucketName, objectName)
+
+	return ccc.StorageClient.RestoreArchivedObject(ctx, bucketName, objectName, opts)
+}
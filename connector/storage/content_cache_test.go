@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"go.opentelemetry.io/otel/metric/noop"
+	"gotest.tools/v3/assert"
+)
+
+func TestContentCache(t *testing.T) {
+	for _, storage := range []common.ContentCacheStorage{common.ContentCacheStorageMemory, common.ContentCacheStorageDisk} {
+		t.Run(string(storage), func(t *testing.T) {
+			cache, err := newContentCache("test", &common.ContentCacheSettings{
+				Storage:       storage,
+				Directory:     t.TempDir(),
+				MaxSize:       10,
+				MaxObjectSize: 5,
+				TTL:           10,
+			}, noop.NewMeterProvider().Meter("connector/storage"))
+			assert.NilError(t, err)
+
+			now := time.Now()
+			cache.now = func() time.Time { return now }
+
+			readEntry := func(key string) (string, bool) {
+				entry, _, _ := cache.lookup(key)
+				if entry == nil {
+					return "", false
+				}
+
+				reader, ok := cache.open(context.TODO(), entry, false)
+				if !ok {
+					return "", false
+				}
+
+				defer reader.Close()
+
+				data, err := io.ReadAll(reader)
+				assert.NilError(t, err)
+
+				return string(data), true
+			}
+
+			stat := &common.StorageObject{ETag: utils.ToPtr("a")}
+
+			_, generation, _ := cache.lookup("a")
+			cache.store(context.TODO(), generation, "a", "bucket", "a.txt", stat, []byte("hello"))
+			cache.store(context.TODO(), generation, "b", "bucket", "dir/b.txt", stat, []byte("world"))
+			// objects that are larger than the maximum object size aren't cached.
+			cache.store(context.TODO(), generation, "c", "bucket", "c.txt", stat, []byte("too large"))
+			assert.Equal(t, cache.Len(), 2)
+
+			entry, _, fresh := cache.lookup("a")
+			assert.Assert(t, fresh)
+			assert.Assert(t, entry.matches(&common.StorageObject{ETag: utils.ToPtr("a")}))
+			assert.Assert(t, !entry.matches(&common.StorageObject{ETag: utils.ToPtr("b")}))
+
+			content, ok := readEntry("a")
+			assert.Assert(t, ok)
+			assert.Equal(t, content, "hello")
+
+			// the least recently used entry is evicted.
+			cache.store(context.TODO(), generation, "d", "other", "d.txt", stat, []byte("d"))
+			assert.Equal(t, cache.Len(), 2)
+
+			_, ok = readEntry("b")
+			assert.Assert(t, !ok)
+
+			// contents of downloads that were in flight during a mutation aren't cached.
+			_, generation, _ = cache.lookup("b")
+			cache.invalidateObject("bucket", "dir/b.txt")
+			cache.store(context.TODO(), generation, "b", "bucket", "dir/b.txt", stat, []byte("world"))
+			assert.Equal(t, cache.Len(), 2)
+
+			now = now.Add(11 * time.Second)
+			_, _, fresh = cache.lookup("a")
+			assert.Assert(t, !fresh)
+
+			assert.Equal(t, cache.purge("bucket", "a"), 1)
+			assert.Equal(t, cache.Len(), 1)
+
+			cache.invalidateBucket("other")
+			assert.Equal(t, cache.Len(), 0)
+
+			cache.Close()
+
+			if storage == common.ContentCacheStorageDisk {
+				_, err := os.Stat(cache.directory)
+				assert.Assert(t, os.IsNotExist(err))
+			}
+		})
+	}
+}
+
+func TestManagerContentCache(t *testing.T) {
+	dir := t.TempDir()
+	manager, err := NewManager(context.TODO(), []ClientConfig{
+		{
+			"id":               "local",
+			"type":             "fs",
+			"defaultDirectory": map[string]any{"value": dir},
+			"contentCache":     map[string]any{"storage": "disk", "directory": t.TempDir()},
+		},
+	}, RuntimeSettings{
+		MaxUploadSizeMBs:   1,
+		MaxDownloadSizeMBs: 1,
+	}, nil, nil, nil, slog.Default())
+	assert.NilError(t, err)
+
+	defer manager.Close(context.TODO())
+
+	bucketArgs := common.StorageBucketArguments{}
+	client, _ := manager.GetClient(nil)
+
+	download := func() string {
+		_, reader, err := manager.GetObject(context.TODO(), bucketArgs, "a.txt", common.GetStorageObjectOptions{})
+		assert.NilError(t, err)
+
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		assert.NilError(t, err)
+
+		return string(data)
+	}
+
+	_, err = manager.PutObject(context.TODO(), bucketArgs, "a.txt", &common.PutStorageObjectOptions{}, []byte("hello"))
+	assert.NilError(t, err)
+
+	assert.Equal(t, download(), "hello")
+	assert.Equal(t, client.contentCache.Len(), 1)
+	assert.Equal(t, download(), "hello")
+
+	// changes outside the connector are detected by the ETag.
+	filePath := filepath.Join(dir, "a.txt")
+	assert.NilError(t, os.WriteFile(filePath, []byte("external"), 0o600))
+	assert.NilError(t, os.Chtimes(filePath, time.Now(), time.Now().Add(time.Minute)))
+	assert.Equal(t, download(), "external")
+
+	// uploads through the manager invalidate the cache.
+	_, err = manager.PutObject(context.TODO(), bucketArgs, "a.txt", &common.PutStorageObjectOptions{}, []byte("world"))
+	assert.NilError(t, err)
+	assert.Equal(t, client.contentCache.Len(), 0)
+	assert.Equal(t, download(), "world")
+
+	assert.NilError(t, manager.PurgeCache(context.TODO(), nil, "", ""))
+	assert.Equal(t, client.contentCache.Len(), 0)
+
+	clientID := common.StorageClientID("unknown")
+	assert.ErrorContains(t, manager.PurgeCache(context.TODO(), &clientID, "", ""), "client not found")
+}
+
+func TestPurgeCacheWithPolicies(t *testing.T) {
+	manager, err := NewManager(context.TODO(), []ClientConfig{
+		{
+			"id":               "local",
+			"type":             "fs",
+			"defaultDirectory": map[string]any{"value": t.TempDir()},
+			"contentCache":     map[string]any{},
+		},
+	}, RuntimeSettings{
+		MaxUploadSizeMBs:   1,
+		MaxDownloadSizeMBs: 1,
+	}, &PolicySettings{Enabled: true}, nil, nil, slog.Default())
+	assert.NilError(t, err)
+
+	defer manager.Close(context.TODO())
+
+	// caches of all buckets can't be purged if access policies are enabled.
+	assert.ErrorContains(t, manager.PurgeCache(context.TODO(), nil, "", ""), "bucket is required")
+}
+
+func TestContentCacheDiskEncryption(t *testing.T) {
+	_, err := NewManager(context.TODO(), []ClientConfig{
+		{
+			"id":       "encrypted",
+			"type":     "s3",
+			"endpoint": map[string]any{"value": "http://localhost:9000"},
+			"authentication": map[string]any{
+				"type":            "static",
+				"accessKeyId":     map[string]any{"value": "access-key"},
+				"secretAccessKey": map[string]any{"value": "secret-key"},
+			},
+			"encryption": map[string]any{
+				"activeKeyId": "v1",
+				"keys": []any{
+					map[string]any{
+						"id":  "v1",
+						"key": map[string]any{"value": base64.StdEncoding.EncodeToString(make([]byte, 32))},
+					},
+				},
+			},
+			"contentCache": map[string]any{"storage": "disk"},
+		},
+	}, RuntimeSettings{
+		MaxUploadSizeMBs:   1,
+		MaxDownloadSizeMBs: 1,
+	}, nil, nil, nil, slog.Default())
+	assert.ErrorIs(t, err, common.ErrContentCacheDiskEncrypted)
+}
@@ -0,0 +1,481 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"golang.org/x/sync/errgroup"
+)
+
+// encryptedClient wraps a storage client with client-side envelope encryption.
+// Objects of encrypted buckets are encrypted before uploading, so the storage provider never sees the plaintext.
+type encryptedClient struct {
+	common.StorageClient
+
+	keyring *common.EncryptionKeyring
+}
+
+var _ common.StorageClient = (*encryptedClient)(nil)
+
+func newEncryptedClient(
+	client common.StorageClient,
+	config *common.ClientEncryptionConfig,
+) (*encryptedClient, error) {
+	keyring, err := config.NewKeyring()
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptedClient{
+		StorageClient: client,
+		keyring:       keyring,
+	}, nil
+}
+
+// Close closes the inner client if it holds resources.
+func (ec *encryptedClient) Close() error {
+	return closeStorageClient(ec.StorageClient)
+}
+
+// ListObjects lists objects in a bucket. The size of encrypted objects is the plaintext size.
+// Objects of buckets that aren't encrypted are only evaluated if the list API returns the metadata,
+// e.g. objects that were uploaded before the bucket is removed from the encryption config.
+func (ec *encryptedClient) ListObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.ListStorageObjectsOptions,
+	predicate func(string) bool,
+) (*common.StorageObjectListResults, error) {
+	// the encryption metadata is required to evaluate the plaintext size.
+	listOptions := *opts
+	listOptions.Include.Metadata = true
+
+	results, err := ec.StorageClient.ListObjects(ctx, bucketName, &listOptions, predicate)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ec.keyring.IsBucketEncrypted(bucketName) {
+		for i := range results.Objects {
+			evalDecryptedObjectSize(&results.Objects[i])
+		}
+
+		return stripObjectListMetadata(results, opts), nil
+	}
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(max(opts.NumThreads, 1))
+
+	for i := range results.Objects {
+		object := &results.Objects[i]
+		if object.IsDirectory || evalDecryptedObjectSize(object) {
+			continue
+		}
+
+		// the list API of some providers, e.g. AWS S3, doesn't return the metadata.
+		eg.Go(func() error {
+			statOptions := common.GetStorageObjectOptions{
+				VersionID: object.VersionID,
+				Include: common.StorageObjectIncludeOptions{
+					Metadata: true,
+				},
+			}
+
+			stat, err := ec.StorageClient.StatObject(egCtx, bucketName, object.Name, statOptions)
+			if err != nil {
+				if code, _ := common.GetStorageErrorCode(err); code == common.ErrorCodeNotFound {
+					return nil
+				}
+
+				return err
+			}
+
+			if stat != nil && evalDecryptedObjectSize(stat) {
+				object.Size = stat.Size
+			}
+
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return stripObjectListMetadata(results, opts), nil
+}
+
+// GetObject returns a stream of the decrypted object data.
+// Whether the object is decrypted depends on the envelope metadata of the object rather than the bucket config,
+// so objects that were encrypted before the encryption config is changed can still be read.
+func (ec *encryptedClient) GetObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (io.ReadCloser, error) {
+	statOptions := opts
+	statOptions.Include.Metadata = true
+
+	object, err := ec.StorageClient.StatObject(ctx, bucketName, objectName, statOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if object == nil {
+		return nil, common.NewStorageError(common.ErrorCodeNotFound, "object not found: "+objectName, nil)
+	}
+
+	dataKey, err := ec.unwrapDataKey(object)
+	if err != nil {
+		return nil, err
+	}
+
+	if dataKey == nil {
+		// the object isn't encrypted, e.g. it was uploaded before the encryption is enabled.
+		return ec.StorageClient.GetObject(ctx, bucketName, objectName, opts)
+	}
+
+	if opts.PartNumber != nil || slices.ContainsFunc(opts.Headers, func(header common.StorageKeyValue) bool {
+		return strings.EqualFold(header.Key, "range")
+	}) {
+		return nil, schema.UnprocessableContentError(
+			"downloading a part or a range of client-side encrypted objects isn't supported",
+			nil,
+		)
+	}
+
+	reader, err := ec.StorageClient.GetObject(ctx, bucketName, objectName, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return common.NewDecryptReader(reader, dataKey)
+}
+
+// PutObject encrypts and uploads the object.
+func (ec *encryptedClient) PutObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts *common.PutStorageObjectOptions,
+	reader io.Reader,
+	objectSize int64,
+) (*common.StorageUploadInfo, error) {
+	if !ec.keyring.IsBucketEncrypted(bucketName) {
+		return ec.StorageClient.PutObject(ctx, bucketName, objectName, opts, reader, objectSize)
+	}
+
+	dataKey, encryptionMetadata, err := ec.keyring.NewDataKey()
+	if err != nil {
+		return nil, schema.InternalServerError("failed to generate the data key: "+err.Error(), nil)
+	}
+
+	plaintextReader := &countingReader{Reader: reader}
+
+	encryptReader, err := common.NewEncryptReader(plaintextReader, dataKey)
+	if err != nil {
+		return nil, schema.InternalServerError(err.Error(), nil)
+	}
+
+	putOptions := common.PutStorageObjectOptions{}
+	if opts != nil {
+		putOptions = *opts
+	}
+
+	// the plaintext size of streams is unknown until the upload completes.
+	// The size of these objects is evaluated from the ciphertext size instead.
+	if objectSize >= 0 {
+		encryptionMetadata = append(encryptionMetadata, common.StorageKeyValue{
+			Key:   common.EncryptionMetadataPlaintextSize,
+			Value: strconv.FormatInt(objectSize, 10),
+		})
+	}
+
+	putOptions.Metadata = mergeEncryptionMetadata(putOptions.Metadata, encryptionMetadata)
+
+	result, err := ec.StorageClient.PutObject(
+		ctx,
+		bucketName,
+		objectName,
+		&putOptions,
+		encryptReader,
+		common.EncryptedSize(objectSize),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintextSize := plaintextReader.count
+	result.Size = &plaintextSize
+
+	return result, nil
+}
+
+// CopyObject creates or replaces an object through server-side copying of an existing object.
+// Encrypted objects can only be copied between encrypted buckets because the wrapped data key is copied with the object metadata.
+func (ec *encryptedClient) CopyObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	src common.StorageCopySrcOptions,
+) (*common.StorageUploadInfo, error) {
+	srcEncrypted := ec.keyring.IsBucketEncrypted(src.Bucket)
+	destEncrypted := ec.keyring.IsBucketEncrypted(dest.Bucket)
+
+	if !srcEncrypted && !destEncrypted {
+		return ec.StorageClient.CopyObject(ctx, dest, src)
+	}
+
+	if srcEncrypted != destEncrypted {
+		return nil, schema.UnprocessableContentError(
+			"copying objects between encrypted and unencrypted buckets isn't supported",
+			nil,
+		)
+	}
+
+	if src.MatchRange {
+		return nil, schema.UnprocessableContentError(
+			"copying a range of client-side encrypted objects isn't supported",
+			nil,
+		)
+	}
+
+	if dest.Metadata != nil {
+		// keep the wrapped data key if the metadata is replaced.
+		object, err := ec.statSource(ctx, src)
+		if err != nil {
+			return nil, err
+		}
+
+		dest.Metadata = mergeEncryptionMetadata(dest.Metadata, object.Metadata)
+	}
+
+	return ec.StorageClient.CopyObject(ctx, dest, src)
+}
+
+// ComposeObject creates an object by concatenating a list of source objects using server-side copying.
+func (ec *encryptedClient) ComposeObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	srcs []common.StorageCopySrcOptions,
+) (*common.StorageUploadInfo, error) {
+	if ec.keyring.IsBucketEncrypted(dest.Bucket) {
+		return nil, schema.UnprocessableContentError(
+			"composing client-side encrypted objects isn't supported",
+			nil,
+		)
+	}
+
+	for _, src := range srcs {
+		if ec.keyring.IsBucketEncrypted(src.Bucket) {
+			return nil, schema.UnprocessableContentError(
+				"composing client-side encrypted objects isn't supported",
+				nil,
+			)
+		}
+	}
+
+	return ec.StorageClient.ComposeObject(ctx, dest, srcs)
+}
+
+// StatObject fetches metadata of an object. The size of encrypted objects is the plaintext size.
+func (ec *encryptedClient) StatObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (*common.StorageObject, error) {
+	statOptions := opts
+	// the encryption metadata is required to evaluate the plaintext size.
+	statOptions.Include.Metadata = true
+
+	result, err := ec.StorageClient.StatObject(ctx, bucketName, objectName, statOptions)
+	if err != nil || result == nil {
+		return result, err
+	}
+
+	evalDecryptedObjectSize(result)
+
+	if !opts.Include.Metadata {
+		result.Metadata = nil
+	}
+
+	return result, nil
+}
+
+// UpdateObject updates object configurations. The encryption metadata is kept if the metadata is replaced.
+func (ec *encryptedClient) UpdateObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.UpdateStorageObjectOptions,
+) error {
+	// objects may be encrypted even if the bucket isn't encrypted anymore.
+	if opts.Metadata != nil {
+		statOptions := common.GetStorageObjectOptions{
+			Include: common.StorageObjectIncludeOptions{
+				Metadata: true,
+			},
+		}
+
+		if opts.VersionID != "" {
+			statOptions.VersionID = &opts.VersionID
+		}
+
+		object, err := ec.StorageClient.StatObject(ctx, bucketName, objectName, statOptions)
+		if err != nil {
+			return err
+		}
+
+		if object != nil {
+			metadata := mergeEncryptionMetadata(*opts.Metadata, object.Metadata)
+			opts.Metadata = &metadata
+		}
+	}
+
+	return ec.StorageClient.UpdateObject(ctx, bucketName, objectName, opts)
+}
+
+// PresignedGetObject generates a presigned URL for HTTP GET operations.
+// Presigned URLs aren't supported for encrypted buckets because the storage provider can't decrypt objects.
+func (ec *encryptedClient) PresignedGetObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.PresignedGetStorageObjectOptions,
+) (string, error) {
+	if ec.keyring.IsBucketEncrypted(bucketName) {
+		return "", errPresignedURLEncrypted
+	}
+
+	return ec.StorageClient.PresignedGetObject(ctx, bucketName, objectName, opts)
+}
+
+// PresignedPutObject generates a presigned URL for HTTP PUT operations.
+// Presigned URLs aren't supported for encrypted buckets because uploaded objects would bypass the encryption.
+func (ec *encryptedClient) PresignedPutObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	expiry time.Duration,
+) (string, error) {
+	if ec.keyring.IsBucketEncrypted(bucketName) {
+		return "", errPresignedURLEncrypted
+	}
+
+	return ec.StorageClient.PresignedPutObject(ctx, bucketName, objectName, expiry)
+}
+
+func (ec *encryptedClient) statSource(
+	ctx context.Context,
+	src common.StorageCopySrcOptions,
+) (*common.StorageObject, error) {
+	statOptions := common.GetStorageObjectOptions{
+		Include: common.StorageObjectIncludeOptions{
+			Metadata: true,
+		},
+		ServerSideEncryption: src.Encryption,
+	}
+
+	if src.VersionID != "" {
+		statOptions.VersionID = &src.VersionID
+	}
+
+	object, err := ec.StorageClient.StatObject(ctx, src.Bucket, src.Name, statOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if object == nil {
+		return nil, common.NewStorageError(common.ErrorCodeNotFound, "source object not found: "+src.Name, nil)
+	}
+
+	return object, nil
+}
+
+func (ec *encryptedClient) unwrapDataKey(object *common.StorageObject) ([]byte, error) {
+	dataKey, err := ec.keyring.UnwrapDataKey(object.Metadata)
+	if err != nil {
+		return nil, schema.InternalServerError(
+			"failed to decrypt the object: "+err.Error(),
+			map[string]any{
+				"object": object.Name,
+			},
+		)
+	}
+
+	return dataKey, nil
+}
+
+var errPresignedURLEncrypted = schema.UnprocessableContentError(
+	"presigned URLs aren't supported for client-side encrypted buckets",
+	nil,
+)
+
+// mergeEncryptionMetadata replaces encryption keys in the metadata with values from the source.
+func mergeEncryptionMetadata(
+	metadata []common.StorageKeyValue,
+	source []common.StorageKeyValue,
+) []common.StorageKeyValue {
+	results := make([]common.StorageKeyValue, 0, len(metadata)+3)
+
+	for _, item := range metadata {
+		if !common.IsEncryptionMetadata(item.Key) {
+			results = append(results, item)
+		}
+	}
+
+	for _, item := range source {
+		if common.IsEncryptionMetadata(item.Key) {
+			results = append(results, item)
+		}
+	}
+
+	return results
+}
+
+// stripObjectListMetadata removes the metadata of listed objects if it isn't requested.
+func stripObjectListMetadata(
+	results *common.StorageObjectListResults,
+	opts *common.ListStorageObjectsOptions,
+) *common.StorageObjectListResults {
+	if !opts.Include.Metadata {
+		for i := range results.Objects {
+			results.Objects[i].Metadata = nil
+		}
+	}
+
+	return results
+}
+
+// countingReader counts bytes that are read from the underlying reader.
+type countingReader struct {
+	io.Reader
+
+	count int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.Reader.Read(p)
+	cr.count += int64(n)
+
+	return n, err
+}
+
+// evalDecryptedObjectSize replaces the size of the client-side encrypted object with the plaintext size.
+// Returns false if the object isn't encrypted or the metadata isn't available.
+func evalDecryptedObjectSize(object *common.StorageObject) bool {
+	if common.GetEncryptionMetadata(object.Metadata, common.EncryptionMetadataAlgorithm) == "" {
+		return false
+	}
+
+	rawSize := common.GetEncryptionMetadata(object.Metadata, common.EncryptionMetadataPlaintextSize)
+	if size, err := strconv.ParseInt(rawSize, 10, 64); err == nil && size >= 0 {
+		object.Size = &size
+	} else if object.Size != nil {
+		size := common.DecryptedSize(*object.Size)
+		object.Size = &size
+	}
+
+	return true
+}
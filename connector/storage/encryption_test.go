@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"gotest.tools/v3/assert"
+)
+
+// mockMemoryClient stores objects in memory. Like AWS S3, the metadata isn't returned by the list API.
+type mockMemoryClient struct {
+	common.StorageClient
+
+	objects map[string]common.StorageObject
+	data    map[string][]byte
+}
+
+func (m *mockMemoryClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts *common.PutStorageObjectOptions,
+	reader io.Reader,
+	objectSize int64,
+) (*common.StorageUploadInfo, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	size := int64(len(data))
+	m.data[objectName] = data
+	m.objects[objectName] = common.StorageObject{
+		Name:     objectName,
+		Size:     &size,
+		Metadata: opts.Metadata,
+	}
+
+	return &common.StorageUploadInfo{Name: objectName, Size: &size}, nil
+}
+
+func (m *mockMemoryClient) StatObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (*common.StorageObject, error) {
+	object, ok := m.objects[objectName]
+	if !ok {
+		return nil, nil
+	}
+
+	if !opts.Include.Metadata {
+		object.Metadata = nil
+	}
+
+	return &object, nil
+}
+
+func (m *mockMemoryClient) ListObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.ListStorageObjectsOptions,
+	predicate func(string) bool,
+) (*common.StorageObjectListResults, error) {
+	results := &common.StorageObjectListResults{}
+
+	for _, object := range m.objects {
+		object.Metadata = nil
+		results.Objects = append(results.Objects, object)
+	}
+
+	return results, nil
+}
+
+func (m *mockMemoryClient) GetObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(m.data[objectName])), nil
+}
+
+func TestEncryptedClientPlaintextSize(t *testing.T) {
+	masterKey := make([]byte, 32)
+	_, err := rand.Read(masterKey)
+	assert.NilError(t, err)
+
+	inner := &mockMemoryClient{
+		objects: map[string]common.StorageObject{},
+		data:    map[string][]byte{},
+	}
+
+	client, err := newEncryptedClient(inner, &common.ClientEncryptionConfig{
+		ActiveKeyID: "v1",
+		Keys: []common.EncryptionKeyConfig{
+			{ID: "v1", Key: utils.ToPtr(utils.NewEnvStringValue(base64.StdEncoding.EncodeToString(masterKey)))},
+		},
+	})
+	assert.NilError(t, err)
+
+	content := bytes.Repeat([]byte("a"), 100_000)
+
+	result, err := client.PutObject(
+		context.TODO(),
+		"bucket",
+		"a.txt",
+		&common.PutStorageObjectOptions{},
+		bytes.NewReader(content),
+		int64(len(content)),
+	)
+	assert.NilError(t, err)
+	assert.Equal(t, *result.Size, int64(len(content)))
+	assert.Assert(t, int64(len(inner.data["a.txt"])) > int64(len(content)))
+
+	// the plaintext size is returned even if the metadata isn't requested.
+	object, err := client.StatObject(context.TODO(), "bucket", "a.txt", common.GetStorageObjectOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, *object.Size, int64(len(content)))
+
+	objects, err := client.ListObjects(context.TODO(), "bucket", &common.ListStorageObjectsOptions{}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(objects.Objects), 1)
+	assert.Equal(t, *objects.Objects[0].Size, int64(len(content)))
+	assert.Assert(t, objects.Objects[0].Metadata == nil)
+
+	reader, err := client.GetObject(context.TODO(), "bucket", "a.txt", common.GetStorageObjectOptions{})
+	assert.NilError(t, err)
+
+	data, err := io.ReadAll(reader)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, data, content)
+
+	_, err = client.GetObject(context.TODO(), "bucket", "a.txt", common.GetStorageObjectOptions{
+		Headers: []common.StorageKeyValue{{Key: "Range", Value: "bytes=0-9"}},
+	})
+	assert.ErrorContains(t, err, "range of client-side encrypted objects isn't supported")
+}
+
+func TestEncryptedClientConfigChange(t *testing.T) {
+	masterKey := make([]byte, 32)
+	_, err := rand.Read(masterKey)
+	assert.NilError(t, err)
+
+	keys := []common.EncryptionKeyConfig{
+		{ID: "v1", Key: utils.ToPtr(utils.NewEnvStringValue(base64.StdEncoding.EncodeToString(masterKey)))},
+	}
+
+	inner := &mockMemoryClient{
+		objects: map[string]common.StorageObject{},
+		data:    map[string][]byte{},
+	}
+
+	client, err := newEncryptedClient(inner, &common.ClientEncryptionConfig{
+		ActiveKeyID: "v1",
+		Keys:        keys,
+	})
+	assert.NilError(t, err)
+
+	content := bytes.Repeat([]byte("a"), 100_000)
+
+	// the plaintext size of streams is counted while uploading.
+	result, err := client.PutObject(
+		context.TODO(),
+		"bucket",
+		"a.txt",
+		&common.PutStorageObjectOptions{},
+		io.MultiReader(bytes.NewReader(content)),
+		-1,
+	)
+	assert.NilError(t, err)
+	assert.Equal(t, *result.Size, int64(len(content)))
+
+	// the bucket is removed from the encryption config.
+	client, err = newEncryptedClient(inner, &common.ClientEncryptionConfig{
+		Buckets:     []string{"other"},
+		ActiveKeyID: "v1",
+		Keys:        keys,
+	})
+	assert.NilError(t, err)
+
+	object, err := client.StatObject(context.TODO(), "bucket", "a.txt", common.GetStorageObjectOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, *object.Size, int64(len(content)))
+	assert.Assert(t, object.Metadata == nil)
+
+	reader, err := client.GetObject(context.TODO(), "bucket", "a.txt", common.GetStorageObjectOptions{})
+	assert.NilError(t, err)
+
+	data, err := io.ReadAll(reader)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, data, content)
+}
@@ -7,7 +7,6 @@ import (
 	"slices"
 	"strings"
 
-	"github.com/hasura/ndc-sdk-go/v2/schema"
 	"github.com/hasura/ndc-storage/connector/storage/common"
 	"github.com/spf13/afero"
 	"go.opentelemetry.io/otel/attribute"
@@ -19,12 +18,19 @@ func (c *Client) MakeBucket(ctx context.Context, args *common.MakeStorageBucketO
 	_, span := c.startOtelSpan(ctx, "MakeBucket", args.Name)
 	defer span.End()
 
-	err := c.client.MkdirAll(args.Name, os.FileMode(c.permissions.Directory))
+	root, err := c.getRootDirectory(args.Name)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	err = c.client.MkdirAll(root.path, os.FileMode(c.permissions.Directory))
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
 
-		return schema.UnauthorizeError(err.Error(), nil)
+		return serializeErrorResponse(err)
 	}
 
 	return nil
@@ -128,7 +134,14 @@ func (c *Client) RemoveBucket(ctx context.Context, bucketName string) error {
 	_, span := c.startOtelSpan(ctx, "RemoveBucket", bucketName)
 	defer span.End()
 
-	dirInfo, err := c.lstatIfPossible(bucketName)
+	root, err := c.getRootDirectory(bucketName)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	dirInfo, err := lstatIfPossible(c.client, root.path)
 	if err != nil {
 		if errors.Is(err, afero.ErrFileNotFound) {
 			return nil
@@ -137,22 +150,22 @@ func (c *Client) RemoveBucket(ctx context.Context, bucketName string) error {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
 
-		return schema.UnprocessableContentError(err.Error(), nil)
+		return serializeErrorResponse(err)
 	}
 
 	if !dirInfo.IsDir() {
 		err := errors.New("the bucket path must be a directory")
 		span.SetStatus(codes.Error, err.Error())
 
-		return schema.UnprocessableContentError(err.Error(), nil)
+		return serializeErrorResponse(err)
 	}
 
-	err = c.client.RemoveAll(bucketName)
+	err = c.client.RemoveAll(root.path)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
 
-		return schema.UnprocessableContentError(err.Error(), nil)
+		return serializeErrorResponse(err)
 	}
 
 	return nil
@@ -2,8 +2,11 @@ package fs
 
 import (
 	"context"
+	"errors"
 	"os"
+	"path/filepath"
 	"slices"
+	"sync"
 
 	"github.com/hasura/ndc-sdk-go/v2/connector"
 	"github.com/hasura/ndc-storage/connector/storage/common"
@@ -19,7 +22,19 @@ type Client struct {
 	client             afero.Fs
 	clientType         string
 	allowedDirectories []string
+	rootDirectories    map[string]*rootDirectory
 	permissions        FilePermissionConfig
+	symlinkPolicy      SymlinkPolicy
+	// file locks serialize mutations of the same path so that conditional writes
+	// aren't interleaved with concurrent modifications.
+	fileLocksMu sync.Mutex
+	fileLocks   map[string]*fileLock
+}
+
+// fileLock is the lock of a file path. The lock is removed when no one holds or waits for it.
+type fileLock struct {
+	mu   sync.Mutex
+	refs int
 }
 
 var _ common.StorageClient = &Client{}
@@ -36,6 +51,12 @@ func New(client afero.Fs, config *ClientConfig) (*Client, error) {
 		client:             client,
 		allowedDirectories: config.AllowedDirectories,
 		permissions:        defaultFilePermissions,
+		symlinkPolicy:      config.SymlinkPolicy,
+		fileLocks:          map[string]*fileLock{},
+	}
+
+	if mc.symlinkPolicy == "" {
+		mc.symlinkPolicy = SymlinkPolicyFollowWithinRoot
 	}
 
 	if defaultDirectory != "" && !slices.Contains(mc.allowedDirectories, defaultDirectory) {
@@ -44,6 +65,15 @@ func New(client afero.Fs, config *ClientConfig) (*Client, error) {
 
 	slices.Sort(mc.allowedDirectories)
 
+	mc.rootDirectories = make(map[string]*rootDirectory, len(mc.allowedDirectories))
+
+	for _, dir := range mc.allowedDirectories {
+		mc.rootDirectories[dir], err = newRootDirectory(client, dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if config.Permissions != nil {
 		mc.permissions = *config.Permissions
 	}
@@ -74,10 +104,6 @@ func (c *Client) startOtelSpan(
 	return ctx, span
 }
 
-func (c *Client) lstatIfPossible(name string) (os.FileInfo, error) {
-	return lstatIfPossible(c.client, name)
-}
-
 func lstatIfPossible(client afero.Fs, name string) (os.FileInfo, error) {
 	if lstater, ok := client.(afero.Lstater); ok {
 		result, _, err := lstater.LstatIfPossible(name)
@@ -87,3 +113,55 @@ func lstatIfPossible(client afero.Fs, name string) (os.FileInfo, error) {
 
 	return client.Stat(name)
 }
+
+// lockFile acquires the exclusive lock of the file path and returns the unlock function.
+func (c *Client) lockFile(name string) func() {
+	key := filepath.Clean(name)
+
+	c.fileLocksMu.Lock()
+
+	lock, ok := c.fileLocks[key]
+	if !ok {
+		lock = &fileLock{}
+		c.fileLocks[key] = lock
+	}
+
+	lock.refs++
+	c.fileLocksMu.Unlock()
+
+	lock.mu.Lock()
+
+	return func() {
+		lock.mu.Unlock()
+
+		c.fileLocksMu.Lock()
+		defer c.fileLocksMu.Unlock()
+
+		lock.refs--
+		if lock.refs == 0 {
+			delete(c.fileLocks, key)
+		}
+	}
+}
+
+// validatePrecondition compares the ETag of the current file with conditional options.
+// The caller must hold the file lock.
+func (c *Client) validatePrecondition(root *rootDirectory, filePath string, ifMatch, ifNoneMatch string) error {
+	var currentETag *string
+
+	_, err := lstatIfPossible(root.fs, filePath)
+	if err != nil {
+		if !errors.Is(err, afero.ErrFileNotFound) {
+			return serializeErrorResponse(err)
+		}
+	} else {
+		etag, err := fileETag(root.fs, filePath)
+		if err != nil {
+			return serializeErrorResponse(err)
+		}
+
+		currentETag = &etag
+	}
+
+	return common.ValidateETagPrecondition(currentETag, ifMatch, ifNoneMatch)
+}
@@ -0,0 +1,125 @@
+package fs
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"gotest.tools/v3/assert"
+)
+
+func TestConditionalWriteLocks(t *testing.T) {
+	root := t.TempDir()
+	client, err := NewOSFileSystem(&ClientConfig{
+		Type:             common.StorageProviderTypeFs,
+		DefaultDirectory: utils.NewEnvStringValue(root),
+	})
+	assert.NilError(t, err)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		successes int
+	)
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			data := []byte("hello")
+			_, err := client.PutObject(context.TODO(), root, "a.txt", &common.PutStorageObjectOptions{
+				IfNoneMatch: common.ETagAny,
+			}, bytes.NewReader(data), int64(len(data)))
+			if err == nil {
+				mu.Lock()
+				successes++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, successes, 1)
+
+	assert.NilError(t, client.RemoveObject(context.TODO(), root, "a.txt", common.RemoveStorageObjectOptions{}))
+
+	// locks are removed when they are released.
+	assert.Equal(t, len(client.fileLocks), 0)
+}
+
+func TestGetObjectIfNoneMatch(t *testing.T) {
+	root := t.TempDir()
+	client, err := NewOSFileSystem(&ClientConfig{
+		Type:             common.StorageProviderTypeFs,
+		DefaultDirectory: utils.NewEnvStringValue(root),
+	})
+	assert.NilError(t, err)
+
+	data := []byte("hello")
+	_, err = client.PutObject(
+		context.TODO(),
+		root,
+		"a.txt",
+		&common.PutStorageObjectOptions{},
+		bytes.NewReader(data),
+		int64(len(data)),
+	)
+	assert.NilError(t, err)
+
+	object, err := client.StatObject(context.TODO(), root, "a.txt", common.GetStorageObjectOptions{})
+	assert.NilError(t, err)
+
+	_, err = client.GetObject(context.TODO(), root, "a.txt", common.GetStorageObjectOptions{
+		IfNoneMatch: *object.ETag,
+	})
+	code, _ := common.GetStorageErrorCode(err)
+	assert.Equal(t, code, common.ErrorCodePreconditionFailed)
+
+	reader, err := client.GetObject(context.TODO(), root, "a.txt", common.GetStorageObjectOptions{
+		IfNoneMatch: "other",
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, reader.Close())
+}
+
+func TestETagOfSameSizeWrites(t *testing.T) {
+	root := t.TempDir()
+	client, err := NewOSFileSystem(&ClientConfig{
+		Type:             common.StorageProviderTypeFs,
+		DefaultDirectory: utils.NewEnvStringValue(root),
+	})
+	assert.NilError(t, err)
+
+	put := func(data string, opts *common.PutStorageObjectOptions) (*common.StorageUploadInfo, error) {
+		return client.PutObject(context.TODO(), root, "a.txt", opts, strings.NewReader(data), int64(len(data)))
+	}
+
+	first, err := put("hello", &common.PutStorageObjectOptions{})
+	assert.NilError(t, err)
+
+	firstObject, err := client.StatObject(context.TODO(), root, "a.txt", common.GetStorageObjectOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, *firstObject.ETag, *first.ETag)
+
+	_, err = put("world", &common.PutStorageObjectOptions{})
+	assert.NilError(t, err)
+
+	// simulate writes within the time granularity of the file system.
+	assert.NilError(t, os.Chtimes(filepath.Join(root, "a.txt"), firstObject.LastModified, firstObject.LastModified))
+
+	object, err := client.StatObject(context.TODO(), root, "a.txt", common.GetStorageObjectOptions{})
+	assert.NilError(t, err)
+	assert.Assert(t, *object.ETag != *first.ETag)
+
+	_, err = put("again", &common.PutStorageObjectOptions{IfMatch: *first.ETag})
+	code, _ := common.GetStorageErrorCode(err)
+	assert.Equal(t, code, common.ErrorCodePreconditionFailed)
+}
@@ -24,12 +24,52 @@ type ClientConfig struct {
 	Permissions *FilePermissionConfig `json:"permissions,omitempty"        mapstructure:"permissions"        yaml:"permissions"`
 	// Default directory for storage files.
 	DefaultDirectory utils.EnvString `json:"defaultDirectory"             mapstructure:"defaultDirectory"   yaml:"defaultDirectory"`
-	// Allowed directories. This setting prevents users to browse files outside the list.
+	// Allowed directories. Objects can't be accessed outside these directories.
 	AllowedDirectories []string `json:"allowedDirectories,omitempty" mapstructure:"allowedDirectories" yaml:"allowedDirectories,omitempty"`
+	// The policy of symbolic links inside allowed directories. Defaults to followWithinRoot.
+	SymlinkPolicy SymlinkPolicy `json:"symlinkPolicy,omitempty"      mapstructure:"symlinkPolicy"      yaml:"symlinkPolicy,omitempty"`
+	// Restrictions of uploaded files. The first policy that matches the directory applies.
+	UploadPolicies []common.UploadPolicyConfig `json:"uploadPolicies,omitempty"     mapstructure:"uploadPolicies"     yaml:"uploadPolicies,omitempty"`
+	// In-memory cache of object metadata and listings.
+	MetadataCache *common.MetadataCacheSettings `json:"metadataCache,omitempty"      mapstructure:"metadataCache"      yaml:"metadataCache,omitempty"`
+	// Cache of small downloaded objects.
+	ContentCache *common.ContentCacheSettings `json:"contentCache,omitempty"       mapstructure:"contentCache"       yaml:"contentCache,omitempty"`
+	// Rate limits and concurrency quotas of the client.
+	Limits *common.ClientLimitSettings `json:"limits,omitempty"             mapstructure:"limits"             yaml:"limits,omitempty"`
 }
 
 // Validate checks if the configuration is valid.
 func (cc ClientConfig) Validate() error {
+	for i, policy := range cc.UploadPolicies {
+		if err := policy.Validate(); err != nil {
+			return fmt.Errorf("uploadPolicies[%d]: %w", i, err)
+		}
+	}
+
+	if cc.SymlinkPolicy != "" {
+		if err := cc.SymlinkPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if cc.MetadataCache != nil {
+		if err := cc.MetadataCache.Validate(); err != nil {
+			return fmt.Errorf("metadataCache: %w", err)
+		}
+	}
+
+	if cc.ContentCache != nil {
+		if err := cc.ContentCache.Validate(); err != nil {
+			return fmt.Errorf("contentCache: %w", err)
+		}
+	}
+
+	if cc.Limits != nil {
+		if err := cc.Limits.Validate(); err != nil {
+			return fmt.Errorf("limits: %w", err)
+		}
+	}
+
 	if cc.Permissions == nil {
 		return nil
 	}
@@ -44,6 +84,10 @@ func (cc ClientConfig) ToBaseConfig() *common.BaseClientConfig {
 		Type:           cc.Type,
 		DefaultBucket:  cc.DefaultDirectory,
 		AllowedBuckets: cc.AllowedDirectories,
+		UploadPolicies: cc.UploadPolicies,
+		Limits:         cc.Limits,
+		MetadataCache:  cc.MetadataCache,
+		ContentCache:   cc.ContentCache,
 	}
 }
 
@@ -69,7 +113,7 @@ func (cc ClientConfig) JSONSchema() *jsonschema.Schema {
 	})
 
 	properties.Set("allowedDirectories", &jsonschema.Schema{
-		Description: "Allowed directories. This setting prevents users to browse files outside the list",
+		Description: "Allowed directories. Objects can't be accessed outside these directories",
 		Type:        "array",
 		Items: &jsonschema.Schema{
 			Type: "string",
@@ -77,6 +121,15 @@ func (cc ClientConfig) JSONSchema() *jsonschema.Schema {
 	})
 
 	properties.Set("permissions", FilePermissionConfig{}.JSONSchema())
+	properties.Set("symlinkPolicy", SymlinkPolicy("").JSONSchema())
+	properties.Set("uploadPolicies", &jsonschema.Schema{
+		Description: "Restrictions of uploaded files. The first policy that matches the directory applies",
+		Type:        "array",
+		Items:       common.UploadPolicyConfig{}.JSONSchema(),
+	})
+	properties.Set("metadataCache", common.MetadataCacheSettings{}.JSONSchema())
+	properties.Set("contentCache", common.ContentCacheSettings{}.JSONSchema())
+	properties.Set("limits", common.ClientLimitSettings{}.JSONSchema())
 
 	return &jsonschema.Schema{
 		Type:       "object",
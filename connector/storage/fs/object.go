@@ -2,6 +2,8 @@ package fs
 
 import (
 	"context"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"io"
@@ -9,9 +11,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/hasura/ndc-sdk-go/v2/schema"
 	"github.com/hasura/ndc-storage/connector/storage/common"
 	"github.com/spf13/afero"
 	"go.opentelemetry.io/otel/attribute"
@@ -35,24 +37,40 @@ func (c *Client) ListObjects(
 		Objects: make([]common.StorageObject, 0),
 	}
 
-	root := filepath.Clean(opts.Prefix)
-	prefixPath := filepath.Join(bucketName, root)
+	// files are never archived.
+	if opts.OngoingRestore != nil && *opts.OngoingRestore {
+		return result, nil
+	}
+
+	root, err := cleanObjectName(opts.Prefix)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	bucketRoot, prefixPath, err := c.resolveObjectPath(bucketName, root)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
 
 	span.SetAttributes(
 		attribute.String("storage.object.prefix", prefixPath),
 		attribute.Bool("storage.option.recursive", opts.Recursive),
 	)
 
-	prefixFile, err := c.lstatIfPossible(prefixPath)
+	prefixFile, err := lstatIfPossible(bucketRoot.fs, prefixPath)
 	if err != nil && !errors.Is(err, afero.ErrFileNotFound) {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
 
-		return nil, schema.UnprocessableContentError(err.Error(), nil)
+		return nil, serializeErrorResponse(err)
 	}
 
 	if prefixFile == nil {
-		baseDir := filepath.Dir(prefixPath)
+		baseDir := filepath.Dir(root)
 
 		filterFn := func(name string) bool {
 			if root != "" && !strings.HasPrefix(name, root) {
@@ -62,12 +80,13 @@ func (c *Client) ListObjects(
 			return predicate == nil || predicate(name)
 		}
 
-		result, err = NewObjectWalker(c.client, bucketName, opts, filterFn).WalkDir(baseDir)
+		result, err = NewObjectWalker(bucketRoot.fs, bucketName, c.symlinkPolicy, opts, filterFn).
+			WalkDir(baseDir)
 		if err != nil {
 			span.SetStatus(codes.Error, err.Error())
 			span.RecordError(err)
 
-			return nil, schema.UnprocessableContentError(err.Error(), nil)
+			return nil, serializeErrorResponse(err)
 		}
 
 		return result, nil
@@ -75,18 +94,19 @@ func (c *Client) ListObjects(
 
 	if !prefixFile.IsDir() {
 		if predicate == nil || predicate(root) {
-			result.Objects = append(result.Objects, serializeStorageObject(root, prefixFile))
+			result.Objects = append(result.Objects, serializeStorageObject(bucketRoot.fs, root, prefixFile))
 		}
 
 		return result, nil
 	}
 
-	result, err = NewObjectWalker(c.client, bucketName, opts, predicate).WalkDirEntries(root)
+	result, err = NewObjectWalker(bucketRoot.fs, bucketName, c.symlinkPolicy, opts, predicate).
+		WalkDirEntries(root)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
 
-		return nil, schema.UnprocessableContentError(err.Error(), nil)
+		return nil, serializeErrorResponse(err)
 	}
 
 	span.SetAttributes(attribute.Int("storage.object_count", count))
@@ -140,18 +160,29 @@ func (c *Client) GetObject(
 
 	span.SetAttributes(attribute.String("storage.key", objectName))
 
-	filePath := filepath.Join(bucketName, objectName)
-
-	object, err := c.client.Open(filePath)
+	root, filePath, err := c.resolveObjectPath(bucketName, objectName)
 	if err != nil {
-		if errors.Is(err, afero.ErrFileNotFound) {
-			return nil, nil
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	if opts.IfNoneMatch != "" {
+		span.SetAttributes(attribute.String("storage.options.if_none_match", opts.IfNoneMatch))
+
+		if err := c.validatePrecondition(root, filePath, "", opts.IfNoneMatch); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, err
 		}
+	}
 
+	object, err := root.fs.Open(filePath)
+	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
 
-		return nil, schema.UnprocessableContentError(err.Error(), nil)
+		return nil, serializeErrorResponse(err)
 	}
 
 	return object, nil
@@ -175,48 +206,104 @@ func (c *Client) PutObject(
 		attribute.Int64("http.response.body.size", objectSize),
 	)
 
-	filePath := filepath.Join(bucketName, objectName)
+	if opts.ServerSideEncryption != nil {
+		return nil, errServerSideEncryptionNotSupported
+	}
+
+	root, filePath, err := c.resolveObjectPath(bucketName, objectName)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
 	if strings.Contains(objectName, "/") || strings.Contains(objectName, "\\") {
 		// ensure that the directory exists
 		baseDir := filepath.Dir(filePath)
 
-		err := c.client.MkdirAll(baseDir, os.FileMode(c.permissions.Directory))
+		err := root.fs.MkdirAll(baseDir, os.FileMode(c.permissions.Directory))
 		if err != nil {
 			span.SetStatus(codes.Error, err.Error())
 			span.RecordError(err)
 
-			return nil, schema.UnprocessableContentError(err.Error(), nil)
+			return nil, serializeErrorResponse(err)
 		}
 	}
 
-	file, err := c.client.OpenFile(
-		filePath,
-		os.O_WRONLY|os.O_CREATE|os.O_TRUNC,
-		os.FileMode(c.permissions.File),
-	)
-	if err != nil {
-		span.SetStatus(codes.Error, err.Error())
-		span.RecordError(err)
+	unlock := c.lockFile(root.realPath(filePath))
+	defer unlock()
 
-		return nil, schema.UnprocessableContentError(err.Error(), nil)
-	}
+	if opts.HasPrecondition() {
+		span.SetAttributes(
+			attribute.String("storage.options.if_match", opts.IfMatch),
+			attribute.String("storage.options.if_none_match", opts.IfNoneMatch),
+		)
 
-	defer func() {
-		_ = file.Close()
-	}()
+		if err := c.validatePrecondition(root, filePath, opts.IfMatch, opts.IfNoneMatch); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
 
-	if _, err := io.Copy(file, reader); err != nil {
+			return nil, err
+		}
+	}
+
+	etag, err := c.writeFile(root, filePath, reader)
+	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
 
-		return nil, schema.UnprocessableContentError(err.Error(), nil)
+		return nil, serializeErrorResponse(err)
 	}
 
-	return &common.StorageUploadInfo{
+	result := &common.StorageUploadInfo{
 		Bucket: bucketName,
 		Name:   objectName,
 		Size:   &objectSize,
-	}, nil
+		ETag:   &etag,
+	}
+
+	if info, err := lstatIfPossible(root.fs, filePath); err == nil {
+		size := info.Size()
+		modTime := info.ModTime()
+		result.Size = &size
+		result.LastModified = &modTime
+	}
+
+	return result, nil
+}
+
+// writeFile writes the content to a temporary file in the same directory and renames it to the destination path,
+// so that the previous content is kept if the upload fails, e.g. the checksum of the content doesn't match.
+// The ETag of the written content is returned.
+func (c *Client) writeFile(root *rootDirectory, filePath string, reader io.Reader) (string, error) {
+	file, err := afero.TempFile(root.fs, filepath.Dir(filePath), "."+filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+
+	tempPath := file.Name()
+	hash := md5.New() //nolint:gosec
+
+	_, err = io.Copy(io.MultiWriter(file, hash), reader)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = root.fs.Chmod(tempPath, os.FileMode(c.permissions.File))
+	}
+
+	if err == nil {
+		err = root.fs.Rename(tempPath, filePath)
+	}
+
+	if err != nil {
+		_ = root.fs.Remove(tempPath)
+
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 // CopyObject creates or replaces an object through server-side copying of an existing object.
@@ -235,14 +322,23 @@ func (c *Client) CopyObject(
 		attribute.String("storage.copy_source", src.Name),
 	)
 
-	srcPath := filepath.Join(src.Bucket, src.Name)
+	if dest.Encryption != nil || src.Encryption != nil {
+		return nil, errServerSideEncryptionNotSupported
+	}
 
-	srcFile, err := c.client.Open(srcPath)
+	srcRoot, srcPath, err := c.resolveObjectPath(src.Bucket, src.Name)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
+
+	srcFile, err := srcRoot.fs.Open(srcPath)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
 
-		return nil, schema.UnprocessableContentError(err.Error(), nil)
+		return nil, serializeErrorResponse(err)
 	}
 
 	defer func() {
@@ -261,7 +357,7 @@ func (c *Client) CopyObject(
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
 
-		return nil, schema.UnprocessableContentError(err.Error(), nil)
+		return nil, serializeErrorResponse(err)
 	}
 
 	return result, nil
@@ -287,9 +383,14 @@ func (c *Client) StatObject(
 
 	span.SetAttributes(attribute.String("storage.key", objectName))
 
-	filePath := filepath.Join(bucketName, objectName)
+	root, filePath, err := c.resolveObjectPath(bucketName, objectName)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return nil, err
+	}
 
-	object, err := c.lstatIfPossible(filePath)
+	object, err := lstatIfPossible(root.fs, filePath)
 	if err != nil {
 		if errors.Is(err, afero.ErrFileNotFound) {
 			return nil, nil
@@ -298,7 +399,7 @@ func (c *Client) StatObject(
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
 
-		return nil, schema.UnprocessableContentError(err.Error(), nil)
+		return nil, serializeErrorResponse(err)
 	}
 
 	objectSize := object.Size()
@@ -310,6 +411,12 @@ func (c *Client) StatObject(
 		LastModified: object.ModTime(),
 	}
 
+	if !result.IsDirectory {
+		if etag, err := fileETag(root.fs, filePath); err == nil {
+			result.ETag = &etag
+		}
+	}
+
 	return result, nil
 }
 
@@ -333,14 +440,12 @@ func (c *Client) RemoveObject(
 		span.SetAttributes(attribute.String("storage.options.version", opts.VersionID))
 	}
 
-	filePath := filepath.Join(bucketName, objectName)
-
-	err := c.client.RemoveAll(filePath)
+	err := c.removeObject(bucketName, objectName)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
 
-		return schema.UnprocessableContentError(err.Error(), nil)
+		return serializeErrorResponse(err)
 	}
 
 	return nil
@@ -375,9 +480,7 @@ func (c *Client) RemoveObjects(
 
 	if opts.NumThreads <= 1 {
 		for _, object := range objects.Objects {
-			filePath := filepath.Join(object.Bucket, object.Name)
-
-			err := c.client.RemoveAll(filePath)
+			err := c.removeObject(bucketName, object.Name)
 			if err != nil {
 				errs = append(errs, common.RemoveStorageObjectError{
 					ObjectName: object.Name,
@@ -386,19 +489,21 @@ func (c *Client) RemoveObjects(
 			}
 		}
 	} else {
+		var errsLock sync.Mutex
+
 		eg := errgroup.Group{}
 		eg.SetLimit(opts.NumThreads)
 
 		removeFunc := func(name string) {
 			eg.Go(func() error {
-				filePath := filepath.Join(bucketName, name)
-
-				err := c.client.RemoveAll(filePath)
+				err := c.removeObject(bucketName, name)
 				if err != nil {
+					errsLock.Lock()
 					errs = append(errs, common.RemoveStorageObjectError{
 						ObjectName: name,
 						Error:      err.Error(),
 					})
+					errsLock.Unlock()
 				}
 
 				return nil
@@ -432,6 +537,18 @@ func (c *Client) RemoveObjects(
 	return errs
 }
 
+func (c *Client) removeObject(bucketName, objectName string) error {
+	root, filePath, err := c.resolveObjectLinkPath(bucketName, objectName)
+	if err != nil {
+		return err
+	}
+
+	unlock := c.lockFile(root.realPath(filePath))
+	defer unlock()
+
+	return root.fs.RemoveAll(filePath)
+}
+
 // UpdateObject updates object configurations.
 func (c *Client) UpdateObject(
 	ctx context.Context,
@@ -442,6 +559,27 @@ func (c *Client) UpdateObject(
 	_, span := c.startOtelSpan(ctx, "UpdateObject", bucketName)
 	defer span.End()
 
+	if !opts.HasPrecondition() {
+		return nil
+	}
+
+	root, filePath, err := c.resolveObjectPath(bucketName, objectName)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+
+		return err
+	}
+
+	unlock := c.lockFile(root.realPath(filePath))
+	defer unlock()
+
+	if err := c.validatePrecondition(root, filePath, opts.IfMatch, opts.IfNoneMatch); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+
+		return err
+	}
+
 	return nil
 }
 
@@ -450,6 +588,16 @@ func (c *Client) RestoreObject(ctx context.Context, bucketName string, objectNam
 	return errNotSupported
 }
 
+// RestoreArchivedObject restores an object from the archive storage tier.
+func (c *Client) RestoreArchivedObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.RestoreArchivedStorageObjectOptions,
+) error {
+	return errNotSupported
+}
+
 // PresignedGetObject generates a presigned URL for HTTP GET operations. Browsers/Mobile clients may point to this URL to directly download objects even if the bucket is private.
 // This presigned URL can have an associated expiration time in seconds after which it is no longer operational.
 // The maximum expiry is 604800 seconds (i.e. 7 days) and minimum is 1 second.
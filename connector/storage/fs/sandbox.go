@@ -0,0 +1,317 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"github.com/invopop/jsonschema"
+	"github.com/spf13/afero"
+)
+
+// maxSymlinkHops is the maximum number of symbolic links that are followed when resolving a path.
+const maxSymlinkHops = 40
+
+var errSymlinkLoop = errors.New("too many levels of symbolic links")
+
+// SymlinkPolicy represents how symbolic links inside allowed directories are handled.
+type SymlinkPolicy string
+
+const (
+	// SymlinkPolicyDeny rejects paths that contain symbolic links.
+	SymlinkPolicyDeny SymlinkPolicy = "deny"
+	// SymlinkPolicyFollowWithinRoot follows symbolic links if the target is inside the same directory.
+	SymlinkPolicyFollowWithinRoot SymlinkPolicy = "followWithinRoot"
+	// SymlinkPolicyFollow follows symbolic links without restrictions.
+	SymlinkPolicyFollow SymlinkPolicy = "follow"
+)
+
+var enumValues_SymlinkPolicy = []SymlinkPolicy{
+	SymlinkPolicyDeny, SymlinkPolicyFollowWithinRoot, SymlinkPolicyFollow,
+}
+
+// ParseSymlinkPolicy parses the SymlinkPolicy from string.
+func ParseSymlinkPolicy(input string) (SymlinkPolicy, error) {
+	result := SymlinkPolicy(input)
+	if !slices.Contains(enumValues_SymlinkPolicy, result) {
+		return "", fmt.Errorf(
+			"invalid SymlinkPolicy, expected one of %v, got: %s",
+			enumValues_SymlinkPolicy,
+			input,
+		)
+	}
+
+	return result, nil
+}
+
+// Validate checks if the policy is valid.
+func (sp SymlinkPolicy) Validate() error {
+	_, err := ParseSymlinkPolicy(string(sp))
+
+	return err
+}
+
+// JSONSchema is used to generate a custom jsonschema.
+func (sp SymlinkPolicy) JSONSchema() *jsonschema.Schema {
+	enumValues := make([]any, len(enumValues_SymlinkPolicy))
+	for i, item := range enumValues_SymlinkPolicy {
+		enumValues[i] = string(item)
+	}
+
+	return &jsonschema.Schema{
+		Description: "The policy of symbolic links inside allowed directories",
+		Type:        "string",
+		Enum:        enumValues,
+		Default:     string(SymlinkPolicyFollowWithinRoot),
+	}
+}
+
+// rootDirectory is an allowed directory that objects can't escape.
+type rootDirectory struct {
+	// The real path of the directory. Symbolic links are evaluated once when the client is created.
+	path string
+	// The filesystem that is rooted at the real path. Object paths are relative to the root directory.
+	fs afero.Fs
+}
+
+func newRootDirectory(client afero.Fs, dir string) (*rootDirectory, error) {
+	realPath, err := evalSymlinks(client, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the allowed directory %s: %w", dir, err)
+	}
+
+	return &rootDirectory{
+		path: realPath,
+		fs:   afero.NewBasePathFs(client, realPath),
+	}, nil
+}
+
+// realPath returns the real path of the object path in the root directory.
+func (rd *rootDirectory) realPath(name string) string {
+	return filepath.Join(rd.path, name)
+}
+
+// getRootDirectory returns the allowed directory that matches the bucket name.
+// Every allowed directory is a jail that objects can't escape.
+func (c *Client) getRootDirectory(bucketName string) (*rootDirectory, error) {
+	cleanName := filepath.Clean(bucketName)
+
+	for _, dir := range c.allowedDirectories {
+		if dir == bucketName || filepath.Clean(dir) == cleanName {
+			return c.rootDirectories[dir], nil
+		}
+	}
+
+	return nil, common.NewStorageError(
+		common.ErrorCodeAccessDenied,
+		fmt.Sprintf("directory %s is not in the allowed directories", bucketName),
+		nil,
+	)
+}
+
+// resolveObjectPath validates the object name and returns the root directory and the path of the object in it.
+// Symbolic links of the path are checked by the symlink policy.
+func (c *Client) resolveObjectPath(bucketName, objectName string) (*rootDirectory, string, error) {
+	return c.resolvePath(bucketName, objectName, true)
+}
+
+// resolveObjectLinkPath is similar to resolveObjectPath, but the last element isn't followed if it is a symbolic link.
+// The path is used to remove the link itself rather than the target.
+func (c *Client) resolveObjectLinkPath(bucketName, objectName string) (*rootDirectory, string, error) {
+	return c.resolvePath(bucketName, objectName, false)
+}
+
+func (c *Client) resolvePath(bucketName, objectName string, followLast bool) (*rootDirectory, string, error) {
+	root, err := c.getRootDirectory(bucketName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	relPath, err := cleanObjectName(objectName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	parentPath, baseName := filepath.Split(relPath)
+	if !followLast {
+		if relPath == "." {
+			return nil, "", common.NewStorageError(
+				common.ErrorCodeInvalidArgument,
+				"object name is required",
+				nil,
+			)
+		}
+
+		relPath = filepath.Clean(parentPath)
+	}
+
+	resolvedPath := relPath
+
+	switch {
+	case relPath == "." || c.symlinkPolicy == SymlinkPolicyFollow:
+	case c.symlinkPolicy == SymlinkPolicyDeny:
+		err = c.checkNoSymlinks(root, relPath)
+	default:
+		resolvedPath, err = c.resolvePathWithinRoot(root, relPath)
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !followLast {
+		resolvedPath = filepath.Join(resolvedPath, baseName)
+	}
+
+	return root, resolvedPath, nil
+}
+
+// checkNoSymlinks checks that none of existing elements of the path below the root is a symbolic link.
+func (c *Client) checkNoSymlinks(root *rootDirectory, relPath string) error {
+	lstater, ok := root.fs.(afero.Lstater)
+	if !ok {
+		return nil
+	}
+
+	currentPath := "."
+
+	for _, name := range strings.Split(relPath, string(filepath.Separator)) {
+		currentPath = filepath.Join(currentPath, name)
+
+		info, _, err := lstater.LstatIfPossible(currentPath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+
+			return serializeErrorResponse(err)
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			return common.NewStorageError(
+				common.ErrorCodeAccessDenied,
+				"symbolic links are not allowed: "+relPath,
+				nil,
+			)
+		}
+	}
+
+	return nil
+}
+
+// resolvePathWithinRoot evaluates symbolic links of the path and checks if the real path is inside the root directory.
+// Returns the real path relative to the root directory.
+func (c *Client) resolvePathWithinRoot(root *rootDirectory, relPath string) (string, error) {
+	realPath, err := evalSymlinks(c.client, root.realPath(relPath))
+	if err != nil {
+		return "", serializeErrorResponse(err)
+	}
+
+	if !isSubPath(root.path, realPath) {
+		return "", common.NewStorageError(
+			common.ErrorCodeAccessDenied,
+			"symbolic link target is outside the allowed directory: "+relPath,
+			nil,
+		)
+	}
+
+	return filepath.Rel(root.path, realPath)
+}
+
+// cleanObjectName cleans the object name and rejects names that escape the parent directory.
+func cleanObjectName(objectName string) (string, error) {
+	name := strings.TrimLeft(filepath.FromSlash(objectName), string(filepath.Separator))
+	if name == "" {
+		return ".", nil
+	}
+
+	if !filepath.IsLocal(name) {
+		return "", common.NewStorageError(
+			common.ErrorCodeAccessDenied,
+			"invalid object path: "+objectName,
+			nil,
+		)
+	}
+
+	return filepath.Clean(name), nil
+}
+
+// isSubPath checks if the target path is the root or inside the root directory.
+func isSubPath(root, target string) bool {
+	rel, err := filepath.Rel(root, target)
+
+	return err == nil && (rel == "." || filepath.IsLocal(rel))
+}
+
+// evalSymlinks returns the absolute path after evaluating symbolic links of existing elements.
+// Non-existent elements are appended as they are. Symbolic links are evaluated if the filesystem supports them.
+func evalSymlinks(client afero.Fs, name string) (string, error) {
+	absPath, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+
+	lstater, isLstater := client.(afero.Lstater)
+	linkReader, isLinkReader := client.(afero.LinkReader)
+
+	if !isLstater || !isLinkReader {
+		return absPath, nil
+	}
+
+	volume := filepath.VolumeName(absPath)
+	resolvedPath := volume + string(filepath.Separator)
+	pending := splitPath(absPath[len(volume):])
+
+	var hops int
+
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		nextPath := filepath.Join(resolvedPath, name)
+
+		info, _, err := lstater.LstatIfPossible(nextPath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return filepath.Join(append([]string{nextPath}, pending...)...), nil
+			}
+
+			return "", err
+		}
+
+		if info.Mode()&os.ModeSymlink == 0 {
+			resolvedPath = nextPath
+
+			continue
+		}
+
+		hops++
+		if hops > maxSymlinkHops {
+			return "", errSymlinkLoop
+		}
+
+		target, err := linkReader.ReadlinkIfPossible(nextPath)
+		if err != nil {
+			return "", err
+		}
+
+		if filepath.IsAbs(target) {
+			volume = filepath.VolumeName(target)
+			resolvedPath = volume + string(filepath.Separator)
+			target = target[len(volume):]
+		}
+
+		pending = append(splitPath(target), pending...)
+	}
+
+	return resolvedPath, nil
+}
+
+func splitPath(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return r == filepath.Separator
+	})
+}
@@ -0,0 +1,226 @@
+package fs
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"gotest.tools/v3/assert"
+)
+
+func newSandboxTestClient(t *testing.T, policy SymlinkPolicy) (*Client, string, string) {
+	t.Helper()
+
+	baseDir := t.TempDir()
+	root := filepath.Join(baseDir, "data")
+	outside := filepath.Join(baseDir, "outside")
+
+	assert.NilError(t, os.MkdirAll(filepath.Join(root, "docs"), 0o755))
+	assert.NilError(t, os.MkdirAll(outside, 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(root, "docs", "a.txt"), []byte("inside"), 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644))
+	assert.NilError(t, os.Symlink(filepath.Join(root, "docs"), filepath.Join(root, "docs-link")))
+	assert.NilError(t, os.Symlink(outside, filepath.Join(root, "outside-link")))
+
+	client, err := NewOSFileSystem(&ClientConfig{
+		Type:             common.StorageProviderTypeFs,
+		DefaultDirectory: utils.NewEnvStringValue(root),
+		SymlinkPolicy:    policy,
+	})
+	assert.NilError(t, err)
+
+	return client, root, outside
+}
+
+func readTestObject(t *testing.T, client *Client, bucketName, objectName string) (string, error) {
+	t.Helper()
+
+	reader, err := client.GetObject(
+		context.TODO(),
+		bucketName,
+		objectName,
+		common.GetStorageObjectOptions{},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	data, err := io.ReadAll(reader)
+	assert.NilError(t, err)
+
+	return string(data), nil
+}
+
+func TestSandboxPathTraversal(t *testing.T) {
+	client, root, outside := newSandboxTestClient(t, SymlinkPolicyFollow)
+
+	for _, name := range []string{
+		"../outside/secret.txt",
+		"docs/../../outside/secret.txt",
+		"/../outside/secret.txt",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := readTestObject(t, client, root, name)
+			assert.ErrorContains(t, err, "invalid object path")
+
+			_, err = client.PutObject(
+				context.TODO(),
+				root,
+				name,
+				&common.PutStorageObjectOptions{},
+				strings.NewReader("overwrite"),
+				9,
+			)
+			assert.ErrorContains(t, err, "invalid object path")
+		})
+	}
+
+	_, err := readTestObject(t, client, outside, "secret.txt")
+	assert.ErrorContains(t, err, "is not in the allowed directories")
+
+	assert.ErrorContains(
+		t,
+		client.RemoveObject(context.TODO(), root, "", common.RemoveStorageObjectOptions{}),
+		"object name is required",
+	)
+
+	content, err := readTestObject(t, client, root, "/docs/a.txt")
+	assert.NilError(t, err)
+	assert.Equal(t, content, "inside")
+}
+
+func TestSandboxSymlinkPolicy(t *testing.T) {
+	testCases := []struct {
+		Policy        SymlinkPolicy
+		InsideError   string
+		OutsideError  string
+		ListedObjects []string
+	}{
+		{
+			Policy:        SymlinkPolicyDeny,
+			InsideError:   "symbolic links are not allowed",
+			OutsideError:  "symbolic links are not allowed",
+			ListedObjects: []string{"docs/a.txt"},
+		},
+		{
+			Policy:        SymlinkPolicyFollowWithinRoot,
+			OutsideError:  "symbolic link target is outside the allowed directory",
+			ListedObjects: []string{"docs/a.txt", "docs-link", "outside-link"},
+		},
+		{
+			Policy:        SymlinkPolicyFollow,
+			ListedObjects: []string{"docs/a.txt", "docs-link", "outside-link"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.Policy), func(t *testing.T) {
+			client, root, outside := newSandboxTestClient(t, tc.Policy)
+
+			content, err := readTestObject(t, client, root, "docs-link/a.txt")
+			if tc.InsideError == "" {
+				assert.NilError(t, err)
+				assert.Equal(t, content, "inside")
+			} else {
+				assert.ErrorContains(t, err, tc.InsideError)
+			}
+
+			content, err = readTestObject(t, client, root, "outside-link/secret.txt")
+			if tc.OutsideError == "" {
+				assert.NilError(t, err)
+				assert.Equal(t, content, "secret")
+			} else {
+				assert.ErrorContains(t, err, tc.OutsideError)
+			}
+
+			result, err := client.ListObjects(
+				context.TODO(),
+				root,
+				&common.ListStorageObjectsOptions{Recursive: true},
+				nil,
+			)
+			assert.NilError(t, err)
+
+			names := make([]string, len(result.Objects))
+			for i, object := range result.Objects {
+				names[i] = filepath.ToSlash(object.Name)
+			}
+
+			assert.DeepEqual(t, names, tc.ListedObjects)
+
+			// removing a link never touches the target.
+			assert.NilError(t, client.RemoveObject(
+				context.TODO(),
+				root,
+				"outside-link",
+				common.RemoveStorageObjectOptions{},
+			))
+
+			_, err = os.Stat(filepath.Join(outside, "secret.txt"))
+			assert.NilError(t, err)
+		})
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	client, root, _ := newSandboxTestClient(t, SymlinkPolicyFollowWithinRoot)
+
+	assertErrorCode := func(err error, expected common.StorageErrorCode) {
+		t.Helper()
+
+		code, ok := common.GetStorageErrorCode(err)
+		assert.Assert(t, ok, err)
+		assert.Equal(t, code, expected)
+	}
+
+	_, err := readTestObject(t, client, root, "docs/missing.txt")
+	assertErrorCode(err, common.ErrorCodeNotFound)
+
+	_, err = readTestObject(t, client, root, "../outside/secret.txt")
+	assertErrorCode(err, common.ErrorCodeAccessDenied)
+
+	err = client.MakeBucket(context.TODO(), &common.MakeStorageBucketOptions{Name: filepath.Join(root, "other")})
+	assertErrorCode(err, common.ErrorCodeAccessDenied)
+}
+
+func TestSandboxRootDirectoryResolvedOnce(t *testing.T) {
+	baseDir := t.TempDir()
+	root := filepath.Join(baseDir, "data")
+	outside := filepath.Join(baseDir, "outside")
+	rootLink := filepath.Join(baseDir, "data-link")
+
+	assert.NilError(t, os.MkdirAll(root, 0o755))
+	assert.NilError(t, os.MkdirAll(outside, 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(root, "a.txt"), []byte("inside"), 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(outside, "a.txt"), []byte("secret"), 0o644))
+	assert.NilError(t, os.Symlink(root, rootLink))
+
+	client, err := NewOSFileSystem(&ClientConfig{
+		Type:             common.StorageProviderTypeFs,
+		DefaultDirectory: utils.NewEnvStringValue(rootLink),
+		SymlinkPolicy:    SymlinkPolicyFollowWithinRoot,
+	})
+	assert.NilError(t, err)
+
+	// swapping the link of the allowed directory doesn't redirect the client.
+	assert.NilError(t, os.Remove(rootLink))
+	assert.NilError(t, os.Symlink(outside, rootLink))
+
+	content, err := readTestObject(t, client, rootLink, "a.txt")
+	assert.NilError(t, err)
+	assert.Equal(t, content, "inside")
+
+	result, err := client.ListObjects(context.TODO(), rootLink, &common.ListStorageObjectsOptions{}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(result.Objects), 1)
+	assert.Equal(t, result.Objects[0].Name, "a.txt")
+}
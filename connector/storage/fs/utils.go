@@ -1,15 +1,50 @@
 package fs
 
 import (
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
 	"os"
+	"syscall"
 
 	"github.com/hasura/ndc-sdk-go/v2/schema"
 	"github.com/hasura/ndc-storage/connector/storage/common"
+	"github.com/spf13/afero"
 )
 
 var errNotSupported = schema.NotSupportedError("FileStore doesn't support this method", nil)
 
-func serializeStorageObject(filePath string, info os.FileInfo) common.StorageObject {
+var errServerSideEncryptionNotSupported = schema.NotSupportedError(
+	"FileStore doesn't support server-side encryption",
+	nil,
+)
+
+// serializeErrorResponse maps the file system error to the stable code of storage errors.
+func serializeErrorResponse(err error) *schema.ConnectorError {
+	var connectorErr *schema.ConnectorError
+	if errors.As(err, &connectorErr) {
+		return connectorErr
+	}
+
+	var code common.StorageErrorCode
+
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		code = common.ErrorCodeNotFound
+	case errors.Is(err, fs.ErrExist):
+		code = common.ErrorCodeAlreadyExists
+	case errors.Is(err, fs.ErrPermission), errors.Is(err, syscall.EROFS):
+		code = common.ErrorCodeAccessDenied
+	default:
+		code = common.ErrorCodeInvalidArgument
+	}
+
+	return common.NewStorageError(code, err.Error(), nil)
+}
+
+func serializeStorageObject(fsys afero.Fs, filePath string, info os.FileInfo) common.StorageObject {
 	result := common.StorageObject{
 		Name:         filePath,
 		IsDirectory:  info.IsDir(),
@@ -19,7 +54,30 @@ func serializeStorageObject(filePath string, info os.FileInfo) common.StorageObj
 	if !result.IsDirectory {
 		size := info.Size()
 		result.Size = &size
+
+		if etag, err := fileETag(fsys, filePath); err == nil {
+			result.ETag = &etag
+		}
 	}
 
 	return result
 }
+
+// fileETag calculates the entity tag from the MD5 hash of the file content, like ETags of single-part S3 uploads.
+// The modification time and size can't be used
+// because writes of the same size within the time granularity of the file system would get the same tag.
+func fileETag(fsys afero.Fs, filePath string) (string, error) {
+	file, err := fsys.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	hash := md5.New() //nolint:gosec
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
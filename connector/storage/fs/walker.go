@@ -2,6 +2,7 @@ package fs
 
 import (
 	"errors"
+	"os"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -11,32 +12,36 @@ import (
 )
 
 type objectWalker struct {
-	client     afero.Fs
-	bucketName string
-	options    *common.ListStorageObjectsOptions
-	startAfter string
-	started    bool
-	predicate  func(string) bool
-	result     *common.StorageObjectListResults
+	client        afero.Fs
+	bucketName    string
+	symlinkPolicy SymlinkPolicy
+	options       *common.ListStorageObjectsOptions
+	startAfter    string
+	started       bool
+	predicate     func(string) bool
+	result        *common.StorageObjectListResults
 }
 
 // NewObjectWalker creates an objectWalker instance.
+// The client is rooted at the bucket directory, so walked paths are relative to the bucket.
 func NewObjectWalker(
 	client afero.Fs,
 	bucketName string,
+	symlinkPolicy SymlinkPolicy,
 	options *common.ListStorageObjectsOptions,
 	predicate func(string) bool,
 ) *objectWalker {
 	startAfter := strings.TrimRight(options.StartAfter, "/")
 
 	return &objectWalker{
-		client:     client,
-		bucketName: bucketName,
-		options:    options,
-		predicate:  predicate,
-		startAfter: startAfter,
-		started:    startAfter == "",
-		result:     &common.StorageObjectListResults{},
+		client:        client,
+		bucketName:    bucketName,
+		symlinkPolicy: symlinkPolicy,
+		options:       options,
+		predicate:     predicate,
+		startAfter:    startAfter,
+		started:       startAfter == "",
+		result:        &common.StorageObjectListResults{},
 	}
 }
 
@@ -61,7 +66,7 @@ func (ow *objectWalker) WalkDirEntries(root string) (*common.StorageObjectListRe
 }
 
 func (ow *objectWalker) walkDir(root string) error {
-	rootStat, err := lstatIfPossible(ow.client, filepath.Join(ow.bucketName, root))
+	rootStat, err := lstatIfPossible(ow.client, root)
 	if err != nil {
 		if errors.Is(err, afero.ErrFileNotFound) {
 			return nil
@@ -71,7 +76,7 @@ func (ow *objectWalker) walkDir(root string) error {
 	}
 
 	if !rootStat.IsDir() {
-		ow.addObject(serializeStorageObject(root, rootStat))
+		ow.addObject(serializeStorageObject(ow.client, root, rootStat))
 
 		return nil
 	}
@@ -80,7 +85,7 @@ func (ow *objectWalker) walkDir(root string) error {
 }
 
 func (ow *objectWalker) walkDirEntries(root string) error {
-	dir, err := ow.client.Open(filepath.Join(ow.bucketName, root))
+	dir, err := ow.client.Open(root)
 	if err != nil {
 		return err
 	}
@@ -98,8 +103,7 @@ func (ow *objectWalker) walkDirEntries(root string) error {
 		var stopped bool
 
 		relPath := filepath.Join(root, name)
-		absPath := filepath.Join(ow.bucketName, relPath)
-		stat, err := lstatIfPossible(ow.client, absPath)
+		stat, err := lstatIfPossible(ow.client, relPath)
 
 		switch {
 		case err != nil:
@@ -111,8 +115,11 @@ func (ow *objectWalker) walkDirEntries(root string) error {
 				Bucket: ow.bucketName,
 				Name:   relPath,
 			})
+		case stat.Mode()&os.ModeSymlink != 0 && ow.symlinkPolicy == SymlinkPolicyDeny:
+			// symbolic links can't be accessed if they are denied.
+			continue
 		case !ow.options.Recursive || !stat.IsDir():
-			stopped = ow.addObject(serializeStorageObject(relPath, stat))
+			stopped = ow.addObject(serializeStorageObject(ow.client, relPath, stat))
 		default:
 			err = ow.walkDirEntries(relPath)
 			if err != nil {
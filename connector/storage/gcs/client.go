@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"net/url"
 
 	"cloud.google.com/go/storage"
@@ -20,8 +21,10 @@ var tracer = connector.NewTracer("connector/storage/gcs")
 type Client struct {
 	publicHost      *url.URL
 	client          *storage.Client
+	transport       http.RoundTripper
 	projectID       string
 	useCustomClient bool
+	useGRPC         bool
 }
 
 var _ common.StorageClient = &Client{}
@@ -42,15 +45,17 @@ func New(ctx context.Context, config *ClientConfig, logger *slog.Logger) (*Clien
 		return nil, errRequireProjectID
 	}
 
-	opts, err := config.toClientOptions(ctx, logger)
+	opts, transport, err := config.toClientOptions(ctx, logger)
 	if err != nil {
 		return nil, err
 	}
 
 	mc := &Client{
 		publicHost:      publicHost,
+		transport:       transport,
 		projectID:       projectID,
 		useCustomClient: config.HTTP != nil || utils.IsDebug(logger),
+		useGRPC:         config.UseGRPC,
 	}
 
 	if config.UseGRPC {
@@ -63,9 +68,27 @@ func New(ctx context.Context, config *ClientConfig, logger *slog.Logger) (*Clien
 		return nil, fmt.Errorf("failed to initialize the Google Cloud Storage client: %w", err)
 	}
 
+	if config.Retry != nil {
+		// retries are handled by the connector's retry policy.
+		mc.client.SetRetry(storage.WithPolicy(storage.RetryNever))
+	}
+
 	return mc, nil
 }
 
+// Close closes connections of the client. The HTTP client of Google Cloud Storage panics if it's used after Close,
+// so only idle connections of the HTTP transport are closed and late requests still succeed.
+// gRPC connections must be closed to be released. Late requests of a closed gRPC client fail with errors.
+func (c *Client) Close() error {
+	if c.useGRPC {
+		return c.client.Close()
+	}
+
+	common.CloseIdleConnections(c.transport)
+
+	return nil
+}
+
 func (c *Client) startOtelSpan(
 	ctx context.Context,
 	name string,
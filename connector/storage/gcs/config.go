@@ -15,6 +15,7 @@ import (
 	"github.com/hasura/ndc-sdk-go/v2/utils"
 	"github.com/hasura/ndc-storage/connector/storage/common"
 	"github.com/invopop/jsonschema"
+	"golang.org/x/oauth2"
 	"google.golang.org/api/option"
 	ghttp "google.golang.org/api/transport/http"
 )
@@ -22,6 +23,7 @@ import (
 var (
 	errRequireCredentials = errors.New("require either credential JSON or file")
 	errRequireProjectID   = errors.New("projectId is required")
+	errRequireAccessToken = errors.New("accessToken is required")
 )
 
 // ClientConfig represent the raw configuration of a MinIO client.
@@ -68,24 +70,26 @@ type OtherConfig struct {
 	HTTP *exhttp.HTTPTransportTLSConfig `json:"http"                       mapstructure:"http"             yaml:"http"`
 }
 
+// toClientOptions returns options of the Google Cloud Storage client
+// and the custom HTTP transport, if any, whose idle connections are closed when the client is closed.
 func (cc ClientConfig) toClientOptions(
 	ctx context.Context,
 	logger *slog.Logger,
-) ([]option.ClientOption, error) {
+) ([]option.ClientOption, http.RoundTripper, error) {
 	opts := []option.ClientOption{
 		option.WithLogger(logger),
 	}
 
 	cred, err := cc.Authentication.toCredentials()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	opts = append(opts, cred)
 
 	endpointURL, port, _, err := cc.ValidateEndpoint()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if endpointURL != nil {
@@ -97,16 +101,18 @@ func (cc ClientConfig) toClientOptions(
 			opts = append(opts, option.WithGRPCConnectionPool(cc.GRPCConnPoolSize))
 		}
 
-		return opts, nil
+		return opts, nil, nil
 	}
 
+	var customTransport http.RoundTripper
+
 	if utils.IsDebug(logger) || cc.HTTP != nil {
 		transport, err := common.NewTransport(cc.HTTP, exhttp.TelemetryConfig{
 			Logger: logger,
 			Port:   port,
 		})
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		httpTransport, err := ghttp.NewTransport(
@@ -121,16 +127,17 @@ func (cc ClientConfig) toClientOptions(
 			)...,
 		)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		httpClient := &http.Client{Transport: httpTransport}
 		opts = append(opts, option.WithHTTPClient(httpClient))
+		customTransport = transport
 	}
 
 	opts = append(opts, storage.WithJSONReads())
 
-	return opts, nil
+	return opts, customTransport, nil
 }
 
 // ValidatePublicHost validates the public host setting.
@@ -163,11 +170,12 @@ type AuthType string
 
 const (
 	AuthTypeCredentials AuthType = "credentials"
+	AuthTypeAccessToken AuthType = "accessToken"
 	AuthTypeAnonymous   AuthType = "anonymous"
 )
 
 var enumValues_AuthType = []AuthType{
-	AuthTypeCredentials, AuthTypeAnonymous,
+	AuthTypeCredentials, AuthTypeAccessToken, AuthTypeAnonymous,
 }
 
 // ParseAuthType parses the AuthType from string.
@@ -199,6 +207,8 @@ type AuthCredentials struct {
 	Credentials *utils.EnvString `json:"credentials,omitempty"     mapstructure:"credentials"     yaml:"credentials,omitempty"`
 	// The given service account or refresh token JSON credentials file.
 	CredentialsFile *utils.EnvString `json:"credentialsFile,omitempty" mapstructure:"credentialsFile" yaml:"credentialsFile,omitempty"`
+	// The OAuth2 access token. The token isn't refreshed.
+	AccessToken *utils.EnvString `json:"accessToken,omitempty"     mapstructure:"accessToken"     yaml:"accessToken,omitempty"`
 }
 
 // JSONSchema is used to generate a custom jsonschema.
@@ -220,6 +230,17 @@ func (ac AuthCredentials) JSONSchema() *jsonschema.Schema {
 		Ref:         envStringRefName,
 	})
 
+	accessTokenProps := jsonschema.NewProperties()
+	accessTokenProps.Set("type", &jsonschema.Schema{
+		Type:        "string",
+		Description: "Authorize with an OAuth2 access token",
+		Enum:        []any{AuthTypeAccessToken},
+	})
+	accessTokenProps.Set("accessToken", &jsonschema.Schema{
+		Description: "The OAuth2 access token. The token isn't refreshed",
+		Ref:         envStringRefName,
+	})
+
 	anonymousProps := jsonschema.NewProperties()
 	anonymousProps.Set("type", &jsonschema.Schema{
 		Type: "string",
@@ -237,6 +258,11 @@ func (ac AuthCredentials) JSONSchema() *jsonschema.Schema {
 					{Required: []string{"credentialsFile"}},
 				},
 			},
+			{
+				Type:       "object",
+				Properties: accessTokenProps,
+				Required:   []string{"type", "accessToken"},
+			},
 			{
 				Type:       "object",
 				Properties: anonymousProps,
@@ -252,6 +278,8 @@ func (ac AuthCredentials) toCredentials() (option.ClientOption, error) {
 		return option.WithoutAuthentication(), nil
 	case AuthTypeCredentials:
 		return ac.parseServiceAccount()
+	case AuthTypeAccessToken:
+		return ac.parseAccessToken()
 	default:
 		return nil, fmt.Errorf("unsupported auth type %s", ac.Type)
 	}
@@ -284,3 +312,23 @@ func (ac AuthCredentials) parseServiceAccount() (option.ClientOption, error) {
 
 	return option.WithCredentialsFile(credPath), nil
 }
+
+func (ac AuthCredentials) parseAccessToken() (option.ClientOption, error) {
+	if ac.AccessToken == nil {
+		return nil, errRequireAccessToken
+	}
+
+	accessToken, err := ac.AccessToken.GetOrDefault("")
+	if err != nil {
+		return nil, fmt.Errorf("accessToken: %w", err)
+	}
+
+	if accessToken == "" {
+		return nil, errRequireAccessToken
+	}
+
+	return option.WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+	})), nil
+}
@@ -34,6 +34,13 @@ func (c *Client) ListObjects(
 	ctx, span := c.startOtelSpan(ctx, "ListObjects", bucketName)
 	defer span.End()
 
+	// Google Cloud Storage objects in archive classes are always online.
+	if opts.OngoingRestore != nil && *opts.OngoingRestore {
+		return &common.StorageObjectListResults{
+			Objects: []common.StorageObject{},
+		}, nil
+	}
+
 	var count int
 
 	maxResults := opts.MaxResults
@@ -172,7 +179,31 @@ func (c *Client) GetObject(
 
 	span.SetAttributes(attribute.String("storage.key", objectName))
 
-	object, err := c.client.Bucket(bucketName).Object(objectName).NewReader(ctx)
+	handle, _, err := applyServerSideEncryption(
+		span,
+		c.client.Bucket(bucketName).Object(objectName),
+		opts.ServerSideEncryption,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.IfNoneMatch != "" {
+		span.SetAttributes(attribute.String("storage.options.if_none_match", opts.IfNoneMatch))
+
+		// reads don't support ETag conditions. The ETag is checked with the object metadata
+		// and the download is pinned to the checked generation.
+		conditions, err := evalObjectConditions(ctx, handle, "", opts.IfNoneMatch, false)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, err
+		}
+
+		handle = handle.If(*conditions)
+	}
+
+	object, err := handle.NewReader(ctx)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
@@ -204,11 +235,41 @@ func (c *Client) PutObject(
 	// estimate the chunk size. If the object size < 16MiB,
 	// the chunk size will be rounded up to the nearest multiple of 256K
 	chunkSize := 16 * 1024 * 1024
-	if objectSize < int64(chunkSize) {
+	if objectSize >= 0 && objectSize < int64(chunkSize) {
 		chunkSize = (int(objectSize/size256K) + 1) * size256K
 	}
 
-	w := c.client.Bucket(bucketName).Object(objectName).NewWriter(ctx)
+	handle, kmsKeyName, err := applyServerSideEncryption(
+		span,
+		c.client.Bucket(bucketName).Object(objectName),
+		opts.ServerSideEncryption,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.HasPrecondition() {
+		span.SetAttributes(
+			attribute.String("storage.options.if_match", opts.IfMatch),
+			attribute.String("storage.options.if_none_match", opts.IfNoneMatch),
+		)
+
+		conditions, err := evalObjectConditions(ctx, handle, opts.IfMatch, opts.IfNoneMatch, false)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+
+			return nil, err
+		}
+
+		handle = handle.If(*conditions)
+	}
+
+	// cancel the context to abort the upload if the reader fails.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := handle.NewWriter(ctx)
 	w.ChunkSize = chunkSize
 	w.Metadata = common.KeyValuesToStringMap(opts.Metadata)
 	w.CacheControl = opts.CacheControl
@@ -218,6 +279,7 @@ func (c *Client) PutObject(
 	w.ContentType = opts.ContentType
 	w.TemporaryHold = opts.LegalHold != nil && *opts.LegalHold
 	w.StorageClass = opts.StorageClass
+	w.KMSKeyName = kmsKeyName
 
 	if opts.Retention != nil {
 		retention := &storage.ObjectRetention{
@@ -228,7 +290,7 @@ func (c *Client) PutObject(
 		w.Retention = retention
 	}
 
-	_, err := io.Copy(w, reader)
+	_, err = io.Copy(w, reader)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
 		span.RecordError(err)
@@ -265,8 +327,40 @@ func (c *Client) CopyObject(
 		attribute.String("storage.copy_source", src.Name),
 	)
 
-	srcHandle := c.client.Bucket(src.Bucket).Object(src.Name)
-	copier := c.client.Bucket(dest.Bucket).Object(dest.Name).CopierFrom(srcHandle)
+	srcHandle, _, err := applyServerSideEncryption(
+		span,
+		c.client.Bucket(src.Bucket).Object(src.Name),
+		src.Encryption,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if src.VersionID != "" {
+		span.SetAttributes(attribute.String("storage.copy_source_version", src.VersionID))
+
+		gen, err := strconv.ParseInt(src.VersionID, 10, 64)
+		if err != nil {
+			return nil, schema.UnprocessableContentError(
+				fmt.Sprintf("invalid generation version: %s", err),
+				nil,
+			)
+		}
+
+		srcHandle = srcHandle.Generation(gen)
+	}
+
+	destHandle, kmsKeyName, err := applyServerSideEncryption(
+		span,
+		c.client.Bucket(dest.Bucket).Object(dest.Name),
+		dest.Encryption,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	copier := destHandle.CopierFrom(srcHandle)
+	copier.DestinationKMSKeyName = kmsKeyName
 
 	object, err := copier.Run(ctx)
 	if err != nil {
@@ -304,7 +398,16 @@ func (c *Client) StatObject(
 
 	span.SetAttributes(attribute.String("storage.key", objectName))
 
-	object, err := c.client.Bucket(bucketName).Object(objectName).Attrs(ctx)
+	handle, _, err := applyServerSideEncryption(
+		span,
+		c.client.Bucket(bucketName).Object(objectName),
+		opts.ServerSideEncryption,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := handle.Attrs(ctx)
 	if err != nil {
 		if errors.Is(err, storage.ErrObjectNotExist) {
 			return nil, nil
@@ -491,6 +594,18 @@ func (c *Client) UpdateObject(
 		handle = handle.Generation(gen)
 	}
 
+	if opts.HasPrecondition() {
+		conditions, err := evalObjectConditions(ctx, handle, opts.IfMatch, opts.IfNoneMatch, true)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+
+			return err
+		}
+
+		handle = handle.If(*conditions)
+	}
+
 	updateAttrs := storage.ObjectAttrsToUpdate{}
 
 	if opts.LegalHold != nil {
@@ -542,6 +657,19 @@ func (c *Client) RestoreObject(ctx context.Context, bucketName string, objectNam
 	return nil
 }
 
+// RestoreArchivedObject restores an object from the archive storage tier.
+func (c *Client) RestoreArchivedObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.RestoreArchivedStorageObjectOptions,
+) error {
+	return schema.NotSupportedError(
+		"Google Cloud Storage objects in archive classes are always online and do not need to be restored",
+		nil,
+	)
+}
+
 // PresignedGetObject generates a presigned URL for HTTP GET operations. Browsers/Mobile clients may point to this URL to directly download objects even if the bucket is private.
 // This presigned URL can have an associated expiration time in seconds after which it is no longer operational.
 // The maximum expiry is 604800 seconds (i.e. 7 days) and minimum is 1 second.
@@ -1,6 +1,7 @@
 package gcs
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"math"
@@ -484,11 +485,25 @@ func evalGoogleErrorResponse(err *googleapi.Error) *schema.ConnectorError {
 		"details":    err.Details,
 	}
 
-	if err.Code >= 500 {
-		return schema.NewConnectorError(err.Code, err.Message, details)
+	return common.NewStorageError(evalGoogleErrorCode(err), err.Message, details)
+}
+
+// evalGoogleErrorCode maps the reason of the Google API error to the stable code of storage errors.
+// Rate limit errors may be returned with the 403 status.
+func evalGoogleErrorCode(err *googleapi.Error) common.StorageErrorCode {
+	for _, item := range err.Errors {
+		switch item.Reason {
+		case "rateLimitExceeded", "userRateLimitExceeded":
+			return common.ErrorCodeThrottled
+		case "conditionNotMet":
+			return common.ErrorCodePreconditionFailed
+		case "conflict":
+			// the bucket name is taken or the bucket is already owned by the project.
+			return common.ErrorCodeAlreadyExists
+		}
 	}
 
-	return schema.UnprocessableContentError(err.Message, details)
+	return common.EvalStorageErrorCode(err.Code)
 }
 
 func serializeErrorResponse(err error) *schema.ConnectorError {
@@ -497,5 +512,94 @@ func serializeErrorResponse(err error) *schema.ConnectorError {
 		return evalGoogleErrorResponse(e)
 	}
 
+	if errors.Is(err, storage.ErrObjectNotExist) || errors.Is(err, storage.ErrBucketNotExist) {
+		return common.NewStorageError(common.ErrorCodeNotFound, err.Error(), nil)
+	}
+
+	if common.IsTransientError(err) {
+		return common.NewTransientError(err)
+	}
+
 	return schema.UnprocessableContentError(err.Error(), nil)
 }
+
+// evalObjectConditions compares ETag preconditions with the current object
+// and converts them to generation-match conditions so that the write is rejected
+// if the object is modified concurrently.
+func evalObjectConditions(
+	ctx context.Context,
+	handle *storage.ObjectHandle,
+	ifMatch, ifNoneMatch string,
+	metadataOnly bool,
+) (*storage.Conditions, error) {
+	if ifMatch == "" && ifNoneMatch == common.ETagAny {
+		return &storage.Conditions{DoesNotExist: true}, nil
+	}
+
+	var currentETag *string
+
+	attrs, err := handle.Attrs(ctx)
+	if err != nil {
+		if !errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, serializeErrorResponse(err)
+		}
+	} else {
+		currentETag = &attrs.Etag
+	}
+
+	if err := common.ValidateETagPrecondition(currentETag, ifMatch, ifNoneMatch); err != nil {
+		return nil, err
+	}
+
+	if attrs == nil {
+		return &storage.Conditions{DoesNotExist: true}, nil
+	}
+
+	conditions := &storage.Conditions{
+		GenerationMatch: attrs.Generation,
+	}
+
+	if metadataOnly {
+		conditions.MetagenerationMatch = attrs.Metageneration
+	}
+
+	return conditions, nil
+}
+
+// applyServerSideEncryption sets the customer-supplied encryption key to the object handle.
+// Returns the Cloud KMS key name if the KMS method is used. Objects are encrypted by Google-managed keys by default.
+func applyServerSideEncryption(
+	span trace.Span,
+	handle *storage.ObjectHandle,
+	sse *common.ServerSideEncryption,
+) (*storage.ObjectHandle, string, error) {
+	if sse == nil {
+		return handle, "", nil
+	}
+
+	span.SetAttributes(attribute.String("storage.server_side_encryption", string(sse.Method)))
+
+	switch sse.Method {
+	case common.ServerSideEncryptionMethodSseC:
+		key, err := sse.CustomerKey()
+		if err != nil {
+			return nil, "", err
+		}
+
+		return handle.Key(key), "", nil
+	case common.ServerSideEncryptionMethodKms:
+		keyName, err := sse.GetKMSKeyID()
+		if err != nil {
+			return nil, "", err
+		}
+
+		return handle, keyName, nil
+	case common.ServerSideEncryptionMethodS3:
+		return handle, "", nil
+	default:
+		return nil, "", schema.UnprocessableContentError(
+			"unsupported server-side encryption method: "+string(sse.Method),
+			nil,
+		)
+	}
+}
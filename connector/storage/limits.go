@@ -0,0 +1,613 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
+)
+
+// clientLimiter enforces rate limits and concurrency quotas of a storage client.
+type clientLimiter struct {
+	clientID       common.StorageClientID
+	concurrency    *semaphore.Weighted
+	requests       *rate.Limiter
+	uploadBytes    *rate.Limiter
+	downloadBytes  *rate.Limiter
+	queueTimeout   time.Duration
+	maxConcurrency int
+	requestsPerSec float64
+}
+
+// newClientLimiter creates a limiter from the settings. The result is nil if the client isn't limited.
+func newClientLimiter(
+	clientID common.StorageClientID,
+	settings *common.ClientLimitSettings,
+) *clientLimiter {
+	if settings == nil {
+		return nil
+	}
+
+	cl := &clientLimiter{
+		clientID:       clientID,
+		queueTimeout:   settings.GetQueueTimeout(),
+		maxConcurrency: settings.MaxConcurrency,
+		requestsPerSec: settings.RequestsPerSecond,
+	}
+
+	if settings.MaxConcurrency > 0 {
+		cl.concurrency = semaphore.NewWeighted(int64(settings.MaxConcurrency))
+	}
+
+	if settings.RequestsPerSecond > 0 {
+		burst := settings.Burst
+		if burst <= 0 {
+			burst = int(math.Ceil(settings.RequestsPerSecond))
+		}
+
+		cl.requests = rate.NewLimiter(rate.Limit(settings.RequestsPerSecond), burst)
+	}
+
+	cl.uploadBytes = newBandwidthLimiter(settings.UploadBytesPerSecond)
+	cl.downloadBytes = newBandwidthLimiter(settings.DownloadBytesPerSecond)
+
+	if cl.concurrency == nil && cl.requests == nil && cl.uploadBytes == nil &&
+		cl.downloadBytes == nil {
+		return nil
+	}
+
+	return cl
+}
+
+// newBandwidthLimiter creates a limiter of bytes per second that allows bursts of 1 second.
+func newBandwidthLimiter(bytesPerSecond int64) *rate.Limiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), int(min(bytesPerSecond, math.MaxInt32)))
+}
+
+// limit wraps the storage client to enforce limits. The client is returned as is if the limiter is nil.
+func (cl *clientLimiter) limit(client common.StorageClient) common.StorageClient {
+	if cl == nil {
+		return client
+	}
+
+	return &limitedClient{
+		StorageClient: client,
+		limiter:       cl,
+	}
+}
+
+// acquire waits for a rate limit token and a concurrency slot until the queue timeout.
+// The release function must be called when the operation finishes.
+func (cl *clientLimiter) acquire(ctx context.Context) (func(), error) {
+	waitCtx := ctx
+
+	if cl.queueTimeout > 0 {
+		var cancel context.CancelFunc
+
+		waitCtx, cancel = context.WithTimeout(ctx, cl.queueTimeout)
+		defer cancel()
+	}
+
+	if cl.requests != nil {
+		if cl.queueTimeout <= 0 {
+			if !cl.requests.Allow() {
+				return nil, cl.limitError("requestsPerSecond", cl.requestsPerSec)
+			}
+		} else if err := cl.requests.Wait(waitCtx); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			return nil, cl.limitError("requestsPerSecond", cl.requestsPerSec)
+		}
+	}
+
+	if cl.concurrency == nil {
+		return func() {}, nil
+	}
+
+	if cl.queueTimeout <= 0 {
+		if !cl.concurrency.TryAcquire(1) {
+			return nil, cl.limitError("maxConcurrency", cl.maxConcurrency)
+		}
+	} else if err := cl.concurrency.Acquire(waitCtx, 1); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		return nil, cl.limitError("maxConcurrency", cl.maxConcurrency)
+	}
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			cl.concurrency.Release(1)
+		})
+	}, nil
+}
+
+// limitError returns a retryable error when the operation exceeds the limit.
+func (cl *clientLimiter) limitError(name string, value any) *schema.ConnectorError {
+	return schema.NewConnectorError(
+		http.StatusTooManyRequests,
+		"the storage client is busy. Please retry later",
+		map[string]any{
+			"client_id": cl.clientID,
+			"limit":     name,
+			"value":     value,
+		},
+	)
+}
+
+// limitedClient enforces rate limits, concurrency quotas and bandwidth caps of the inner storage client.
+// Presigned URLs are generated locally so they aren't limited.
+type limitedClient struct {
+	common.StorageClient
+
+	limiter *clientLimiter
+}
+
+var _ common.StorageClient = (*limitedClient)(nil)
+
+// Close closes the inner client if it holds resources.
+func (lc *limitedClient) Close() error {
+	return closeStorageClient(lc.StorageClient)
+}
+
+// MakeBucket creates a new bucket.
+func (lc *limitedClient) MakeBucket(
+	ctx context.Context,
+	options *common.MakeStorageBucketOptions,
+) error {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	return lc.StorageClient.MakeBucket(ctx, options)
+}
+
+// ListBuckets lists all buckets.
+func (lc *limitedClient) ListBuckets(
+	ctx context.Context,
+	options *common.ListStorageBucketsOptions,
+	predicate func(string) bool,
+) (*common.StorageBucketListResults, error) {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	return lc.StorageClient.ListBuckets(ctx, options, predicate)
+}
+
+// GetBucket gets a bucket by name.
+func (lc *limitedClient) GetBucket(
+	ctx context.Context,
+	name string,
+	options common.BucketOptions,
+) (*common.StorageBucket, error) {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	return lc.StorageClient.GetBucket(ctx, name, options)
+}
+
+// BucketExists checks if a bucket exists.
+func (lc *limitedClient) BucketExists(ctx context.Context, bucketName string) (bool, error) {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	defer release()
+
+	return lc.StorageClient.BucketExists(ctx, bucketName)
+}
+
+// RemoveBucket removes a bucket, bucket should be empty to be successfully removed.
+func (lc *limitedClient) RemoveBucket(ctx context.Context, bucketName string) error {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	return lc.StorageClient.RemoveBucket(ctx, bucketName)
+}
+
+// UpdateBucket updates configurations for the bucket.
+func (lc *limitedClient) UpdateBucket(
+	ctx context.Context,
+	bucketName string,
+	opts common.UpdateStorageBucketOptions,
+) error {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	return lc.StorageClient.UpdateBucket(ctx, bucketName, opts)
+}
+
+// ListObjects lists objects in a bucket.
+func (lc *limitedClient) ListObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.ListStorageObjectsOptions,
+	predicate func(string) bool,
+) (*common.StorageObjectListResults, error) {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	return lc.StorageClient.ListObjects(ctx, bucketName, opts, predicate)
+}
+
+// ListIncompleteUploads lists partially uploaded objects in a bucket.
+func (lc *limitedClient) ListIncompleteUploads(
+	ctx context.Context,
+	bucketName string,
+	args common.ListIncompleteUploadsOptions,
+) ([]common.StorageObjectMultipartInfo, error) {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	return lc.StorageClient.ListIncompleteUploads(ctx, bucketName, args)
+}
+
+// ListDeletedObjects lists deleted objects in a bucket.
+func (lc *limitedClient) ListDeletedObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.ListStorageObjectsOptions,
+	predicate func(string) bool,
+) (*common.StorageObjectListResults, error) {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	return lc.StorageClient.ListDeletedObjects(ctx, bucketName, opts, predicate)
+}
+
+// GetObject returns a stream of the object data.
+// The concurrency slot is held until the stream is closed and reading is throttled by the download bandwidth.
+func (lc *limitedClient) GetObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (io.ReadCloser, error) {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := lc.StorageClient.GetObject(ctx, bucketName, objectName, opts)
+	if err != nil || reader == nil {
+		release()
+
+		return reader, err
+	}
+
+	return newLimitedReadCloser(&limitedReadCloser{
+		ReadCloser: reader,
+		limiter:    lc.limiter.downloadBytes,
+		wait:       newBandwidthWaiter(ctx, lc.limiter.downloadBytes),
+		release:    release,
+	}), nil
+}
+
+// PutObject uploads an object. Reading the content is throttled by the upload bandwidth.
+func (lc *limitedClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts *common.PutStorageObjectOptions,
+	reader io.Reader,
+	objectSize int64,
+) (*common.StorageUploadInfo, error) {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	if lc.limiter.uploadBytes != nil {
+		reader = newLimitedReadCloser(&limitedReadCloser{
+			ReadCloser: withNopCloser(reader),
+			limiter:    lc.limiter.uploadBytes,
+			wait:       newBandwidthWaiter(ctx, lc.limiter.uploadBytes),
+		})
+	}
+
+	return lc.StorageClient.PutObject(ctx, bucketName, objectName, opts, reader, objectSize)
+}
+
+// CopyObject creates or replaces an object through server-side copying of an existing object.
+func (lc *limitedClient) CopyObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	src common.StorageCopySrcOptions,
+) (*common.StorageUploadInfo, error) {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	return lc.StorageClient.CopyObject(ctx, dest, src)
+}
+
+// ComposeObject creates an object by concatenating a list of source objects using server-side copying.
+func (lc *limitedClient) ComposeObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	sources []common.StorageCopySrcOptions,
+) (*common.StorageUploadInfo, error) {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	return lc.StorageClient.ComposeObject(ctx, dest, sources)
+}
+
+// StatObject fetches metadata of an object.
+func (lc *limitedClient) StatObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (*common.StorageObject, error) {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	return lc.StorageClient.StatObject(ctx, bucketName, objectName, opts)
+}
+
+// RemoveObject removes an object with some specified options.
+func (lc *limitedClient) RemoveObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.RemoveStorageObjectOptions,
+) error {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	return lc.StorageClient.RemoveObject(ctx, bucketName, objectName, opts)
+}
+
+// RemoveObjects removes a list of objects. The operation fails if any object can't be removed.
+func (lc *limitedClient) RemoveObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.RemoveStorageObjectsOptions,
+	predicate func(string) bool,
+) []common.RemoveStorageObjectError {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return []common.RemoveStorageObjectError{
+			{Error: err.Error()},
+		}
+	}
+
+	defer release()
+
+	return lc.StorageClient.RemoveObjects(ctx, bucketName, opts, predicate)
+}
+
+// UpdateObject updates object configurations.
+func (lc *limitedClient) UpdateObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.UpdateStorageObjectOptions,
+) error {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	return lc.StorageClient.UpdateObject(ctx, bucketName, objectName, opts)
+}
+
+// RestoreObject restores a soft-deleted object.
+func (lc *limitedClient) RestoreObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+) error {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	return lc.StorageClient.RestoreObject(ctx, bucketName, objectName)
+}
+
+// RestoreArchivedObject restores an object from the archive storage tier.
+func (lc *limitedClient) RestoreArchivedObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.RestoreArchivedStorageObjectOptions,
+) error {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	return lc.StorageClient.RestoreArchivedObject(ctx, bucketName, objectName, opts)
+}
+
+// RemoveIncompleteUpload removes a partially uploaded object.
+func (lc *limitedClient) RemoveIncompleteUpload(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+) error {
+	release, err := lc.limiter.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	return lc.StorageClient.RemoveIncompleteUpload(ctx, bucketName, objectName)
+}
+
+// newBandwidthWaiter returns a function that waits until n bytes are allowed by the bandwidth limiter.
+func newBandwidthWaiter(ctx context.Context, limiter *rate.Limiter) func(n int) error {
+	if limiter == nil {
+		return nil
+	}
+
+	return func(n int) error {
+		return limiter.WaitN(ctx, n)
+	}
+}
+
+// limitedReadCloser throttles reading of the stream and releases the concurrency slot once when closed.
+type limitedReadCloser struct {
+	io.ReadCloser
+
+	limiter   *rate.Limiter
+	wait      func(n int) error
+	release   func()
+	closeOnce sync.Once
+}
+
+// newLimitedReadCloser keeps the Seeker and ReaderAt interfaces of the inner reader,
+// so that storage SDKs can retry and read parts in parallel.
+func newLimitedReadCloser(reader *limitedReadCloser) io.ReadCloser {
+	if seekerAt, ok := reader.ReadCloser.(readSeekerAt); ok {
+		return &limitedReadSeekerAt{
+			limitedReadCloser: reader,
+			seekerAt:          seekerAt,
+		}
+	}
+
+	return reader
+}
+
+func (r *limitedReadCloser) Read(p []byte) (int, error) {
+	if r.wait == nil {
+		return r.ReadCloser.Read(p)
+	}
+
+	// a read can't be larger than the burst of the limiter.
+	if burst := r.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		if waitErr := r.wait(n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+
+	return n, err
+}
+
+func (r *limitedReadCloser) Close() error {
+	r.closeOnce.Do(func() {
+		if r.release != nil {
+			r.release()
+		}
+	})
+
+	return r.ReadCloser.Close()
+}
+
+// limitedReadSeekerAt is a limitedReadCloser that also throttles reading at offsets.
+type limitedReadSeekerAt struct {
+	*limitedReadCloser
+
+	seekerAt readSeekerAt
+}
+
+func (r *limitedReadSeekerAt) Seek(offset int64, whence int) (int64, error) {
+	return r.seekerAt.Seek(offset, whence)
+}
+
+func (r *limitedReadSeekerAt) ReadAt(p []byte, off int64) (int, error) {
+	if r.wait == nil {
+		return r.seekerAt.ReadAt(p, off)
+	}
+
+	var total int
+
+	// a read can't be larger than the burst of the limiter.
+	for len(p) > 0 {
+		chunk := p[:min(len(p), r.limiter.Burst())]
+
+		n, err := r.seekerAt.ReadAt(chunk, off)
+		total += n
+		off += int64(n)
+		p = p[n:]
+
+		if n > 0 {
+			if waitErr := r.wait(n); waitErr != nil {
+				return total, waitErr
+			}
+		}
+
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"golang.org/x/time/rate"
+	"gotest.tools/v3/assert"
+)
+
+func assertTooManyRequests(t *testing.T, err error, limit string) {
+	t.Helper()
+
+	var connectorError *schema.ConnectorError
+	assert.Assert(t, errors.As(err, &connectorError))
+	assert.Equal(t, connectorError.StatusCode(), http.StatusTooManyRequests)
+	assert.Equal(t, connectorError.Details["limit"], limit)
+}
+
+func TestClientLimiterConcurrency(t *testing.T) {
+	limiter := newClientLimiter("test", &common.ClientLimitSettings{
+		MaxConcurrency: 1,
+		QueueTimeout:   utils.ToPtr(0),
+	})
+
+	release, err := limiter.acquire(context.TODO())
+	assert.NilError(t, err)
+
+	_, err = limiter.acquire(context.TODO())
+	assertTooManyRequests(t, err, "maxConcurrency")
+
+	release()
+	// releasing twice doesn't free another slot.
+	release()
+
+	release, err = limiter.acquire(context.TODO())
+	assert.NilError(t, err)
+
+	_, err = limiter.acquire(context.TODO())
+	assertTooManyRequests(t, err, "maxConcurrency")
+
+	release()
+}
+
+func TestClientLimiterQueue(t *testing.T) {
+	limiter := newClientLimiter("test", &common.ClientLimitSettings{
+		MaxConcurrency: 1,
+		QueueTimeout:   utils.ToPtr(1),
+	})
+
+	release, err := limiter.acquire(context.TODO())
+	assert.NilError(t, err)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		release()
+	}()
+
+	// the operation waits in the queue until the slot is released.
+	release2, err := limiter.acquire(context.TODO())
+	assert.NilError(t, err)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	_, err = limiter.acquire(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	release2()
+}
+
+func TestClientLimiterRate(t *testing.T) {
+	limiter := newClientLimiter("test", &common.ClientLimitSettings{
+		RequestsPerSecond: 1,
+		Burst:             2,
+		QueueTimeout:      utils.ToPtr(0),
+	})
+
+	for range 2 {
+		release, err := limiter.acquire(context.TODO())
+		assert.NilError(t, err)
+		release()
+	}
+
+	_, err := limiter.acquire(context.TODO())
+	assertTooManyRequests(t, err, "requestsPerSecond")
+
+	assert.Assert(t, newClientLimiter("test", &common.ClientLimitSettings{}) == nil)
+	assert.Assert(t, newClientLimiter("test", nil) == nil)
+}
+
+func TestLimitedReadCloser(t *testing.T) {
+	limiter := newBandwidthLimiter(4)
+	reader := &limitedReadCloser{
+		ReadCloser: io.NopCloser(strings.NewReader("abcdefgh")),
+		limiter:    limiter,
+		wait:       newBandwidthWaiter(context.TODO(), limiter),
+	}
+
+	start := time.Now()
+
+	var buf bytes.Buffer
+
+	_, err := io.Copy(&buf, reader)
+	assert.NilError(t, err)
+	assert.Equal(t, buf.String(), "abcdefgh")
+	// the first 4 bytes are in the burst, the rest wait for 1 second.
+	assert.Assert(t, time.Since(start) >= 900*time.Millisecond)
+	assert.NilError(t, reader.Close())
+}
+
+func TestLimitedReadSeekerAt(t *testing.T) {
+	var waited []int
+
+	reader := newLimitedReadCloser(&limitedReadCloser{
+		ReadCloser: withNopCloser(bytes.NewReader([]byte("hello world"))),
+		limiter:    rate.NewLimiter(rate.Inf, 4),
+		wait: func(n int) error {
+			waited = append(waited, n)
+
+			return nil
+		},
+	})
+
+	// the Seeker and ReaderAt interfaces are kept for retries and parallel uploads.
+	seekerAt, ok := reader.(readSeekerAt)
+	assert.Assert(t, ok)
+
+	buf := make([]byte, 11)
+	n, err := seekerAt.ReadAt(buf, 0)
+	assert.NilError(t, err)
+	assert.Equal(t, string(buf[:n]), "hello world")
+	// reads at offsets are split by the burst of the limiter.
+	assert.DeepEqual(t, waited, []int{4, 4, 3})
+
+	_, ok = newLimitedReadCloser(&limitedReadCloser{
+		ReadCloser: io.NopCloser(strings.NewReader("hello")),
+	}).(io.Seeker)
+	assert.Assert(t, !ok)
+}
+
+func TestClientLimitSettingsValidate(t *testing.T) {
+	assert.NilError(t, common.ClientLimitSettings{MaxConcurrency: 1}.Validate())
+	assert.ErrorContains(
+		t,
+		common.ClientLimitSettings{RequestsPerSecond: -1}.Validate(),
+		"requestsPerSecond must not be negative",
+	)
+	assert.ErrorContains(
+		t,
+		common.ClientLimitSettings{QueueTimeout: utils.ToPtr(-1)}.Validate(),
+		"queueTimeout must not be negative",
+	)
+}
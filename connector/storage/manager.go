@@ -2,10 +2,13 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"slices"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hasura/ndc-sdk-go/v2/connector"
@@ -13,100 +16,233 @@ import (
 	"github.com/hasura/ndc-sdk-go/v2/utils"
 	"github.com/hasura/ndc-storage/connector/storage/azblob"
 	"github.com/hasura/ndc-storage/connector/storage/common"
+	"github.com/hasura/ndc-storage/connector/storage/gcs"
 	"github.com/hasura/ndc-storage/connector/storage/minio"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 )
 
 var tracer = connector.NewTracer("connector/storage")
 
 // Manager represents the high-level client that manages internal clients and configurations.
 type Manager struct {
-	clients    []Client
-	httpClient *common.HTTPClient
-	runtime    RuntimeSettings
-	logger     *slog.Logger
+	clients     atomic.Pointer[[]Client]
+	configs     []ClientConfig
+	clientCache *clientCache
+	httpClient  *common.HTTPClient
+	runtime     RuntimeSettings
+	policy      *PolicyEnforcer
+	audit       *Auditor
+	metrics     *storageMetrics
+	meter       metric.Meter
+	logger      *slog.Logger
+	// key prefix of temporary snapshot objects of transactions, which are hidden from listing results.
+	snapshotPrefix string
+
+	// secret fingerprints of clients, indexed by the order of configurations.
+	clientSecrets     []*secretReferences
+	reloadLock        sync.Mutex
+	stopSecretWatcher context.CancelFunc
+
+	closed         atomic.Bool
+	uploads        uploadTracker
+	circuitMetrics metric.Registration
 }
 
 // NewManager creates a storage client manager instance.
+// Client metrics are recorded with the meter of the connector, or the global meter if it's nil.
 func NewManager(
 	ctx context.Context,
 	configs []ClientConfig,
 	runtimeSettings RuntimeSettings,
+	policySettings *PolicySettings,
+	auditSettings *AuditSettings,
+	meter metric.Meter,
 	logger *slog.Logger,
 ) (*Manager, error) {
-	httpClient, err := common.NewHTTPClient(runtimeSettings.HTTP, logger)
+	if meter == nil {
+		meter = otel.Meter("connector/storage")
+	}
+
+	httpClient, err := common.NewHTTPClient(runtimeSettings.HTTP, runtimeSettings.HTTPRetry, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize the http client: %w", err)
 	}
 
 	result := &Manager{
-		clients:    make([]Client, len(configs)),
-		httpClient: httpClient,
-		runtime:    runtimeSettings,
-		logger:     logger,
+		configs:       configs,
+		clientCache:   newClientCache(runtimeSettings.ClientCache, meter),
+		httpClient:    httpClient,
+		runtime:       runtimeSettings,
+		policy:        NewPolicyEnforcer(policySettings),
+		metrics:       newStorageMetrics(meter),
+		meter:         meter,
+		logger:        logger,
+		clientSecrets: make([]*secretReferences, len(configs)),
 	}
 
+	clients := make([]Client, len(configs))
+
 	for i, config := range configs {
-		baseConfig, client, err := config.ToStorageClient(ctx, logger)
+		client, refs, err := result.newClient(ctx, i, config)
 		if err != nil {
-			return nil, fmt.Errorf("failed to initialize storage client %d: %w", i, err)
+			return nil, err
 		}
 
-		configID := baseConfig.ID
-		if configID == "" {
-			configID = strconv.Itoa(i)
-		}
+		clients[i] = *client
+		result.clientSecrets[i] = refs
+	}
+
+	result.clients.Store(&clients)
+	result.registerCircuitMetrics()
+
+	result.audit, err = newAuditor(result, auditSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	watcherCtx, stopSecretWatcher := context.WithCancel(context.Background())
+	result.stopSecretWatcher = stopSecretWatcher
+	result.watchSecrets(watcherCtx, runtimeSettings.Secrets)
 
-		defaultBucket, err := baseConfig.DefaultBucket.GetOrDefault("")
+	return result, nil
+}
+
+// newClient initializes the storage client at the index of the configuration.
+func (m *Manager) newClient(
+	ctx context.Context,
+	index int,
+	config ClientConfig,
+) (*Client, *secretReferences, error) {
+	config, refs, err := config.resolveSecrets(true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize storage client %d: %w", index, err)
+	}
+
+	baseConfig, client, err := config.ToStorageClient(ctx, m.logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize storage client %d: %w", index, err)
+	}
+
+	configID := baseConfig.ID
+	if configID == "" {
+		configID = strconv.Itoa(index)
+	}
+
+	defaultBucket, err := baseConfig.DefaultBucket.GetOrDefault("")
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"failed to initialize storage client %s; defaultBucket: %w",
+			configID,
+			err,
+		)
+	}
+
+	if len(baseConfig.CustomerKeys) > 0 {
+		client, err = newCustomerKeyClient(client, baseConfig.CustomerKeys)
 		if err != nil {
-			return nil, fmt.Errorf(
-				"failed to initialize storage client %s; defaultBucket: %w",
+			return nil, nil, fmt.Errorf(
+				"failed to initialize storage client %s; %w",
 				configID,
 				err,
 			)
 		}
+	}
 
-		c := Client{
-			id:             common.StorageClientID(baseConfig.ID),
-			defaultBucket:  defaultBucket,
-			allowedBuckets: baseConfig.AllowedBuckets,
-			StorageClient:  client,
+	if baseConfig.Encryption != nil {
+		client, err = newEncryptedClient(client, baseConfig.Encryption)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"failed to initialize storage client %s; encryption: %w",
+				configID,
+				err,
+			)
 		}
+	}
 
-		if baseConfig.DefaultPresignedExpiry != nil {
-			presignedExpiry, err := time.ParseDuration(*baseConfig.DefaultPresignedExpiry)
-			if err != nil {
-				return nil, fmt.Errorf(
-					"failed to parse defaultPresignedExpiry in client %s: %w",
-					configID,
-					err,
-				)
-			}
-
-			c.defaultPresignedExpiry = &presignedExpiry
-		}
+	// configurations of dynamic credentials and the initial configuration file aren't validated in advance.
+	if baseConfig.Encryption != nil && baseConfig.ContentCache != nil &&
+		baseConfig.ContentCache.Storage == common.ContentCacheStorageDisk {
+		return nil, nil, fmt.Errorf(
+			"failed to initialize storage client %s; contentCache: %w",
+			configID,
+			common.ErrContentCacheDiskEncrypted,
+		)
+	}
+
+	contentCache, err := newContentCache(common.StorageClientID(configID), baseConfig.ContentCache, m.meter)
+	if err != nil {
+		return nil, nil, fmt.Errorf(
+			"failed to initialize storage client %s; contentCache: %w",
+			configID,
+			err,
+		)
+	}
+
+	client = newClientLimiter(common.StorageClientID(configID), baseConfig.Limits).limit(client)
+	breaker := newCircuitBreaker(common.StorageClientID(configID), baseConfig.CircuitBreaker, m.logger)
+	metadataCache := newMetadataCache(common.StorageClientID(configID), baseConfig.MetadataCache, m.meter)
+
+	c := &Client{
+		id:             common.StorageClientID(configID),
+		provider:       baseConfig.Type,
+		defaultBucket:  defaultBucket,
+		allowedBuckets: baseConfig.AllowedBuckets,
+		uploadPolicies: baseConfig.UploadPolicies,
+		breaker:        breaker,
+		metadataCache:  metadataCache,
+		contentCache:   contentCache,
+		StorageClient: contentCache.wrap(metadataCache.wrap(breaker.guard(newRetryClient(
+			m.metrics.instrument(
+				client,
+				common.StorageClientID(configID),
+				baseConfig.Type,
+			),
+			baseConfig.Retry,
+		)))),
+	}
 
-		if c.id == "" {
-			c.id = common.StorageClientID(strconv.Itoa(i))
+	if baseConfig.DefaultPresignedExpiry != nil {
+		presignedExpiry, err := time.ParseDuration(*baseConfig.DefaultPresignedExpiry)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"failed to parse defaultPresignedExpiry in client %s: %w",
+				configID,
+				err,
+			)
 		}
 
-		result.clients[i] = c
+		c.defaultPresignedExpiry = &presignedExpiry
 	}
 
-	return result, nil
+	c.lease = newClientLease(c)
+
+	return c, refs, nil
+}
+
+// getClients returns the current snapshot of clients.
+func (m *Manager) getClients() []Client {
+	clients := m.clients.Load()
+	if clients == nil {
+		return nil
+	}
+
+	return *clients
 }
 
 // GetClient gets the inner client by key.
 func (m *Manager) GetClient(clientID *common.StorageClientID) (*Client, bool) {
-	if len(m.clients) == 0 {
+	clients := m.getClients()
+	if len(clients) == 0 {
 		return nil, false
 	}
 
 	if clientID == nil || *clientID == "" {
-		return &m.clients[0], true
+		return &clients[0], true
 	}
 
-	for _, c := range m.clients {
+	for _, c := range clients {
 		if c.id == *clientID {
 			return &c, true
 		}
@@ -117,20 +253,42 @@ func (m *Manager) GetClient(clientID *common.StorageClientID) (*Client, bool) {
 
 // GetClientIDs gets all client IDs.
 func (m *Manager) GetClientIDs() []string {
-	results := make([]string, len(m.clients))
+	clients := m.getClients()
+	results := make([]string, len(clients))
 
-	for i, client := range m.clients {
+	for i, client := range clients {
 		results[i] = string(client.id)
 	}
 
 	return results
 }
 
+// GetOrCreateClient gets the client by ID, or creates a temporary client from credentials.
+// The client is held until the leases of the context are released.
 func (m *Manager) GetOrCreateClient(
 	ctx context.Context,
 	arguments common.StorageClientCredentialArguments,
 ) (*Client, error) {
-	if len(m.clients) == 0 || !arguments.IsEmpty() {
+	leases := clientLeasesFromContext(ctx)
+
+	for {
+		client, err := m.getOrCreateClient(ctx, arguments)
+		if err != nil || client == nil || leases.add(client) {
+			return client, err
+		}
+		// the client was closed by a concurrent eviction or reload. Retry with the current one.
+	}
+}
+
+func (m *Manager) getOrCreateClient(
+	ctx context.Context,
+	arguments common.StorageClientCredentialArguments,
+) (*Client, error) {
+	if m.closed.Load() {
+		return nil, errManagerClosed
+	}
+
+	if len(m.getClients()) == 0 || !arguments.IsEmpty() {
 		return m.createTemporaryClient(ctx, arguments)
 	}
 
@@ -143,11 +301,32 @@ func (m *Manager) GetOrCreateClient(
 }
 
 // GetClientAndBucket gets the inner client by key and bucket name.
+// The client is held until the leases of the context are released.
 func (m *Manager) GetClientAndBucket(
 	ctx context.Context,
 	arguments common.StorageBucketArguments,
 ) (*Client, string, error) {
-	if len(m.clients) == 0 || !arguments.IsEmpty() {
+	leases := clientLeasesFromContext(ctx)
+
+	for {
+		client, bucketName, err := m.getClientAndBucket(ctx, arguments)
+		if err != nil || client == nil || leases.add(client) {
+			return client, bucketName, err
+		}
+		// the client was closed by a concurrent eviction or reload. Retry with the current one.
+	}
+}
+
+func (m *Manager) getClientAndBucket(
+	ctx context.Context,
+	arguments common.StorageBucketArguments,
+) (*Client, string, error) {
+	if m.closed.Load() {
+		return nil, "", errManagerClosed
+	}
+
+	clients := m.getClients()
+	if len(clients) == 0 || !arguments.IsEmpty() {
 		if arguments.Bucket == "" {
 			return nil, "", schema.UnprocessableContentError("bucket is required", nil)
 		}
@@ -184,7 +363,7 @@ func (m *Manager) GetClientAndBucket(
 		return client, bucketName, nil
 	}
 
-	for _, c := range m.clients {
+	for _, c := range clients {
 		if c.defaultBucket == arguments.Bucket ||
 			slices.Contains(c.allowedBuckets, arguments.Bucket) {
 			return &c, arguments.Bucket, nil
@@ -192,7 +371,7 @@ func (m *Manager) GetClientAndBucket(
 	}
 
 	// return the first client by default
-	return &m.clients[0], arguments.Bucket, nil
+	return &clients[0], arguments.Bucket, nil
 }
 
 func (m *Manager) createTemporaryClient(
@@ -208,67 +387,206 @@ func (m *Manager) createTemporaryClient(
 		clientType = *arguments.ClientType
 	}
 
-	clientId := common.StorageClientID(fmt.Sprintf("%s-temp", clientType))
-	defaultPresignedExpiry := 24 * time.Hour
-
 	span.SetAttributes(attribute.String("storage.client.type", string(clientType)))
 
+	cacheKey := newClientCacheKey(
+		string(clientType),
+		arguments.Endpoint,
+		arguments.AccessKeyID,
+		arguments.SecretAccessKey,
+		arguments.SessionToken,
+		arguments.ServiceAccountJSON,
+		arguments.AccessToken,
+		arguments.SASToken,
+		arguments.AccountURL,
+	)
+
+	return m.clientCache.Get(ctx, cacheKey, func() (*Client, error) {
+		client, err := m.newTemporaryClient(ctx, clientType, arguments)
+		if err != nil {
+			return nil, err
+		}
+
+		client.provider = clientType
+		client.StorageClient = m.metrics.instrument(client.StorageClient, client.id, clientType)
+		client.lease = newClientLease(client)
+
+		return client, nil
+	})
+}
+
+func (m *Manager) newTemporaryClient(
+	ctx context.Context,
+	clientType common.StorageProviderType,
+	arguments common.StorageClientCredentialArguments,
+) (*Client, error) {
 	switch clientType {
 	case common.StorageProviderTypeAzblob:
 		return m.createTemporaryAzblobClient(ctx, arguments)
-	case common.StorageProviderTypeS3, common.StorageProviderTypeGcs:
-		fallthrough
-	default:
-		if arguments.AccessKeyID == "" && arguments.SecretAccessKey == "" {
-			return nil, schema.UnprocessableContentError(
-				"accessKeyId and secretAccessKey arguments are required",
-				nil,
-			)
+	case common.StorageProviderTypeGcs:
+		if arguments.ServiceAccountJSON != "" || arguments.AccessToken != "" {
+			return m.createTemporaryGCSClient(ctx, arguments)
 		}
 
-		clientConfig := &minio.ClientConfig{
-			BaseClientConfig: common.BaseClientConfig{
-				ID: string(clientId),
-			},
-			OtherConfig: minio.OtherConfig{
-				Authentication: minio.AuthCredentials{
-					Type: minio.AuthTypeStatic,
-					AccessKeyID: &utils.EnvString{
-						Value: utils.ToPtr(arguments.AccessKeyID),
-					},
-					SecretAccessKey: &utils.EnvString{
-						Value: utils.ToPtr(arguments.SecretAccessKey),
-					},
+		// fallback to the S3-compatible API with HMAC keys.
+		return m.createTemporaryMinioClient(ctx, clientType, arguments)
+	default:
+		return m.createTemporaryMinioClient(ctx, clientType, arguments)
+	}
+}
+
+func (m *Manager) createTemporaryMinioClient(
+	ctx context.Context,
+	clientType common.StorageProviderType,
+	arguments common.StorageClientCredentialArguments,
+) (*Client, error) {
+	if arguments.AccessKeyID == "" && arguments.SecretAccessKey == "" {
+		return nil, schema.UnprocessableContentError(
+			"accessKeyId and secretAccessKey arguments are required",
+			nil,
+		)
+	}
+
+	clientId := common.StorageClientID(fmt.Sprintf("%s-temp", clientType))
+	defaultPresignedExpiry := 24 * time.Hour
+	clientConfig := &minio.ClientConfig{
+		BaseClientConfig: common.BaseClientConfig{
+			ID: string(clientId),
+		},
+		OtherConfig: minio.OtherConfig{
+			Authentication: minio.AuthCredentials{
+				Type: minio.AuthTypeStatic,
+				AccessKeyID: &utils.EnvString{
+					Value: utils.ToPtr(arguments.AccessKeyID),
+				},
+				SecretAccessKey: &utils.EnvString{
+					Value: utils.ToPtr(arguments.SecretAccessKey),
 				},
-				HTTP: m.runtime.HTTP,
 			},
+			HTTP: m.runtime.HTTP,
+		},
+	}
+
+	if arguments.SessionToken != "" {
+		clientConfig.Authentication.SessionToken = &utils.EnvString{
+			Value: utils.ToPtr(arguments.SessionToken),
 		}
+	}
 
-		if arguments.Endpoint != "" {
-			clientConfig.Endpoint = &utils.EnvString{
-				Value: &arguments.Endpoint,
-			}
+	if arguments.Endpoint != "" {
+		clientConfig.Endpoint = &utils.EnvString{
+			Value: &arguments.Endpoint,
+		}
+	}
+
+	client, err := minio.New(ctx, clientType, clientConfig, m.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		id:                     clientId,
+		StorageClient:          client,
+		defaultPresignedExpiry: &defaultPresignedExpiry,
+	}, nil
+}
+
+func (m *Manager) createTemporaryGCSClient(
+	ctx context.Context,
+	arguments common.StorageClientCredentialArguments,
+) (*Client, error) {
+	clientId := "gcs-temp"
+	defaultPresignedExpiry := 24 * time.Hour
+	clientConfig := &gcs.ClientConfig{
+		BaseClientConfig: common.BaseClientConfig{
+			ID: clientId,
+		},
+		OtherConfig: gcs.OtherConfig{
+			HTTP: m.runtime.HTTP,
+		},
+	}
+
+	if arguments.Endpoint != "" {
+		clientConfig.Endpoint = &utils.EnvString{
+			Value: &arguments.Endpoint,
 		}
+	}
 
-		client, err := minio.New(ctx, clientType, clientConfig, m.logger)
+	if arguments.ServiceAccountJSON != "" {
+		projectID, err := parseGCSServiceAccountJSON(arguments.ServiceAccountJSON)
 		if err != nil {
 			return nil, err
 		}
 
-		return &Client{
-			id:                     clientId,
-			StorageClient:          client,
-			defaultPresignedExpiry: &defaultPresignedExpiry,
-		}, nil
+		clientConfig.ProjectID = utils.NewEnvStringValue(projectID)
+		clientConfig.Authentication = gcs.AuthCredentials{
+			Type: gcs.AuthTypeCredentials,
+			Credentials: &utils.EnvString{
+				Value: utils.ToPtr(arguments.ServiceAccountJSON),
+			},
+		}
+	} else {
+		clientConfig.Authentication = gcs.AuthCredentials{
+			Type: gcs.AuthTypeAccessToken,
+			AccessToken: &utils.EnvString{
+				Value: utils.ToPtr(arguments.AccessToken),
+			},
+		}
+	}
+
+	client, err := gcs.New(ctx, clientConfig, m.logger)
+	if err != nil {
+		return nil, err
 	}
+
+	return &Client{
+		id:                     common.StorageClientID(clientId),
+		StorageClient:          client,
+		defaultPresignedExpiry: &defaultPresignedExpiry,
+	}, nil
+}
+
+// parseGCSServiceAccountJSON validates the service account JSON of dynamic credentials and returns the project ID.
+// Other credential types such as external_account are rejected because the Google client would fetch tokens
+// from URLs and files that the credentials specify.
+func parseGCSServiceAccountJSON(rawJSON string) (string, error) {
+	var serviceAccount struct {
+		Type      string `json:"type"`
+		ProjectID string `json:"project_id"`
+	}
+
+	if err := json.Unmarshal([]byte(rawJSON), &serviceAccount); err != nil {
+		return "", schema.UnprocessableContentError("invalid service account JSON", map[string]any{
+			"cause": err.Error(),
+		})
+	}
+
+	if serviceAccount.Type != "service_account" {
+		return "", schema.UnprocessableContentError(
+			"invalid service account JSON: the credential type must be service_account",
+			map[string]any{
+				"type": serviceAccount.Type,
+			},
+		)
+	}
+
+	return serviceAccount.ProjectID, nil
 }
 
 func (m *Manager) createTemporaryAzblobClient(
 	ctx context.Context,
 	arguments common.StorageClientCredentialArguments,
 ) (*Client, error) {
-	if arguments.Endpoint == "" {
-		return nil, schema.UnprocessableContentError("endpoint is required for azblob", nil)
+	serviceURL := arguments.AccountURL
+	if serviceURL == "" {
+		serviceURL = arguments.Endpoint
+	}
+
+	if serviceURL == "" {
+		return nil, schema.UnprocessableContentError(
+			"endpoint or account_url is required for azblob",
+			nil,
+		)
 	}
 
 	clientId := "azblob-temp"
@@ -282,9 +600,20 @@ func (m *Manager) createTemporaryAzblobClient(
 		},
 	}
 
-	if arguments.AccessKeyID != "" || arguments.SecretAccessKey != "" {
+	switch {
+	case arguments.SASToken != "":
+		clientConfig.Authentication = azblob.AuthCredentials{
+			Type: azblob.AuthTypeSAS,
+			SASToken: &utils.EnvString{
+				Value: utils.ToPtr(arguments.SASToken),
+			},
+			AccountURL: &utils.EnvString{
+				Value: &serviceURL,
+			},
+		}
+	case arguments.AccessKeyID != "" || arguments.SecretAccessKey != "":
 		clientConfig.Endpoint = &utils.EnvString{
-			Value: &arguments.Endpoint,
+			Value: &serviceURL,
 		}
 
 		clientConfig.Authentication = azblob.AuthCredentials{
@@ -296,7 +625,14 @@ func (m *Manager) createTemporaryAzblobClient(
 				Value: utils.ToPtr(arguments.SecretAccessKey),
 			},
 		}
-	} else {
+	default:
+		if arguments.Endpoint == "" {
+			return nil, schema.UnprocessableContentError(
+				"endpoint is required for the azblob connection string",
+				nil,
+			)
+		}
+
 		clientConfig.Authentication = azblob.AuthCredentials{
 			Type: azblob.AuthTypeConnectionString,
 			ConnectionString: &utils.EnvString{
@@ -316,3 +652,23 @@ func (m *Manager) createTemporaryAzblobClient(
 		defaultPresignedExpiry: &defaultPresignedExpiry,
 	}, nil
 }
+
+// authorizeBucket checks if the session of the request is allowed to operate on the bucket.
+func (m *Manager) authorizeBucket(
+	ctx context.Context,
+	op PolicyOperation,
+	client *Client,
+	bucketName string,
+) error {
+	return m.policy.AuthorizeBucket(ctx, op, string(client.id), bucketName)
+}
+
+// authorizeObject checks if the session of the request is allowed to operate on the object.
+func (m *Manager) authorizeObject(
+	ctx context.Context,
+	op PolicyOperation,
+	client *Client,
+	bucketName, objectName string,
+) error {
+	return m.policy.AuthorizeObject(ctx, op, string(client.id), bucketName, objectName)
+}
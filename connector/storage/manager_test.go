@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseGCSServiceAccountJSON(t *testing.T) {
+	projectID, err := parseGCSServiceAccountJSON(`{"type":"service_account","project_id":"test"}`)
+	assert.NilError(t, err)
+	assert.Equal(t, projectID, "test")
+
+	_, err = parseGCSServiceAccountJSON(
+		`{"type":"external_account","credential_source":{"url":"http://169.254.169.254/token"}}`,
+	)
+	assert.ErrorContains(t, err, "the credential type must be service_account")
+
+	_, err = parseGCSServiceAccountJSON(`{"project_id":"test"}`)
+	assert.ErrorContains(t, err, "the credential type must be service_account")
+
+	_, err = parseGCSServiceAccountJSON(`{`)
+	assert.ErrorContains(t, err, "invalid service account JSON")
+}
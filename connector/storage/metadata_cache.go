@@ -0,0 +1,494 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+type metadataCacheEntry struct {
+	key    string
+	bucket string
+	// the object name of stat results.
+	object string
+	// the prefix of listing results.
+	prefix    string
+	isList    bool
+	value     any
+	expiresAt time.Time
+}
+
+// metadataCache is an LRU cache of stat and listing results of a storage client.
+// Entries of a bucket are invalidated when objects of the bucket are mutated through the client.
+type metadataCache struct {
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+	buckets map[string]map[*list.Element]struct{}
+	// generations of buckets are increased on every invalidation so results of reads
+	// that were in flight during the mutation aren't cached.
+	generations map[string]uint64
+
+	requestCounter metric.Int64Counter
+	attributes     []attribute.KeyValue
+}
+
+// newMetadataCache creates a metadata cache from the settings. The result is nil if the setting is empty.
+func newMetadataCache(
+	clientID common.StorageClientID,
+	settings *common.MetadataCacheSettings,
+	meter metric.Meter,
+) *metadataCache {
+	if settings == nil {
+		return nil
+	}
+
+	requestCounter, err := meter.Int64Counter(
+		"storage.metadata_cache.requests",
+		metric.WithDescription("The number of lookups in the metadata cache of storage clients"),
+	)
+	if err != nil {
+		requestCounter = noop.Int64Counter{}
+	}
+
+	return &metadataCache{
+		ttl:            settings.GetTTL(),
+		maxEntries:     settings.GetMaxEntries(),
+		now:            time.Now,
+		entries:        map[string]*list.Element{},
+		order:          list.New(),
+		buckets:        map[string]map[*list.Element]struct{}{},
+		generations:    map[string]uint64{},
+		requestCounter: requestCounter,
+		attributes:     []attribute.KeyValue{attribute.String("client_id", string(clientID))},
+	}
+}
+
+// wrap wraps the storage client with the cache. The client is returned as is if the cache is nil.
+func (mc *metadataCache) wrap(client common.StorageClient) common.StorageClient {
+	if mc == nil {
+		return client
+	}
+
+	return &metadataCacheClient{
+		StorageClient: client,
+		cache:         mc,
+	}
+}
+
+// Len returns the number of cached results.
+func (mc *metadataCache) Len() int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	return mc.order.Len()
+}
+
+// get returns the cached value of the key. If the key isn't cached,
+// the generation of the bucket is returned to store the result later.
+func (mc *metadataCache) get(
+	ctx context.Context,
+	operation string,
+	bucket string,
+	key string,
+) (any, uint64, bool) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	now := mc.now()
+	mc.evictExpired(now)
+
+	elem, ok := mc.entries[key]
+	if !ok {
+		mc.recordRequest(ctx, operation, "miss")
+
+		return nil, mc.generations[bucket], false
+	}
+
+	entry, _ := elem.Value.(*metadataCacheEntry)
+	mc.order.MoveToFront(elem)
+	mc.recordRequest(ctx, operation, "hit")
+
+	return entry.value, 0, true
+}
+
+// set caches the value if the bucket wasn't invalidated since the generation.
+func (mc *metadataCache) set(entry *metadataCacheEntry, generation uint64) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if mc.generations[entry.bucket] != generation {
+		return
+	}
+
+	if elem, ok := mc.entries[entry.key]; ok {
+		mc.remove(elem)
+	}
+
+	entry.expiresAt = mc.now().Add(mc.ttl)
+	elem := mc.order.PushFront(entry)
+	mc.entries[entry.key] = elem
+
+	bucketEntries, ok := mc.buckets[entry.bucket]
+	if !ok {
+		bucketEntries = map[*list.Element]struct{}{}
+		mc.buckets[entry.bucket] = bucketEntries
+	}
+
+	bucketEntries[elem] = struct{}{}
+
+	for mc.order.Len() > mc.maxEntries {
+		mc.remove(mc.order.Back())
+	}
+}
+
+// invalidateObject removes stat results of the object and listings that may contain the object.
+func (mc *metadataCache) invalidateObject(bucket string, object string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.generations[bucket]++
+
+	for elem := range mc.buckets[bucket] {
+		entry, _ := elem.Value.(*metadataCacheEntry)
+		if (entry.isList && strings.HasPrefix(object, entry.prefix)) ||
+			(!entry.isList && entry.object == object) {
+			mc.remove(elem)
+		}
+	}
+}
+
+// invalidateBucket removes all cached results of the bucket.
+func (mc *metadataCache) invalidateBucket(bucket string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.generations[bucket]++
+
+	for elem := range mc.buckets[bucket] {
+		mc.remove(elem)
+	}
+}
+
+// purge removes cached results of objects in the bucket whose names start with the prefix
+// and listings that may contain such objects. All cached results are removed if the bucket is empty.
+func (mc *metadataCache) purge(bucket string, prefix string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	for bucketName, bucketEntries := range mc.buckets {
+		if bucket != "" && bucketName != bucket {
+			continue
+		}
+
+		mc.generations[bucketName]++
+
+		for elem := range bucketEntries {
+			entry, _ := elem.Value.(*metadataCacheEntry)
+			if (entry.isList && (strings.HasPrefix(entry.prefix, prefix) || strings.HasPrefix(prefix, entry.prefix))) ||
+				(!entry.isList && strings.HasPrefix(entry.object, prefix)) {
+				mc.remove(elem)
+			}
+		}
+	}
+}
+
+func (mc *metadataCache) evictExpired(now time.Time) {
+	for elem := mc.order.Back(); elem != nil; {
+		entry, _ := elem.Value.(*metadataCacheEntry)
+		if entry.expiresAt.After(now) {
+			return
+		}
+
+		prev := elem.Prev()
+		mc.remove(elem)
+		elem = prev
+	}
+}
+
+// remove deletes the entry from the cache. The lock must be held.
+func (mc *metadataCache) remove(elem *list.Element) {
+	entry, _ := mc.order.Remove(elem).(*metadataCacheEntry)
+	delete(mc.entries, entry.key)
+
+	bucketEntries := mc.buckets[entry.bucket]
+	delete(bucketEntries, elem)
+
+	if len(bucketEntries) == 0 {
+		delete(mc.buckets, entry.bucket)
+	}
+}
+
+func (mc *metadataCache) recordRequest(ctx context.Context, operation string, result string) {
+	mc.requestCounter.Add(ctx, 1, metric.WithAttributes(
+		slices.Concat(mc.attributes, []attribute.KeyValue{
+			attribute.String("operation", operation),
+			attribute.String("result", result),
+		})...,
+	))
+}
+
+// metadataCacheClient caches stat and listing results of the inner storage client
+// and invalidates them when objects are mutated through the client.
+type metadataCacheClient struct {
+	common.StorageClient
+
+	cache *metadataCache
+}
+
+var _ common.StorageClient = (*metadataCacheClient)(nil)
+
+// Close closes the inner client if it holds resources.
+func (mcc *metadataCacheClient) Close() error {
+	return closeStorageClient(mcc.StorageClient)
+}
+
+// StatObject fetches metadata of an object. Requests with custom headers or
+// server-side encryption keys aren't cached.
+func (mcc *metadataCacheClient) StatObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (*common.StorageObject, error) {
+	if opts.ServerSideEncryption != nil || len(opts.Headers) > 0 || len(opts.RequestParams) > 0 {
+		return mcc.StorageClient.StatObject(ctx, bucketName, objectName, opts)
+	}
+
+	key := newStatCacheKey(bucketName, objectName, opts)
+
+	value, generation, ok := mcc.cache.get(ctx, "StatObject", bucketName, key)
+	if ok {
+		object, _ := value.(*common.StorageObject)
+		// callers set fields of the result, so the cached value is copied.
+		result := *object
+
+		return &result, nil
+	}
+
+	result, err := mcc.StorageClient.StatObject(ctx, bucketName, objectName, opts)
+	if err != nil || result == nil {
+		return result, err
+	}
+
+	cached := *result
+	mcc.cache.set(&metadataCacheEntry{
+		key:    key,
+		bucket: bucketName,
+		object: objectName,
+		value:  &cached,
+	}, generation)
+
+	return result, nil
+}
+
+// ListObjects lists objects in a bucket. Listings that are filtered by a predicate aren't cached.
+func (mcc *metadataCacheClient) ListObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.ListStorageObjectsOptions,
+	predicate func(string) bool,
+) (*common.StorageObjectListResults, error) {
+	if predicate != nil || opts == nil {
+		return mcc.StorageClient.ListObjects(ctx, bucketName, opts, predicate)
+	}
+
+	key := newListCacheKey(bucketName, opts)
+
+	value, generation, ok := mcc.cache.get(ctx, "ListObjects", bucketName, key)
+	if ok {
+		results, _ := value.(*common.StorageObjectListResults)
+
+		return cloneObjectListResults(results), nil
+	}
+
+	results, err := mcc.StorageClient.ListObjects(ctx, bucketName, opts, predicate)
+	if err != nil || results == nil {
+		return results, err
+	}
+
+	mcc.cache.set(&metadataCacheEntry{
+		key:    key,
+		bucket: bucketName,
+		prefix: opts.Prefix,
+		isList: true,
+		value:  cloneObjectListResults(results),
+	}, generation)
+
+	return results, nil
+}
+
+// RemoveBucket removes a bucket, bucket should be empty to be successfully removed.
+func (mcc *metadataCacheClient) RemoveBucket(ctx context.Context, bucketName string) error {
+	defer mcc.cache.invalidateBucket(bucketName)
+
+	return mcc.StorageClient.RemoveBucket(ctx, bucketName)
+}
+
+// UpdateBucket updates configurations for the bucket.
+func (mcc *metadataCacheClient) UpdateBucket(
+	ctx context.Context,
+	bucketName string,
+	opts common.UpdateStorageBucketOptions,
+) error {
+	defer mcc.cache.invalidateBucket(bucketName)
+
+	return mcc.StorageClient.UpdateBucket(ctx, bucketName, opts)
+}
+
+// PutObject uploads an object.
+func (mcc *metadataCacheClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts *common.PutStorageObjectOptions,
+	reader io.Reader,
+	objectSize int64,
+) (*common.StorageUploadInfo, error) {
+	defer mcc.cache.invalidateObject(bucketName, objectName)
+
+	return mcc.StorageClient.PutObject(ctx, bucketName, objectName, opts, reader, objectSize)
+}
+
+// CopyObject creates or replaces an object through server-side copying of an existing object.
+func (mcc *metadataCacheClient) CopyObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	src common.StorageCopySrcOptions,
+) (*common.StorageUploadInfo, error) {
+	defer mcc.cache.invalidateObject(dest.Bucket, dest.Name)
+
+	return mcc.StorageClient.CopyObject(ctx, dest, src)
+}
+
+// ComposeObject creates an object by concatenating a list of source objects using server-side copying.
+func (mcc *metadataCacheClient) ComposeObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	sources []common.StorageCopySrcOptions,
+) (*common.StorageUploadInfo, error) {
+	defer mcc.cache.invalidateObject(dest.Bucket, dest.Name)
+
+	return mcc.StorageClient.ComposeObject(ctx, dest, sources)
+}
+
+// RemoveObject removes an object with some specified options.
+func (mcc *metadataCacheClient) RemoveObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.RemoveStorageObjectOptions,
+) error {
+	defer mcc.cache.invalidateObject(bucketName, objectName)
+
+	return mcc.StorageClient.RemoveObject(ctx, bucketName, objectName, opts)
+}
+
+// RemoveObjects removes a list of objects. All cached results of the bucket are invalidated.
+func (mcc *metadataCacheClient) RemoveObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.RemoveStorageObjectsOptions,
+	predicate func(string) bool,
+) []common.RemoveStorageObjectError {
+	defer mcc.cache.invalidateBucket(bucketName)
+
+	return mcc.StorageClient.RemoveObjects(ctx, bucketName, opts, predicate)
+}
+
+// UpdateObject updates object configurations.
+func (mcc *metadataCacheClient) UpdateObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.UpdateStorageObjectOptions,
+) error {
+	defer mcc.cache.invalidateObject(bucketName, objectName)
+
+	return mcc.StorageClient.UpdateObject(ctx, bucketName, objectName, opts)
+}
+
+// RestoreObject restores a soft-deleted object.
+func (mcc *metadataCacheClient) RestoreObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+) error {
+	defer mcc.cache.invalidateObject(bucketName, objectName)
+
+	return mcc.StorageClient.RestoreObject(ctx, bucketName, objectName)
+}
+
+// RestoreArchivedObject restores an object from the archive storage tier.
+func (mcc *metadataCacheClient) RestoreArchivedObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.RestoreArchivedStorageObjectOptions,
+) error {
+	defer mcc.cache.invalidateObject(bucketName, objectName)
+
+	return mcc.StorageClient.RestoreArchivedObject(ctx, bucketName, objectName, opts)
+}
+
+func newStatCacheKey(bucketName, objectName string, opts common.GetStorageObjectOptions) string {
+	var versionID, partNumber string
+
+	if opts.VersionID != nil {
+		versionID = *opts.VersionID
+	}
+
+	if opts.PartNumber != nil {
+		partNumber = strconv.Itoa(*opts.PartNumber)
+	}
+
+	return strings.Join([]string{
+		"stat",
+		bucketName,
+		objectName,
+		versionID,
+		partNumber,
+		fmt.Sprintf("%+v", opts.Include),
+	}, "\x00")
+}
+
+func newListCacheKey(bucketName string, opts *common.ListStorageObjectsOptions) string {
+	var ongoingRestore string
+
+	if opts.OngoingRestore != nil {
+		ongoingRestore = strconv.FormatBool(*opts.OngoingRestore)
+	}
+
+	return strings.Join([]string{
+		"list",
+		bucketName,
+		opts.Prefix,
+		strconv.FormatBool(opts.Recursive),
+		strconv.Itoa(opts.MaxResults),
+		opts.StartAfter,
+		fmt.Sprintf("%+v", opts.Include),
+		ongoingRestore,
+	}, "\x00")
+}
+
+// cloneObjectListResults copies the list of objects because callers set fields of listed objects.
+func cloneObjectListResults(results *common.StorageObjectListResults) *common.StorageObjectListResults {
+	return &common.StorageObjectListResults{
+		Objects:  slices.Clone(results.Objects),
+		PageInfo: results.PageInfo,
+	}
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"go.opentelemetry.io/otel/metric/noop"
+	"gotest.tools/v3/assert"
+)
+
+func TestMetadataCache(t *testing.T) {
+	cache := newMetadataCache(
+		"test",
+		&common.MetadataCacheSettings{TTL: 10, MaxEntries: 2},
+		noop.NewMeterProvider().Meter("connector/storage"),
+	)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	_, generation, ok := cache.get(context.TODO(), "StatObject", "bucket", "a")
+	assert.Assert(t, !ok)
+	cache.set(&metadataCacheEntry{key: "a", bucket: "bucket", object: "a.txt", value: 1}, generation)
+	cache.set(&metadataCacheEntry{key: "b", bucket: "bucket", prefix: "dir/", isList: true, value: 2}, generation)
+
+	value, _, ok := cache.get(context.TODO(), "StatObject", "bucket", "a")
+	assert.Assert(t, ok)
+	assert.Equal(t, value, 1)
+
+	// the least recently used entry is evicted.
+	cache.set(&metadataCacheEntry{key: "c", bucket: "other", object: "c.txt", value: 3}, 0)
+	assert.Equal(t, cache.Len(), 2)
+
+	_, _, ok = cache.get(context.TODO(), "ListObjects", "bucket", "b")
+	assert.Assert(t, !ok)
+
+	// results of reads that were in flight during a mutation aren't cached.
+	_, generation, _ = cache.get(context.TODO(), "ListObjects", "bucket", "b")
+	cache.invalidateObject("bucket", "dir/b.txt")
+	cache.set(&metadataCacheEntry{key: "b", bucket: "bucket", prefix: "dir/", isList: true, value: 2}, generation)
+	assert.Equal(t, cache.Len(), 2)
+
+	_, _, ok = cache.get(context.TODO(), "StatObject", "bucket", "a")
+	assert.Assert(t, ok)
+
+	cache.invalidateObject("bucket", "a.txt")
+	_, _, ok = cache.get(context.TODO(), "StatObject", "bucket", "a")
+	assert.Assert(t, !ok)
+
+	now = now.Add(11 * time.Second)
+	assert.Equal(t, cache.Len(), 1)
+	_, _, ok = cache.get(context.TODO(), "StatObject", "other", "c")
+	assert.Assert(t, !ok)
+	assert.Equal(t, cache.Len(), 0)
+}
+
+func TestManagerMetadataCache(t *testing.T) {
+	dir := t.TempDir()
+	manager, err := NewManager(context.TODO(), []ClientConfig{
+		{
+			"id":               "local",
+			"type":             "fs",
+			"defaultDirectory": map[string]any{"value": dir},
+			"metadataCache":    map[string]any{"ttl": 60},
+		},
+	}, RuntimeSettings{
+		MaxUploadSizeMBs:   1,
+		MaxDownloadSizeMBs: 1,
+	}, nil, nil, nil, slog.Default())
+	assert.NilError(t, err)
+
+	defer manager.Close(context.TODO())
+
+	bucketArgs := common.StorageBucketArguments{}
+
+	_, err = manager.PutObject(context.TODO(), bucketArgs, "a.txt", &common.PutStorageObjectOptions{}, []byte("hello"))
+	assert.NilError(t, err)
+
+	stat, err := manager.StatObject(context.TODO(), bucketArgs, "a.txt", common.GetStorageObjectOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, *stat.Size, int64(5))
+
+	listResults, err := manager.ListObjects(context.TODO(), bucketArgs, &common.ListStorageObjectsOptions{}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(listResults.Objects), 1)
+
+	// changes outside the connector aren't visible until the cache expires.
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("external"), 0o600))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("external"), 0o600))
+
+	stat, err = manager.StatObject(context.TODO(), bucketArgs, "a.txt", common.GetStorageObjectOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, *stat.Size, int64(5))
+
+	listResults, err = manager.ListObjects(context.TODO(), bucketArgs, &common.ListStorageObjectsOptions{}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(listResults.Objects), 1)
+
+	// uploads through the manager invalidate the cache.
+	_, err = manager.PutObject(
+		context.TODO(),
+		bucketArgs,
+		"a.txt",
+		&common.PutStorageObjectOptions{},
+		[]byte("hello world"),
+	)
+	assert.NilError(t, err)
+
+	stat, err = manager.StatObject(context.TODO(), bucketArgs, "a.txt", common.GetStorageObjectOptions{})
+	assert.NilError(t, err)
+	assert.Equal(t, *stat.Size, int64(11))
+
+	listResults, err = manager.ListObjects(context.TODO(), bucketArgs, &common.ListStorageObjectsOptions{}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(listResults.Objects), 2)
+}
@@ -0,0 +1,551 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// storageMetrics hold metric instruments of storage client operations.
+type storageMetrics struct {
+	requestCounter  metric.Int64Counter
+	errorCounter    metric.Int64Counter
+	duration        metric.Float64Histogram
+	activeRequests  metric.Int64UpDownCounter
+	uploadedBytes   metric.Int64Counter
+	downloadedBytes metric.Int64Counter
+}
+
+func newStorageMetrics(meter metric.Meter) *storageMetrics {
+	requestCounter, err := meter.Int64Counter(
+		"storage.client.requests",
+		metric.WithDescription("The number of operations of storage clients"),
+	)
+	if err != nil {
+		requestCounter = noop.Int64Counter{}
+	}
+
+	errorCounter, err := meter.Int64Counter(
+		"storage.client.errors",
+		metric.WithDescription("The number of failed operations of storage clients"),
+	)
+	if err != nil {
+		errorCounter = noop.Int64Counter{}
+	}
+
+	duration, err := meter.Float64Histogram(
+		"storage.client.duration",
+		metric.WithDescription("Duration of operations of storage clients"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		duration = noop.Float64Histogram{}
+	}
+
+	activeRequests, err := meter.Int64UpDownCounter(
+		"storage.client.active_requests",
+		metric.WithDescription("The number of in-flight operations of storage clients"),
+	)
+	if err != nil {
+		activeRequests = noop.Int64UpDownCounter{}
+	}
+
+	uploadedBytes, err := meter.Int64Counter(
+		"storage.client.uploaded_bytes",
+		metric.WithDescription("The number of bytes that are uploaded to the storage"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		uploadedBytes = noop.Int64Counter{}
+	}
+
+	downloadedBytes, err := meter.Int64Counter(
+		"storage.client.downloaded_bytes",
+		metric.WithDescription("The number of bytes that are downloaded from the storage"),
+		metric.WithUnit("By"),
+	)
+	if err != nil {
+		downloadedBytes = noop.Int64Counter{}
+	}
+
+	return &storageMetrics{
+		requestCounter:  requestCounter,
+		errorCounter:    errorCounter,
+		duration:        duration,
+		activeRequests:  activeRequests,
+		uploadedBytes:   uploadedBytes,
+		downloadedBytes: downloadedBytes,
+	}
+}
+
+// instrument wraps the storage client to record metrics of every operation.
+func (sm *storageMetrics) instrument(
+	client common.StorageClient,
+	clientID common.StorageClientID,
+	provider common.StorageProviderType,
+) common.StorageClient {
+	if sm == nil {
+		return client
+	}
+
+	return &instrumentedClient{
+		StorageClient: client,
+		metrics:       sm,
+		attributes: []attribute.KeyValue{
+			attribute.String("client_id", string(clientID)),
+			attribute.String("provider", string(provider)),
+		},
+	}
+}
+
+// metricErrorCode returns the NDC error code of the error, or 500 if the error isn't a connector error.
+func metricErrorCode(err error) string {
+	var connectorError *schema.ConnectorError
+	if errors.As(err, &connectorError) {
+		return strconv.Itoa(connectorError.StatusCode())
+	}
+
+	return strconv.Itoa(http.StatusInternalServerError)
+}
+
+// instrumentedClient records request counts, errors, latency and transferred bytes of the inner storage client.
+type instrumentedClient struct {
+	common.StorageClient
+
+	metrics    *storageMetrics
+	attributes []attribute.KeyValue
+}
+
+var _ common.StorageClient = (*instrumentedClient)(nil)
+
+// Close closes the inner client if it holds resources.
+func (ic *instrumentedClient) Close() error {
+	return closeStorageClient(ic.StorageClient)
+}
+
+// begin records the start of the operation. The record must be ended with the result of the operation.
+func (ic *instrumentedClient) begin(ctx context.Context, operation string) *clientMetricRecord {
+	attrs := metric.WithAttributeSet(attribute.NewSet(
+		slices.Concat(ic.attributes, []attribute.KeyValue{attribute.String("operation", operation)})...,
+	))
+
+	ic.metrics.activeRequests.Add(ctx, 1, attrs)
+	ic.metrics.requestCounter.Add(ctx, 1, attrs)
+
+	return &clientMetricRecord{
+		metrics:   ic.metrics,
+		attrs:     attrs,
+		startTime: time.Now(),
+	}
+}
+
+func (ic *instrumentedClient) transferAttributes() metric.MeasurementOption {
+	return metric.WithAttributes(ic.attributes...)
+}
+
+// MakeBucket creates a new bucket.
+func (ic *instrumentedClient) MakeBucket(
+	ctx context.Context,
+	options *common.MakeStorageBucketOptions,
+) (err error) {
+	record := ic.begin(ctx, "MakeBucket")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.MakeBucket(ctx, options)
+}
+
+// ListBuckets lists all buckets.
+func (ic *instrumentedClient) ListBuckets(
+	ctx context.Context,
+	options *common.ListStorageBucketsOptions,
+	predicate func(string) bool,
+) (_ *common.StorageBucketListResults, err error) {
+	record := ic.begin(ctx, "ListBuckets")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.ListBuckets(ctx, options, predicate)
+}
+
+// GetBucket gets a bucket by name.
+func (ic *instrumentedClient) GetBucket(
+	ctx context.Context,
+	name string,
+	options common.BucketOptions,
+) (_ *common.StorageBucket, err error) {
+	record := ic.begin(ctx, "GetBucket")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.GetBucket(ctx, name, options)
+}
+
+// BucketExists checks if a bucket exists.
+func (ic *instrumentedClient) BucketExists(ctx context.Context, bucketName string) (_ bool, err error) {
+	record := ic.begin(ctx, "BucketExists")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.BucketExists(ctx, bucketName)
+}
+
+// RemoveBucket removes a bucket, bucket should be empty to be successfully removed.
+func (ic *instrumentedClient) RemoveBucket(ctx context.Context, bucketName string) (err error) {
+	record := ic.begin(ctx, "RemoveBucket")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.RemoveBucket(ctx, bucketName)
+}
+
+// UpdateBucket updates configurations for the bucket.
+func (ic *instrumentedClient) UpdateBucket(
+	ctx context.Context,
+	bucketName string,
+	opts common.UpdateStorageBucketOptions,
+) (err error) {
+	record := ic.begin(ctx, "UpdateBucket")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.UpdateBucket(ctx, bucketName, opts)
+}
+
+// ListObjects lists objects in a bucket.
+func (ic *instrumentedClient) ListObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.ListStorageObjectsOptions,
+	predicate func(string) bool,
+) (_ *common.StorageObjectListResults, err error) {
+	record := ic.begin(ctx, "ListObjects")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.ListObjects(ctx, bucketName, opts, predicate)
+}
+
+// ListIncompleteUploads lists partially uploaded objects in a bucket.
+func (ic *instrumentedClient) ListIncompleteUploads(
+	ctx context.Context,
+	bucketName string,
+	args common.ListIncompleteUploadsOptions,
+) (_ []common.StorageObjectMultipartInfo, err error) {
+	record := ic.begin(ctx, "ListIncompleteUploads")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.ListIncompleteUploads(ctx, bucketName, args)
+}
+
+// ListDeletedObjects lists deleted objects in a bucket.
+func (ic *instrumentedClient) ListDeletedObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.ListStorageObjectsOptions,
+	predicate func(string) bool,
+) (_ *common.StorageObjectListResults, err error) {
+	record := ic.begin(ctx, "ListDeletedObjects")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.ListDeletedObjects(ctx, bucketName, opts, predicate)
+}
+
+// GetObject returns a stream of the object data. Downloaded bytes are recorded when the stream is closed.
+func (ic *instrumentedClient) GetObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (_ io.ReadCloser, err error) {
+	record := ic.begin(ctx, "GetObject")
+	defer func() { record.end(ctx, err) }()
+
+	reader, err := ic.StorageClient.GetObject(ctx, bucketName, objectName, opts)
+	if err != nil || reader == nil {
+		return reader, err
+	}
+
+	return newMetricReadCloser(reader, func(size int64) {
+		ic.metrics.downloadedBytes.Add(ctx, size, ic.transferAttributes())
+	}), nil
+}
+
+// PutObject uploads an object and records the number of uploaded bytes.
+func (ic *instrumentedClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts *common.PutStorageObjectOptions,
+	reader io.Reader,
+	objectSize int64,
+) (_ *common.StorageUploadInfo, err error) {
+	record := ic.begin(ctx, "PutObject")
+	defer func() { record.end(ctx, err) }()
+
+	var size int64
+
+	countReader := newMetricReadCloser(withNopCloser(reader), func(n int64) {
+		size = n
+	})
+
+	defer func() {
+		_ = countReader.Close()
+		ic.metrics.uploadedBytes.Add(ctx, size, ic.transferAttributes())
+	}()
+
+	return ic.StorageClient.PutObject(ctx, bucketName, objectName, opts, countReader, objectSize)
+}
+
+// CopyObject creates or replaces an object through server-side copying of an existing object.
+func (ic *instrumentedClient) CopyObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	src common.StorageCopySrcOptions,
+) (_ *common.StorageUploadInfo, err error) {
+	record := ic.begin(ctx, "CopyObject")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.CopyObject(ctx, dest, src)
+}
+
+// ComposeObject creates an object by concatenating a list of source objects using server-side copying.
+func (ic *instrumentedClient) ComposeObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	sources []common.StorageCopySrcOptions,
+) (_ *common.StorageUploadInfo, err error) {
+	record := ic.begin(ctx, "ComposeObject")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.ComposeObject(ctx, dest, sources)
+}
+
+// StatObject fetches metadata of an object.
+func (ic *instrumentedClient) StatObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (_ *common.StorageObject, err error) {
+	record := ic.begin(ctx, "StatObject")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.StatObject(ctx, bucketName, objectName, opts)
+}
+
+// RemoveObject removes an object with some specified options.
+func (ic *instrumentedClient) RemoveObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.RemoveStorageObjectOptions,
+) (err error) {
+	record := ic.begin(ctx, "RemoveObject")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.RemoveObject(ctx, bucketName, objectName, opts)
+}
+
+// RemoveObjects removes a list of objects. The operation fails if any object can't be removed.
+func (ic *instrumentedClient) RemoveObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.RemoveStorageObjectsOptions,
+	predicate func(string) bool,
+) []common.RemoveStorageObjectError {
+	record := ic.begin(ctx, "RemoveObjects")
+
+	errs := ic.StorageClient.RemoveObjects(ctx, bucketName, opts, predicate)
+	if len(errs) > 0 {
+		record.end(ctx, errors.New(errs[0].Error))
+	} else {
+		record.end(ctx, nil)
+	}
+
+	return errs
+}
+
+// UpdateObject updates object configurations.
+func (ic *instrumentedClient) UpdateObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.UpdateStorageObjectOptions,
+) (err error) {
+	record := ic.begin(ctx, "UpdateObject")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.UpdateObject(ctx, bucketName, objectName, opts)
+}
+
+// RestoreObject restores a soft-deleted object.
+func (ic *instrumentedClient) RestoreObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+) (err error) {
+	record := ic.begin(ctx, "RestoreObject")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.RestoreObject(ctx, bucketName, objectName)
+}
+
+// RestoreArchivedObject restores an object from the archive storage tier.
+func (ic *instrumentedClient) RestoreArchivedObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.RestoreArchivedStorageObjectOptions,
+) (err error) {
+	record := ic.begin(ctx, "RestoreArchivedObject")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.RestoreArchivedObject(ctx, bucketName, objectName, opts)
+}
+
+// RemoveIncompleteUpload removes a partially uploaded object.
+func (ic *instrumentedClient) RemoveIncompleteUpload(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+) (err error) {
+	record := ic.begin(ctx, "RemoveIncompleteUpload")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.RemoveIncompleteUpload(ctx, bucketName, objectName)
+}
+
+// PresignedGetObject generates a presigned URL for HTTP GET operations.
+func (ic *instrumentedClient) PresignedGetObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.PresignedGetStorageObjectOptions,
+) (_ string, err error) {
+	record := ic.begin(ctx, "PresignedGetObject")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.PresignedGetObject(ctx, bucketName, objectName, opts)
+}
+
+// PresignedPutObject generates a presigned URL for HTTP PUT operations.
+func (ic *instrumentedClient) PresignedPutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	expiry time.Duration,
+) (_ string, err error) {
+	record := ic.begin(ctx, "PresignedPutObject")
+	defer func() { record.end(ctx, err) }()
+
+	return ic.StorageClient.PresignedPutObject(ctx, bucketName, objectName, expiry)
+}
+
+// clientMetricRecord holds the state of an in-flight operation.
+type clientMetricRecord struct {
+	metrics   *storageMetrics
+	attrs     metric.MeasurementOption
+	startTime time.Time
+}
+
+func (r *clientMetricRecord) end(ctx context.Context, err error) {
+	r.metrics.activeRequests.Add(ctx, -1, r.attrs)
+	r.metrics.duration.Record(ctx, time.Since(r.startTime).Seconds(), r.attrs)
+
+	if err != nil {
+		r.metrics.errorCounter.Add(ctx, 1, r.attrs, metric.WithAttributes(
+			attribute.String("error_code", metricErrorCode(err)),
+		))
+	}
+}
+
+// metricReadCloser counts bytes of the stream and calls onClose with the total size once.
+type metricReadCloser struct {
+	io.ReadCloser
+
+	size      atomic.Int64
+	onClose   func(size int64)
+	closeOnce sync.Once
+}
+
+// newMetricReadCloser wraps the reader to count read bytes.
+// The Seeker and ReaderAt interfaces of the reader are kept, so that storage SDKs can retry and read parts in parallel.
+func newMetricReadCloser(reader io.ReadCloser, onClose func(size int64)) io.ReadCloser {
+	result := &metricReadCloser{
+		ReadCloser: reader,
+		onClose:    onClose,
+	}
+
+	if seekerAt, ok := reader.(readSeekerAt); ok {
+		return &metricReadSeekerAt{
+			metricReadCloser: result,
+			seekerAt:         seekerAt,
+		}
+	}
+
+	return result
+}
+
+func (r *metricReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.size.Add(int64(n))
+
+	return n, err
+}
+
+func (r *metricReadCloser) Close() error {
+	r.closeOnce.Do(func() {
+		if r.onClose != nil {
+			r.onClose(r.size.Load())
+		}
+	})
+
+	return r.ReadCloser.Close()
+}
+
+// metricReadSeekerAt is a metricReadCloser that also counts bytes which are read at offsets.
+type metricReadSeekerAt struct {
+	*metricReadCloser
+
+	seekerAt readSeekerAt
+}
+
+func (r *metricReadSeekerAt) Seek(offset int64, whence int) (int64, error) {
+	return r.seekerAt.Seek(offset, whence)
+}
+
+func (r *metricReadSeekerAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.seekerAt.ReadAt(p, off)
+	r.size.Add(int64(n))
+
+	return n, err
+}
+
+// readSeekerAt is a reader that supports seeking and reading at offsets, e.g. files and in-memory buffers.
+type readSeekerAt interface {
+	io.Reader
+	io.Seeker
+	io.ReaderAt
+}
+
+// nopReadSeekerAtCloser is similar to io.NopCloser, but keeps the Seeker and ReaderAt interfaces.
+type nopReadSeekerAtCloser struct {
+	readSeekerAt
+}
+
+func (nopReadSeekerAtCloser) Close() error {
+	return nil
+}
+
+// withNopCloser returns a ReadCloser with a no-op Close method that keeps optional interfaces of the reader.
+func withNopCloser(reader io.Reader) io.ReadCloser {
+	if seekerAt, ok := reader.(readSeekerAt); ok {
+		return nopReadSeekerAtCloser{seekerAt}
+	}
+
+	return io.NopCloser(reader)
+}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"gotest.tools/v3/assert"
+)
+
+func findMetric(t *testing.T, rm metricdata.ResourceMetrics, name string) metricdata.Aggregation {
+	t.Helper()
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m.Data
+			}
+		}
+	}
+
+	t.Fatalf("metric %s not found", name)
+
+	return nil
+}
+
+func sumDataPoints(
+	data metricdata.Aggregation,
+	filter func(attribute.Set) bool,
+) int64 {
+	sum, _ := data.(metricdata.Sum[int64])
+
+	var result int64
+
+	for _, dp := range sum.DataPoints {
+		if filter(dp.Attributes) {
+			result += dp.Value
+		}
+	}
+
+	return result
+}
+
+func hasAttribute(key, value string) func(attribute.Set) bool {
+	return func(set attribute.Set) bool {
+		v, ok := set.Value(attribute.Key(key))
+
+		return ok && v.AsString() == value
+	}
+}
+
+func TestClientMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	defer func() {
+		_ = provider.Shutdown(context.TODO())
+	}()
+
+	manager, err := NewManager(context.TODO(), []ClientConfig{
+		{
+			"id":               "local",
+			"type":             "fs",
+			"defaultDirectory": map[string]any{"value": t.TempDir()},
+			"metadataCache":    map[string]any{},
+			"contentCache":     map[string]any{},
+		},
+	}, RuntimeSettings{
+		MaxUploadSizeMBs:   1,
+		MaxDownloadSizeMBs: 1,
+	}, nil, nil, provider.Meter("connector/storage"), slog.Default())
+	assert.NilError(t, err)
+
+	defer manager.stopSecretWatcher()
+
+	_, err = manager.PutObject(
+		context.TODO(),
+		common.StorageBucketArguments{},
+		"a.txt",
+		&common.PutStorageObjectOptions{},
+		[]byte("hello"),
+	)
+	assert.NilError(t, err)
+
+	_, object, err := manager.GetObject(
+		context.TODO(),
+		common.StorageBucketArguments{},
+		"a.txt",
+		common.GetStorageObjectOptions{},
+	)
+	assert.NilError(t, err)
+
+	data, err := io.ReadAll(object)
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), "hello")
+	assert.NilError(t, object.Close())
+
+	_, err = manager.StatObject(context.TODO(), common.StorageBucketArguments{}, "a.txt", common.GetStorageObjectOptions{})
+	assert.NilError(t, err)
+
+	_, _, err = manager.GetObject(
+		context.TODO(),
+		common.StorageBucketArguments{},
+		"../a.txt",
+		common.GetStorageObjectOptions{},
+	)
+	assert.ErrorContains(t, err, "invalid object path")
+
+	var rm metricdata.ResourceMetrics
+	assert.NilError(t, reader.Collect(context.TODO(), &rm))
+
+	requests := findMetric(t, rm, "storage.client.requests")
+	assert.Equal(t, sumDataPoints(requests, hasAttribute("operation", "PutObject")), int64(1))
+	assert.Equal(t, sumDataPoints(requests, hasAttribute("operation", "GetObject")), int64(1))
+	assert.Equal(t, sumDataPoints(requests, hasAttribute("client_id", "local")), sumDataPoints(
+		requests,
+		hasAttribute("provider", "fs"),
+	))
+
+	errs := findMetric(t, rm, "storage.client.errors")
+	assert.Equal(t, sumDataPoints(errs, hasAttribute("error_code", "403")), int64(1))
+
+	active := findMetric(t, rm, "storage.client.active_requests")
+	assert.Equal(t, sumDataPoints(active, hasAttribute("provider", "fs")), int64(0))
+
+	uploaded := findMetric(t, rm, "storage.client.uploaded_bytes")
+	assert.Equal(t, sumDataPoints(uploaded, hasAttribute("client_id", "local")), int64(5))
+
+	downloaded := findMetric(t, rm, "storage.client.downloaded_bytes")
+	assert.Equal(t, sumDataPoints(downloaded, hasAttribute("client_id", "local")), int64(5))
+
+	// cache metrics are recorded with the meter of the connector.
+	cacheRequests := findMetric(t, rm, "storage.metadata_cache.requests")
+	assert.Assert(t, sumDataPoints(cacheRequests, hasAttribute("client_id", "local")) > 0)
+
+	contentCacheRequests := findMetric(t, rm, "storage.content_cache.requests")
+	assert.Assert(t, sumDataPoints(contentCacheRequests, hasAttribute("client_id", "local")) > 0)
+
+	duration, ok := findMetric(t, rm, "storage.client.duration").(metricdata.Histogram[float64])
+	assert.Assert(t, ok)
+	assert.Assert(t, len(duration.DataPoints) > 0)
+}
+
+func TestReadCloserInterfaces(t *testing.T) {
+	var size int64
+
+	reader := newMetricReadCloser(withNopCloser(bytes.NewReader([]byte("hello world"))), func(n int64) {
+		size = n
+	})
+
+	// the Seeker and ReaderAt interfaces are kept for retries and parallel uploads.
+	seekerAt, ok := reader.(readSeekerAt)
+	assert.Assert(t, ok)
+
+	buf := make([]byte, 5)
+	n, err := seekerAt.ReadAt(buf, 6)
+	assert.NilError(t, err)
+	assert.Equal(t, string(buf[:n]), "world")
+
+	_, err = seekerAt.Seek(0, io.SeekStart)
+	assert.NilError(t, err)
+
+	data, err := io.ReadAll(reader)
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), "hello world")
+	assert.NilError(t, reader.Close())
+	assert.Equal(t, size, int64(16))
+
+	_, ok = newMetricReadCloser(io.NopCloser(strings.NewReader("hello")), nil).(io.Seeker)
+	assert.Assert(t, !ok)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"net/url"
 
 	"github.com/hasura/ndc-sdk-go/v2/connector"
@@ -22,6 +23,7 @@ type Client struct {
 	providerType common.StorageProviderType
 	isDebug      bool
 	client       *minio.Client
+	transport    http.RoundTripper
 }
 
 var _ common.StorageClient = &Client{}
@@ -55,10 +57,18 @@ func New(
 	}
 
 	mc.client = c
+	mc.transport = opts.Transport
 
 	return mc, nil
 }
 
+// Close closes idle connections of the HTTP transport.
+func (mc *Client) Close() error {
+	common.CloseIdleConnections(mc.transport)
+
+	return nil
+}
+
 func (mc *Client) startOtelSpan(
 	ctx context.Context,
 	name string,
@@ -1,6 +1,7 @@
 package minio
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -127,6 +128,11 @@ func (cc ClientConfig) toMinioOptions(
 		opts.MaxRetries = maxRetries
 	}
 
+	if cc.Retry != nil {
+		// retries are handled by the connector's retry policy.
+		opts.MaxRetries = 1
+	}
+
 	if cc.Region != nil {
 		opts.Region, err = cc.Region.GetOrDefault("")
 		if err != nil {
@@ -166,12 +172,19 @@ func (cc ClientConfig) ValidatePublicHost() (*url.URL, error) {
 type AuthType string
 
 const (
-	AuthTypeStatic AuthType = "static"
-	AuthTypeIAM    AuthType = "iam"
+	AuthTypeStatic            AuthType = "static"
+	AuthTypeIAM               AuthType = "iam"
+	AuthTypeAssumeRole        AuthType = "assumeRole"
+	AuthTypeWebIdentity       AuthType = "webIdentity"
+	AuthTypeSharedCredentials AuthType = "sharedCredentials"
 )
 
 var enumValues_AuthType = []AuthType{
-	AuthTypeStatic, AuthTypeIAM,
+	AuthTypeStatic,
+	AuthTypeIAM,
+	AuthTypeAssumeRole,
+	AuthTypeWebIdentity,
+	AuthTypeSharedCredentials,
 }
 
 // ParseAuthType parses the AuthType from string.
@@ -198,16 +211,32 @@ func (at AuthType) Validate() error {
 // AuthCredentials represent the authentication credentials information.
 type AuthCredentials struct {
 	// The authentication type
-	Type AuthType `json:"type"                      mapstructure:"type"            yaml:"type"`
+	Type AuthType `json:"type"                           mapstructure:"type"                 yaml:"type"`
 	// Access Key ID.
-	AccessKeyID *utils.EnvString `json:"accessKeyId,omitempty"     mapstructure:"accessKeyId"     yaml:"accessKeyId,omitempty"`
+	AccessKeyID *utils.EnvString `json:"accessKeyId,omitempty"          mapstructure:"accessKeyId"          yaml:"accessKeyId,omitempty"`
 	// Secret Access Key.
-	SecretAccessKey *utils.EnvString `json:"secretAccessKey,omitempty" mapstructure:"secretAccessKey" yaml:"secretAccessKey,omitempty"`
+	SecretAccessKey *utils.EnvString `json:"secretAccessKey,omitempty"      mapstructure:"secretAccessKey"      yaml:"secretAccessKey,omitempty"`
 	// Optional temporary session token credentials. Used for testing only.
 	// See https://docs.aws.amazon.com/IAM/latest/UserGuide/id_credentials_temp_use-resources.html
-	SessionToken *utils.EnvString `json:"sessionToken,omitempty"    mapstructure:"sessionToken"    yaml:"sessionToken,omitempty"`
+	SessionToken *utils.EnvString `json:"sessionToken,omitempty"         mapstructure:"sessionToken"         yaml:"sessionToken,omitempty"`
 	// Custom endpoint to fetch IAM role credentials.
-	IAMAuthEndpoint *utils.EnvString `json:"iamAuthEndpoint,omitempty" mapstructure:"iamAuthEndpoint" yaml:"iamAuthEndpoint,omitempty"`
+	IAMAuthEndpoint *utils.EnvString `json:"iamAuthEndpoint,omitempty"      mapstructure:"iamAuthEndpoint"      yaml:"iamAuthEndpoint,omitempty"`
+	// The Amazon Resource Name (ARN) of the role to assume.
+	RoleARN *utils.EnvString `json:"roleArn,omitempty"              mapstructure:"roleArn"              yaml:"roleArn,omitempty"`
+	// An identifier for the assumed role session.
+	RoleSessionName *utils.EnvString `json:"roleSessionName,omitempty"      mapstructure:"roleSessionName"      yaml:"roleSessionName,omitempty"`
+	// A unique identifier that is required by the trust policy of the role.
+	ExternalID *utils.EnvString `json:"externalId,omitempty"           mapstructure:"externalId"           yaml:"externalId,omitempty"`
+	// The duration in seconds of the role session. Defaults to 1 hour.
+	DurationSeconds *int `json:"durationSeconds,omitempty"      mapstructure:"durationSeconds"      yaml:"durationSeconds,omitempty"`
+	// Custom endpoint of the Security Token Service. Defaults to https://sts.amazonaws.com.
+	STSEndpoint *utils.EnvString `json:"stsEndpoint,omitempty"          mapstructure:"stsEndpoint"          yaml:"stsEndpoint,omitempty"`
+	// Path to the web identity token file, for example, the projected service account token in Kubernetes.
+	WebIdentityTokenFile *utils.EnvString `json:"webIdentityTokenFile,omitempty" mapstructure:"webIdentityTokenFile" yaml:"webIdentityTokenFile,omitempty"`
+	// Path to the shared credentials file. Defaults to ~/.aws/credentials.
+	CredentialsFile *utils.EnvString `json:"credentialsFile,omitempty"      mapstructure:"credentialsFile"      yaml:"credentialsFile,omitempty"`
+	// The profile in the shared credentials file. Defaults to the AWS_PROFILE environment variable or default.
+	Profile *utils.EnvString `json:"profile,omitempty"              mapstructure:"profile"              yaml:"profile,omitempty"`
 }
 
 // JSONSchema is used to generate a custom jsonschema.
@@ -232,6 +261,48 @@ func (ac AuthCredentials) JSONSchema() *jsonschema.Schema {
 	})
 	iamProps.Set("iamAuthEndpoint", envStringRef)
 
+	assumeRoleProps := jsonschema.NewProperties()
+	assumeRoleProps.Set("type", &jsonschema.Schema{
+		Type:        "string",
+		Description: "Assume a role with static or IAM source credentials",
+		Enum:        []any{AuthTypeAssumeRole},
+	})
+	assumeRoleProps.Set("roleArn", envStringRef)
+	assumeRoleProps.Set("roleSessionName", envStringRef)
+	assumeRoleProps.Set("externalId", envStringRef)
+	assumeRoleProps.Set("durationSeconds", &jsonschema.Schema{
+		Type:    "integer",
+		Minimum: json.Number("900"),
+	})
+	assumeRoleProps.Set("stsEndpoint", envStringRef)
+	assumeRoleProps.Set("accessKeyId", envStringRef)
+	assumeRoleProps.Set("secretAccessKey", envStringRef)
+	assumeRoleProps.Set("sessionToken", envStringRef)
+	assumeRoleProps.Set("iamAuthEndpoint", envStringRef)
+
+	webIdentityProps := jsonschema.NewProperties()
+	webIdentityProps.Set("type", &jsonschema.Schema{
+		Type:        "string",
+		Description: "Assume a role with a web identity token file",
+		Enum:        []any{AuthTypeWebIdentity},
+	})
+	webIdentityProps.Set("roleArn", envStringRef)
+	webIdentityProps.Set("webIdentityTokenFile", envStringRef)
+	webIdentityProps.Set("durationSeconds", &jsonschema.Schema{
+		Type:    "integer",
+		Minimum: json.Number("900"),
+	})
+	webIdentityProps.Set("stsEndpoint", envStringRef)
+
+	sharedCredentialsProps := jsonschema.NewProperties()
+	sharedCredentialsProps.Set("type", &jsonschema.Schema{
+		Type:        "string",
+		Description: "Read credentials of a profile from the shared credentials file",
+		Enum:        []any{AuthTypeSharedCredentials},
+	})
+	sharedCredentialsProps.Set("credentialsFile", envStringRef)
+	sharedCredentialsProps.Set("profile", envStringRef)
+
 	return &jsonschema.Schema{
 		OneOf: []*jsonschema.Schema{
 			{
@@ -244,6 +315,21 @@ func (ac AuthCredentials) JSONSchema() *jsonschema.Schema {
 				Properties: iamProps,
 				Required:   []string{"type"},
 			},
+			{
+				Type:       "object",
+				Properties: assumeRoleProps,
+				Required:   []string{"type", "roleArn"},
+			},
+			{
+				Type:       "object",
+				Properties: webIdentityProps,
+				Required:   []string{"type", "roleArn", "webIdentityTokenFile"},
+			},
+			{
+				Type:       "object",
+				Properties: sharedCredentialsProps,
+				Required:   []string{"type"},
+			},
 		},
 	}
 }
@@ -254,6 +340,12 @@ func (ac AuthCredentials) toCredentials() (*credentials.Credentials, error) {
 		return ac.parseIAMAuth()
 	case AuthTypeStatic:
 		return ac.parseStaticAccessIDSecret()
+	case AuthTypeAssumeRole:
+		return ac.parseAssumeRole()
+	case AuthTypeWebIdentity:
+		return ac.parseWebIdentity()
+	case AuthTypeSharedCredentials:
+		return ac.parseSharedCredentials()
 	default:
 		return nil, fmt.Errorf("unsupported auth type %s", ac.Type)
 	}
@@ -0,0 +1,208 @@
+package minio
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+const defaultSTSEndpoint = "https://sts.amazonaws.com"
+
+var (
+	errRequireRoleARN              = errors.New("roleArn is required")
+	errRequireWebIdentityTokenFile = errors.New("webIdentityTokenFile is required")
+)
+
+// assumeRoleProvider retrieves temporary credentials of a role with source credentials.
+// Source credentials are retrieved on every refresh so rotated IAM credentials are used.
+type assumeRoleProvider struct {
+	credentials.Expiry
+
+	source      *credentials.Credentials
+	stsEndpoint string
+	options     credentials.STSAssumeRoleOptions
+}
+
+var _ credentials.Provider = (*assumeRoleProvider)(nil)
+
+// RetrieveWithCredContext assumes the role with source credentials.
+func (arp *assumeRoleProvider) RetrieveWithCredContext(
+	cc *credentials.CredContext,
+) (credentials.Value, error) {
+	sourceValue, err := arp.source.GetWithContext(cc)
+	if err != nil {
+		return credentials.Value{}, fmt.Errorf("failed to retrieve source credentials: %w", err)
+	}
+
+	options := arp.options
+	options.AccessKey = sourceValue.AccessKeyID
+	options.SecretKey = sourceValue.SecretAccessKey
+	options.SessionToken = sourceValue.SessionToken
+
+	stsAssumeRole := &credentials.STSAssumeRole{
+		STSEndpoint: arp.stsEndpoint,
+		Options:     options,
+	}
+
+	value, err := stsAssumeRole.RetrieveWithCredContext(cc)
+	if err != nil {
+		return credentials.Value{}, err
+	}
+
+	arp.SetExpiration(value.Expiration, credentials.DefaultExpiryWindow)
+
+	return value, nil
+}
+
+// Retrieve assumes the role with source credentials.
+func (arp *assumeRoleProvider) Retrieve() (credentials.Value, error) {
+	return arp.RetrieveWithCredContext(nil)
+}
+
+func (ac AuthCredentials) parseAssumeRole() (*credentials.Credentials, error) {
+	roleARN, err := getEnvStringOrDefault(ac.RoleARN, "roleArn")
+	if err != nil {
+		return nil, err
+	}
+
+	if roleARN == "" {
+		return nil, errRequireRoleARN
+	}
+
+	roleSessionName, err := getEnvStringOrDefault(ac.RoleSessionName, "roleSessionName")
+	if err != nil {
+		return nil, err
+	}
+
+	externalID, err := getEnvStringOrDefault(ac.ExternalID, "externalId")
+	if err != nil {
+		return nil, err
+	}
+
+	stsEndpoint, err := ac.getSTSEndpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	// use static source credentials if the access key is set. Otherwise, use the IAM role of the environment.
+	var source *credentials.Credentials
+	if ac.AccessKeyID != nil || ac.SecretAccessKey != nil {
+		source, err = ac.parseStaticAccessIDSecret()
+	} else {
+		source, err = ac.parseIAMAuth()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	options := credentials.STSAssumeRoleOptions{
+		RoleARN:         roleARN,
+		RoleSessionName: roleSessionName,
+		ExternalID:      externalID,
+	}
+
+	if ac.DurationSeconds != nil {
+		options.DurationSeconds = *ac.DurationSeconds
+	}
+
+	return credentials.New(&assumeRoleProvider{
+		source:      source,
+		stsEndpoint: stsEndpoint,
+		options:     options,
+	}), nil
+}
+
+func (ac AuthCredentials) parseWebIdentity() (*credentials.Credentials, error) {
+	roleARN, err := getEnvStringOrDefault(ac.RoleARN, "roleArn")
+	if err != nil {
+		return nil, err
+	}
+
+	if roleARN == "" {
+		return nil, errRequireRoleARN
+	}
+
+	tokenFile, err := getEnvStringOrDefault(ac.WebIdentityTokenFile, "webIdentityTokenFile")
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenFile == "" {
+		return nil, errRequireWebIdentityTokenFile
+	}
+
+	stsEndpoint, err := ac.getSTSEndpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	var durationSeconds int
+	if ac.DurationSeconds != nil {
+		durationSeconds = *ac.DurationSeconds
+	}
+
+	// the token file is read on every refresh because it is rotated by the orchestrator, e.g. Kubernetes.
+	getToken := func() (*credentials.WebIdentityToken, error) {
+		token, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the web identity token file: %w", err)
+		}
+
+		return &credentials.WebIdentityToken{
+			Token:  string(token),
+			Expiry: durationSeconds,
+		}, nil
+	}
+
+	return credentials.NewSTSWebIdentity(
+		stsEndpoint,
+		getToken,
+		func(swi *credentials.STSWebIdentity) {
+			swi.RoleARN = roleARN
+		},
+	)
+}
+
+func (ac AuthCredentials) parseSharedCredentials() (*credentials.Credentials, error) {
+	credentialsFile, err := getEnvStringOrDefault(ac.CredentialsFile, "credentialsFile")
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := getEnvStringOrDefault(ac.Profile, "profile")
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewFileAWSCredentials(credentialsFile, profile), nil
+}
+
+func (ac AuthCredentials) getSTSEndpoint() (string, error) {
+	stsEndpoint, err := getEnvStringOrDefault(ac.STSEndpoint, "stsEndpoint")
+	if err != nil {
+		return "", err
+	}
+
+	if stsEndpoint == "" {
+		return defaultSTSEndpoint, nil
+	}
+
+	return stsEndpoint, nil
+}
+
+func getEnvStringOrDefault(value *utils.EnvString, name string) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+
+	result, err := value.GetOrDefault("")
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package minio
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"gotest.tools/v3/assert"
+)
+
+func TestAssumeRoleCredentials(t *testing.T) {
+	var requestForm map[string][]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NilError(t, r.ParseForm())
+		requestForm = r.PostForm
+
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = w.Write([]byte(`<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>ASIATEST</AccessKeyId>
+      <SecretAccessKey>assumed-secret</SecretAccessKey>
+      <SessionToken>assumed-token</SessionToken>
+      <Expiration>2099-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleResult>
+</AssumeRoleResponse>`))
+	}))
+	defer server.Close()
+
+	creds, err := AuthCredentials{
+		Type:            AuthTypeAssumeRole,
+		AccessKeyID:     utils.ToPtr(utils.NewEnvStringValue("source-key")),
+		SecretAccessKey: utils.ToPtr(utils.NewEnvStringValue("source-secret")),
+		RoleARN:         utils.ToPtr(utils.NewEnvStringValue("arn:aws:iam::123456789012:role/test")),
+		RoleSessionName: utils.ToPtr(utils.NewEnvStringValue("ndc-storage")),
+		ExternalID:      utils.ToPtr(utils.NewEnvStringValue("external-id")),
+		STSEndpoint:     utils.ToPtr(utils.NewEnvStringValue(server.URL)),
+	}.toCredentials()
+	assert.NilError(t, err)
+
+	value, err := creds.Get()
+	assert.NilError(t, err)
+	assert.Equal(t, value.AccessKeyID, "ASIATEST")
+	assert.Equal(t, value.SecretAccessKey, "assumed-secret")
+	assert.Equal(t, value.SessionToken, "assumed-token")
+	assert.DeepEqual(t, requestForm["RoleArn"], []string{"arn:aws:iam::123456789012:role/test"})
+	assert.DeepEqual(t, requestForm["RoleSessionName"], []string{"ndc-storage"})
+	assert.DeepEqual(t, requestForm["ExternalId"], []string{"external-id"})
+
+	_, err = AuthCredentials{Type: AuthTypeAssumeRole}.toCredentials()
+	assert.ErrorIs(t, err, errRequireRoleARN)
+}
+
+func TestSharedCredentials(t *testing.T) {
+	credentialsFile := filepath.Join(t.TempDir(), "credentials")
+	assert.NilError(t, os.WriteFile(credentialsFile, []byte(`[default]
+aws_access_key_id = default-key
+aws_secret_access_key = default-secret
+
+[cross-account]
+aws_access_key_id = cross-key
+aws_secret_access_key = cross-secret
+aws_session_token = cross-token
+`), 0o600))
+
+	creds, err := AuthCredentials{
+		Type:            AuthTypeSharedCredentials,
+		CredentialsFile: utils.ToPtr(utils.NewEnvStringValue(credentialsFile)),
+		Profile:         utils.ToPtr(utils.NewEnvStringValue("cross-account")),
+	}.toCredentials()
+	assert.NilError(t, err)
+
+	value, err := creds.Get()
+	assert.NilError(t, err)
+	assert.Equal(t, value.AccessKeyID, "cross-key")
+	assert.Equal(t, value.SecretAccessKey, "cross-secret")
+	assert.Equal(t, value.SessionToken, "cross-token")
+}
+
+func TestWebIdentityCredentials(t *testing.T) {
+	_, err := AuthCredentials{
+		Type:    AuthTypeWebIdentity,
+		RoleARN: utils.ToPtr(utils.NewEnvStringValue("arn:aws:iam::123456789012:role/test")),
+	}.toCredentials()
+	assert.ErrorIs(t, err, errRequireWebIdentityTokenFile)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"github.com/hasura/ndc-sdk-go/v2/connector"
@@ -33,22 +34,26 @@ func (mc *Client) ListObjects(
 	logger := connector.GetLogger(ctx)
 	maxResults := opts.MaxResults
 
-	objChan := mc.client.ListObjects(ctx, bucketName, mc.validateListObjectsOptions(span, opts))
-	minioObjects := []minio.ObjectInfo{}
+	// stop listing once the page is full.
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	for obj := range objChan {
-		if obj.Err != nil {
-			span.SetStatus(codes.Error, obj.Err.Error())
-			span.RecordError(obj.Err)
+	objChan := mc.client.ListObjects(listCtx, bucketName, mc.validateListObjectsOptions(span, opts))
 
-			return nil, serializeErrorResponse(obj.Err)
-		}
-
-		if predicate != nil && !predicate(obj.Key) {
-			continue
-		}
+	minioObjects, err := listObjectsPage(
+		ctx,
+		objChan,
+		opts,
+		predicate,
+		func(ctx context.Context, obj *minio.ObjectInfo) (bool, error) {
+			return mc.isRestoreOngoing(ctx, bucketName, obj)
+		},
+	)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
 
-		minioObjects = append(minioObjects, obj)
+		return nil, serializeErrorResponse(err)
 	}
 
 	if len(minioObjects) == 0 {
@@ -118,7 +123,7 @@ func (mc *Client) ListObjects(
 		lhFunc(object, i)
 	}
 
-	err := eg.Wait()
+	err = eg.Wait()
 	if err != nil {
 		logger.Error("failed to include object data: " + err.Error())
 		span.AddEvent(
@@ -227,7 +232,11 @@ func (mc *Client) GetObject(
 	defer span.End()
 
 	span.SetAttributes(attribute.String("storage.key", objectName))
-	options := serializeGetObjectOptions(span, opts)
+
+	options, err := serializeGetObjectOptions(span, opts)
+	if err != nil {
+		return nil, err
+	}
 
 	object, err := mc.client.GetObject(ctx, bucketName, objectName, options)
 	if err != nil {
@@ -237,6 +246,18 @@ func (mc *Client) GetObject(
 		return nil, evalNotFoundError(err, objectNotFoundErrorCode)
 	}
 
+	if opts.IfNoneMatch != "" {
+		// the object is downloaded lazily. Send the request now so unmodified objects fail early.
+		if _, err := object.Stat(); err != nil {
+			_ = object.Close()
+
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+
+			return nil, evalNotFoundError(err, objectNotFoundErrorCode)
+		}
+	}
+
 	return object, nil
 }
 
@@ -281,6 +302,13 @@ func (mc *Client) PutObject(
 		options.Expires = *opts.Expires
 	}
 
+	sse, err := serializeServerSideEncryption(span, opts.ServerSideEncryption)
+	if err != nil {
+		return nil, err
+	}
+
+	options.ServerSideEncryption = sse
+
 	if opts.Retention != nil {
 		options.Mode = validateObjectRetentionMode(opts.Retention.Mode)
 		options.RetainUntilDate = opts.Retention.RetainUntilDate
@@ -302,6 +330,29 @@ func (mc *Client) PutObject(
 		options.AutoChecksum = parseChecksumType(*opts.AutoChecksum)
 	}
 
+	if opts.IfMatch != "" {
+		span.SetAttributes(attribute.String("storage.options.if_match", opts.IfMatch))
+		options.SetMatchETag(common.NormalizeETag(opts.IfMatch))
+	}
+
+	if opts.IfNoneMatch != "" {
+		span.SetAttributes(attribute.String("storage.options.if_none_match", opts.IfNoneMatch))
+
+		// S3 only accepts the wildcard on uploads to prevent overwriting existing objects.
+		if opts.IfNoneMatch != "*" {
+			err := common.NewStorageError(
+				common.ErrorCodeInvalidArgument,
+				"if_none_match of uploads only supports the wildcard (*)",
+				nil,
+			)
+			span.SetStatus(codes.Error, err.Error())
+
+			return nil, err
+		}
+
+		options.SetMatchETagExcept(opts.IfNoneMatch)
+	}
+
 	object, err := mc.client.PutObject(ctx, bucketName, objectName, reader, objectSize, options)
 	if err != nil {
 		span.SetStatus(codes.Error, err.Error())
@@ -332,8 +383,15 @@ func (mc *Client) CopyObject(
 		attribute.String("storage.copy_source", src.Name),
 	)
 
-	destOptions := convertCopyDestOptions(dest)
-	srcOptions := serializeCopySourceOptions(src)
+	destOptions, err := convertCopyDestOptions(span, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	srcOptions, err := serializeCopySourceOptions(span, src)
+	if err != nil {
+		return nil, err
+	}
 
 	object, err := mc.client.CopyObject(ctx, *destOptions, srcOptions)
 	if err != nil {
@@ -365,13 +423,21 @@ func (mc *Client) ComposeObject(
 
 	for i, src := range sources {
 		srcKeys[i] = src.Name
-		source := serializeCopySourceOptions(src)
+
+		source, err := serializeCopySourceOptions(span, src)
+		if err != nil {
+			return nil, err
+		}
+
 		srcOptions[i] = source
 	}
 
 	span.SetAttributes(attribute.StringSlice("storage.copy_sources", srcKeys))
 
-	destOptions := convertCopyDestOptions(dest)
+	destOptions, err := convertCopyDestOptions(span, dest)
+	if err != nil {
+		return nil, err
+	}
 
 	object, err := mc.client.ComposeObject(ctx, *destOptions, srcOptions...)
 	if err != nil {
@@ -397,7 +463,11 @@ func (mc *Client) StatObject(
 	defer span.End()
 
 	span.SetAttributes(attribute.String("storage.key", objectName))
-	options := serializeGetObjectOptions(span, opts)
+
+	options, err := serializeGetObjectOptions(span, opts)
+	if err != nil {
+		return nil, err
+	}
 
 	object, err := mc.client.StatObject(ctx, bucketName, objectName, options)
 	if err != nil {
@@ -526,6 +596,20 @@ func (mc *Client) UpdateObject(
 		span.SetAttributes(attribute.String("storage.options.version", opts.VersionID))
 	}
 
+	if opts.HasPrecondition() {
+		versionID, err := mc.validateObjectPrecondition(ctx, bucketName, objectName, opts)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			span.RecordError(err)
+
+			return err
+		}
+
+		// pin the validated version so that a concurrent upload to a versioned bucket
+		// creates a new version rather than receiving the changes.
+		opts.VersionID = versionID
+	}
+
 	if opts.LegalHold != nil {
 		err := mc.SetObjectLegalHold(ctx, bucketName, objectName, opts.VersionID, opts.LegalHold)
 		if err != nil {
@@ -556,11 +640,198 @@ func (mc *Client) UpdateObject(
 	return nil
 }
 
+// validateObjectPrecondition checks conditional options before updating the object
+// and returns the version ID of the validated object.
+// S3 doesn't support conditional headers on tagging, retention and legal hold APIs.
+// The ETag of the current object is compared before updating instead. The validated version is
+// updated in versioned buckets. Otherwise, a concurrent upload may still be updated.
+func (mc *Client) validateObjectPrecondition(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.UpdateStorageObjectOptions,
+) (string, error) {
+	options := minio.StatObjectOptions{}
+	options.VersionID = opts.VersionID
+
+	var currentETag *string
+
+	versionID := opts.VersionID
+
+	object, err := mc.client.StatObject(ctx, bucketName, objectName, options)
+	if err != nil {
+		if respErr := evalNotFoundError(err, objectNotFoundErrorCode); respErr != nil {
+			return "", respErr
+		}
+	} else {
+		currentETag = &object.ETag
+
+		if versionID == "" && object.VersionID != "null" {
+			versionID = object.VersionID
+		}
+	}
+
+	return versionID, common.ValidateETagPrecondition(currentETag, opts.IfMatch, opts.IfNoneMatch)
+}
+
 // RestoreObject restores a soft-deleted object.
 func (mc *Client) RestoreObject(ctx context.Context, bucketName string, objectName string) error {
 	return schema.NotSupportedError("MinIO does not support this function", nil)
 }
 
+// RestoreArchivedObject restores a temporary copy of an archived object.
+func (mc *Client) RestoreArchivedObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.RestoreArchivedStorageObjectOptions,
+) error {
+	ctx, span := mc.startOtelSpan(ctx, "RestoreArchivedObject", bucketName)
+	defer span.End()
+
+	span.SetAttributes(attribute.String("storage.key", objectName))
+
+	if opts.VersionID != "" {
+		span.SetAttributes(attribute.String("storage.options.version", opts.VersionID))
+	}
+
+	if opts.Days == nil || *opts.Days <= 0 {
+		return schema.UnprocessableContentError("days is required and must be positive", nil)
+	}
+
+	span.SetAttributes(attribute.Int("storage.options.days", *opts.Days))
+
+	req := minio.RestoreRequest{}
+	req.SetDays(*opts.Days)
+
+	if opts.Tier != nil {
+		span.SetAttributes(attribute.String("storage.options.tier", string(*opts.Tier)))
+		req.SetGlacierJobParameters(minio.GlacierJobParameters{
+			Tier: minio.TierType(*opts.Tier),
+		})
+	}
+
+	err := mc.client.RestoreObject(ctx, bucketName, objectName, opts.VersionID, req)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+
+		return serializeErrorResponse(err)
+	}
+
+	return nil
+}
+
+// listObjectsPage reads objects from the listing channel until the page is full.
+// The restore status of archived objects is fetched in batches with bounded concurrency
+// if the ongoing restore filter is set, so that the bucket isn't stat-ed object by object.
+func listObjectsPage(
+	ctx context.Context,
+	objChan <-chan minio.ObjectInfo,
+	opts *common.ListStorageObjectsOptions,
+	predicate func(string) bool,
+	isRestoreOngoing func(ctx context.Context, obj *minio.ObjectInfo) (bool, error),
+) ([]minio.ObjectInfo, error) {
+	results := []minio.ObjectInfo{}
+	// read one more object than the page size to detect the next page.
+	isFull := func() bool {
+		return opts.MaxResults > 0 && len(results) > opts.MaxResults
+	}
+
+	concurrency := defaultRestoreStatConcurrency
+	if opts.NumThreads > 1 {
+		concurrency = opts.NumThreads
+	}
+
+	batch := make([]minio.ObjectInfo, 0, concurrency)
+
+	flush := func() error {
+		matches := make([]bool, len(batch))
+
+		eg, egCtx := errgroup.WithContext(ctx)
+		eg.SetLimit(concurrency)
+
+		for i := range batch {
+			eg.Go(func() error {
+				ongoing, err := isRestoreOngoing(egCtx, &batch[i])
+				matches[i] = err == nil && ongoing == *opts.OngoingRestore
+
+				return err
+			})
+		}
+
+		if err := eg.Wait(); err != nil {
+			return err
+		}
+
+		for i, obj := range batch {
+			if matches[i] && !isFull() {
+				results = append(results, obj)
+			}
+		}
+
+		batch = batch[:0]
+
+		return nil
+	}
+
+	for obj := range objChan {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+
+		if predicate != nil && !predicate(obj.Key) {
+			continue
+		}
+
+		if opts.OngoingRestore == nil {
+			results = append(results, obj)
+		} else if batch = append(batch, obj); len(batch) >= concurrency {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+
+		if isFull() {
+			return results, nil
+		}
+	}
+
+	if len(batch) > 0 {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// isRestoreOngoing checks if the restore of an archived object is in progress.
+// The restore status isn't returned by the list API so archived objects are stat-ed one by one.
+func (mc *Client) isRestoreOngoing(
+	ctx context.Context,
+	bucketName string,
+	obj *minio.ObjectInfo,
+) (bool, error) {
+	if obj.Restore != nil {
+		return obj.Restore.OngoingRestore, nil
+	}
+
+	if !slices.Contains(archiveStorageClasses, obj.StorageClass) {
+		return false, nil
+	}
+
+	stat, err := mc.client.StatObject(ctx, bucketName, obj.Key, minio.StatObjectOptions{
+		VersionID: obj.VersionID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	obj.Restore = stat.Restore
+
+	return stat.Restore != nil && stat.Restore.OngoingRestore, nil
+}
+
 // SetObjectRetention applies object retention lock onto an object.
 func (mc *Client) SetObjectRetention(
 	ctx context.Context,
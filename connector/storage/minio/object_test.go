@@ -0,0 +1,101 @@
+package minio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"github.com/minio/minio-go/v7"
+	"gotest.tools/v3/assert"
+)
+
+func TestListObjectsPageOngoingRestore(t *testing.T) {
+	newObjectChan := func(ctx context.Context) <-chan minio.ObjectInfo {
+		objChan := make(chan minio.ObjectInfo)
+
+		go func() {
+			defer close(objChan)
+
+			for i := range 100 {
+				select {
+				case objChan <- minio.ObjectInfo{Key: fmt.Sprintf("%03d", i), StorageClass: "GLACIER"}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		return objChan
+	}
+
+	var lock sync.Mutex
+
+	var statCount, running, maxRunning int
+
+	// every third object is being restored.
+	isRestoreOngoing := func(_ context.Context, obj *minio.ObjectInfo) (bool, error) {
+		lock.Lock()
+		statCount++
+		running++
+		maxRunning = max(maxRunning, running)
+		lock.Unlock()
+
+		defer func() {
+			lock.Lock()
+			running--
+			lock.Unlock()
+		}()
+
+		var index int
+		_, err := fmt.Sscanf(obj.Key, "%d", &index)
+
+		return index%3 == 0, err
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	objects, err := listObjectsPage(ctx, newObjectChan(ctx), &common.ListStorageObjectsOptions{
+		MaxResults:     3,
+		NumThreads:     4,
+		OngoingRestore: utils.ToPtr(true),
+	}, nil, isRestoreOngoing)
+	assert.NilError(t, err)
+
+	// one more object is returned to detect the next page.
+	keys := make([]string, len(objects))
+	for i, obj := range objects {
+		keys[i] = obj.Key
+	}
+
+	assert.DeepEqual(t, keys, []string{"000", "003", "006", "009"})
+	// the listing stops once the page is full.
+	assert.Equal(t, statCount, 12)
+	assert.Assert(t, maxRunning <= 4)
+
+	statCount = 0
+
+	objects, err = listObjectsPage(ctx, newObjectChan(ctx), &common.ListStorageObjectsOptions{
+		OngoingRestore: utils.ToPtr(false),
+	}, func(key string) bool {
+		return key < "010"
+	}, isRestoreOngoing)
+	assert.NilError(t, err)
+	assert.Equal(t, len(objects), 6)
+	assert.Equal(t, statCount, 10)
+}
+
+func TestPutObjectIfNoneMatch(t *testing.T) {
+	client := &Client{providerType: common.StorageProviderTypeS3}
+
+	// the request is rejected before it is sent.
+	_, err := client.PutObject(context.TODO(), "bucket", "a.txt", &common.PutStorageObjectOptions{
+		IfNoneMatch: "abc",
+	}, strings.NewReader("hello"), 5)
+	code, _ := common.GetStorageErrorCode(err)
+	assert.Equal(t, code, common.ErrorCodeInvalidArgument)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/hasura/ndc-sdk-go/v2/utils"
 	"github.com/hasura/ndc-storage/connector/storage/common"
 	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
 	"github.com/minio/minio-go/v7/pkg/notification"
 	"github.com/minio/minio-go/v7/pkg/sse"
 	"go.opentelemetry.io/otel/attribute"
@@ -19,8 +20,13 @@ import (
 const (
 	objectNotFoundErrorCode  = "NoSuchKey"
 	userMetadataHeaderPrefix = "x-amz-meta-"
+	// the number of concurrent requests to fetch the restore status of archived objects.
+	defaultRestoreStatConcurrency = 8
 )
 
+// storage classes of archived objects that must be restored before being read.
+var archiveStorageClasses = []string{"GLACIER", "DEEP_ARCHIVE"}
+
 func serializeGrant(grant minio.Grant) common.StorageGrant {
 	g := common.StorageGrant{}
 
@@ -301,11 +307,18 @@ func serializeUploadObjectInfo(obj minio.UploadInfo) common.StorageUploadInfo {
 func serializeGetObjectOptions(
 	span trace.Span,
 	opts common.GetStorageObjectOptions,
-) minio.GetObjectOptions {
+) (minio.GetObjectOptions, error) {
 	options := minio.GetObjectOptions{
 		Checksum: opts.Include.Checksum,
 	}
 
+	sse, err := serializeServerSideEncryption(span, opts.ServerSideEncryption)
+	if err != nil {
+		return options, err
+	}
+
+	options.ServerSideEncryption = sse
+
 	span.SetAttributes(attribute.Bool("storage.request_object_checksum", options.Checksum))
 
 	if opts.VersionID != nil && !isStringNull(*opts.VersionID) {
@@ -318,6 +331,14 @@ func serializeGetObjectOptions(
 		span.SetAttributes(attribute.Int("storage.part_number", options.PartNumber))
 	}
 
+	if opts.IfNoneMatch != "" {
+		span.SetAttributes(attribute.String("storage.options.if_none_match", opts.IfNoneMatch))
+
+		if err := options.SetMatchETagExcept(common.NormalizeETag(opts.IfNoneMatch)); err != nil {
+			return options, schema.UnprocessableContentError(err.Error(), nil)
+		}
+	}
+
 	for _, item := range opts.Headers {
 		span.SetAttributes(
 			attribute.StringSlice("http.request.header."+item.Key, []string{item.Value}),
@@ -336,10 +357,13 @@ func serializeGetObjectOptions(
 		span.SetAttributes(attribute.String("url.query", q.Encode()))
 	}
 
-	return options
+	return options, nil
 }
 
-func serializeCopySourceOptions(src common.StorageCopySrcOptions) minio.CopySrcOptions {
+func serializeCopySourceOptions(
+	span trace.Span,
+	src common.StorageCopySrcOptions,
+) (minio.CopySrcOptions, error) {
 	srcOptions := minio.CopySrcOptions{
 		Bucket:      src.Bucket,
 		Object:      src.Name,
@@ -359,10 +383,20 @@ func serializeCopySourceOptions(src common.StorageCopySrcOptions) minio.CopySrcO
 		srcOptions.MatchUnmodifiedSince = *src.MatchUnmodifiedSince
 	}
 
-	return srcOptions
+	sse, err := serializeServerSideEncryption(span, src.Encryption)
+	if err != nil {
+		return srcOptions, err
+	}
+
+	srcOptions.Encryption = sse
+
+	return srcOptions, nil
 }
 
-func convertCopyDestOptions(dst common.StorageCopyDestOptions) *minio.CopyDestOptions {
+func convertCopyDestOptions(
+	span trace.Span,
+	dst common.StorageCopyDestOptions,
+) (*minio.CopyDestOptions, error) {
 	destOptions := minio.CopyDestOptions{
 		Bucket:          dst.Bucket,
 		Object:          dst.Name,
@@ -382,7 +416,59 @@ func convertCopyDestOptions(dst common.StorageCopyDestOptions) *minio.CopyDestOp
 		destOptions.Mode = validateObjectRetentionMode(*dst.Mode)
 	}
 
-	return &destOptions
+	sse, err := serializeServerSideEncryption(span, dst.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	destOptions.Encryption = sse
+
+	return &destOptions, nil
+}
+
+func serializeServerSideEncryption(
+	span trace.Span,
+	input *common.ServerSideEncryption,
+) (encrypt.ServerSide, error) {
+	if input == nil {
+		return nil, nil
+	}
+
+	span.SetAttributes(attribute.String("storage.server_side_encryption", string(input.Method)))
+
+	switch input.Method {
+	case common.ServerSideEncryptionMethodSseC:
+		key, err := input.CustomerKey()
+		if err != nil {
+			return nil, err
+		}
+
+		sse, err := encrypt.NewSSEC(key)
+		if err != nil {
+			return nil, schema.UnprocessableContentError(err.Error(), nil)
+		}
+
+		return sse, nil
+	case common.ServerSideEncryptionMethodKms:
+		keyID, err := input.GetKMSKeyID()
+		if err != nil {
+			return nil, err
+		}
+
+		sse, err := encrypt.NewSSEKMS(keyID, nil)
+		if err != nil {
+			return nil, schema.UnprocessableContentError(err.Error(), nil)
+		}
+
+		return sse, nil
+	case common.ServerSideEncryptionMethodS3:
+		return encrypt.NewSSE(), nil
+	default:
+		return nil, schema.UnprocessableContentError(
+			"unsupported server-side encryption method: "+string(input.Method),
+			nil,
+		)
+	}
 }
 
 func validateLegalHoldStatus(input *bool) minio.LegalHoldStatus {
@@ -639,11 +725,27 @@ func evalMinioErrorResponse(err minio.ErrorResponse) *schema.ConnectorError {
 		details["region"] = err.Region
 	}
 
-	if err.StatusCode >= 500 {
-		return schema.NewConnectorError(err.StatusCode, err.Message, details)
-	}
+	return common.NewStorageError(evalMinioErrorCode(err), err.Message, details)
+}
 
-	return schema.UnprocessableContentError(err.Message, details)
+// evalMinioErrorCode maps the error code of the S3 response to the stable code of storage errors.
+func evalMinioErrorCode(err minio.ErrorResponse) common.StorageErrorCode {
+	switch err.Code {
+	case "NoSuchKey", "NoSuchBucket", "NoSuchVersion", "NoSuchUpload":
+		return common.ErrorCodeNotFound
+	case "BucketAlreadyExists", "BucketAlreadyOwnedByYou":
+		return common.ErrorCodeAlreadyExists
+	case "PreconditionFailed", "NotModified":
+		return common.ErrorCodePreconditionFailed
+	case "AccessDenied", "InvalidAccessKeyId", "SignatureDoesNotMatch", "ExpiredToken", "InvalidToken":
+		return common.ErrorCodeAccessDenied
+	case "SlowDown", "SlowDownRead", "SlowDownWrite", "RequestLimitExceeded", "TooManyRequests":
+		return common.ErrorCodeThrottled
+	case "BucketNotEmpty", "InvalidObjectState":
+		return common.ErrorCodeInvalidArgument
+	default:
+		return common.EvalStorageErrorCode(err.StatusCode)
+	}
 }
 
 func serializeErrorResponse(err error) error {
@@ -657,6 +759,10 @@ func serializeErrorResponse(err error) error {
 		return evalMinioErrorResponse(*errRespPtr)
 	}
 
+	if common.IsTransientError(err) {
+		return common.NewTransientError(err)
+	}
+
 	return schema.UnprocessableContentError(err.Error(), nil)
 }
 
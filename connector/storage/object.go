@@ -1,9 +1,12 @@
 package storage
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"log/slog"
 	"mime"
@@ -18,6 +21,9 @@ import (
 	"github.com/minio/minio-go/v7/pkg/s3utils"
 )
 
+// defaultStreamPartSize is the default part size of multipart uploads for streams of unknown length.
+const defaultStreamPartSize = 16 * 1024 * 1024
+
 // ListObjects lists objects in a bucket.
 func (m *Manager) ListObjects(
 	ctx context.Context,
@@ -32,6 +38,21 @@ func (m *Manager) ListObjects(
 		}, nil
 	}
 
+	opts.Prefix, predicate, err = m.policy.AuthorizeList(
+		ctx,
+		PolicyOperationRead,
+		string(client.id),
+		bucketName,
+		opts.Prefix,
+		opts.Recursive,
+		predicate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	predicate = m.hideTransactionSnapshots(predicate)
+
 	results, err := client.ListObjects(ctx, bucketName, opts, predicate)
 	if err != nil {
 		return nil, err
@@ -57,6 +78,21 @@ func (m *Manager) ListDeletedObjects(
 		return nil, err
 	}
 
+	opts.Prefix, predicate, err = m.policy.AuthorizeList(
+		ctx,
+		PolicyOperationRead,
+		string(client.id),
+		bucketName,
+		opts.Prefix,
+		opts.Recursive,
+		predicate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	predicate = m.hideTransactionSnapshots(predicate)
+
 	results, err := client.ListDeletedObjects(ctx, bucketName, opts, predicate)
 	if err != nil {
 		return nil, err
@@ -81,7 +117,35 @@ func (m *Manager) ListIncompleteUploads(
 		return nil, err
 	}
 
-	return client.ListIncompleteUploads(ctx, bucketName, opts)
+	prefix, predicate, err := m.policy.AuthorizeList(
+		ctx,
+		PolicyOperationRead,
+		string(client.id),
+		bucketName,
+		opts.Prefix,
+		true,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Prefix = prefix
+
+	results, err := client.ListIncompleteUploads(ctx, bucketName, opts)
+	if err != nil || predicate == nil {
+		return results, err
+	}
+
+	filteredResults := make([]common.StorageObjectMultipartInfo, 0, len(results))
+
+	for _, item := range results {
+		if item.Name != nil && predicate(*item.Name) {
+			filteredResults = append(filteredResults, item)
+		}
+	}
+
+	return filteredResults, nil
 }
 
 // GetObject returns a stream of the object data. Most of the common errors occur when reading the stream.
@@ -96,27 +160,51 @@ func (m *Manager) GetObject(
 		return nil, nil, err
 	}
 
+	err = m.authorizeObject(ctx, PolicyOperationRead, client, bucketName, objectName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if client.contentCache != nil && isContentCacheable(opts) {
+		return m.getObjectWithContentCache(ctx, client, bucketName, objectName, opts)
+	}
+
 	objectStat, err := m.statObject(ctx, client, bucketName, objectName, opts)
 	if err != nil || objectStat == nil {
 		return nil, nil, err
 	}
 
+	if err := m.validateDownload(objectStat, objectName, opts); err != nil {
+		return nil, nil, err
+	}
+
+	content, err := client.GetObject(ctx, bucketName, objectName, opts)
+
+	return objectStat, content, err
+}
+
+// validateDownload checks if the object can be downloaded directly.
+func (m *Manager) validateDownload(
+	objectStat *common.StorageObject,
+	objectName string,
+	opts common.GetStorageObjectOptions,
+) error {
 	if opts.PreValidate != nil {
 		err := opts.PreValidate(objectStat)
 		if err != nil {
-			return nil, nil, schema.UnprocessableContentError(err.Error(), nil)
+			return schema.UnprocessableContentError(err.Error(), nil)
 		}
 	}
 
 	if objectStat.IsDirectory {
-		return nil, nil, schema.UnprocessableContentError(
+		return schema.UnprocessableContentError(
 			"cannot download directory: "+objectName,
 			nil,
 		)
 	}
 
 	if objectStat.Size == nil || *objectStat.Size > m.runtime.MaxDownloadSizeMBs*1024*1024 {
-		return nil, nil, schema.UnprocessableContentError(
+		return schema.UnprocessableContentError(
 			fmt.Sprintf(
 				"file size > %d MB is not allowed to be downloaded directly. Please use presignedGetObject function for large files",
 				m.runtime.MaxDownloadSizeMBs,
@@ -125,9 +213,7 @@ func (m *Manager) GetObject(
 		)
 	}
 
-	content, err := client.GetObject(ctx, bucketName, objectName, opts)
-
-	return objectStat, content, err
+	return nil
 }
 
 // PutObject uploads objects that are less than 128MiB in a single PUT operation. For objects that are greater than 128MiB in size,
@@ -138,19 +224,47 @@ func (m *Manager) PutObject(
 	objectName string,
 	opts *common.PutStorageObjectOptions,
 	data []byte,
-) (*common.StorageUploadInfo, error) {
+) (result *common.StorageUploadInfo, err error) {
+	record := m.audit.begin(ctx, AuditOperationUploadObject, bucketInfo.Bucket, objectName)
+	defer func() { record.end(err) }()
+
 	client, bucketName, err := m.GetClientAndBucket(ctx, bucketInfo)
 	if err != nil {
 		return nil, err
 	}
 
+	record.setTarget(client, bucketName)
+
+	err = m.authorizeObject(ctx, PolicyOperationWrite, client, bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
 	contentLength := int64(len(data))
 	if contentLength > m.runtime.MaxUploadSizeMBs*1024*1024 {
 		return nil, maxUploadSizeLimitError(m.runtime.MaxUploadSizeMBs)
 	}
 
-	result, err := client.PutObject(
-		ctx,
+	if uploadPolicy := client.getUploadPolicy(bucketName); uploadPolicy != nil {
+		if err := uploadPolicy.ValidateUpload(opts, data, contentLength); err != nil {
+			return nil, err
+		}
+	}
+
+	applySnapshot, err := m.snapshotObject(ctx, client, bucketName, objectName, opts.ServerSideEncryption)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadCtx, done, err := m.beginUpload(ctx, client, bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer done()
+
+	result, err = client.PutObject(
+		uploadCtx,
 		bucketName,
 		objectName,
 		opts,
@@ -161,8 +275,11 @@ func (m *Manager) PutObject(
 		return nil, err
 	}
 
+	applySnapshot()
+
 	result.Bucket = bucketName
 	result.ClientID = string(client.id)
+	record.setUploadInfo(result)
 
 	return result, nil
 }
@@ -173,7 +290,10 @@ func (m *Manager) PutObject(
 func (m *Manager) CopyObject(
 	ctx context.Context,
 	args *common.CopyStorageObjectArguments,
-) (*common.StorageUploadInfo, error) {
+) (result *common.StorageUploadInfo, err error) {
+	record := m.audit.begin(ctx, AuditOperationCopyObject, args.Dest.Bucket, args.Dest.Name)
+	defer func() { record.end(err) }()
+
 	client, bucketName, err := m.GetClientAndBucket(ctx, common.StorageBucketArguments{
 		StorageClientCredentialArguments: common.StorageClientCredentialArguments{
 			ClientID: args.ClientID,
@@ -184,18 +304,50 @@ func (m *Manager) CopyObject(
 		return nil, err
 	}
 
+	record.setTarget(client, bucketName)
+
 	args.Dest.Bucket = bucketName
 
 	if args.Source.Bucket == "" {
 		args.Source.Bucket = client.defaultBucket
 	}
 
-	result, err := client.CopyObject(ctx, args.Dest, args.Source)
+	err = m.authorizeObject(ctx, PolicyOperationRead, client, args.Source.Bucket, args.Source.Name)
 	if err != nil {
 		return nil, err
 	}
 
+	err = m.authorizeObject(ctx, PolicyOperationWrite, client, bucketName, args.Dest.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateCopyUploadPolicy(ctx, client, args.Dest, []common.StorageCopySrcOptions{args.Source})
+	if err != nil {
+		return nil, err
+	}
+
+	applySnapshot, err := m.snapshotObject(ctx, client, bucketName, args.Dest.Name, args.Dest.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadCtx, done, err := m.beginUpload(ctx, client, bucketName, args.Dest.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer done()
+
+	result, err = client.CopyObject(uploadCtx, args.Dest, args.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	applySnapshot()
+
 	result.ClientID = string(client.id)
+	record.setUploadInfo(result)
 
 	return result, nil
 }
@@ -204,7 +356,10 @@ func (m *Manager) CopyObject(
 func (m *Manager) ComposeObject(
 	ctx context.Context,
 	args *common.ComposeStorageObjectArguments,
-) (*common.StorageUploadInfo, error) {
+) (result *common.StorageUploadInfo, err error) {
+	record := m.audit.begin(ctx, AuditOperationComposeObject, args.Dest.Bucket, args.Dest.Name)
+	defer func() { record.end(err) }()
+
 	client, bucketName, err := m.GetClientAndBucket(ctx, common.StorageBucketArguments{
 		StorageClientCredentialArguments: common.StorageClientCredentialArguments{
 			ClientID: args.ClientID,
@@ -215,6 +370,13 @@ func (m *Manager) ComposeObject(
 		return nil, err
 	}
 
+	record.setTarget(client, bucketName)
+
+	err = m.authorizeObject(ctx, PolicyOperationWrite, client, bucketName, args.Dest.Name)
+	if err != nil {
+		return nil, err
+	}
+
 	args.Dest.Bucket = bucketName
 	srcs := make([]common.StorageCopySrcOptions, len(args.Sources))
 
@@ -223,19 +385,147 @@ func (m *Manager) ComposeObject(
 			src.Bucket = client.defaultBucket
 		}
 
+		err = m.authorizeObject(ctx, PolicyOperationRead, client, src.Bucket, src.Name)
+		if err != nil {
+			return nil, err
+		}
+
 		srcs[i] = src
 	}
 
-	result, err := client.ComposeObject(ctx, args.Dest, srcs)
+	err = validateCopyUploadPolicy(ctx, client, args.Dest, srcs)
+	if err != nil {
+		return nil, err
+	}
+
+	applySnapshot, err := m.snapshotObject(ctx, client, bucketName, args.Dest.Name, args.Dest.Encryption)
 	if err != nil {
 		return nil, err
 	}
 
+	uploadCtx, done, err := m.beginUpload(ctx, client, bucketName, args.Dest.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer done()
+
+	result, err = client.ComposeObject(uploadCtx, args.Dest, srcs)
+	if err != nil {
+		return nil, err
+	}
+
+	applySnapshot()
+
 	result.ClientID = string(client.id)
+	record.setUploadInfo(result)
 
 	return result, nil
 }
 
+// validateCopyUploadPolicy checks source objects against the upload policy of the destination bucket,
+// because the content of server-side copies doesn't pass through the connector.
+func validateCopyUploadPolicy(
+	ctx context.Context,
+	client *Client,
+	dest common.StorageCopyDestOptions,
+	sources []common.StorageCopySrcOptions,
+) error {
+	uploadPolicy := client.getUploadPolicy(dest.Bucket)
+	if uploadPolicy == nil || len(sources) == 0 {
+		return nil
+	}
+
+	var size int64
+
+	var firstObject *common.StorageObject
+
+	for i, src := range sources {
+		object, err := client.StatObject(ctx, src.Bucket, src.Name, common.GetStorageObjectOptions{
+			ServerSideEncryption: src.Encryption,
+			VersionID:            copySourceVersionID(src),
+			Include: common.StorageObjectIncludeOptions{
+				Metadata: true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		if object == nil {
+			return common.NewStorageError(common.ErrorCodeNotFound, "source object not found: "+src.Name, nil)
+		}
+
+		switch {
+		case src.MatchRange:
+			size += src.End - src.Start + 1
+		case object.Size != nil:
+			size += *object.Size
+		default:
+		}
+
+		if i == 0 {
+			firstObject = object
+		}
+	}
+
+	// metadata of the source object is copied if the destination metadata is empty.
+	metadata := dest.Metadata
+	if len(metadata) == 0 && len(sources) == 1 {
+		metadata = firstObject.Metadata
+	}
+
+	if err := uploadPolicy.ValidateMetadata(metadata); err != nil {
+		return err
+	}
+
+	if err := uploadPolicy.ValidateSize(size); err != nil {
+		return err
+	}
+
+	if len(uploadPolicy.AllowedContentTypes) == 0 && len(uploadPolicy.DeniedContentTypes) == 0 {
+		return nil
+	}
+
+	// the content type is detected from leading bytes of the first source.
+	reader, err := client.GetObject(ctx, sources[0].Bucket, sources[0].Name, common.GetStorageObjectOptions{
+		ServerSideEncryption: sources[0].Encryption,
+		VersionID:            copySourceVersionID(sources[0]),
+	})
+	if err != nil {
+		return err
+	}
+
+	defer reader.Close()
+
+	if sources[0].MatchRange && sources[0].Start > 0 {
+		if _, err := io.CopyN(io.Discard, reader, sources[0].Start); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+
+	head, err := io.ReadAll(io.LimitReader(reader, common.UploadSniffLength))
+	if err != nil {
+		return err
+	}
+
+	var contentType string
+
+	if firstObject.ContentType != nil {
+		contentType = *firstObject.ContentType
+	}
+
+	return uploadPolicy.ValidateContent(head, contentType)
+}
+
+func copySourceVersionID(src common.StorageCopySrcOptions) *string {
+	if src.VersionID == "" {
+		return nil
+	}
+
+	return &src.VersionID
+}
+
 // StatObject fetches metadata of an object.
 func (m *Manager) StatObject(
 	ctx context.Context,
@@ -248,6 +538,11 @@ func (m *Manager) StatObject(
 		return nil, err
 	}
 
+	err = m.authorizeObject(ctx, PolicyOperationRead, client, bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
 	return m.statObject(ctx, client, bucketName, objectName, opts)
 }
 
@@ -278,13 +573,44 @@ func (m *Manager) RemoveObject(
 	bucketInfo common.StorageBucketArguments,
 	objectName string,
 	opts common.RemoveStorageObjectOptions,
-) error {
+) (err error) {
+	record := m.audit.begin(ctx, AuditOperationRemoveObject, bucketInfo.Bucket, objectName)
+	defer func() { record.end(err) }()
+
+	record.setVersionID(opts.VersionID)
+
 	client, bucketName, err := m.GetClientAndBucket(ctx, bucketInfo)
 	if err != nil {
 		return err
 	}
 
-	return client.RemoveObject(ctx, bucketName, objectName, opts)
+	record.setTarget(client, bucketName)
+
+	err = m.authorizeObject(ctx, PolicyOperationDelete, client, bucketName, objectName)
+	if err != nil {
+		return err
+	}
+
+	if TransactionFromContext(ctx) != nil && opts.VersionID != "" {
+		return schema.UnprocessableContentError(
+			"removing a specific object version can't be reverted in a transaction",
+			nil,
+		)
+	}
+
+	applySnapshot, err := m.snapshotObject(ctx, client, bucketName, objectName, nil)
+	if err != nil {
+		return err
+	}
+
+	err = client.RemoveObject(ctx, bucketName, objectName, opts)
+	if err != nil {
+		return err
+	}
+
+	applySnapshot()
+
+	return nil
 }
 
 // UpdateObject updates object configuration.
@@ -293,17 +619,41 @@ func (m *Manager) UpdateObject(
 	bucketInfo common.StorageBucketArguments,
 	objectName string,
 	opts common.UpdateStorageObjectOptions,
-) error {
+) (err error) {
 	if opts.IsEmpty() {
 		return nil
 	}
 
+	record := m.audit.begin(ctx, AuditOperationUpdateObject, bucketInfo.Bucket, objectName)
+	defer func() { record.end(err) }()
+
+	record.setVersionID(opts.VersionID)
+
 	client, bucketName, err := m.GetClientAndBucket(ctx, bucketInfo)
 	if err != nil {
 		return err
 	}
 
-	return client.UpdateObject(ctx, bucketName, objectName, opts)
+	record.setTarget(client, bucketName)
+
+	err = m.authorizeObject(ctx, PolicyOperationWrite, client, bucketName, objectName)
+	if err != nil {
+		return err
+	}
+
+	applySnapshot, err := m.snapshotObjectAttributes(ctx, client, bucketName, objectName, opts)
+	if err != nil {
+		return err
+	}
+
+	err = client.UpdateObject(ctx, bucketName, objectName, opts)
+	if err != nil {
+		return err
+	}
+
+	applySnapshot()
+
+	return nil
 }
 
 // RemoveObjects remove a list of objects obtained from an input channel. The call sends a delete request to the server up to 1000 objects at a time.
@@ -313,12 +663,40 @@ func (m *Manager) RemoveObjects(
 	bucketInfo common.StorageBucketArguments,
 	opts *common.RemoveStorageObjectsOptions,
 	predicate func(string) bool,
-) ([]common.RemoveStorageObjectError, error) {
+) (errs []common.RemoveStorageObjectError, err error) {
+	// the object field of the event is the prefix of removed objects.
+	record := m.audit.begin(ctx, AuditOperationRemoveObjects, bucketInfo.Bucket, opts.Prefix)
+	defer func() {
+		// partial failures are returned as results but audited as failures.
+		if err == nil && len(errs) > 0 {
+			record.end(fmt.Errorf("failed to remove %d objects", len(errs)))
+
+			return
+		}
+
+		record.end(err)
+	}()
+
 	client, bucketName, err := m.GetClientAndBucket(ctx, bucketInfo)
 	if err != nil {
 		return nil, err
 	}
 
+	record.setTarget(client, bucketName)
+
+	opts.Prefix, predicate, err = m.policy.AuthorizeList(
+		ctx,
+		PolicyOperationDelete,
+		string(client.id),
+		bucketName,
+		opts.Prefix,
+		true,
+		predicate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	return client.RemoveObjects(ctx, bucketName, opts, predicate), nil
 }
 
@@ -327,20 +705,63 @@ func (m *Manager) RestoreObject(
 	ctx context.Context,
 	bucketInfo common.StorageBucketArguments,
 	objectName string,
-) error {
+) (err error) {
+	record := m.audit.begin(ctx, AuditOperationRestoreObject, bucketInfo.Bucket, objectName)
+	defer func() { record.end(err) }()
+
 	client, bucketName, err := m.GetClientAndBucket(ctx, bucketInfo)
 	if err != nil {
 		return err
 	}
 
+	record.setTarget(client, bucketName)
+
+	err = m.authorizeObject(ctx, PolicyOperationWrite, client, bucketName, objectName)
+	if err != nil {
+		return err
+	}
+
 	return client.RestoreObject(ctx, bucketName, objectName)
 }
 
+// RestoreArchivedObject restores an object from the archive storage tier.
+func (m *Manager) RestoreArchivedObject(
+	ctx context.Context,
+	bucketInfo common.StorageBucketArguments,
+	objectName string,
+	opts common.RestoreArchivedStorageObjectOptions,
+) (err error) {
+	record := m.audit.begin(
+		ctx,
+		AuditOperationRestoreArchivedObject,
+		bucketInfo.Bucket,
+		objectName,
+	)
+	defer func() { record.end(err) }()
+
+	client, bucketName, err := m.GetClientAndBucket(ctx, bucketInfo)
+	if err != nil {
+		return err
+	}
+
+	record.setTarget(client, bucketName)
+
+	err = m.authorizeObject(ctx, PolicyOperationWrite, client, bucketName, objectName)
+	if err != nil {
+		return err
+	}
+
+	return client.RestoreArchivedObject(ctx, bucketName, objectName, opts)
+}
+
 // RemoveIncompleteUpload removes a partially uploaded object.
 func (m *Manager) RemoveIncompleteUpload(
 	ctx context.Context,
 	args *common.RemoveIncompleteUploadArguments,
-) error {
+) (err error) {
+	record := m.audit.begin(ctx, AuditOperationRemoveIncompleteUpload, args.Bucket, args.Name)
+	defer func() { record.end(err) }()
+
 	client, bucketName, err := m.GetClientAndBucket(ctx, common.StorageBucketArguments{
 		StorageClientCredentialArguments: common.StorageClientCredentialArguments{
 			ClientID: args.ClientID,
@@ -351,6 +772,13 @@ func (m *Manager) RemoveIncompleteUpload(
 		return err
 	}
 
+	record.setTarget(client, bucketName)
+
+	err = m.authorizeObject(ctx, PolicyOperationDelete, client, bucketName, args.Name)
+	if err != nil {
+		return err
+	}
+
 	return client.RemoveIncompleteUpload(ctx, bucketName, args.Name)
 }
 
@@ -362,7 +790,10 @@ func (m *Manager) PresignedGetObject(
 	bucketInfo common.StorageBucketArguments,
 	objectName string,
 	opts common.PresignedGetStorageObjectOptions,
-) (*common.PresignedURLResponse, error) {
+) (_ *common.PresignedURLResponse, err error) {
+	record := m.audit.begin(ctx, AuditOperationPresignDownload, bucketInfo.Bucket, objectName)
+	defer func() { record.end(err) }()
+
 	if err := s3utils.CheckValidObjectName(objectName); err != nil {
 		return nil, schema.UnprocessableContentError(err.Error(), nil)
 	}
@@ -372,6 +803,13 @@ func (m *Manager) PresignedGetObject(
 		return nil, err
 	}
 
+	record.setTarget(client, bucketName)
+
+	err = m.authorizeObject(ctx, PolicyOperationPresign, client, bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
 	var exp time.Duration
 
 	if opts.Expiry != nil {
@@ -393,9 +831,12 @@ func (m *Manager) PresignedGetObject(
 		return nil, err
 	}
 
+	expiredAt := time.Now().Add(exp)
+	record.setExpiredAt(expiredAt)
+
 	return &common.PresignedURLResponse{
 		URL:       rawURL,
-		ExpiredAt: time.Now().Add(exp),
+		ExpiredAt: expiredAt,
 	}, nil
 }
 
@@ -408,7 +849,10 @@ func (m *Manager) PresignedPutObject(
 	bucketInfo common.StorageBucketArguments,
 	objectName string,
 	expiry *scalar.DurationString,
-) (*common.PresignedURLResponse, error) {
+) (_ *common.PresignedURLResponse, err error) {
+	record := m.audit.begin(ctx, AuditOperationPresignUpload, bucketInfo.Bucket, objectName)
+	defer func() { record.end(err) }()
+
 	if err := s3utils.CheckValidObjectName(objectName); err != nil {
 		return nil, schema.UnprocessableContentError(err.Error(), nil)
 	}
@@ -418,6 +862,13 @@ func (m *Manager) PresignedPutObject(
 		return nil, err
 	}
 
+	record.setTarget(client, bucketName)
+
+	err = m.authorizeObject(ctx, PolicyOperationPresign, client, bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
 	var exp time.Duration
 
 	if expiry != nil {
@@ -438,9 +889,12 @@ func (m *Manager) PresignedPutObject(
 		return nil, err
 	}
 
+	expiredAt := time.Now().Add(exp)
+	record.setExpiredAt(expiredAt)
+
 	return &common.PresignedURLResponse{
 		URL:       rawURL,
-		ExpiredAt: time.Now().Add(exp),
+		ExpiredAt: expiredAt,
 	}, nil
 }
 
@@ -451,12 +905,30 @@ func (m *Manager) UploadObjectFromURL(
 	objectName string,
 	httpRequest *common.HTTPRequestOptions,
 	opts *common.PutStorageObjectOptions,
-) (*common.StorageUploadInfo, error) {
+) (result *common.StorageUploadInfo, err error) {
+	record := m.audit.begin(ctx, AuditOperationUploadObjectFromURL, bucketInfo.Bucket, objectName)
+	defer func() { record.end(err) }()
+
 	client, bucketName, err := m.GetClientAndBucket(ctx, bucketInfo)
 	if err != nil {
 		return nil, err
 	}
 
+	record.setTarget(client, bucketName)
+
+	err = m.authorizeObject(ctx, PolicyOperationWrite, client, bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	// the download from the URL is a part of the upload and is drained on shutdown as well.
+	ctx, done, err := m.beginUpload(ctx, client, bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer done()
+
 	var contentLength int64 = -1
 
 	maxUploadSizeBytes := m.runtime.MaxUploadSizeMBs * 1024 * 1024
@@ -470,34 +942,30 @@ func (m *Manager) UploadObjectFromURL(
 		}
 	}
 
-	resp, err := m.httpClient.Request(ctx, httpRequest)
+	var checksum hash.Hash
+
+	if httpRequest.ExpectedChecksum != nil {
+		checksum, err = httpRequest.ExpectedChecksum.NewHash()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	download, err := m.httpClient.Download(ctx, httpRequest)
 	if err != nil {
 		return nil, err
 	}
 
 	defer func() {
-		_ = resp.Body.Close()
+		_ = download.Close()
 	}()
 
+	resp := download.Response
+
 	if resp.ContentLength > 0 {
 		contentLength = resp.ContentLength
 	}
 
-	respBody := resp.Body
-
-	if contentLength < 0 {
-		var buf bytes.Buffer
-
-		contentLength, err = io.Copy(&buf, resp.Body)
-		if err != nil {
-			return nil, schema.UnprocessableContentError(err.Error(), nil)
-		}
-
-		_ = resp.Body.Close()
-
-		respBody = io.NopCloser(&buf)
-	}
-
 	if contentLength > maxUploadSizeBytes {
 		return nil, maxUploadSizeLimitError(m.runtime.MaxUploadSizeMBs)
 	}
@@ -527,13 +995,66 @@ func (m *Manager) UploadObjectFromURL(
 		opts.ContentLanguage = contentLanguage
 	}
 
-	result, err := client.PutObject(ctx, bucketName, objectName, opts, respBody, contentLength)
+	var body io.Reader = download
+
+	maxSizeError := maxUploadSizeLimitError(m.runtime.MaxUploadSizeMBs)
+
+	// verify the content of the stream before uploading.
+	if uploadPolicy := client.getUploadPolicy(bucketName); uploadPolicy != nil {
+		peekReader := bufio.NewReaderSize(download, common.UploadSniffLength)
+
+		head, err := peekReader.Peek(common.UploadSniffLength)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		if err := uploadPolicy.ValidateUpload(opts, head, contentLength); err != nil {
+			return nil, err
+		}
+
+		if uploadPolicy.MaxSize != nil && *uploadPolicy.MaxSize < maxUploadSizeBytes {
+			maxUploadSizeBytes = *uploadPolicy.MaxSize
+			maxSizeError = uploadPolicy.MaxSizeError()
+		}
+
+		body = peekReader
+	}
+
+	applySnapshot, err := m.snapshotObject(ctx, client, bucketName, objectName, opts.ServerSideEncryption)
+	if err != nil {
+		return nil, err
+	}
+
+	// the response body of unknown length is streamed with multipart uploads.
+	// Use a moderate part size so that the connector buffers a few parts only.
+	if contentLength < 0 && opts.PartSize == 0 {
+		opts.PartSize = defaultStreamPartSize
+	}
+
+	reader := &uploadStreamReader{
+		reader:           body,
+		size:             contentLength,
+		maxSize:          maxUploadSizeBytes,
+		maxSizeError:     maxSizeError,
+		checksum:         checksum,
+		expectedChecksum: httpRequest.ExpectedChecksum,
+	}
+
+	result, err = client.PutObject(ctx, bucketName, objectName, opts, reader, contentLength)
 	if err != nil {
+		// prefer the original error of the stream reader rather than the wrapped error of the storage client.
+		if reader.err != nil {
+			return nil, reader.err
+		}
+
 		return nil, err
 	}
 
+	applySnapshot()
+
 	result.Bucket = bucketName
 	result.ClientID = string(client.id)
+	record.setUploadInfo(result)
 
 	return result, nil
 }
@@ -545,9 +1066,11 @@ func (m *Manager) contentLengthFromHEAD(
 	ctx context.Context,
 	httpRequest *common.HTTPRequestOptions,
 ) int64 {
-	httpRequest.Method = utils.ToPtr(common.DownloadHTTPMethod(http.MethodHead))
+	// the HEAD request is sent with a copy so the method of the download request isn't changed.
+	headRequest := *httpRequest
+	headRequest.Method = utils.ToPtr(common.DownloadHTTPMethod(http.MethodHead))
 
-	resp, err := m.httpClient.Request(ctx, httpRequest)
+	resp, err := m.httpClient.Request(ctx, &headRequest)
 	if err != nil {
 		slog.Debug(
 			fmt.Sprintf("failed to send HEAD request: %s", err),
@@ -557,19 +1080,67 @@ func (m *Manager) contentLengthFromHEAD(
 		return -1
 	}
 
+	if resp.Body != nil {
+		_ = resp.Body.Close()
+	}
+
 	if resp.StatusCode >= 300 {
 		slog.Debug("failed to send HEAD request: "+resp.Status, slog.String("url", httpRequest.URL))
 
 		return -1
 	}
 
-	if resp.Body != nil {
-		_ = resp.Body.Close()
+	return resp.ContentLength
+}
+
+// uploadStreamReader counts read bytes to enforce the upload size limit
+// and verifies the checksum of the content at the end of the stream.
+type uploadStreamReader struct {
+	reader           io.Reader
+	size             int64
+	maxSize          int64
+	maxSizeError     error
+	count            int64
+	checksum         hash.Hash
+	expectedChecksum *common.HTTPExpectedChecksum
+	verified         bool
+	err              error
+}
+
+// Read implements the io.Reader interface.
+func (r *uploadStreamReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
 	}
 
-	httpRequest.Method = utils.ToPtr(common.DownloadHTTPMethod(http.MethodGet))
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
 
-	return resp.ContentLength
+	if r.count > r.maxSize {
+		r.err = r.maxSizeError
+
+		return 0, r.err
+	}
+
+	if r.checksum == nil {
+		return n, err
+	}
+
+	_, _ = r.checksum.Write(p[:n])
+
+	// Storage clients may stop reading when the object size is reached without receiving io.EOF.
+	// The last chunk is held back if the checksum doesn't match so that the upload can't be completed.
+	if !r.verified && (errors.Is(err, io.EOF) || (r.size >= 0 && r.count >= r.size)) {
+		r.verified = true
+
+		if verifyErr := r.expectedChecksum.Verify(r.checksum.Sum(nil)); verifyErr != nil {
+			r.err = verifyErr
+
+			return 0, r.err
+		}
+	}
+
+	return n, err
 }
 
 func maxUploadSizeLimitError(mbs int64) error {
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"gotest.tools/v3/assert"
+)
+
+func TestUploadObjectFromURLChecksumMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("corrupted"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	manager, err := NewManager(context.TODO(), []ClientConfig{
+		{
+			"id":               "local",
+			"type":             "fs",
+			"defaultDirectory": map[string]any{"value": dir},
+		},
+	}, RuntimeSettings{
+		MaxUploadSizeMBs:   1,
+		MaxDownloadSizeMBs: 1,
+	}, nil, nil, nil, slog.Default())
+	assert.NilError(t, err)
+
+	defer manager.Close(context.TODO())
+
+	bucketArgs := common.StorageBucketArguments{}
+
+	_, err = manager.PutObject(context.TODO(), bucketArgs, "a.txt", &common.PutStorageObjectOptions{}, []byte("hello"))
+	assert.NilError(t, err)
+
+	_, err = manager.UploadObjectFromURL(context.TODO(), bucketArgs, "a.txt", &common.HTTPRequestOptions{
+		URL: server.URL,
+		ExpectedChecksum: &common.HTTPExpectedChecksum{
+			Algorithm: common.HTTPChecksumAlgorithmSha256,
+			// the SHA-256 checksum of "hello world".
+			Value: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+		},
+	}, &common.PutStorageObjectOptions{})
+	assert.ErrorContains(t, err, "checksum")
+
+	// the previous content is kept and no temporary file is left.
+	data, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), "hello")
+
+	entries, err := os.ReadDir(dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 1)
+}
+
+func TestUploadObjectFromURLHeadFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	manager, err := NewManager(context.TODO(), []ClientConfig{
+		{
+			"id":               "local",
+			"type":             "fs",
+			"defaultDirectory": map[string]any{"value": dir},
+		},
+	}, RuntimeSettings{
+		MaxUploadSizeMBs:   1,
+		MaxDownloadSizeMBs: 1,
+	}, nil, nil, nil, slog.Default())
+	assert.NilError(t, err)
+
+	defer manager.Close(context.TODO())
+
+	// the content is downloaded with the GET request even if the HEAD request fails.
+	request := &common.HTTPRequestOptions{URL: server.URL}
+	_, err = manager.UploadObjectFromURL(
+		context.TODO(),
+		common.StorageBucketArguments{},
+		"a.txt",
+		request,
+		&common.PutStorageObjectOptions{},
+	)
+	assert.NilError(t, err)
+	assert.Assert(t, request.Method == nil)
+
+	data, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), "hello")
+}
+
+func TestCopyObjectUploadPolicy(t *testing.T) {
+	dir := t.TempDir()
+	manager, err := NewManager(context.TODO(), []ClientConfig{
+		{
+			"id":               "local",
+			"type":             "fs",
+			"defaultDirectory": map[string]any{"value": dir},
+			"uploadPolicies": []any{
+				map[string]any{
+					"allowedContentTypes": []any{"text/plain"},
+					"maxSize":             10,
+				},
+			},
+		},
+	}, RuntimeSettings{
+		MaxUploadSizeMBs:   1,
+		MaxDownloadSizeMBs: 1,
+	}, nil, nil, nil, slog.Default())
+	assert.NilError(t, err)
+
+	defer manager.Close(context.TODO())
+
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("hello"), 0o600))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "b.png"), []byte("\x89PNG\r\n\x1a\n"), 0o600))
+
+	_, err = manager.CopyObject(context.TODO(), &common.CopyStorageObjectArguments{
+		Source: common.StorageCopySrcOptions{Name: "a.txt"},
+		Dest:   common.StorageCopyDestOptions{Name: "c.txt"},
+	})
+	assert.NilError(t, err)
+
+	_, err = manager.CopyObject(context.TODO(), &common.CopyStorageObjectArguments{
+		Source: common.StorageCopySrcOptions{Name: "b.png"},
+		Dest:   common.StorageCopyDestOptions{Name: "d.txt"},
+	})
+	assert.ErrorContains(t, err, "content type image/png is not allowed by the upload policy")
+
+	_, err = manager.ComposeObject(context.TODO(), &common.ComposeStorageObjectArguments{
+		Sources: []common.StorageCopySrcOptions{{Name: "a.txt"}, {Name: "c.txt"}, {Name: "a.txt"}},
+		Dest:    common.StorageCopyDestOptions{Name: "e.txt"},
+	})
+	assert.ErrorContains(t, err, "object size > 10 bytes is not allowed by the upload policy")
+
+	_, err = os.Stat(filepath.Join(dir, "d.txt"))
+	assert.Assert(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "e.txt"))
+	assert.Assert(t, os.IsNotExist(err))
+}
@@ -0,0 +1,550 @@
+package storage
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"github.com/invopop/jsonschema"
+)
+
+// DefaultPolicySessionArgument is the default name of the argument that receives session variables.
+const DefaultPolicySessionArgument = "session"
+
+// PolicySessionSecretKey is the key of the shared secret in the session argument.
+// The engine adds the secret with argument presets so that session variables can't be forged by clients.
+const PolicySessionSecretKey = "x-ndc-session-secret"
+
+// PolicyMatchAny is the wildcard value of session variable conditions that matches any non-empty value.
+const PolicyMatchAny = "*"
+
+var policyTemplateRegex = regexp.MustCompile(`\{([^{}]+)\}`)
+
+type sessionContextKey struct{}
+
+type unverifiedSessionContextKey struct{}
+
+// PolicyOperation represents an operation type of access policy rules.
+type PolicyOperation string
+
+const (
+	PolicyOperationRead    PolicyOperation = "read"
+	PolicyOperationWrite   PolicyOperation = "write"
+	PolicyOperationDelete  PolicyOperation = "delete"
+	PolicyOperationPresign PolicyOperation = "presign"
+)
+
+var enumValues_PolicyOperation = []PolicyOperation{
+	PolicyOperationRead, PolicyOperationWrite, PolicyOperationDelete, PolicyOperationPresign,
+}
+
+// ParsePolicyOperation parses the PolicyOperation from string.
+func ParsePolicyOperation(input string) (PolicyOperation, error) {
+	result := PolicyOperation(input)
+	if !slices.Contains(enumValues_PolicyOperation, result) {
+		return "", fmt.Errorf(
+			"invalid PolicyOperation, expected one of %v, got: %s",
+			enumValues_PolicyOperation,
+			input,
+		)
+	}
+
+	return result, nil
+}
+
+// Validate checks if the operation is valid.
+func (po PolicyOperation) Validate() error {
+	_, err := ParsePolicyOperation(string(po))
+
+	return err
+}
+
+// JSONSchema is used to generate a custom jsonschema.
+func (po PolicyOperation) JSONSchema() *jsonschema.Schema {
+	enumValues := make([]any, len(enumValues_PolicyOperation))
+	for i, item := range enumValues_PolicyOperation {
+		enumValues[i] = string(item)
+	}
+
+	return &jsonschema.Schema{
+		Type: "string",
+		Enum: enumValues,
+	}
+}
+
+// PolicySettings represent access policies which are evaluated against session variables of the request.
+// If enabled, operations that don't match any rule are denied.
+type PolicySettings struct {
+	// Enable access policies.
+	Enabled bool `json:"enabled"                   jsonschema:"default=false"   yaml:"enabled"`
+	// Name of the argument that receives session variables or forwarded headers in a JSON object.
+	SessionArgument string `json:"sessionArgument,omitempty" jsonschema:"default=session" yaml:"sessionArgument,omitempty"`
+	// Shared secret that the engine sends in the x-ndc-session-secret key of the session argument.
+	// Session variables are trusted only if the secret matches. Required if policies are enabled.
+	SessionSecret *utils.EnvString `json:"sessionSecret,omitempty"   yaml:"sessionSecret,omitempty"`
+	// List of rules that grant access to storage resources. An operation is allowed if any rule grants it.
+	Rules []PolicyRule `json:"rules"                     yaml:"rules"`
+}
+
+// Validate checks if the policy settings are valid.
+func (ps PolicySettings) Validate() error {
+	if ps.Enabled && ps.SessionSecret == nil {
+		return errors.New("sessionSecret is required to verify that session variables are sent by the engine")
+	}
+
+	for i, rule := range ps.Rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("invalid policy rule at %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// GetSessionArgument returns the name of the session argument or the default value.
+func (ps PolicySettings) GetSessionArgument() string {
+	if ps.SessionArgument == "" {
+		return DefaultPolicySessionArgument
+	}
+
+	return ps.SessionArgument
+}
+
+// HasSessionSecret checks if the session secret is configured, so that session variables can be verified.
+func (ps *PolicySettings) HasSessionSecret() bool {
+	return ps != nil && ps.SessionSecret != nil
+}
+
+// VerifySession checks if session variables are sent by the engine with the shared secret.
+// The secret is removed from the verified session. Session variables are ignored if the secret isn't configured.
+func (ps *PolicySettings) VerifySession(session Session) (Session, error) {
+	if ps == nil || ps.SessionSecret == nil {
+		return Session{}, nil
+	}
+
+	secret, err := ps.SessionSecret.Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the session secret: %w", err)
+	}
+
+	if secret == "" ||
+		subtle.ConstantTimeCompare([]byte(session.Get(PolicySessionSecretKey)), []byte(secret)) != 1 {
+		return nil, schema.ForbiddenError(
+			"session variables aren't trusted: the session secret doesn't match",
+			nil,
+		)
+	}
+
+	delete(session, PolicySessionSecretKey)
+
+	return session, nil
+}
+
+// PolicyRule represents a rule that grants operations on storage resources to sessions
+// whose variables match all conditions.
+type PolicyRule struct {
+	// Optional name of the rule.
+	Name string `json:"name,omitempty"     yaml:"name,omitempty"`
+	// Session variables that the request must match, e.g. x-hasura-role: user.
+	// Use * to match any non-empty value. An empty map matches all requests.
+	Match map[string]string `json:"match,omitempty"    yaml:"match,omitempty"`
+	// Allowed client IDs. Allow all clients if empty.
+	Clients []string `json:"clients,omitempty"  yaml:"clients,omitempty"`
+	// Allowed bucket names. Allow all buckets if empty.
+	Buckets []string `json:"buckets,omitempty"  yaml:"buckets,omitempty"`
+	// Allowed object key prefixes. Session variables can be templated in curly braces, e.g. users/{x-hasura-user-id}/.
+	// Allow all objects if empty. Bucket-level write and delete operations require a rule without prefixes.
+	Prefixes []string `json:"prefixes,omitempty" yaml:"prefixes,omitempty"`
+	// Allowed operations.
+	Operations []PolicyOperation `json:"operations"         yaml:"operations"`
+}
+
+// Validate checks if the policy rule is valid.
+func (pr PolicyRule) Validate() error {
+	if len(pr.Operations) == 0 {
+		return errors.New("operations must not be empty")
+	}
+
+	for _, op := range pr.Operations {
+		if err := op.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// matches checks if the rule is applicable to the session, client and bucket.
+func (pr PolicyRule) matches(
+	session Session,
+	op PolicyOperation,
+	clientID, bucketName string,
+) bool {
+	if !slices.Contains(pr.Operations, op) {
+		return false
+	}
+
+	if len(pr.Clients) > 0 && !slices.Contains(pr.Clients, clientID) {
+		return false
+	}
+
+	if len(pr.Buckets) > 0 && !slices.Contains(pr.Buckets, bucketName) {
+		return false
+	}
+
+	for key, expected := range pr.Match {
+		value := session.Get(key)
+		if value == "" || (expected != PolicyMatchAny && value != expected) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// renderPrefixes renders templated prefixes with session variables.
+// Prefixes that reference missing or unsafe session variables are skipped.
+func (pr PolicyRule) renderPrefixes(session Session) []string {
+	results := make([]string, 0, len(pr.Prefixes))
+
+	for _, prefix := range pr.Prefixes {
+		valid := true
+		rendered := policyTemplateRegex.ReplaceAllStringFunc(prefix, func(s string) string {
+			value := session.Get(s[1 : len(s)-1])
+			if value == "" || value == "." || value == ".." || strings.Contains(value, "/") {
+				valid = false
+			}
+
+			return value
+		})
+
+		if valid {
+			results = append(results, rendered)
+		}
+	}
+
+	return results
+}
+
+// Session holds session variables of the request. Keys are case-insensitive.
+type Session map[string]string
+
+// ParseSession parses session variables from a JSON object.
+func ParseSession(input any) (Session, error) {
+	if input == nil {
+		return Session{}, nil
+	}
+
+	var rawSession map[string]any
+
+	switch value := input.(type) {
+	case map[string]any:
+		rawSession = value
+	case map[string]string:
+		result := make(Session, len(value))
+		for key, v := range value {
+			result[strings.ToLower(key)] = v
+		}
+
+		return result, nil
+	default:
+		return nil, fmt.Errorf("expected a JSON object of session variables, got %T", input)
+	}
+
+	result := make(Session, len(rawSession))
+
+	for key, rawValue := range rawSession {
+		switch v := rawValue.(type) {
+		case nil:
+		case string:
+			result[strings.ToLower(key)] = v
+		case []any:
+			// forwarded headers may have many values. Take the first one.
+			if len(v) > 0 {
+				result[strings.ToLower(key)] = fmt.Sprint(v[0])
+			}
+		default:
+			result[strings.ToLower(key)] = fmt.Sprint(v)
+		}
+	}
+
+	return result, nil
+}
+
+// Get returns the value of a session variable.
+func (s Session) Get(key string) string {
+	return s[strings.ToLower(key)]
+}
+
+// ContextWithSession returns a new context with session variables.
+func ContextWithSession(ctx context.Context, session Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, session)
+}
+
+// SessionFromContext gets session variables from context if exist.
+func SessionFromContext(ctx context.Context) Session {
+	session, ok := ctx.Value(sessionContextKey{}).(Session)
+	if !ok {
+		return Session{}
+	}
+
+	return session
+}
+
+// ContextWithUnverifiedSession returns a new context with session variables that can't be verified
+// because the session secret isn't configured. They are only recorded in audit events and never authorize requests.
+func ContextWithUnverifiedSession(ctx context.Context, session Session) context.Context {
+	return context.WithValue(ctx, unverifiedSessionContextKey{}, session)
+}
+
+// unverifiedSessionFromContext gets unverified session variables from context if exist.
+func unverifiedSessionFromContext(ctx context.Context) Session {
+	session, ok := ctx.Value(unverifiedSessionContextKey{}).(Session)
+	if !ok {
+		return Session{}
+	}
+
+	return session
+}
+
+// PolicyEnforcer evaluates access policies of storage operations.
+type PolicyEnforcer struct {
+	rules []PolicyRule
+}
+
+// NewPolicyEnforcer creates a policy enforcer. Returns nil if the policy is disabled.
+func NewPolicyEnforcer(settings *PolicySettings) *PolicyEnforcer {
+	if settings == nil || !settings.Enabled {
+		return nil
+	}
+
+	return &PolicyEnforcer{
+		rules: settings.Rules,
+	}
+}
+
+// allowedPrefixes returns object key prefixes that the session is allowed to access.
+// The result is nil if objects aren't restricted by prefixes.
+func (pe *PolicyEnforcer) allowedPrefixes(
+	ctx context.Context,
+	op PolicyOperation,
+	clientID, bucketName string,
+) ([]string, error) {
+	session := SessionFromContext(ctx)
+	granted := false
+	prefixes := []string{}
+
+	for _, rule := range pe.rules {
+		if !rule.matches(session, op, clientID, bucketName) {
+			continue
+		}
+
+		if len(rule.Prefixes) == 0 {
+			return nil, nil
+		}
+
+		for _, prefix := range rule.renderPrefixes(session) {
+			granted = true
+
+			if !slices.Contains(prefixes, prefix) {
+				prefixes = append(prefixes, prefix)
+			}
+		}
+	}
+
+	if !granted {
+		return nil, newPolicyDeniedError(op, clientID, bucketName, "")
+	}
+
+	return prefixes, nil
+}
+
+// AuthorizeBucket checks if the session is allowed to operate on the bucket.
+// Read operations are allowed if any rule grants access to the bucket.
+// Other operations require a rule without prefix restrictions.
+func (pe *PolicyEnforcer) AuthorizeBucket(
+	ctx context.Context,
+	op PolicyOperation,
+	clientID, bucketName string,
+) error {
+	if pe == nil {
+		return nil
+	}
+
+	prefixes, err := pe.allowedPrefixes(ctx, op, clientID, bucketName)
+	if err != nil {
+		return err
+	}
+
+	if prefixes != nil && op != PolicyOperationRead {
+		return newPolicyDeniedError(op, clientID, bucketName, "")
+	}
+
+	return nil
+}
+
+// AuthorizeObject checks if the session is allowed to operate on the object.
+func (pe *PolicyEnforcer) AuthorizeObject(
+	ctx context.Context,
+	op PolicyOperation,
+	clientID, bucketName, objectName string,
+) error {
+	if pe == nil {
+		return nil
+	}
+
+	prefixes, err := pe.allowedPrefixes(ctx, op, clientID, bucketName)
+	if err != nil {
+		return err
+	}
+
+	if prefixes == nil {
+		return nil
+	}
+
+	if !isSafeObjectPath(objectName) {
+		return newPolicyDeniedError(op, clientID, bucketName, objectName)
+	}
+
+	for _, prefix := range prefixes {
+		if matchPolicyPrefix(objectName, prefix) {
+			return nil
+		}
+	}
+
+	return newPolicyDeniedError(op, clientID, bucketName, objectName)
+}
+
+// AuthorizeList checks if the session is allowed to list objects with the prefix.
+// Returns the narrowed prefix and a predicate that filters out objects which the session isn't allowed to access.
+func (pe *PolicyEnforcer) AuthorizeList(
+	ctx context.Context,
+	op PolicyOperation,
+	clientID, bucketName string,
+	prefix string,
+	recursive bool,
+	predicate func(string) bool,
+) (string, func(string) bool, error) {
+	if pe == nil {
+		return prefix, predicate, nil
+	}
+
+	prefixes, err := pe.allowedPrefixes(ctx, op, clientID, bucketName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if prefixes == nil {
+		return prefix, predicate, nil
+	}
+
+	// prefixes with parent directory segments may escape allowed prefixes on backends that normalize paths.
+	if !isSafeObjectPath(prefix) {
+		return "", nil, newPolicyDeniedError(op, clientID, bucketName, prefix)
+	}
+
+	// the requested prefix is inside an allowed prefix.
+	for _, allowedPrefix := range prefixes {
+		if strings.HasPrefix(prefix, policyDirectoryPrefix(allowedPrefix)) {
+			return prefix, predicate, nil
+		}
+	}
+
+	// allowed prefixes that are inside the requested prefix.
+	innerPrefixes := []string{}
+
+	for _, allowedPrefix := range prefixes {
+		if strings.HasPrefix(allowedPrefix, prefix) {
+			innerPrefixes = append(innerPrefixes, allowedPrefix)
+		}
+	}
+
+	if len(innerPrefixes) == 0 {
+		return "", nil, newPolicyDeniedError(op, clientID, bucketName, prefix)
+	}
+
+	// the listing is narrowed to the allowed prefix if all keys with the prefix are allowed.
+	if recursive && len(innerPrefixes) == 1 &&
+		policyDirectoryPrefix(innerPrefixes[0]) == innerPrefixes[0] {
+		return innerPrefixes[0], predicate, nil
+	}
+
+	return prefix, func(name string) bool {
+		if predicate != nil && !predicate(name) {
+			return false
+		}
+
+		for _, allowedPrefix := range innerPrefixes {
+			// keep parent directories of allowed prefixes in hierarchical listings.
+			if matchPolicyPrefix(name, allowedPrefix) ||
+				(strings.HasSuffix(name, "/") && strings.HasPrefix(allowedPrefix, name)) {
+				return true
+			}
+		}
+
+		return false
+	}, nil
+}
+
+// matchPolicyPrefix checks if the object key is inside the allowed prefix at a path segment boundary,
+// so that the prefix users/1 matches users/1 and users/1/a.txt, but not users/10/a.txt.
+func matchPolicyPrefix(name, prefix string) bool {
+	return name == prefix || strings.HasPrefix(name, policyDirectoryPrefix(prefix))
+}
+
+// policyDirectoryPrefix returns the prefix of keys inside the allowed prefix.
+func policyDirectoryPrefix(prefix string) string {
+	if prefix == "" || strings.HasSuffix(prefix, "/") {
+		return prefix
+	}
+
+	return prefix + "/"
+}
+
+// FilterBuckets returns a predicate that filters out buckets which the session isn't allowed to access.
+func (pe *PolicyEnforcer) FilterBuckets(
+	ctx context.Context,
+	clientID string,
+	predicate func(string) bool,
+) func(string) bool {
+	if pe == nil {
+		return predicate
+	}
+
+	return func(name string) bool {
+		if predicate != nil && !predicate(name) {
+			return false
+		}
+
+		_, err := pe.allowedPrefixes(ctx, PolicyOperationRead, clientID, name)
+
+		return err == nil
+	}
+}
+
+func isSafeObjectPath(name string) bool {
+	return !slices.Contains(strings.Split(name, "/"), "..")
+}
+
+func newPolicyDeniedError(op PolicyOperation, clientID, bucketName, objectName string) error {
+	details := map[string]any{
+		"operation": op,
+		"client_id": clientID,
+		"bucket":    bucketName,
+	}
+
+	if objectName != "" {
+		details["object"] = objectName
+	}
+
+	return schema.ForbiddenError(
+		fmt.Sprintf("the %s operation is not allowed by access policies", op),
+		details,
+	)
+}
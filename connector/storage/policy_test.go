@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"gotest.tools/v3/assert"
+)
+
+func TestPolicyEnforcer(t *testing.T) {
+	enforcer := NewPolicyEnforcer(&PolicySettings{
+		Enabled: true,
+		Rules: []PolicyRule{
+			{
+				Name:       "admin",
+				Match:      map[string]string{"x-hasura-role": "admin"},
+				Operations: []PolicyOperation{PolicyOperationRead, PolicyOperationWrite, PolicyOperationDelete},
+			},
+			{
+				Name:       "user",
+				Match:      map[string]string{"x-hasura-role": "user", "x-hasura-user-id": PolicyMatchAny},
+				Clients:    []string{"minio"},
+				Buckets:    []string{"default"},
+				Prefixes:   []string{"users/{x-hasura-user-id}/", "public/"},
+				Operations: []PolicyOperation{PolicyOperationRead, PolicyOperationWrite},
+			},
+		},
+	})
+
+	session, err := ParseSession(map[string]any{
+		"X-Hasura-Role":    "user",
+		"X-Hasura-User-Id": "1",
+	})
+	assert.NilError(t, err)
+
+	userCtx := ContextWithSession(context.TODO(), session)
+	adminCtx := ContextWithSession(context.TODO(), Session{"x-hasura-role": "admin"})
+
+	assert.NilError(t, enforcer.AuthorizeObject(userCtx, PolicyOperationWrite, "minio", "default", "users/1/a.txt"))
+	assert.NilError(t, enforcer.AuthorizeObject(userCtx, PolicyOperationRead, "minio", "default", "public/b.txt"))
+	assert.ErrorContains(
+		t,
+		enforcer.AuthorizeObject(userCtx, PolicyOperationRead, "minio", "default", "users/2/a.txt"),
+		"not allowed",
+	)
+	assert.ErrorContains(
+		t,
+		enforcer.AuthorizeObject(userCtx, PolicyOperationRead, "minio", "default", "users/1/../2/a.txt"),
+		"not allowed",
+	)
+	assert.ErrorContains(
+		t,
+		enforcer.AuthorizeObject(userCtx, PolicyOperationDelete, "minio", "default", "users/1/a.txt"),
+		"not allowed",
+	)
+	assert.ErrorContains(
+		t,
+		enforcer.AuthorizeObject(userCtx, PolicyOperationRead, "minio", "other", "users/1/a.txt"),
+		"not allowed",
+	)
+	assert.ErrorContains(
+		t,
+		enforcer.AuthorizeObject(context.TODO(), PolicyOperationRead, "minio", "default", "public/b.txt"),
+		"not allowed",
+	)
+	assert.NilError(t, enforcer.AuthorizeObject(adminCtx, PolicyOperationDelete, "gcs", "other", "users/2/a.txt"))
+
+	assert.NilError(t, enforcer.AuthorizeBucket(userCtx, PolicyOperationRead, "minio", "default"))
+	assert.ErrorContains(
+		t,
+		enforcer.AuthorizeBucket(userCtx, PolicyOperationWrite, "minio", "default"),
+		"not allowed",
+	)
+	assert.NilError(t, enforcer.AuthorizeBucket(adminCtx, PolicyOperationWrite, "minio", "default"))
+
+	prefix, _, err := enforcer.AuthorizeList(userCtx, PolicyOperationRead, "minio", "default", "users/", true, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, prefix, "users/1/")
+
+	prefix, predicate, err := enforcer.AuthorizeList(userCtx, PolicyOperationRead, "minio", "default", "", false, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, prefix, "")
+	assert.Assert(t, predicate("users/"))
+	assert.Assert(t, predicate("public/c.txt"))
+	assert.Assert(t, !predicate("users/2/"))
+	assert.Assert(t, !predicate("private/"))
+
+	_, _, err = enforcer.AuthorizeList(userCtx, PolicyOperationRead, "minio", "default", "private/", true, nil)
+	assert.ErrorContains(t, err, "not allowed")
+
+	// prefixes can't escape the allowed prefix with parent directory segments.
+	_, _, err = enforcer.AuthorizeList(userCtx, PolicyOperationRead, "minio", "default", "users/1/../2/", true, nil)
+	assert.ErrorContains(t, err, "not allowed")
+
+	_, _, err = enforcer.AuthorizeList(adminCtx, PolicyOperationDelete, "minio", "default", "users/1/../2/", true, nil)
+	assert.NilError(t, err)
+
+	deleteCtx := ContextWithSession(context.TODO(), Session{"x-hasura-role": "deleter", "x-hasura-user-id": "1"})
+	deleter := NewPolicyEnforcer(&PolicySettings{
+		Enabled: true,
+		Rules: []PolicyRule{
+			{
+				Match:      map[string]string{"x-hasura-role": "deleter", "x-hasura-user-id": PolicyMatchAny},
+				Prefixes:   []string{"users/{x-hasura-user-id}/"},
+				Operations: []PolicyOperation{PolicyOperationDelete},
+			},
+		},
+	})
+
+	_, _, err = deleter.AuthorizeList(deleteCtx, PolicyOperationDelete, "minio", "default", "users/1/", true, nil)
+	assert.NilError(t, err)
+
+	_, _, err = deleter.AuthorizeList(deleteCtx, PolicyOperationDelete, "minio", "default", "users/1/../2/", true, nil)
+	assert.ErrorContains(t, err, "not allowed")
+}
+
+func TestPolicyPrefixBoundary(t *testing.T) {
+	enforcer := NewPolicyEnforcer(&PolicySettings{
+		Enabled: true,
+		Rules: []PolicyRule{
+			{
+				Match:      map[string]string{"x-hasura-user-id": PolicyMatchAny},
+				Prefixes:   []string{"users/{x-hasura-user-id}"},
+				Operations: []PolicyOperation{PolicyOperationRead},
+			},
+		},
+	})
+
+	ctx := ContextWithSession(context.TODO(), Session{"x-hasura-user-id": "1"})
+
+	assert.NilError(t, enforcer.AuthorizeObject(ctx, PolicyOperationRead, "minio", "default", "users/1"))
+	assert.NilError(t, enforcer.AuthorizeObject(ctx, PolicyOperationRead, "minio", "default", "users/1/a.txt"))
+	assert.ErrorContains(
+		t,
+		enforcer.AuthorizeObject(ctx, PolicyOperationRead, "minio", "default", "users/10/a.txt"),
+		"not allowed",
+	)
+	assert.ErrorContains(
+		t,
+		enforcer.AuthorizeObject(ctx, PolicyOperationRead, "minio", "default", "users/1.txt"),
+		"not allowed",
+	)
+
+	prefix, predicate, err := enforcer.AuthorizeList(ctx, PolicyOperationRead, "minio", "default", "users/1", true, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, prefix, "users/1")
+	assert.Assert(t, predicate("users/1/a.txt"))
+	assert.Assert(t, !predicate("users/10/a.txt"))
+
+	prefix, predicate, err = enforcer.AuthorizeList(ctx, PolicyOperationRead, "minio", "default", "users/", true, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, prefix, "users/")
+	assert.Assert(t, predicate("users/1/a.txt"))
+	assert.Assert(t, !predicate("users/10/a.txt"))
+
+	prefix, predicate, err = enforcer.AuthorizeList(ctx, PolicyOperationRead, "minio", "default", "users/1/", true, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, prefix, "users/1/")
+	assert.Assert(t, predicate == nil)
+}
+
+func TestPolicyVerifySession(t *testing.T) {
+	assert.ErrorContains(t, PolicySettings{Enabled: true}.Validate(), "sessionSecret is required")
+
+	settings := &PolicySettings{
+		Enabled:       true,
+		SessionSecret: utils.ToPtr(utils.NewEnvStringValue("secret")),
+	}
+	assert.NilError(t, settings.Validate())
+
+	session, err := settings.VerifySession(Session{"x-hasura-role": "admin", PolicySessionSecretKey: "secret"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, session, Session{"x-hasura-role": "admin"})
+
+	_, err = settings.VerifySession(Session{"x-hasura-role": "admin", PolicySessionSecretKey: "forged"})
+	assert.ErrorContains(t, err, "session variables aren't trusted")
+
+	_, err = settings.VerifySession(Session{"x-hasura-role": "admin"})
+	assert.ErrorContains(t, err, "session variables aren't trusted")
+
+	// session variables are ignored if the secret isn't configured.
+	session, err = (&PolicySettings{}).VerifySession(Session{"x-hasura-role": "admin"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, session, Session{})
+}
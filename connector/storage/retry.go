@@ -0,0 +1,406 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// retryClient retries idempotent operations of the inner storage client with exponential backoff.
+// Operations that aren't safe to repeat, such as creating and removing buckets, removing many objects,
+// uploading non-seekable streams and writes with ETag preconditions, are attempted once.
+// The repeated attempt of a conditional write fails if the lost response of the previous attempt succeeded.
+// Copies and compositions are retried because they write the same content to the destination again.
+type retryClient struct {
+	common.StorageClient
+
+	policy common.RetryPolicy
+}
+
+var _ common.StorageClient = (*retryClient)(nil)
+
+// newRetryClient wraps the storage client with the retry policy. The client is returned as is if the policy is nil.
+func newRetryClient(client common.StorageClient, policy *common.RetryPolicy) common.StorageClient {
+	if policy == nil {
+		return client
+	}
+
+	return &retryClient{
+		StorageClient: client,
+		policy:        *policy,
+	}
+}
+
+// Close closes the inner client if it holds resources.
+func (rc *retryClient) Close() error {
+	return closeStorageClient(rc.StorageClient)
+}
+
+// ListBuckets lists all buckets.
+func (rc *retryClient) ListBuckets(
+	ctx context.Context,
+	options *common.ListStorageBucketsOptions,
+	predicate func(string) bool,
+) (*common.StorageBucketListResults, error) {
+	return retryOperation(
+		ctx,
+		rc,
+		"ListBuckets",
+		func(ctx context.Context) (*common.StorageBucketListResults, error) {
+			return rc.StorageClient.ListBuckets(ctx, options, predicate)
+		},
+	)
+}
+
+// GetBucket gets a bucket by name.
+func (rc *retryClient) GetBucket(
+	ctx context.Context,
+	name string,
+	options common.BucketOptions,
+) (*common.StorageBucket, error) {
+	return retryOperation(ctx, rc, "GetBucket", func(ctx context.Context) (*common.StorageBucket, error) {
+		return rc.StorageClient.GetBucket(ctx, name, options)
+	})
+}
+
+// BucketExists checks if a bucket exists.
+func (rc *retryClient) BucketExists(ctx context.Context, bucketName string) (bool, error) {
+	return retryOperation(ctx, rc, "BucketExists", func(ctx context.Context) (bool, error) {
+		return rc.StorageClient.BucketExists(ctx, bucketName)
+	})
+}
+
+// UpdateBucket updates configurations for the bucket.
+func (rc *retryClient) UpdateBucket(
+	ctx context.Context,
+	bucketName string,
+	opts common.UpdateStorageBucketOptions,
+) error {
+	_, err := retryOperation(ctx, rc, "UpdateBucket", func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, rc.StorageClient.UpdateBucket(ctx, bucketName, opts)
+	})
+
+	return err
+}
+
+// ListObjects lists objects in a bucket.
+func (rc *retryClient) ListObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.ListStorageObjectsOptions,
+	predicate func(string) bool,
+) (*common.StorageObjectListResults, error) {
+	return retryOperation(
+		ctx,
+		rc,
+		"ListObjects",
+		func(ctx context.Context) (*common.StorageObjectListResults, error) {
+			return rc.StorageClient.ListObjects(ctx, bucketName, opts, predicate)
+		},
+	)
+}
+
+// ListIncompleteUploads lists partially uploaded objects in a bucket.
+func (rc *retryClient) ListIncompleteUploads(
+	ctx context.Context,
+	bucketName string,
+	args common.ListIncompleteUploadsOptions,
+) ([]common.StorageObjectMultipartInfo, error) {
+	return retryOperation(
+		ctx,
+		rc,
+		"ListIncompleteUploads",
+		func(ctx context.Context) ([]common.StorageObjectMultipartInfo, error) {
+			return rc.StorageClient.ListIncompleteUploads(ctx, bucketName, args)
+		},
+	)
+}
+
+// ListDeletedObjects lists deleted objects in a bucket.
+func (rc *retryClient) ListDeletedObjects(
+	ctx context.Context,
+	bucketName string,
+	opts *common.ListStorageObjectsOptions,
+	predicate func(string) bool,
+) (*common.StorageObjectListResults, error) {
+	return retryOperation(
+		ctx,
+		rc,
+		"ListDeletedObjects",
+		func(ctx context.Context) (*common.StorageObjectListResults, error) {
+			return rc.StorageClient.ListDeletedObjects(ctx, bucketName, opts, predicate)
+		},
+	)
+}
+
+// GetObject returns a stream of the object data. Only opening the stream is retried.
+// The timeout of the attempt covers reading the stream until it's closed.
+func (rc *retryClient) GetObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (io.ReadCloser, error) {
+	return retryAttempts(
+		ctx,
+		rc,
+		"GetObject",
+		rc.policy.GetMaxAttempts(),
+		func(ctx context.Context, cancel context.CancelFunc) (io.ReadCloser, error) {
+			reader, err := rc.StorageClient.GetObject(ctx, bucketName, objectName, opts)
+			if err != nil || reader == nil {
+				cancel()
+
+				return reader, err
+			}
+
+			return &cancelReadCloser{
+				ReadCloser: reader,
+				cancel:     cancel,
+			}, nil
+		},
+	)
+}
+
+// PutObject uploads an object. The upload is only retried if the reader is seekable
+// so that the content can be rewound before the next attempt, and if the upload has no ETag precondition.
+func (rc *retryClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts *common.PutStorageObjectOptions,
+	reader io.Reader,
+	objectSize int64,
+) (*common.StorageUploadInfo, error) {
+	maxAttempts := 1
+
+	seeker, ok := reader.(io.Seeker)
+
+	var offset int64
+
+	if ok && (opts == nil || !opts.HasPrecondition()) {
+		var err error
+
+		offset, err = seeker.Seek(0, io.SeekCurrent)
+		if err == nil {
+			maxAttempts = rc.policy.GetMaxAttempts()
+		}
+	}
+
+	attempt := 0
+
+	return retryAttempts(
+		ctx,
+		rc,
+		"PutObject",
+		maxAttempts,
+		func(ctx context.Context, cancel context.CancelFunc) (*common.StorageUploadInfo, error) {
+			defer cancel()
+
+			attempt++
+			if attempt > 1 {
+				if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+					return nil, err
+				}
+			}
+
+			return rc.StorageClient.PutObject(ctx, bucketName, objectName, opts, reader, objectSize)
+		},
+	)
+}
+
+// CopyObject creates or replaces an object through server-side copying of an existing object.
+func (rc *retryClient) CopyObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	src common.StorageCopySrcOptions,
+) (*common.StorageUploadInfo, error) {
+	return retryOperation(ctx, rc, "CopyObject", func(ctx context.Context) (*common.StorageUploadInfo, error) {
+		return rc.StorageClient.CopyObject(ctx, dest, src)
+	})
+}
+
+// ComposeObject creates an object by concatenating a list of source objects using server-side copying.
+func (rc *retryClient) ComposeObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	sources []common.StorageCopySrcOptions,
+) (*common.StorageUploadInfo, error) {
+	return retryOperation(
+		ctx,
+		rc,
+		"ComposeObject",
+		func(ctx context.Context) (*common.StorageUploadInfo, error) {
+			return rc.StorageClient.ComposeObject(ctx, dest, sources)
+		},
+	)
+}
+
+// StatObject fetches metadata of an object.
+func (rc *retryClient) StatObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (*common.StorageObject, error) {
+	return retryOperation(ctx, rc, "StatObject", func(ctx context.Context) (*common.StorageObject, error) {
+		return rc.StorageClient.StatObject(ctx, bucketName, objectName, opts)
+	})
+}
+
+// RemoveObject removes an object with some specified options.
+func (rc *retryClient) RemoveObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.RemoveStorageObjectOptions,
+) error {
+	_, err := retryOperation(ctx, rc, "RemoveObject", func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, rc.StorageClient.RemoveObject(ctx, bucketName, objectName, opts)
+	})
+
+	return err
+}
+
+// UpdateObject updates object configurations. Updates with ETag preconditions are attempted once.
+func (rc *retryClient) UpdateObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.UpdateStorageObjectOptions,
+) error {
+	maxAttempts := rc.policy.GetMaxAttempts()
+	if opts.HasPrecondition() {
+		maxAttempts = 1
+	}
+
+	_, err := retryAttempts(
+		ctx,
+		rc,
+		"UpdateObject",
+		maxAttempts,
+		func(ctx context.Context, cancel context.CancelFunc) (struct{}, error) {
+			defer cancel()
+
+			return struct{}{}, rc.StorageClient.UpdateObject(ctx, bucketName, objectName, opts)
+		},
+	)
+
+	return err
+}
+
+// RemoveIncompleteUpload removes a partially uploaded object.
+func (rc *retryClient) RemoveIncompleteUpload(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+) error {
+	_, err := retryOperation(
+		ctx,
+		rc,
+		"RemoveIncompleteUpload",
+		func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, rc.StorageClient.RemoveIncompleteUpload(ctx, bucketName, objectName)
+		},
+	)
+
+	return err
+}
+
+// retryOperation runs the operation until it succeeds, the error isn't retryable or attempts are exhausted.
+func retryOperation[T any](
+	ctx context.Context,
+	rc *retryClient,
+	operation string,
+	fn func(ctx context.Context) (T, error),
+) (T, error) {
+	return retryAttempts(
+		ctx,
+		rc,
+		operation,
+		rc.policy.GetMaxAttempts(),
+		func(ctx context.Context, cancel context.CancelFunc) (T, error) {
+			defer cancel()
+
+			return fn(ctx)
+		},
+	)
+}
+
+// retryAttempts runs the operation with the timeout of each attempt.
+// The operation must call the cancel function of the attempt when it doesn't need the context anymore.
+func retryAttempts[T any](
+	ctx context.Context,
+	rc *retryClient,
+	operation string,
+	maxAttempts int,
+	fn func(ctx context.Context, cancel context.CancelFunc) (T, error),
+) (T, error) {
+	timeout := rc.policy.GetTimeout(operation)
+
+	for attempt := 1; ; attempt++ {
+		var (
+			attemptCtx context.Context
+			cancel     context.CancelFunc
+		)
+
+		if timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+		} else {
+			attemptCtx, cancel = context.WithCancel(ctx)
+		}
+
+		result, err := fn(attemptCtx, cancel)
+		if err == nil {
+			return result, nil
+		}
+
+		cancel()
+
+		// the attempt is retried if it timed out while the request is still alive.
+		timedOut := errors.Is(attemptCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+		if attempt >= maxAttempts || ctx.Err() != nil ||
+			(!timedOut && !rc.policy.IsRetryableError(err)) {
+			return result, err
+		}
+
+		backoff := rc.policy.Backoff(attempt)
+
+		trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+			attribute.String("storage.operation", operation),
+			attribute.Int("storage.retry.attempt", attempt),
+			attribute.String("storage.retry.backoff", backoff.String()),
+			attribute.String("error", err.Error()),
+		))
+
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return result, err
+		case <-timer.C:
+		}
+	}
+}
+
+// cancelReadCloser cancels the context of the attempt when the stream is closed.
+type cancelReadCloser struct {
+	io.ReadCloser
+
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+}
+
+func (r *cancelReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+
+	r.closeOnce.Do(r.cancel)
+
+	return err
+}
@@ -0,0 +1,301 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"gotest.tools/v3/assert"
+)
+
+type mockFlakyClient struct {
+	common.StorageClient
+
+	failures int
+	err      error
+	calls    int
+	payloads []string
+}
+
+func (m *mockFlakyClient) StatObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (*common.StorageObject, error) {
+	m.calls++
+	if m.calls <= m.failures {
+		return nil, m.err
+	}
+
+	return &common.StorageObject{Name: objectName}, nil
+}
+
+func (m *mockFlakyClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts *common.PutStorageObjectOptions,
+	reader io.Reader,
+	objectSize int64,
+) (*common.StorageUploadInfo, error) {
+	m.calls++
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	m.payloads = append(m.payloads, string(data))
+
+	if m.calls <= m.failures {
+		return nil, m.err
+	}
+
+	return &common.StorageUploadInfo{Name: objectName}, nil
+}
+
+// mockLostResponseClient applies writes but loses the response of the first attempt.
+type mockLostResponseClient struct {
+	common.StorageClient
+
+	err     error
+	exists  bool
+	calls   int
+	removed bool
+}
+
+func (m *mockLostResponseClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts *common.PutStorageObjectOptions,
+	reader io.Reader,
+	objectSize int64,
+) (*common.StorageUploadInfo, error) {
+	m.calls++
+
+	if opts.IfNoneMatch == common.ETagAny && m.exists {
+		return nil, common.NewPreconditionFailedError("", nil)
+	}
+
+	m.exists = true
+
+	if m.calls == 1 {
+		return nil, m.err
+	}
+
+	return &common.StorageUploadInfo{Name: objectName}, nil
+}
+
+func (m *mockLostResponseClient) UpdateObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts common.UpdateStorageObjectOptions,
+) error {
+	m.calls++
+
+	if m.calls == 1 {
+		return m.err
+	}
+
+	return common.NewPreconditionFailedError("", nil)
+}
+
+func (m *mockLostResponseClient) RemoveBucket(ctx context.Context, bucketName string) error {
+	m.calls++
+
+	if m.removed {
+		return common.NewStorageError(common.ErrorCodeNotFound, "bucket not found", nil)
+	}
+
+	m.removed = true
+
+	return m.err
+}
+
+func newTestRetryPolicy() *common.RetryPolicy {
+	return &common.RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 1,
+		MaxBackoff:     5,
+		Jitter:         utils.ToPtr(0.0),
+	}
+}
+
+func TestRetryClient(t *testing.T) {
+	unavailable := schema.NewConnectorError(http.StatusServiceUnavailable, "unavailable", map[string]any{
+		"statusCode": http.StatusServiceUnavailable,
+	})
+
+	t.Run("retryable", func(t *testing.T) {
+		inner := &mockFlakyClient{failures: 2, err: unavailable}
+		client := newRetryClient(inner, newTestRetryPolicy())
+
+		result, err := client.StatObject(context.TODO(), "bucket", "a.txt", common.GetStorageObjectOptions{})
+		assert.NilError(t, err)
+		assert.Equal(t, result.Name, "a.txt")
+		assert.Equal(t, inner.calls, 3)
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		inner := &mockFlakyClient{failures: 5, err: unavailable}
+		client := newRetryClient(inner, newTestRetryPolicy())
+
+		_, err := client.StatObject(context.TODO(), "bucket", "a.txt", common.GetStorageObjectOptions{})
+		assert.ErrorIs(t, err, unavailable)
+		assert.Equal(t, inner.calls, 3)
+	})
+
+	t.Run("not_retryable", func(t *testing.T) {
+		notFound := schema.UnprocessableContentError("not found", map[string]any{
+			"statusCode": http.StatusNotFound,
+		})
+		inner := &mockFlakyClient{failures: 1, err: notFound}
+		client := newRetryClient(inner, newTestRetryPolicy())
+
+		_, err := client.StatObject(context.TODO(), "bucket", "a.txt", common.GetStorageObjectOptions{})
+		assert.ErrorIs(t, err, notFound)
+		assert.Equal(t, inner.calls, 1)
+	})
+
+	t.Run("seekable_upload", func(t *testing.T) {
+		inner := &mockFlakyClient{failures: 1, err: unavailable}
+		client := newRetryClient(inner, newTestRetryPolicy())
+
+		_, err := client.PutObject(
+			context.TODO(),
+			"bucket",
+			"a.txt",
+			&common.PutStorageObjectOptions{},
+			strings.NewReader("hello"),
+			5,
+		)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, inner.payloads, []string{"hello", "hello"})
+	})
+
+	t.Run("stream_upload", func(t *testing.T) {
+		inner := &mockFlakyClient{failures: 1, err: unavailable}
+		client := newRetryClient(inner, newTestRetryPolicy())
+
+		_, err := client.PutObject(
+			context.TODO(),
+			"bucket",
+			"a.txt",
+			&common.PutStorageObjectOptions{},
+			io.NopCloser(strings.NewReader("hello")),
+			5,
+		)
+		assert.ErrorIs(t, err, unavailable)
+		assert.Equal(t, inner.calls, 1)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		inner := &mockFlakyClient{failures: 5, err: unavailable}
+		client := newRetryClient(inner, newTestRetryPolicy())
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		_, err := client.StatObject(ctx, "bucket", "a.txt", common.GetStorageObjectOptions{})
+		assert.ErrorIs(t, err, unavailable)
+		assert.Equal(t, inner.calls, 1)
+	})
+
+	inner := &mockFlakyClient{}
+	assert.Equal(t, newRetryClient(inner, nil), common.StorageClient(inner))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := newTestRetryPolicy()
+
+	assert.Equal(t, policy.Backoff(1), time.Millisecond)
+	assert.Equal(t, policy.Backoff(2), 2*time.Millisecond)
+	assert.Equal(t, policy.Backoff(10), 5*time.Millisecond)
+	assert.Equal(t, common.RetryPolicy{}.GetMaxAttempts(), 3)
+
+	policy.OperationTimeouts = map[string]int{"GetObject": 30}
+	policy.Timeout = 5
+	assert.Equal(t, policy.GetTimeout("GetObject"), 30*time.Second)
+	assert.Equal(t, policy.GetTimeout("StatObject"), 5*time.Second)
+
+	assert.Assert(t, policy.IsRetryableError(common.NewTransientError(io.ErrUnexpectedEOF)))
+	assert.Assert(t, policy.IsRetryableError(io.ErrUnexpectedEOF))
+	assert.Assert(t, !policy.IsRetryableError(context.DeadlineExceeded))
+	assert.Assert(t, !policy.IsRetryableError(errors.New("invalid argument")))
+
+	policy.RetryableStatusCodes = []int{http.StatusConflict}
+	assert.Assert(t, policy.IsRetryableError(schema.UnprocessableContentError("conflict", map[string]any{
+		"statusCode": http.StatusConflict,
+	})))
+	// the status code of the provider response takes precedence over the retryability of the error code.
+	throttled := common.NewStorageError(common.ErrorCodeThrottled, "slow down", map[string]any{
+		"statusCode": http.StatusTooManyRequests,
+	})
+	assert.Assert(t, !policy.IsRetryableError(throttled))
+	// stable codes that providers map from specific reasons take precedence over the status code.
+	rateLimited := common.NewStorageError(common.ErrorCodeThrottled, "rate limit exceeded", map[string]any{
+		"statusCode": http.StatusForbidden,
+	})
+	assert.Assert(t, policy.IsRetryableError(rateLimited))
+
+	assert.NilError(t, policy.Validate())
+	assert.ErrorContains(t, common.RetryPolicy{Jitter: utils.ToPtr(2.0)}.Validate(), "jitter")
+	assert.ErrorContains(
+		t,
+		common.RetryPolicy{RetryableStatusCodes: []int{1000}}.Validate(),
+		"invalid retryable status code",
+	)
+}
+
+func TestRetryClientNonIdempotent(t *testing.T) {
+	unavailable := schema.NewConnectorError(http.StatusServiceUnavailable, "unavailable", map[string]any{
+		"statusCode": http.StatusServiceUnavailable,
+	})
+
+	// the first attempt succeeds in the storage, but the response is lost.
+	// Repeated attempts would fail with spurious errors, so the original error is returned.
+	t.Run("conditional_upload", func(t *testing.T) {
+		inner := &mockLostResponseClient{err: unavailable}
+		client := newRetryClient(inner, newTestRetryPolicy())
+
+		_, err := client.PutObject(
+			context.TODO(),
+			"bucket",
+			"a.txt",
+			&common.PutStorageObjectOptions{IfNoneMatch: common.ETagAny},
+			strings.NewReader("hello"),
+			5,
+		)
+		assert.ErrorIs(t, err, unavailable)
+		assert.Equal(t, inner.calls, 1)
+	})
+
+	t.Run("conditional_update", func(t *testing.T) {
+		inner := &mockLostResponseClient{err: unavailable}
+		client := newRetryClient(inner, newTestRetryPolicy())
+
+		err := client.UpdateObject(context.TODO(), "bucket", "a.txt", common.UpdateStorageObjectOptions{
+			IfMatch:  "abc",
+			Metadata: &[]common.StorageKeyValue{},
+		})
+		assert.ErrorIs(t, err, unavailable)
+		assert.Equal(t, inner.calls, 1)
+	})
+
+	t.Run("remove_bucket", func(t *testing.T) {
+		inner := &mockLostResponseClient{err: unavailable}
+		client := newRetryClient(inner, newTestRetryPolicy())
+
+		assert.ErrorIs(t, client.RemoveBucket(context.TODO(), "bucket"), unavailable)
+		assert.Equal(t, inner.calls, 1)
+	})
+}
@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const defaultSecretReloadInterval = 30
+
+// SecretSettings hold settings of the rotation of secrets which are read from files or environment variables.
+type SecretSettings struct {
+	// Interval in seconds to check if secret files are changed. Set 0 to disable the periodic check.
+	// Secrets are also checked when the connector receives the SIGHUP signal.
+	ReloadInterval int `json:"reloadInterval" jsonschema:"min=0,default=30" yaml:"reloadInterval"`
+}
+
+var defaultSecretSettings = SecretSettings{
+	ReloadInterval: defaultSecretReloadInterval,
+}
+
+// secretReferences hold fingerprints of secret files and environment variables that a client configuration references.
+type secretReferences struct {
+	files map[string]string
+	envs  map[string]string
+}
+
+func newSecretReferences() *secretReferences {
+	return &secretReferences{
+		files: map[string]string{},
+		envs:  map[string]string{},
+	}
+}
+
+// IsEmpty checks if the client configuration doesn't reference any secret.
+func (sr *secretReferences) IsEmpty() bool {
+	return len(sr.files) == 0 && len(sr.envs) == 0
+}
+
+// Changed checks if any referenced secret file or environment variable is changed.
+func (sr *secretReferences) Changed() (bool, error) {
+	for name, value := range sr.envs {
+		if os.Getenv(name) != value {
+			return true, nil
+		}
+	}
+
+	for filePath, hash := range sr.files {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return false, fmt.Errorf("failed to read secret file %s: %w", filePath, err)
+		}
+
+		if hashSecret(content) != hash {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// resolve walks the raw client configuration and replaces file references of secret values,
+// such as mounted Kubernetes secrets or Docker secrets, with their contents.
+// Files aren't read if readFiles is false, for example, when the configuration is validated.
+func (sr *secretReferences) resolve(value any, readFiles bool) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		if filePath, ok := getSecretFilePath(v); ok {
+			if !readFiles {
+				return map[string]any{"value": ""}, nil
+			}
+
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read secret file %s: %w", filePath, err)
+			}
+
+			sr.files[filePath] = hashSecret(content)
+
+			return map[string]any{
+				"value": strings.TrimRight(string(content), "\r\n"),
+			}, nil
+		}
+
+		if envName, ok := v["env"].(string); ok && envName != "" {
+			sr.envs[envName] = os.Getenv(envName)
+		}
+
+		result := make(map[string]any, len(v))
+
+		for key, item := range v {
+			resolved, err := sr.resolve(item, readFiles)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+
+			result[key] = resolved
+		}
+
+		return result, nil
+	case []any:
+		result := make([]any, len(v))
+
+		for i, item := range v {
+			resolved, err := sr.resolve(item, readFiles)
+			if err != nil {
+				return nil, fmt.Errorf("%d: %w", i, err)
+			}
+
+			result[i] = resolved
+		}
+
+		return result, nil
+	default:
+		return value, nil
+	}
+}
+
+// getSecretFilePath returns the path if the value is a file reference, i.e. an object with the file field only.
+func getSecretFilePath(value map[string]any) (string, bool) {
+	if len(value) != 1 {
+		return "", false
+	}
+
+	filePath, ok := value["file"].(string)
+
+	return filePath, ok && filePath != ""
+}
+
+func hashSecret(content []byte) string {
+	hash := sha256.Sum256(content)
+
+	return hex.EncodeToString(hash[:])
+}
+
+// resolveSecrets returns a copy of the configuration whose secret file references are replaced with their contents.
+func (cc ClientConfig) resolveSecrets(readFiles bool) (ClientConfig, *secretReferences, error) {
+	refs := newSecretReferences()
+
+	resolved, err := refs.resolve(map[string]any(cc), readFiles)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, _ := resolved.(map[string]any)
+
+	return result, refs, nil
+}
+
+// watchSecrets rebuilds clients whose secrets are rotated periodically or when the SIGHUP signal is received.
+func (m *Manager) watchSecrets(ctx context.Context, settings *SecretSettings) {
+	if settings == nil {
+		settings = &defaultSecretSettings
+	}
+
+	hasSecrets := false
+
+	for _, refs := range m.clientSecrets {
+		if !refs.IsEmpty() {
+			hasSecrets = true
+
+			break
+		}
+	}
+
+	if !hasSecrets {
+		return
+	}
+
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(signalChan)
+
+		var tickerChan <-chan time.Time
+
+		if settings.ReloadInterval > 0 {
+			ticker := time.NewTicker(time.Duration(settings.ReloadInterval) * time.Second)
+			defer ticker.Stop()
+
+			tickerChan = ticker.C
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signalChan:
+				m.logger.Info("received SIGHUP, reloading rotated secrets")
+				m.reloadSecrets(ctx)
+			case <-tickerChan:
+				m.reloadSecrets(ctx)
+			}
+		}
+	}()
+}
+
+// reloadSecrets rebuilds clients whose secrets are changed and swaps them atomically.
+// In-flight requests keep using the old clients until they finish.
+func (m *Manager) reloadSecrets(ctx context.Context) {
+	m.reloadLock.Lock()
+	defer m.reloadLock.Unlock()
+
+	currentClients := m.getClients()
+
+	var newClients []Client
+
+	var oldClients []Client
+
+	for i, refs := range m.clientSecrets {
+		changed, err := refs.Changed()
+		if err != nil {
+			m.logger.Error(
+				"failed to check rotated secrets of the storage client",
+				"client_id", currentClients[i].id,
+				"error", err,
+			)
+
+			continue
+		}
+
+		if !changed {
+			continue
+		}
+
+		client, newRefs, err := m.newClient(ctx, i, m.configs[i])
+		if err != nil {
+			m.logger.Error(
+				"failed to reload the storage client with rotated secrets. Keep using the old client",
+				"client_id", currentClients[i].id,
+				"error", err,
+			)
+
+			continue
+		}
+
+		if newClients == nil {
+			newClients = make([]Client, len(currentClients))
+			copy(newClients, currentClients)
+		}
+
+		newClients[i] = *client
+		m.clientSecrets[i] = newRefs
+		oldClients = append(oldClients, currentClients[i])
+
+		m.logger.Info("reloaded the storage client with rotated secrets", "client_id", client.id)
+	}
+
+	if newClients == nil {
+		return
+	}
+
+	m.clients.Store(&newClients)
+
+	for _, client := range oldClients {
+		// old clients are closed after in-flight requests release them.
+		client.retire()
+	}
+}
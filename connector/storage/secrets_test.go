@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hasura/ndc-sdk-go/v2/utils"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"gotest.tools/v3/assert"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	assert.NilError(t, os.WriteFile(secretFile, []byte("secret-value\n"), 0o600))
+	t.Setenv("TEST_ACCESS_KEY_ID", "access-key")
+
+	config := ClientConfig{
+		"type": "s3",
+		"authentication": map[string]any{
+			"type":            "static",
+			"accessKeyId":     map[string]any{"env": "TEST_ACCESS_KEY_ID"},
+			"secretAccessKey": map[string]any{"file": secretFile},
+		},
+	}
+
+	resolved, refs, err := config.resolveSecrets(true)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, resolved["authentication"], map[string]any{
+		"type":            "static",
+		"accessKeyId":     map[string]any{"env": "TEST_ACCESS_KEY_ID"},
+		"secretAccessKey": map[string]any{"value": "secret-value"},
+	})
+	// the original configuration isn't modified.
+	assert.DeepEqual(
+		t,
+		config["authentication"].(map[string]any)["secretAccessKey"],
+		map[string]any{"file": secretFile},
+	)
+
+	changed, err := refs.Changed()
+	assert.NilError(t, err)
+	assert.Assert(t, !changed)
+
+	assert.NilError(t, os.WriteFile(secretFile, []byte("rotated-value"), 0o600))
+
+	changed, err = refs.Changed()
+	assert.NilError(t, err)
+	assert.Assert(t, changed)
+
+	_, refs, err = config.resolveSecrets(true)
+	assert.NilError(t, err)
+
+	t.Setenv("TEST_ACCESS_KEY_ID", "rotated-key")
+
+	changed, err = refs.Changed()
+	assert.NilError(t, err)
+	assert.Assert(t, changed)
+
+	// secret files aren't read when validating.
+	assert.NilError(t, ClientConfig{
+		"type": "s3",
+		"authentication": map[string]any{
+			"type":            "static",
+			"accessKeyId":     map[string]any{"value": "access-key"},
+			"secretAccessKey": map[string]any{"file": "/not/found"},
+		},
+	}.Validate())
+
+	_, _, err = ClientConfig{
+		"type": "s3",
+		"authentication": map[string]any{
+			"type":            "static",
+			"accessKeyId":     map[string]any{"value": "access-key"},
+			"secretAccessKey": map[string]any{"file": "/not/found"},
+		},
+	}.resolveSecrets(true)
+	assert.ErrorContains(t, err, "failed to read secret file /not/found")
+}
+
+func TestReloadSecrets(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	assert.NilError(t, os.WriteFile(secretFile, []byte("secret-value"), 0o600))
+
+	newConfig := func(id string, secretAccessKey map[string]any) ClientConfig {
+		return ClientConfig{
+			"id":       id,
+			"type":     "s3",
+			"endpoint": map[string]any{"value": "http://localhost:9000"},
+			"authentication": map[string]any{
+				"type":            "static",
+				"accessKeyId":     map[string]any{"value": "access-key"},
+				"secretAccessKey": secretAccessKey,
+			},
+		}
+	}
+
+	manager, err := NewManager(context.TODO(), []ClientConfig{
+		newConfig("rotated", map[string]any{"file": secretFile}),
+		newConfig("static", map[string]any{"value": "secret-value"}),
+	}, RuntimeSettings{
+		Secrets: &SecretSettings{ReloadInterval: 0},
+	}, nil, nil, nil, slog.Default())
+	assert.NilError(t, err)
+
+	defer manager.stopSecretWatcher()
+
+	oldClients := manager.getClients()
+
+	// nothing is changed.
+	manager.reloadSecrets(context.TODO())
+	assert.Equal(t, &manager.getClients()[0], &oldClients[0])
+
+	// an in-flight request holds the old client.
+	ctx, release := ContextWithClientLeases(context.TODO())
+	client, _, err := manager.GetClientAndBucket(ctx, common.StorageBucketArguments{
+		StorageClientCredentialArguments: common.StorageClientCredentialArguments{
+			ClientID: utils.ToPtr(common.StorageClientID("rotated")),
+		},
+		Bucket: "bucket",
+	})
+	assert.NilError(t, err)
+	assert.Assert(t, client.StorageClient == oldClients[0].StorageClient)
+
+	assert.NilError(t, os.WriteFile(secretFile, []byte("rotated-value"), 0o600))
+	manager.reloadSecrets(context.TODO())
+
+	newClients := manager.getClients()
+	assert.Equal(t, newClients[0].id, oldClients[0].id)
+	assert.Assert(t, newClients[0].StorageClient != oldClients[0].StorageClient)
+
+	// the old client is closed after the in-flight request finishes.
+	assert.Assert(t, !oldClients[0].lease.closed)
+	release()
+	assert.Assert(t, oldClients[0].lease.closed)
+	assert.Assert(t, !oldClients[1].lease.closed)
+	// clients without rotated secrets are reused.
+	assert.Assert(t, newClients[1].StorageClient == oldClients[1].StorageClient)
+
+	// the old client is kept if the secret file can't be read.
+	assert.NilError(t, os.Remove(secretFile))
+	manager.reloadSecrets(context.TODO())
+	assert.Assert(t, manager.getClients()[0].StorageClient == newClients[0].StorageClient)
+}
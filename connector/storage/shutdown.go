@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+)
+
+const (
+	defaultShutdownTimeout = 30
+	// the maximum time to remove the incomplete multipart upload of an aborted upload.
+	incompleteUploadCleanupTimeout = 30 * time.Second
+)
+
+var errManagerClosed = schema.NewConnectorError(
+	http.StatusServiceUnavailable,
+	"the connector is shutting down",
+	nil,
+)
+
+// GetShutdownTimeout returns the maximum duration to wait for in-flight uploads on shutdown.
+func (rs RuntimeSettings) GetShutdownTimeout() time.Duration {
+	if rs.ShutdownTimeout == nil {
+		return defaultShutdownTimeout * time.Second
+	}
+
+	return time.Duration(*rs.ShutdownTimeout) * time.Second
+}
+
+// Close shuts down the manager gracefully. New operations are rejected and in-flight uploads are drained
+// until the shutdown timeout. Uploads that don't finish in time are aborted and their incomplete multipart uploads are removed.
+// Then audit sinks are flushed and clients and idle connections are closed.
+// Close returns within the shutdown timeout plus the cleanup timeout even if the context has no deadline.
+func (m *Manager) Close(ctx context.Context) error {
+	if !m.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+
+	m.stopSecretWatcher()
+
+	shutdownTimeout := m.runtime.GetShutdownTimeout()
+
+	closeCtx, cancelClose := context.WithTimeout(ctx, shutdownTimeout+incompleteUploadCleanupTimeout)
+	defer cancelClose()
+
+	drainCtx, cancel := context.WithTimeout(closeCtx, shutdownTimeout)
+	defer cancel()
+
+	if aborted := m.uploads.Close(closeCtx, drainCtx); aborted > 0 {
+		m.logger.Warn(
+			"aborted in-flight uploads that didn't finish before the shutdown timeout",
+			"count", aborted,
+		)
+	}
+
+	// audit sinks are flushed before clients are closed because object sinks write with them.
+	err := m.audit.Close(closeCtx)
+
+	for _, client := range m.getClients() {
+		client.retire()
+	}
+
+	m.clientCache.Close()
+	m.httpClient.CloseIdleConnections()
+
+	if m.circuitMetrics != nil {
+		_ = m.circuitMetrics.Unregister()
+	}
+
+	return err
+}
+
+// beginUpload registers an in-flight upload to the object. The returned context is canceled
+// if the upload doesn't finish before the shutdown timeout. The done function must be called when the upload returns.
+func (m *Manager) beginUpload(
+	ctx context.Context,
+	client *Client,
+	bucketName string,
+	objectName string,
+) (context.Context, func(), error) {
+	uploadCtx, cancel := context.WithCancel(ctx)
+
+	upload := m.uploads.Add(cancel)
+	if upload == nil {
+		cancel()
+
+		return nil, nil, errManagerClosed
+	}
+
+	done := func() {
+		defer cancel()
+
+		m.uploads.Done(upload, func() {
+			m.removeIncompleteUpload(ctx, client, bucketName, objectName)
+		})
+	}
+
+	return uploadCtx, done, nil
+}
+
+// removeIncompleteUpload cleans up the incomplete multipart upload of an aborted upload.
+// Other providers don't keep parts of aborted uploads. Uncommitted blocks of Azure Blob Storage
+// are garbage collected by the service, and removing the blob would delete the existing object.
+func (m *Manager) removeIncompleteUpload(
+	ctx context.Context,
+	client *Client,
+	bucketName string,
+	objectName string,
+) {
+	if client.provider != common.StorageProviderTypeS3 {
+		return
+	}
+
+	cleanupCtx, cancel := context.WithTimeout(
+		context.WithoutCancel(ctx),
+		incompleteUploadCleanupTimeout,
+	)
+	defer cancel()
+
+	if err := client.RemoveIncompleteUpload(cleanupCtx, bucketName, objectName); err != nil {
+		m.logger.Error(
+			"failed to remove the incomplete upload of an aborted upload",
+			"client_id", client.id,
+			"bucket", bucketName,
+			"object", objectName,
+			"error", err,
+		)
+	}
+}
+
+// uploadTracker tracks in-flight uploads so that they can be drained on shutdown.
+type uploadTracker struct {
+	mu      sync.Mutex
+	closing bool
+	uploads map[*inflightUpload]struct{}
+	wg      sync.WaitGroup
+}
+
+type inflightUpload struct {
+	cancel  context.CancelFunc
+	aborted bool
+}
+
+// Add registers a new upload. The result is nil if the tracker is closing.
+func (ut *uploadTracker) Add(cancel context.CancelFunc) *inflightUpload {
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+
+	if ut.closing {
+		return nil
+	}
+
+	if ut.uploads == nil {
+		ut.uploads = map[*inflightUpload]struct{}{}
+	}
+
+	upload := &inflightUpload{cancel: cancel}
+	ut.uploads[upload] = struct{}{}
+	ut.wg.Add(1)
+
+	return upload
+}
+
+// Done unregisters the upload. The cleanup function is called if the upload was aborted by the shutdown.
+func (ut *uploadTracker) Done(upload *inflightUpload, cleanup func()) {
+	defer ut.wg.Done()
+
+	ut.mu.Lock()
+	delete(ut.uploads, upload)
+	aborted := upload.aborted
+	ut.mu.Unlock()
+
+	if aborted {
+		cleanup()
+	}
+}
+
+// Close rejects new uploads and waits for in-flight uploads until the drain context is done.
+// Remaining uploads are aborted, then the tracker waits for them to clean up until the parent context is done.
+// Returns the number of aborted uploads.
+func (ut *uploadTracker) Close(ctx context.Context, drainCtx context.Context) int {
+	ut.mu.Lock()
+	ut.closing = true
+	ut.mu.Unlock()
+
+	drained := make(chan struct{})
+
+	go func() {
+		ut.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return 0
+	case <-drainCtx.Done():
+	}
+
+	ut.mu.Lock()
+
+	aborted := len(ut.uploads)
+
+	for upload := range ut.uploads {
+		upload.aborted = true
+		upload.cancel()
+	}
+
+	ut.mu.Unlock()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+	}
+
+	return aborted
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"gotest.tools/v3/assert"
+)
+
+func TestUploadTrackerDrain(t *testing.T) {
+	var tracker uploadTracker
+
+	uploadCtx, cancel := context.WithCancel(context.TODO())
+	upload := tracker.Add(cancel)
+	assert.Assert(t, upload != nil)
+
+	finished := make(chan struct{})
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		tracker.Done(upload, func() {
+			t.Error("finished uploads must not be cleaned up")
+		})
+		close(finished)
+	}()
+
+	assert.Equal(t, tracker.Close(context.TODO(), context.TODO()), 0)
+	<-finished
+	assert.NilError(t, uploadCtx.Err())
+	assert.Assert(t, tracker.Add(func() {}) == nil)
+}
+
+func TestUploadTrackerAbort(t *testing.T) {
+	var tracker uploadTracker
+
+	var cleaned atomic.Bool
+
+	uploadCtx, cancel := context.WithCancel(context.TODO())
+	upload := tracker.Add(cancel)
+
+	go func() {
+		// the upload only returns when it's aborted.
+		<-uploadCtx.Done()
+		tracker.Done(upload, func() {
+			cleaned.Store(true)
+		})
+	}()
+
+	drainCtx, cancelDrain := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancelDrain()
+
+	assert.Equal(t, tracker.Close(context.TODO(), drainCtx), 1)
+	assert.Assert(t, cleaned.Load())
+}
+
+func TestManagerClose(t *testing.T) {
+	manager, err := NewManager(context.TODO(), []ClientConfig{
+		{
+			"id":               "local",
+			"type":             "fs",
+			"defaultDirectory": map[string]any{"value": t.TempDir()},
+		},
+	}, RuntimeSettings{
+		MaxUploadSizeMBs:   1,
+		MaxDownloadSizeMBs: 1,
+	}, nil, nil, nil, slog.Default())
+	assert.NilError(t, err)
+
+	_, err = manager.PutObject(
+		context.TODO(),
+		common.StorageBucketArguments{},
+		"a.txt",
+		&common.PutStorageObjectOptions{},
+		[]byte("hello"),
+	)
+	assert.NilError(t, err)
+
+	assert.NilError(t, manager.Close(context.TODO()))
+	// closing twice is a no-op.
+	assert.NilError(t, manager.Close(context.TODO()))
+
+	_, err = manager.PutObject(
+		context.TODO(),
+		common.StorageBucketArguments{},
+		"b.txt",
+		&common.PutStorageObjectOptions{},
+		[]byte("hello"),
+	)
+	assert.ErrorIs(t, err, errManagerClosed)
+
+	_, _, err = manager.GetObject(
+		context.TODO(),
+		common.StorageBucketArguments{},
+		"a.txt",
+		common.GetStorageObjectOptions{},
+	)
+	assert.ErrorIs(t, err, errManagerClosed)
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"io"
+
+	"github.com/hasura/ndc-storage/connector/storage/common"
+)
+
+// customerKeyClient wraps a storage client to resolve customer-provided keys of the server-side encryption.
+// Arguments reference keys in the client configuration by ID so that raw keys aren't sent in requests.
+type customerKeyClient struct {
+	common.StorageClient
+
+	keys map[string]string
+}
+
+var _ common.StorageClient = (*customerKeyClient)(nil)
+
+func newCustomerKeyClient(
+	client common.StorageClient,
+	configs []common.EncryptionKeyConfig,
+) (*customerKeyClient, error) {
+	keys, err := common.LoadCustomerKeys(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &customerKeyClient{
+		StorageClient: client,
+		keys:          keys,
+	}, nil
+}
+
+// Close closes the inner client if it holds resources.
+func (ckc *customerKeyClient) Close() error {
+	return closeStorageClient(ckc.StorageClient)
+}
+
+// GetObject returns a stream of the object data.
+func (ckc *customerKeyClient) GetObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (io.ReadCloser, error) {
+	opts.ServerSideEncryption = ckc.resolve(opts.ServerSideEncryption)
+
+	return ckc.StorageClient.GetObject(ctx, bucketName, objectName, opts)
+}
+
+// PutObject uploads the object.
+func (ckc *customerKeyClient) PutObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts *common.PutStorageObjectOptions,
+	reader io.Reader,
+	objectSize int64,
+) (*common.StorageUploadInfo, error) {
+	if opts != nil && opts.ServerSideEncryption != nil {
+		putOptions := *opts
+		putOptions.ServerSideEncryption = ckc.resolve(opts.ServerSideEncryption)
+		opts = &putOptions
+	}
+
+	return ckc.StorageClient.PutObject(ctx, bucketName, objectName, opts, reader, objectSize)
+}
+
+// CopyObject creates or replaces an object through server-side copying of an existing object.
+func (ckc *customerKeyClient) CopyObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	src common.StorageCopySrcOptions,
+) (*common.StorageUploadInfo, error) {
+	dest.Encryption = ckc.resolve(dest.Encryption)
+	src.Encryption = ckc.resolve(src.Encryption)
+
+	return ckc.StorageClient.CopyObject(ctx, dest, src)
+}
+
+// ComposeObject creates an object by concatenating a list of source objects using server-side copying.
+func (ckc *customerKeyClient) ComposeObject(
+	ctx context.Context,
+	dest common.StorageCopyDestOptions,
+	srcs []common.StorageCopySrcOptions,
+) (*common.StorageUploadInfo, error) {
+	dest.Encryption = ckc.resolve(dest.Encryption)
+	sources := make([]common.StorageCopySrcOptions, len(srcs))
+
+	for i, src := range srcs {
+		src.Encryption = ckc.resolve(src.Encryption)
+		sources[i] = src
+	}
+
+	return ckc.StorageClient.ComposeObject(ctx, dest, sources)
+}
+
+// StatObject fetches metadata of an object.
+func (ckc *customerKeyClient) StatObject(
+	ctx context.Context,
+	bucketName, objectName string,
+	opts common.GetStorageObjectOptions,
+) (*common.StorageObject, error) {
+	opts.ServerSideEncryption = ckc.resolve(opts.ServerSideEncryption)
+
+	return ckc.StorageClient.StatObject(ctx, bucketName, objectName, opts)
+}
+
+// resolve fills the key of the SSE_C method from the client configuration if the key ID is set.
+func (ckc *customerKeyClient) resolve(
+	sse *common.ServerSideEncryption,
+) *common.ServerSideEncryption {
+	if sse == nil || sse.Method != common.ServerSideEncryptionMethodSseC ||
+		(sse.Key != nil && *sse.Key != "") || sse.KeyID == nil {
+		return sse
+	}
+
+	key, ok := ckc.keys[*sse.KeyID]
+	if !ok {
+		return sse
+	}
+
+	return &common.ServerSideEncryption{
+		Method: sse.Method,
+		Key:    &key,
+		KeyID:  sse.KeyID,
+	}
+}
@@ -0,0 +1,409 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hasura/ndc-sdk-go/v2/schema"
+	"github.com/hasura/ndc-storage/connector/storage/common"
+)
+
+// DefaultTransactionSnapshotPrefix is the default key prefix of temporary snapshot objects.
+const DefaultTransactionSnapshotPrefix = ".ndc-storage/transactions/"
+
+type transactionContextKey struct{}
+
+// Transaction records compensating actions of object mutations in a mutation request.
+// If an operation fails, applied changes are reverted in the reverse order.
+type Transaction struct {
+	id             string
+	snapshotPrefix string
+
+	lock sync.Mutex
+	// snapshots maps snapshotted objects to whether their compensating actions were registered.
+	snapshots     map[string]bool
+	versionings   map[string]bool
+	compensations []transactionAction
+	cleanups      []transactionAction
+}
+
+type transactionAction struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// NewTransaction creates a new transaction.
+func NewTransaction(snapshotPrefix string) *Transaction {
+	if snapshotPrefix == "" {
+		snapshotPrefix = DefaultTransactionSnapshotPrefix
+	}
+
+	return &Transaction{
+		id:             newTransactionID(),
+		snapshotPrefix: snapshotPrefix,
+		snapshots:      map[string]bool{},
+		versionings:    map[string]bool{},
+	}
+}
+
+// ID returns the unique identity of the transaction.
+func (tx *Transaction) ID() string {
+	return tx.id
+}
+
+// ContextWithTransaction returns a new context with the transaction.
+func ContextWithTransaction(ctx context.Context, tx *Transaction) context.Context {
+	return context.WithValue(ctx, transactionContextKey{}, tx)
+}
+
+// TransactionFromContext gets the transaction from context if exists.
+func TransactionFromContext(ctx context.Context) *Transaction {
+	tx, ok := ctx.Value(transactionContextKey{}).(*Transaction)
+	if !ok {
+		return nil
+	}
+
+	return tx
+}
+
+// SetTransactionSnapshotPrefix hides temporary snapshot objects of transactions under the prefix
+// and their parent directories from listing results.
+func (m *Manager) SetTransactionSnapshotPrefix(snapshotPrefix string) {
+	if snapshotPrefix == "" {
+		snapshotPrefix = DefaultTransactionSnapshotPrefix
+	}
+
+	m.snapshotPrefix = strings.TrimSuffix(snapshotPrefix, "/") + "/"
+}
+
+// hideTransactionSnapshots wraps the list predicate to exclude temporary snapshot objects of transactions.
+func (m *Manager) hideTransactionSnapshots(predicate func(string) bool) func(string) bool {
+	if m.snapshotPrefix == "" {
+		return predicate
+	}
+
+	return func(name string) bool {
+		// directory names may not have the trailing slash.
+		if strings.HasPrefix(name, m.snapshotPrefix) ||
+			strings.HasPrefix(m.snapshotPrefix, strings.TrimSuffix(name, "/")+"/") {
+			return false
+		}
+
+		return predicate == nil || predicate(name)
+	}
+}
+
+// SnapshotPrefix returns the key prefix of temporary snapshot objects of the transaction.
+// Snapshots are kept under this prefix if the rollback fails so that objects can be recovered manually.
+func (tx *Transaction) SnapshotPrefix() string {
+	return path.Join(tx.snapshotPrefix, tx.id) + "/"
+}
+
+// Commit completes the transaction and removes temporary snapshots.
+func (tx *Transaction) Commit(ctx context.Context) error {
+	_, cleanups := tx.takeActions()
+
+	return errors.Join(runTransactionActions(ctx, cleanups)...)
+}
+
+// Rollback reverts applied changes in the reverse order and removes temporary snapshots.
+func (tx *Transaction) Rollback(ctx context.Context) error {
+	compensations, cleanups := tx.takeActions()
+	slices.Reverse(compensations)
+
+	errs := runTransactionActions(ctx, compensations)
+	// keep snapshots if the rollback fails so that they can be recovered manually.
+	if len(errs) == 0 {
+		errs = runTransactionActions(ctx, cleanups)
+	}
+
+	return errors.Join(errs...)
+}
+
+// takeActions detaches registered actions from the transaction, so that they run without holding the lock.
+func (tx *Transaction) takeActions() ([]transactionAction, []transactionAction) {
+	tx.lock.Lock()
+	defer tx.lock.Unlock()
+
+	compensations, cleanups := tx.compensations, tx.cleanups
+	tx.compensations = nil
+	tx.cleanups = nil
+
+	return compensations, cleanups
+}
+
+// isSnapshotted checks if the compensating action of the object snapshot was registered.
+func (tx *Transaction) isSnapshotted(snapshotKey string) bool {
+	tx.lock.Lock()
+	defer tx.lock.Unlock()
+
+	return tx.snapshots[snapshotKey]
+}
+
+func (tx *Transaction) addCompensation(name string, run func(ctx context.Context) error) {
+	tx.compensations = append(tx.compensations, transactionAction{name: name, run: run})
+}
+
+func (tx *Transaction) addCleanup(name string, run func(ctx context.Context) error) {
+	tx.cleanups = append(tx.cleanups, transactionAction{name: name, run: run})
+}
+
+func runTransactionActions(ctx context.Context, actions []transactionAction) []error {
+	var errs []error
+
+	for _, action := range actions {
+		if err := action.run(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", action.name, err))
+		}
+	}
+
+	return errs
+}
+
+// snapshotObject takes a snapshot of the current state of the object if the mutation is running in a transaction.
+// The object is restored from the previous version if the bucket versioning is enabled.
+// Otherwise, a temporary copy of the object is created. The returned function registers the compensating action
+// and must be called after the mutation succeeds only, so that failed operations aren't reverted.
+// The temporary copy is a write of the session, so it's checked against access and upload policies.
+func (m *Manager) snapshotObject(
+	ctx context.Context,
+	client *Client,
+	bucketName, objectName string,
+	encryption *common.ServerSideEncryption,
+) (func(), error) {
+	tx := TransactionFromContext(ctx)
+	if tx == nil {
+		return func() {}, nil
+	}
+
+	// the first snapshot keeps the original state of the object.
+	snapshotKey := path.Join(string(client.id), bucketName, objectName)
+	if tx.isSnapshotted(snapshotKey) {
+		return func() {}, nil
+	}
+
+	// the customer-provided key is required to read and copy objects which were encrypted with the SSE_C method.
+	if encryption != nil && encryption.Method != common.ServerSideEncryptionMethodSseC {
+		encryption = nil
+	}
+
+	current, err := client.StatObject(ctx, bucketName, objectName, common.GetStorageObjectOptions{
+		ServerSideEncryption: encryption,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dest := common.StorageCopyDestOptions{
+		Bucket:     bucketName,
+		Name:       objectName,
+		Encryption: encryption,
+	}
+
+	if current == nil {
+		return tx.applySnapshot(snapshotKey, "remove "+objectName, func(ctx context.Context) error {
+			return client.RemoveObject(ctx, bucketName, objectName, common.RemoveStorageObjectOptions{})
+		}), nil
+	}
+
+	if current.IsDirectory {
+		return nil, schema.UnprocessableContentError(
+			"cannot overwrite a directory in a transaction: "+objectName,
+			nil,
+		)
+	}
+
+	if current.VersionID != nil && *current.VersionID != "" && *current.VersionID != "null" &&
+		m.isBucketVersioningEnabled(ctx, tx, client, bucketName) {
+		src := common.StorageCopySrcOptions{
+			Bucket:     bucketName,
+			Name:       objectName,
+			VersionID:  *current.VersionID,
+			Encryption: encryption,
+		}
+
+		return tx.applySnapshot(snapshotKey, "restore "+objectName, func(ctx context.Context) error {
+			_, err := client.CopyObject(ctx, dest, src)
+
+			return err
+		}), nil
+	}
+
+	src := common.StorageCopySrcOptions{
+		Bucket:     bucketName,
+		Name:       path.Join(tx.SnapshotPrefix(), objectName),
+		Encryption: encryption,
+	}
+
+	if err := m.authorizeObject(ctx, PolicyOperationWrite, client, src.Bucket, src.Name); err != nil {
+		return nil, err
+	}
+
+	snapshotDest := common.StorageCopyDestOptions{
+		Bucket:     src.Bucket,
+		Name:       src.Name,
+		Encryption: encryption,
+	}
+
+	snapshotSrc := common.StorageCopySrcOptions{
+		Bucket:     bucketName,
+		Name:       objectName,
+		Encryption: encryption,
+	}
+
+	err = validateCopyUploadPolicy(ctx, client, snapshotDest, []common.StorageCopySrcOptions{snapshotSrc})
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := client.CopyObject(ctx, snapshotDest, snapshotSrc); err != nil {
+		return nil, err
+	}
+
+	tx.lock.Lock()
+	defer tx.lock.Unlock()
+
+	// the temporary copy is overwritten if the mutation fails and the object is snapshotted again.
+	if _, ok := tx.snapshots[snapshotKey]; !ok {
+		tx.snapshots[snapshotKey] = false
+
+		tx.addCleanup("remove snapshot "+src.Name, func(ctx context.Context) error {
+			return client.RemoveObject(ctx, src.Bucket, src.Name, common.RemoveStorageObjectOptions{})
+		})
+	}
+
+	return tx.applySnapshot(snapshotKey, "restore "+objectName, func(ctx context.Context) error {
+		_, err := client.CopyObject(ctx, dest, src)
+
+		return err
+	}), nil
+}
+
+// applySnapshot returns a function that registers the compensating action of a snapshot.
+func (tx *Transaction) applySnapshot(snapshotKey string, name string, run func(ctx context.Context) error) func() {
+	return func() {
+		tx.lock.Lock()
+		defer tx.lock.Unlock()
+
+		if tx.snapshots[snapshotKey] {
+			return
+		}
+
+		tx.snapshots[snapshotKey] = true
+		tx.addCompensation(name, run)
+	}
+}
+
+// snapshotObjectAttributes takes a snapshot of metadata, tags and legal hold of the object.
+// The returned function registers the compensating action and must be called after the update succeeds.
+func (m *Manager) snapshotObjectAttributes(
+	ctx context.Context,
+	client *Client,
+	bucketName, objectName string,
+	opts common.UpdateStorageObjectOptions,
+) (func(), error) {
+	tx := TransactionFromContext(ctx)
+	if tx == nil {
+		return func() {}, nil
+	}
+
+	if opts.Retention != nil {
+		return nil, schema.UnprocessableContentError(
+			"object retention can't be reverted in a transaction",
+			nil,
+		)
+	}
+
+	statOptions := common.GetStorageObjectOptions{
+		Include: common.StorageObjectIncludeOptions{
+			Metadata:  opts.Metadata != nil,
+			Tags:      opts.Tags != nil,
+			LegalHold: opts.LegalHold != nil,
+		},
+	}
+
+	if opts.VersionID != "" {
+		statOptions.VersionID = &opts.VersionID
+	}
+
+	current, err := client.StatObject(ctx, bucketName, objectName, statOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if current == nil {
+		return func() {}, nil
+	}
+
+	revertOptions := common.UpdateStorageObjectOptions{
+		VersionID: opts.VersionID,
+	}
+
+	if opts.Metadata != nil {
+		revertOptions.Metadata = &current.Metadata
+	}
+
+	if opts.Tags != nil {
+		revertOptions.Tags = &current.Tags
+	}
+
+	if opts.LegalHold != nil && current.LegalHold != nil {
+		revertOptions.LegalHold = current.LegalHold
+	}
+
+	return func() {
+		tx.lock.Lock()
+		defer tx.lock.Unlock()
+
+		tx.addCompensation("revert "+objectName, func(ctx context.Context) error {
+			return client.UpdateObject(ctx, bucketName, objectName, revertOptions)
+		})
+	}, nil
+}
+
+func (m *Manager) isBucketVersioningEnabled(
+	ctx context.Context,
+	tx *Transaction,
+	client *Client,
+	bucketName string,
+) bool {
+	key := path.Join(string(client.id), bucketName)
+
+	tx.lock.Lock()
+	enabled, ok := tx.versionings[key]
+	tx.lock.Unlock()
+
+	if ok {
+		return enabled
+	}
+
+	bucket, err := client.GetBucket(ctx, bucketName, common.BucketOptions{
+		Include: common.BucketIncludeOptions{
+			Versioning: true,
+		},
+	})
+	enabled = err == nil && bucket != nil && bucket.Versioning != nil && bucket.Versioning.Enabled
+
+	tx.lock.Lock()
+	tx.versionings[key] = enabled
+	tx.lock.Unlock()
+
+	return enabled
+}
+
+func newTransactionID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().UTC().Format("20060102150405.000000000")
+	}
+
+	return time.Now().UTC().Format("20060102150405") + "-" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hasura/ndc-storage/connector/storage/common"
+	"gotest.tools/v3/assert"
+)
+
+func TestTransactionRollback(t *testing.T) {
+	dir := t.TempDir()
+	manager, err := NewManager(context.TODO(), []ClientConfig{
+		{
+			"id":               "local",
+			"type":             "fs",
+			"defaultDirectory": map[string]any{"value": dir},
+		},
+	}, RuntimeSettings{
+		MaxUploadSizeMBs:   1,
+		MaxDownloadSizeMBs: 1,
+	}, nil, nil, nil, slog.Default())
+	assert.NilError(t, err)
+
+	defer manager.Close(context.TODO())
+
+	manager.SetTransactionSnapshotPrefix("")
+
+	bucketArgs := common.StorageBucketArguments{}
+
+	_, err = manager.PutObject(context.TODO(), bucketArgs, "a.txt", &common.PutStorageObjectOptions{}, []byte("hello"))
+	assert.NilError(t, err)
+
+	tx := NewTransaction("")
+	ctx := ContextWithTransaction(context.TODO(), tx)
+
+	// failed mutations don't register compensating actions.
+	_, err = manager.PutObject(ctx, bucketArgs, "a.txt", &common.PutStorageObjectOptions{
+		IfNoneMatch: "*",
+	}, []byte("world"))
+	code, _ := common.GetStorageErrorCode(err)
+	assert.Equal(t, code, common.ErrorCodePreconditionFailed)
+	assert.Equal(t, len(tx.compensations), 0)
+
+	_, err = manager.PutObject(ctx, bucketArgs, "a.txt", &common.PutStorageObjectOptions{}, []byte("world"))
+	assert.NilError(t, err)
+
+	_, err = manager.PutObject(ctx, bucketArgs, "b.txt", &common.PutStorageObjectOptions{}, []byte("new"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(tx.compensations), 2)
+	assert.Equal(t, len(tx.cleanups), 1)
+
+	// temporary snapshots are hidden from listing results.
+	for _, recursive := range []bool{true, false} {
+		results, err := manager.ListObjects(context.TODO(), bucketArgs, &common.ListStorageObjectsOptions{
+			Recursive: recursive,
+		}, nil)
+		assert.NilError(t, err)
+
+		names := []string{}
+		for _, object := range results.Objects {
+			names = append(names, object.Name)
+		}
+
+		assert.DeepEqual(t, names, []string{"a.txt", "b.txt"})
+	}
+
+	assert.NilError(t, tx.Rollback(context.TODO()))
+
+	data, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(data), "hello")
+
+	_, err = os.Stat(filepath.Join(dir, "b.txt"))
+	assert.Assert(t, os.IsNotExist(err))
+
+	_, err = os.Stat(filepath.Join(dir, DefaultTransactionSnapshotPrefix, tx.ID(), "a.txt"))
+	assert.Assert(t, os.IsNotExist(err))
+}
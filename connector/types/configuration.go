@@ -3,12 +3,16 @@ package types
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/hasura/ndc-storage/connector/storage"
+	"github.com/hasura/ndc-storage/connector/storage/common"
 )
 
 const (
 	ConfigurationFileName = "configuration.yaml"
+
+	defaultReloadInterval = 10
 )
 
 // Configuration contains required settings for the connector.
@@ -21,6 +25,14 @@ type Configuration struct {
 	Runtime storage.RuntimeSettings `json:"runtime"               yaml:"runtime"`
 	// Schema generator settings.
 	Generator GeneratorSettings `json:"generator,omitempty"   yaml:"generator,omitempty"`
+	// Settings for transactional mutations.
+	Transaction TransactionSettings `json:"transaction,omitempty" yaml:"transaction,omitempty"`
+	// Access policies which are evaluated against session variables of requests.
+	Policy *storage.PolicySettings `json:"policy,omitempty"      yaml:"policy,omitempty"`
+	// Audit logs of mutations and presigned URL issuance.
+	Audit *storage.AuditSettings `json:"audit,omitempty"       yaml:"audit,omitempty"`
+	// Settings for reloading the configuration file without restarting the connector.
+	Reload ReloadSettings `json:"reload,omitempty"      yaml:"reload,omitempty"`
 }
 
 // Validate checks if the configuration is valid.
@@ -40,15 +52,42 @@ func (c Configuration) Validate() error {
 		return errors.New("maxDownloadSizeMBs must be larger than 0")
 	}
 
+	if c.Policy != nil {
+		if err := c.Policy.Validate(); err != nil {
+			return fmt.Errorf("invalid policy: %w", err)
+		}
+	}
+
+	if c.Audit != nil {
+		if err := c.Audit.Validate(); err != nil {
+			return fmt.Errorf("invalid audit settings: %w", err)
+		}
+	}
+
+	if c.Reload.Interval != nil && *c.Reload.Interval < 0 {
+		return errors.New("reload interval must not be negative")
+	}
+
 	return nil
 }
 
 // ConcurrencySettings represent settings for concurrent webhook executions to remote servers.
 type ConcurrencySettings struct {
 	// Maximum number of concurrent executions if there are many query variables.
-	Query int `json:"query"    jsonschema:"min=1,default=5" yaml:"query"`
+	Query int `json:"query"            jsonschema:"min=1,default=5" yaml:"query"`
 	// Maximum number of concurrent executions if there are many mutation operations.
-	Mutation int `json:"mutation" jsonschema:"min=1,default=1" yaml:"mutation"`
+	Mutation int `json:"mutation"         jsonschema:"min=1,default=1" yaml:"mutation"`
+	// Maximum number of concurrent items of batch uploads from URLs. The query concurrency is used if not set.
+	Upload int `json:"upload,omitempty" jsonschema:"min=0"           yaml:"upload,omitempty"`
+}
+
+// GetUpload returns the maximum number of concurrent items of batch uploads.
+func (cs ConcurrencySettings) GetUpload() int {
+	if cs.Upload > 0 {
+		return cs.Upload
+	}
+
+	return max(cs.Query, 1)
 }
 
 // GeneratorSettings represent settings for schema generation.
@@ -57,4 +96,46 @@ type GeneratorSettings struct {
 	PromptQLCompatible bool `json:"promptqlCompatible,omitempty" jsonschema:"default=false" yaml:"promptqlCompatible"`
 	// Allow users to input dynamic credentials.
 	DynamicCredentials bool `json:"dynamicCredentials,omitempty" jsonschema:"default=false" yaml:"dynamicCredentials"`
+	// Storage providers whose specific dynamic credential arguments are exposed. Arguments of all providers are exposed if empty.
+	DynamicCredentialProviders []common.StorageProviderType `json:"dynamicCredentialProviders,omitempty" yaml:"dynamicCredentialProviders,omitempty"`
+}
+
+// GetDynamicCredentialProviders returns the list of storage providers of dynamic credentials.
+// The result is nil if dynamic credentials are disabled.
+func (gs GeneratorSettings) GetDynamicCredentialProviders() []common.StorageProviderType {
+	if !gs.DynamicCredentials {
+		return nil
+	}
+
+	if gs.DynamicCredentialProviders == nil {
+		return []common.StorageProviderType{}
+	}
+
+	return gs.DynamicCredentialProviders
+}
+
+// TransactionSettings represent settings for transactional mutations.
+type TransactionSettings struct {
+	// Execute operations of a mutation request in a transaction. Overwritten and removed objects are snapshotted before changes.
+	// If an operation fails, applied changes are reverted in the reverse order.
+	Enabled bool `json:"enabled,omitempty"        jsonschema:"default=false"                      yaml:"enabled"`
+	// The key prefix of temporary snapshot objects if the bucket versioning isn't enabled.
+	SnapshotPrefix string `json:"snapshotPrefix,omitempty" jsonschema:"default=.ndc-storage/transactions/" yaml:"snapshotPrefix,omitempty"`
+}
+
+// ReloadSettings represent settings for reloading the configuration file without restarting the connector.
+type ReloadSettings struct {
+	// Watch the configuration file and apply changes without restarting. The configuration is also reloaded when the connector receives the SIGHUP signal.
+	Enabled bool `json:"enabled,omitempty"  jsonschema:"default=false"    yaml:"enabled"`
+	// Interval in seconds to check if the configuration file is changed. Set 0 to reload on the SIGHUP signal only.
+	Interval *int `json:"interval,omitempty" jsonschema:"min=0,default=10" yaml:"interval,omitempty"`
+}
+
+// GetInterval returns the check interval of the configuration file.
+func (rs ReloadSettings) GetInterval() time.Duration {
+	if rs.Interval == nil {
+		return defaultReloadInterval * time.Second
+	}
+
+	return time.Duration(*rs.Interval) * time.Second
 }
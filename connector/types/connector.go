@@ -2,6 +2,7 @@ package types
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/hasura/ndc-sdk-go/v2/connector"
 	"github.com/hasura/ndc-storage/connector/storage"
@@ -15,11 +16,44 @@ const (
 )
 
 // State is the global state which is shared for every connector request.
+// The storage manager and concurrency settings are swapped atomically when the configuration is reloaded.
 type State struct {
 	*connector.TelemetryState
 
-	Storage     *storage.Manager
-	Concurrency ConcurrencySettings
+	storage     atomic.Pointer[storage.Manager]
+	concurrency atomic.Pointer[ConcurrencySettings]
+}
+
+// NewState creates a new connector state.
+func NewState(
+	telemetry *connector.TelemetryState,
+	manager *storage.Manager,
+	concurrency ConcurrencySettings,
+) *State {
+	state := &State{
+		TelemetryState: telemetry,
+	}
+
+	state.Reload(manager, concurrency)
+
+	return state
+}
+
+// Storage returns the current storage manager.
+func (s *State) Storage() *storage.Manager {
+	return s.storage.Load()
+}
+
+// Concurrency returns the current concurrency settings.
+func (s *State) Concurrency() ConcurrencySettings {
+	return *s.concurrency.Load()
+}
+
+// Reload replaces the storage manager and concurrency settings, and returns the previous manager.
+func (s *State) Reload(manager *storage.Manager, concurrency ConcurrencySettings) *storage.Manager {
+	s.concurrency.Store(&concurrency)
+
+	return s.storage.Swap(manager)
 }
 
 // QueryVariablesFromContext gets the query variables from context.
@@ -41,6 +41,9 @@ func jsonSchemaConfiguration() error {
 			{
 				Required: []string{"env"},
 			},
+			{
+				Required: []string{"file"},
+			},
 		},
 	}
 	envString.Properties.Set("env", &jsonschema.Schema{
@@ -49,6 +52,10 @@ func jsonSchemaConfiguration() error {
 	envString.Properties.Set("value", &jsonschema.Schema{
 		Type: "string",
 	})
+	envString.Properties.Set("file", &jsonschema.Schema{
+		Type:        "string",
+		Description: "Path of the file that contains the secret value, e.g. a mounted Kubernetes secret",
+	})
 
 	reflectSchema.Definitions["EnvString"] = envString
 